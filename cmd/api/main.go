@@ -2,7 +2,7 @@ package main
 
 import (
 	"context"
-	"database/sql"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
@@ -11,100 +11,251 @@ import (
 	"time"
 
 	"github.com/joho/godotenv"
-	_ "github.com/lib/pq"
 
 	"github.com/kazshi01/payment-system/internal/auth"
 	"github.com/kazshi01/payment-system/internal/docs"
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/banktransfer"
+	"github.com/kazshi01/payment-system/internal/domain/ledger"
 	"github.com/kazshi01/payment-system/internal/infra/clock"
-	"github.com/kazshi01/payment-system/internal/infra/db"
 	"github.com/kazshi01/payment-system/internal/infra/db/pg"
 	"github.com/kazshi01/payment-system/internal/infra/idgen"
-	"github.com/kazshi01/payment-system/internal/infra/redislocker"
+	"github.com/kazshi01/payment-system/internal/infra/pdf"
+	"github.com/kazshi01/payment-system/internal/infra/receiptpdf"
+	"github.com/kazshi01/payment-system/internal/infra/webhooksender"
 	"github.com/kazshi01/payment-system/internal/interface/httpi"
 	"github.com/kazshi01/payment-system/internal/usecase"
 )
 
 func main() {
+	storageKind := flag.String("storage", "postgres", "postgres or memory (DB・Redis なしで起動する開発用)")
+	flag.Parse()
+
 	// --- .env を読み込む ---
 
-	// 開発時は.envがないとエラーにする
-	if err := godotenv.Load(); err != nil {
+	// 開発時は.envがないとエラーにする（memory は環境変数だけでも起動できる）
+	if err := godotenv.Load(); err != nil && *storageKind != "memory" {
 		log.Fatal("Error loading .env file")
 	}
 
-	user := os.Getenv("POSTGRES_USER")
-	pass := os.Getenv("POSTGRES_PASSWORD")
-	name := os.Getenv("POSTGRES_DB")
-	host := os.Getenv("POSTGRES_HOST")
-	if host == "" {
-		host = "localhost"
+	// --- Repository & Tx & Locker ---
+	var st *storage
+	switch *storageKind {
+	case "postgres":
+		st = newPostgresStorage()
+	case "memory":
+		st = newMemoryStorage()
+	default:
+		log.Fatalf("invalid --storage: %q (postgres or memory)", *storageKind)
 	}
+	defer st.close()
 
-	dsn := fmt.Sprintf("postgres://%s:%s@%s:5432/%s?sslmode=disable", user, pass, host, name)
+	// オーソリ有効期限（例: "168h"）。未設定なら usecase の既定値
+	var authTTL time.Duration
+	if v := os.Getenv("AUTHORIZATION_TTL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			log.Fatalf("invalid AUTHORIZATION_TTL: %v", err)
+		}
+		authTTL = d
+	}
 
-	// --- DB 接続 ---
-	sqlDB, err := sql.Open("postgres", dsn)
-	if err != nil {
-		log.Fatal(err)
+	// コンビニ払いの支払期限（例: "72h"）。未設定なら usecase の既定値
+	var konbiniTTL time.Duration
+	if v := os.Getenv("KONBINI_PAYMENT_TTL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			log.Fatalf("invalid KONBINI_PAYMENT_TTL: %v", err)
+		}
+		konbiniTTL = d
 	}
 
-	defer sqlDB.Close()
+	// PENDING の注文を自動で取り消すまでの期間（例: "72h"）。未設定なら usecase の既定値
+	var pendingTTL time.Duration
+	if v := os.Getenv("PENDING_ORDER_TTL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			log.Fatalf("invalid PENDING_ORDER_TTL: %v", err)
+		}
+		pendingTTL = d
+	}
 
-	if err := sqlDB.Ping(); err != nil {
-		log.Fatal(err)
+	// Idempotency-Key の保持期間（例: "24h"）。未設定なら httpi の既定値
+	var idemTTL time.Duration
+	if v := os.Getenv("IDEMPOTENCY_KEY_TTL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			log.Fatalf("invalid IDEMPOTENCY_KEY_TTL: %v", err)
+		}
+		idemTTL = d
+	}
+
+	// 元帳に計上する PG 手数料率（ベーシスポイント。例: "360" = 3.6%）。未設定なら計上しない
+	var feeRate ledger.FeeRate
+	if v := os.Getenv("PAYMENT_FEE_BPS"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 || n > 10_000 {
+			log.Fatalf("invalid PAYMENT_FEE_BPS: %q (0-10000)", v)
+		}
+		feeRate = ledger.FeeRate(n)
 	}
 
-	log.Println("DB connected")
+	// --- Payment Gateway ---
+	// PAYMENT_GATEWAY=stripe で Stripe 互換 API（ローカルは cmd/fakepg）、未設定ならモック
+	var (
+		gateway  domain.PaymentGateway = pg.Nop{}
+		konbini  domain.KonbiniGateway = pg.Nop{}
+		provider                       = pg.NopProvider
+	)
+	verifiers := map[string]httpi.WebhookVerifier{}
+	if os.Getenv("PAYMENT_GATEWAY") == "stripe" {
+		s, err := pg.NewStripe(pg.StripeConfig{
+			BaseURL:   os.Getenv("STRIPE_BASE_URL"),
+			SecretKey: os.Getenv("STRIPE_SECRET_KEY"),
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		gateway, konbini, provider = s, s, pg.StripeProvider
 
-	// Redis
-	raddr := os.Getenv("REDIS_ADDR")
-	rpass := os.Getenv("REDIS_PASSWORD")
-	rdbStr := os.Getenv("REDIS_DB")
+		// Webhook は署名シークレットがある場合のみ受け付ける
+		if secret := os.Getenv("STRIPE_WEBHOOK_SECRET"); secret != "" {
+			wh, err := pg.NewStripeWebhook(secret)
+			if err != nil {
+				log.Fatal(err)
+			}
+			verifiers[pg.StripeProvider] = wh
+		}
+	}
+	log.Printf("Payment gateway: %s", provider)
 
-	rdb := 0
-	if rdbStr != "" {
-		i, err := strconv.Atoi(rdbStr)
+	// --- Bank transfer ---
+	// BANK_TRANSFER_BANK_CODE があれば銀行振込を受け付ける
+	var bank domain.BankTransferGateway
+	if os.Getenv("BANK_TRANSFER_BANK_CODE") != "" {
+		b, err := newBankTransfer()
 		if err != nil {
 			log.Fatal(err)
 		}
-		rdb = i
+		bank = b
+		log.Println("Bank transfer: enabled")
 	}
 
-	locker := redislocker.New(raddr, rpass, rdb)
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-	defer cancel()
+	// --- Usecase ---
+	orderUC := &usecase.OrderUsecase{
+		Repo:     st.orders,
+		Items:    st.items,
+		Taxes:    st.taxes,
+		Coupons:  st.coupons,
+		Payments: st.payments,
+		Events:   st.events,
+		Refunds:  st.refunds,
+		Auths:    st.auths,
+		Outbox:   st.outbox,
+		Ledger:   st.ledger,
+		Tx:       st.tx,
+		PG:       gateway,
+		Provider: provider,
+		Clock:    clock.System{},
+		IDGen:    idgen.UUIDGen{},
+		Locker:   st.locker,
+
+		Bank:      bank,
+		Transfers: st.transfers,
+
+		Konbini:    konbini,
+		Konbinis:   st.konbinis,
+		KonbiniTTL: konbiniTTL,
 
-	if err := locker.Ping(ctx); err != nil {
-		log.Fatalf("redis ping failed (addr=%s db=%d): %v", raddr, rdb, err)
+		AuthorizationTTL: authTTL,
+		PendingOrderTTL:  pendingTTL,
+		Merchants:        st.merchants,
+		FeeRate:          feeRate,
 	}
-	defer func() {
-		if err := locker.Close(); err != nil {
-			log.Printf("warn: redis close: %v", err)
-		}
-	}()
 
-	log.Println("Redis connected")
+	// --- 期限切れオーソリの自動取り消し ---
+	go voidExpiredAuthorizationsLoop(orderUC, time.Minute)
 
-	// --- Repository & Tx ---
-	repo := db.NewPostgresOrderRepository(sqlDB)
-	txMgr := &db.TxManager{DB: sqlDB}
+	// --- 支払期限切れのコンビニ払いの取り消し ---
+	go expireKonbiniPaymentsLoop(orderUC, time.Minute)
 
-	// --- Payment Gateway ---
-	gateway := pg.Nop{} // まだモック
+	// --- 放置された PENDING 注文の取り消し（複数台でも1台だけが行う） ---
+	go expireStaleOrdersLoop(orderUC, time.Minute)
 
-	// --- Usecase ---
-	orderUC := &usecase.OrderUsecase{
-		Repo:   repo,
-		Tx:     txMgr,
-		PG:     gateway,
+	// --- 期限切れ Idempotency-Key の削除 ---
+	go deleteExpiredIdempotencyKeysLoop(st.idem, time.Minute)
+
+	webhookUC := &usecase.WebhookUsecase{
+		Orders: orderUC,
+		Inbox:  st.inbox,
 		Clock:  clock.System{},
-		IDGen:  idgen.UUIDGen{},
-		Locker: locker,
+	}
+
+	// 配信（送信）は cmd/relay が行う。API は登録と手動再送のみ
+	merchantWebhookUC := &usecase.MerchantWebhookUsecase{
+		Endpoints:  st.endpoints,
+		Deliveries: st.deliveries,
+		Orders:     st.orders,
+		Sender:     webhooksender.New(10 * time.Second),
+		Tx:         st.tx,
+		Clock:      clock.System{},
+		IDGen:      idgen.UUIDGen{},
+	}
+
+	merchantSettingsUC := &usecase.MerchantSettingsUsecase{
+		Repo:  st.merchants,
+		Clock: clock.System{},
+	}
+
+	receiptUC := &usecase.ReceiptUsecase{
+		Orders:    orderUC,
+		Receipts:  st.receipts,
+		Merchants: st.merchants,
+		Renderer:  receiptpdf.New(receiptFont()),
+		Tx:        st.tx,
+		Clock:     clock.System{},
+	}
+
+	couponUC := &usecase.CouponUsecase{
+		Repo:  st.coupons,
+		Clock: clock.System{},
+		IDGen: idgen.UUIDGen{},
+	}
+
+	ledgerUC := &usecase.LedgerUsecase{Repo: st.ledger}
+
+	// 突き合わせは cmd/reconcile が行う。API は結果の参照のみ
+	reconciliationUC := &usecase.ReconciliationUsecase{
+		Payments: st.payments,
+		Runs:     st.recons,
+		Clock:    clock.System{},
+		IDGen:    idgen.UUIDGen{},
 	}
 
 	// --- OrderHandler ---
 	handler := &httpi.OrderHandler{UC: orderUC}
 
+	// --- WebhookHandler ---
+	webhookH := &httpi.WebhookHandler{UC: webhookUC, Verifiers: verifiers}
+
+	// --- WebhookEndpointHandler ---
+	webhookEndpointH := &httpi.WebhookEndpointHandler{UC: merchantWebhookUC}
+
+	// --- MerchantSettingsHandler ---
+	merchantSettingsH := &httpi.MerchantSettingsHandler{UC: merchantSettingsUC}
+
+	// --- ReceiptHandler ---
+	receiptH := &httpi.ReceiptHandler{UC: receiptUC}
+
+	// --- CouponHandler ---
+	couponH := &httpi.CouponHandler{UC: couponUC}
+
+	// --- LedgerHandler ---
+	ledgerH := &httpi.LedgerHandler{UC: ledgerUC}
+
+	// --- ReconciliationHandler ---
+	reconciliationH := &httpi.ReconciliationHandler{UC: reconciliationUC}
+
 	// --- AuthHandler ---
 	authH, err := httpi.NewAuthHandler(context.Background())
 	if err != nil {
@@ -121,11 +272,48 @@ func main() {
 		log.Fatal(err)
 	}
 
+	idem := httpi.Idempotency(httpi.IdempotencyConfig{Store: st.idem, TTL: idemTTL})
+
 	mux := http.NewServeMux()
 	mux.HandleFunc("GET /", httpi.Home)
 
-	mux.Handle("POST /orders", mw(http.HandlerFunc(handler.Create)))
+	mux.Handle("POST /orders", mw(idem(http.HandlerFunc(handler.Create))))
+	mux.Handle("GET /orders", mw(http.HandlerFunc(handler.List)))
+	mux.Handle("GET /orders/{id}", mw(http.HandlerFunc(handler.Get)))
 	mux.Handle("POST /orders/{id}/pay", mw(http.HandlerFunc(handler.Pay)))
+	mux.Handle("POST /orders/{id}/cancel", mw(http.HandlerFunc(handler.Cancel)))
+	mux.Handle("POST /orders/{id}/authorize", mw(http.HandlerFunc(handler.Authorize)))
+	mux.Handle("POST /orders/{id}/capture", mw(http.HandlerFunc(handler.Capture)))
+	mux.Handle("POST /orders/{id}/void", mw(http.HandlerFunc(handler.Void)))
+	mux.Handle("POST /orders/{id}/refunds", mw(http.HandlerFunc(handler.Refund)))
+	mux.Handle("GET /orders/{id}/payments", mw(http.HandlerFunc(handler.ListPayments)))
+	mux.Handle("GET /orders/{id}/events", mw(http.HandlerFunc(handler.ListEvents)))
+	mux.Handle("GET /orders/{id}/receipt.pdf", mw(http.HandlerFunc(receiptH.Get)))
+	mux.Handle("POST /orders/{id}/bank-transfer", mw(http.HandlerFunc(handler.IssueBankTransfer)))
+
+	mux.Handle("POST /bank-statements", mw(http.HandlerFunc(handler.ImportBankStatement)))
+	mux.Handle("GET /bank-deposits", mw(http.HandlerFunc(handler.ListBankDeposits)))
+
+	mux.Handle("POST /webhook-endpoints", mw(http.HandlerFunc(webhookEndpointH.Create)))
+	mux.Handle("GET /webhook-endpoints", mw(http.HandlerFunc(webhookEndpointH.List)))
+	mux.Handle("DELETE /webhook-endpoints/{id}", mw(http.HandlerFunc(webhookEndpointH.Delete)))
+	mux.Handle("GET /webhook-endpoints/{id}/deliveries", mw(http.HandlerFunc(webhookEndpointH.ListDeliveries)))
+	mux.Handle("GET /webhook-deliveries/{id}", mw(http.HandlerFunc(webhookEndpointH.GetDelivery)))
+	mux.Handle("POST /webhook-deliveries/{id}/redeliver", mw(http.HandlerFunc(webhookEndpointH.Redeliver)))
+
+	mux.Handle("GET /merchant/settings", mw(http.HandlerFunc(merchantSettingsH.Get)))
+	mux.Handle("PUT /merchant/settings", mw(http.HandlerFunc(merchantSettingsH.Put)))
+
+	mux.Handle("POST /coupons", mw(http.HandlerFunc(couponH.Create)))
+	mux.Handle("GET /coupons/{code}", mw(http.HandlerFunc(couponH.Get)))
+
+	mux.Handle("GET /ledger/accounts/{account}/balance", mw(http.HandlerFunc(ledgerH.Balance)))
+
+	mux.Handle("GET /reconciliation-runs", mw(http.HandlerFunc(reconciliationH.List)))
+	mux.Handle("GET /reconciliation-runs/{id}", mw(http.HandlerFunc(reconciliationH.Get)))
+
+	// PG からの通知（署名検証のみ、OIDC 不要）
+	mux.HandleFunc("POST /webhooks/{provider}", webhookH.Receive)
 
 	mux.HandleFunc("GET /auth/login", authH.Login)
 	mux.HandleFunc("GET /auth/callback", authH.Callback)
@@ -147,3 +335,114 @@ func main() {
 	log.Println("Listening on :8080")
 	log.Fatal(http.ListenAndServe(":8080", mux))
 }
+
+// 一定間隔で期限切れオーソリを取り消す（1回あたり最大100件）
+func voidExpiredAuthorizationsLoop(uc *usecase.OrderUsecase, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for range t.C {
+		n, err := uc.VoidExpiredAuthorizations(context.Background(), 100)
+		if err != nil {
+			log.Printf("warn: void expired authorizations: %v", err)
+			continue
+		}
+		if n > 0 {
+			log.Printf("voided %d expired authorizations", n)
+		}
+	}
+}
+
+// 一定間隔で支払期限を過ぎたコンビニ払いの注文を取り消す（1回あたり最大100件）
+func expireKonbiniPaymentsLoop(uc *usecase.OrderUsecase, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for range t.C {
+		n, err := uc.ExpireKonbiniPayments(context.Background(), 100)
+		if err != nil {
+			log.Printf("warn: expire konbini payments: %v", err)
+			continue
+		}
+		if n > 0 {
+			log.Printf("canceled %d orders with expired konbini payments", n)
+		}
+	}
+}
+
+// 一定間隔で放置された PENDING 注文を取り消す（100件ずつ、対象がなくなるまで）
+func expireStaleOrdersLoop(uc *usecase.OrderUsecase, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for range t.C {
+		n, err := uc.ExpireStaleOrders(context.Background(), 100)
+		if err != nil {
+			log.Printf("warn: expire stale orders: %v", err)
+		}
+		if n > 0 {
+			log.Printf("canceled %d stale pending orders", n)
+		}
+	}
+}
+
+// 一定間隔で期限切れの Idempotency-Key を削除する（1回あたり最大1000件）
+func deleteExpiredIdempotencyKeysLoop(store domain.IdempotencyStore, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for range t.C {
+		if _, err := store.DeleteExpired(context.Background(), time.Now(), 1000); err != nil {
+			log.Printf("warn: delete expired idempotency keys: %v", err)
+		}
+	}
+}
+
+// RECEIPT_FONT_FILE の TrueType フォント（.ttf）を領収書に埋め込む。
+// 未設定なら埋め込まない標準の日本語フォントを使う（見た目はビューア依存）
+func receiptFont() pdf.Font {
+	path := os.Getenv("RECEIPT_FONT_FILE")
+	if path == "" {
+		log.Println("warn: RECEIPT_FONT_FILE is not set; receipts use a non-embedded Japanese font")
+		return pdf.StandardJapaneseFont()
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		log.Fatalf("read RECEIPT_FONT_FILE: %v", err)
+	}
+	f, err := pdf.ParseTrueType(b)
+	if err != nil {
+		log.Fatalf("RECEIPT_FONT_FILE %s: %v", path, err)
+	}
+	log.Printf("Receipt font: %s", path)
+	return f
+}
+
+// BANK_TRANSFER_* から振込先の発行を組み立てる。
+// BANK_TRANSFER_VA_FROM / _TO を指定すればバーチャル口座、なければ共通口座 + 振込依頼人コード
+func newBankTransfer() (*pg.BankTransfer, error) {
+	cfg := pg.BankTransferConfig{
+		Account: banktransfer.Destination{
+			BankCode:      os.Getenv("BANK_TRANSFER_BANK_CODE"),
+			BankName:      os.Getenv("BANK_TRANSFER_BANK_NAME"),
+			BranchCode:    os.Getenv("BANK_TRANSFER_BRANCH_CODE"),
+			BranchName:    os.Getenv("BANK_TRANSFER_BRANCH_NAME"),
+			AccountType:   banktransfer.AccountType(os.Getenv("BANK_TRANSFER_ACCOUNT_TYPE")),
+			AccountNumber: os.Getenv("BANK_TRANSFER_ACCOUNT_NUMBER"),
+			AccountName:   os.Getenv("BANK_TRANSFER_ACCOUNT_NAME"),
+		},
+	}
+	for name, p := range map[string]*int{
+		"BANK_TRANSFER_VA_FROM": &cfg.VirtualAccountFrom,
+		"BANK_TRANSFER_VA_TO":   &cfg.VirtualAccountTo,
+	} {
+		if v := os.Getenv(name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %q", name, v)
+			}
+			*p = n
+		}
+	}
+	return pg.NewBankTransfer(cfg)
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	_ "github.com/lib/pq"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/infra/db"
+	"github.com/kazshi01/payment-system/internal/infra/memory"
+	"github.com/kazshi01/payment-system/internal/infra/redislocker"
+)
+
+// storage は API が使うリポジトリ・Tx・Locker 一式
+type storage struct {
+	orders     domain.OrderRepository
+	items      domain.OrderItemRepository
+	taxes      domain.OrderTaxRepository
+	merchants  domain.MerchantSettingsRepository
+	coupons    domain.CouponRepository
+	receipts   domain.ReceiptRepository
+	payments   domain.PaymentRepository
+	events     domain.EventRepository
+	refunds    domain.RefundRepository
+	ledger     domain.LedgerRepository
+	recons     domain.ReconciliationRunRepository
+	auths      domain.AuthorizationRepository
+	konbinis   domain.KonbiniRepository
+	transfers  domain.BankTransferRepository
+	inbox      domain.WebhookInbox
+	idem       domain.IdempotencyStore
+	outbox     domain.OutboxRepository // nil なら下流へは配信しない
+	endpoints  domain.WebhookEndpointRepository
+	deliveries domain.WebhookDeliveryRepository
+	tx         domain.Tx
+	locker     domain.Locker
+
+	close func()
+}
+
+// Postgres と（LOCKER に応じて）Redis に接続する
+func newPostgresStorage() *storage {
+	user := os.Getenv("POSTGRES_USER")
+	pass := os.Getenv("POSTGRES_PASSWORD")
+	name := os.Getenv("POSTGRES_DB")
+	host := os.Getenv("POSTGRES_HOST")
+	if host == "" {
+		host = "localhost"
+	}
+
+	dsn := fmt.Sprintf("postgres://%s:%s@%s:5432/%s?sslmode=disable", user, pass, host, name)
+
+	// --- DB 接続 ---
+	sqlDB, err := sql.Open("postgres", dsn)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := sqlDB.Ping(); err != nil {
+		log.Fatal(err)
+	}
+
+	log.Println("DB connected")
+
+	// --- Locker ---
+	// LOCKER=postgres で advisory lock（Redis 不要）、未設定なら Redis
+	var locker domain.Locker
+	switch os.Getenv("LOCKER") {
+	case "", "redis":
+		locker = newRedisLocker()
+	case "postgres":
+		locker = db.NewPostgresLocker(sqlDB, 0)
+	default:
+		log.Fatalf("invalid LOCKER: %q (redis or postgres)", os.Getenv("LOCKER"))
+	}
+
+	return &storage{
+		orders:     db.NewPostgresOrderRepository(sqlDB),
+		items:      db.NewPostgresOrderItemRepository(sqlDB),
+		taxes:      db.NewPostgresOrderTaxRepository(sqlDB),
+		merchants:  db.NewPostgresMerchantSettingsRepository(sqlDB),
+		coupons:    db.NewPostgresCouponRepository(sqlDB),
+		receipts:   db.NewPostgresReceiptRepository(sqlDB),
+		payments:   db.NewPostgresPaymentRepository(sqlDB),
+		events:     db.NewPostgresEventRepository(sqlDB),
+		refunds:    db.NewPostgresRefundRepository(sqlDB),
+		ledger:     db.NewPostgresLedgerRepository(sqlDB),
+		recons:     db.NewPostgresReconciliationRunRepository(sqlDB),
+		auths:      db.NewPostgresAuthorizationRepository(sqlDB),
+		konbinis:   db.NewPostgresKonbiniRepository(sqlDB),
+		transfers:  db.NewPostgresBankTransferRepository(sqlDB),
+		inbox:      db.NewPostgresWebhookInbox(sqlDB),
+		idem:       db.NewPostgresIdempotencyStore(sqlDB),
+		outbox:     db.NewPostgresOutboxRepository(sqlDB),
+		endpoints:  db.NewPostgresWebhookEndpointRepository(sqlDB),
+		deliveries: db.NewPostgresWebhookDeliveryRepository(sqlDB),
+		tx:         &db.TxManager{DB: sqlDB},
+		locker:     locker,
+		close: func() {
+			if err := locker.Close(); err != nil {
+				log.Printf("warn: locker close: %v", err)
+			}
+			_ = sqlDB.Close()
+		},
+	}
+}
+
+// プロセス内のメモリだけで動かす（再起動で消える）
+// outbox を読む relay が無いため、下流・加盟店 Webhook への配信は行わない
+func newMemoryStorage() *storage {
+	s := memory.NewStore()
+
+	log.Println("Storage: memory (data is lost on restart)")
+
+	return &storage{
+		orders:     memory.NewOrderRepository(s),
+		items:      memory.NewOrderItemRepository(s),
+		taxes:      memory.NewOrderTaxRepository(s),
+		merchants:  memory.NewMerchantSettingsRepository(s),
+		coupons:    memory.NewCouponRepository(s),
+		receipts:   memory.NewReceiptRepository(s),
+		payments:   memory.NewPaymentRepository(s),
+		events:     memory.NewEventRepository(s),
+		refunds:    memory.NewRefundRepository(s),
+		ledger:     memory.NewLedgerRepository(s),
+		recons:     memory.NewReconciliationRunRepository(s),
+		auths:      memory.NewAuthorizationRepository(s),
+		konbinis:   memory.NewKonbiniRepository(s),
+		transfers:  memory.NewBankTransferRepository(s),
+		inbox:      memory.NewWebhookInbox(s),
+		idem:       memory.NewIdempotencyStore(s),
+		endpoints:  memory.NewWebhookEndpointRepository(s),
+		deliveries: memory.NewWebhookDeliveryRepository(s),
+		tx:         s,
+		locker:     memory.NewLocker(),
+		close:      func() {},
+	}
+}
+
+// REDIS_* の設定で Redis に接続する（疎通できなければ起動しない）
+func newRedisLocker() *redislocker.Locker {
+	raddr := os.Getenv("REDIS_ADDR")
+	rpass := os.Getenv("REDIS_PASSWORD")
+	rdbStr := os.Getenv("REDIS_DB")
+
+	rdb := 0
+	if rdbStr != "" {
+		i, err := strconv.Atoi(rdbStr)
+		if err != nil {
+			log.Fatal(err)
+		}
+		rdb = i
+	}
+
+	locker := redislocker.New(raddr, rpass, rdb)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if err := locker.Ping(ctx); err != nil {
+		log.Fatalf("redis ping failed (addr=%s db=%d): %v", raddr, rdb, err)
+	}
+
+	log.Println("Redis connected")
+	return locker
+}
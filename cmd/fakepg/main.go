@@ -0,0 +1,35 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/infra/db/pg/fakepg"
+)
+
+// ローカル開発用の Stripe 互換フェイク PG
+//
+//	go run ./cmd/fakepg -addr :12111
+//	PAYMENT_GATEWAY=stripe STRIPE_BASE_URL=http://localhost:12111 STRIPE_SECRET_KEY=sk_test_fake make dev
+//
+// -webhook-url を指定すると、コンビニ払いの店頭支払い（POST /__fake/payment_intents/{id}/pay）を
+// STRIPE_WEBHOOK_SECRET で署名した payment_intent.succeeded として送る
+func main() {
+	addr := flag.String("addr", ":12111", "listen address")
+	delay := flag.Duration("timeout-delay", 30*time.Second, "delay for scripted timeouts")
+	webhookURL := flag.String("webhook-url", "", "where to send webhook events (e.g. http://localhost:8080/webhooks/stripe)")
+	flag.Parse()
+
+	srv := fakepg.New(fakepg.Config{
+		SecretKey:     os.Getenv("STRIPE_SECRET_KEY"),
+		TimeoutDelay:  *delay,
+		WebhookURL:    *webhookURL,
+		WebhookSecret: os.Getenv("STRIPE_WEBHOOK_SECRET"),
+	})
+
+	log.Printf("fakepg listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, srv))
+}
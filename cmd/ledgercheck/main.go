@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/joho/godotenv"
+	_ "github.com/lib/pq"
+
+	"github.com/kazshi01/payment-system/internal/infra/db"
+	"github.com/kazshi01/payment-system/internal/usecase"
+)
+
+// 元帳の不変条件を検査する。違反があれば一覧を出して終了コード 1
+//
+//	go run ./cmd/ledgercheck -limit 100
+//
+// - すべての仕訳で、通貨ごとの明細の合計が 0
+// - 支払い済み（返金済みを含む）の注文に、決済額どおりの売上計上と返金額どおりの返金計上がある
+func main() {
+	limit := flag.Int("limit", 100, "violations to report per check")
+	timeout := flag.Duration("timeout", 5*time.Minute, "give up after this long")
+	flag.Parse()
+
+	// 開発時は.envがないとエラーにする
+	if err := godotenv.Load(); err != nil {
+		log.Fatal("Error loading .env file")
+	}
+
+	host := os.Getenv("POSTGRES_HOST")
+	if host == "" {
+		host = "localhost"
+	}
+	dsn := fmt.Sprintf("postgres://%s:%s@%s:5432/%s?sslmode=disable",
+		os.Getenv("POSTGRES_USER"), os.Getenv("POSTGRES_PASSWORD"), host, os.Getenv("POSTGRES_DB"))
+
+	// --- DB 接続 ---
+	sqlDB, err := sql.Open("postgres", dsn)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer sqlDB.Close()
+
+	if err := sqlDB.Ping(); err != nil {
+		log.Fatal(err)
+	}
+
+	uc := &usecase.LedgerUsecase{Repo: db.NewPostgresLedgerRepository(sqlDB)}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	vs, err := uc.Check(ctx, *limit)
+	if err != nil {
+		log.Fatalf("ledger check: %v", err)
+	}
+	for _, v := range vs {
+		fmt.Println(v)
+	}
+	if len(vs) > 0 {
+		log.Fatalf("ledger check: %d violation(s)", len(vs))
+	}
+	log.Println("ledger check: ok")
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/joho/godotenv"
+	_ "github.com/lib/pq"
+
+	"github.com/kazshi01/payment-system/internal/domain/money"
+	"github.com/kazshi01/payment-system/internal/domain/settlement"
+	"github.com/kazshi01/payment-system/internal/infra/clock"
+	"github.com/kazshi01/payment-system/internal/infra/db"
+	"github.com/kazshi01/payment-system/internal/infra/idgen"
+	"github.com/kazshi01/payment-system/internal/usecase"
+)
+
+// 日付は日本時間で区切る
+var jst = time.FixedZone("JST", 9*60*60)
+
+// PG の精算ファイル（CSV）を payments と突き合わせ、結果を reconciliation_runs に記録する
+//
+//	go run ./cmd/reconcile -file settlement.csv -date 2026-10-01
+//
+// 列の既定値は Stripe の Itemized payout reconciliation レポート（金額は主単位、返金・手数料の行は除く）
+func main() {
+	file := flag.String("file", "", "settlement CSV (required)")
+	provider := flag.String("provider", "stripe", "payments.provider to reconcile")
+	date := flag.String("date", "", "JST day of our payments to compare (YYYY-MM-DD, default yesterday)")
+	from := flag.String("from", "", "start of the period (RFC3339, overrides -date)")
+	to := flag.String("to", "", "end of the period, exclusive (RFC3339, overrides -date)")
+	timeout := flag.Duration("timeout", 5*time.Minute, "give up after this long")
+
+	var m settlement.Mapping
+	var currency, unit string
+	flag.StringVar(&m.TxID, "tx-id-column", "payment_intent_id", "column of the provider transaction ID")
+	flag.StringVar(&m.Amount, "amount-column", "gross", "column of the amount")
+	flag.StringVar(&m.Currency, "currency-column", "currency", "column of the currency (empty to use -currency)")
+	flag.StringVar(&currency, "currency", "", "currency when the file has no currency column")
+	flag.StringVar(&unit, "amount-unit", string(settlement.UnitMajor), "major (12.34) or minor (1234)")
+	flag.StringVar(&m.Category, "category-column", "reporting_category", "column to filter rows by (empty to read all rows)")
+	flag.StringVar(&m.CategoryValue, "category", "charge", "value of -category-column to read")
+	flag.Parse()
+
+	if *file == "" {
+		log.Fatal("-file is required")
+	}
+	m.Unit = settlement.AmountUnit(unit)
+	if m.Category == "" {
+		m.CategoryValue = ""
+	}
+	if currency != "" {
+		c, err := money.ParseCurrency(currency)
+		if err != nil {
+			log.Fatalf("invalid -currency: %v", err)
+		}
+		m.DefaultCurrency = c
+	}
+	start, end, err := period(*date, *from, *to)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	f, err := os.Open(*file)
+	if err != nil {
+		log.Fatal(err)
+	}
+	rows, err := settlement.Parse(f, m)
+	f.Close()
+	if err != nil {
+		log.Fatalf("%s: %v", *file, err)
+	}
+
+	// 開発時は.envがないとエラーにする
+	if err := godotenv.Load(); err != nil {
+		log.Fatal("Error loading .env file")
+	}
+
+	host := os.Getenv("POSTGRES_HOST")
+	if host == "" {
+		host = "localhost"
+	}
+	dsn := fmt.Sprintf("postgres://%s:%s@%s:5432/%s?sslmode=disable",
+		os.Getenv("POSTGRES_USER"), os.Getenv("POSTGRES_PASSWORD"), host, os.Getenv("POSTGRES_DB"))
+
+	// --- DB 接続 ---
+	sqlDB, err := sql.Open("postgres", dsn)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer sqlDB.Close()
+
+	if err := sqlDB.Ping(); err != nil {
+		log.Fatal(err)
+	}
+
+	uc := &usecase.ReconciliationUsecase{
+		Payments: db.NewPostgresPaymentRepository(sqlDB),
+		Runs:     db.NewPostgresReconciliationRunRepository(sqlDB),
+		Clock:    clock.System{},
+		IDGen:    idgen.UUIDGen{},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	run, err := uc.Reconcile(ctx, usecase.ReconcileInput{
+		Provider: *provider,
+		Source:   filepath.Base(*file),
+		From:     start,
+		To:       end,
+		Rows:     rows,
+	})
+	if err != nil {
+		log.Fatalf("reconcile: %v", err)
+	}
+
+	for _, it := range run.Items {
+		if it.Status != settlement.StatusMatched {
+			fmt.Printf("%s\t%s\tours=%s\ttheirs=%s\n", it.Status, it.TxID, amount(it.Ours), amount(it.Theirs))
+		}
+	}
+	s := run.Summary
+	log.Printf("reconciliation run %s: %s [%s, %s) matched=%d amount_mismatch=%d missing_in_ours=%d missing_in_theirs=%d",
+		run.ID, run.Source, start.Format(time.RFC3339), end.Format(time.RFC3339),
+		s.Matched, s.AmountMismatch, s.MissingInOurs, s.MissingInTheirs)
+}
+
+// 比べる期間。-from / -to があればそれを、なければ -date（既定は昨日）の日本時間の 1 日
+func period(date, from, to string) (time.Time, time.Time, error) {
+	if from != "" || to != "" {
+		start, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid -from: %w", err)
+		}
+		end, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid -to: %w", err)
+		}
+		return start, end, nil
+	}
+
+	var day time.Time
+	if date == "" {
+		y, m, d := time.Now().In(jst).AddDate(0, 0, -1).Date()
+		day = time.Date(y, m, d, 0, 0, 0, 0, jst)
+	} else {
+		var err error
+		if day, err = time.ParseInLocation("2006-01-02", date, jst); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid -date: %w", err)
+		}
+	}
+	return day, day.AddDate(0, 0, 1), nil
+}
+
+func amount(m money.Money) string {
+	if m.Currency == "" {
+		return "-"
+	}
+	return m.String()
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/joho/godotenv"
+	_ "github.com/lib/pq"
+
+	"github.com/kazshi01/payment-system/internal/infra/clock"
+	"github.com/kazshi01/payment-system/internal/infra/db"
+	"github.com/kazshi01/payment-system/internal/infra/idgen"
+	"github.com/kazshi01/payment-system/internal/infra/redisstream"
+	"github.com/kazshi01/payment-system/internal/infra/webhooksender"
+	"github.com/kazshi01/payment-system/internal/usecase"
+)
+
+// outbox の未配信メッセージを Redis Streams と加盟店 Webhook に配信する
+//
+//	go run ./cmd/relay -interval 1s
+func main() {
+	interval := flag.Duration("interval", time.Second, "polling interval when the outbox is drained")
+	batch := flag.Int("batch", 100, "messages per batch")
+	maxAttempts := flag.Int("max-attempts", 20, "give up a message after this many failures")
+	lease := flag.Duration("lease", time.Minute, "how long claimed messages are hidden from other relays")
+	webhookInterval := flag.Duration("webhook-interval", 5*time.Second, "polling interval for merchant webhook deliveries")
+	flag.Parse()
+
+	// 開発時は.envがないとエラーにする
+	if err := godotenv.Load(); err != nil {
+		log.Fatal("Error loading .env file")
+	}
+
+	host := os.Getenv("POSTGRES_HOST")
+	if host == "" {
+		host = "localhost"
+	}
+	dsn := fmt.Sprintf("postgres://%s:%s@%s:5432/%s?sslmode=disable",
+		os.Getenv("POSTGRES_USER"), os.Getenv("POSTGRES_PASSWORD"), host, os.Getenv("POSTGRES_DB"))
+
+	// --- DB 接続 ---
+	sqlDB, err := sql.Open("postgres", dsn)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer sqlDB.Close()
+
+	if err := sqlDB.Ping(); err != nil {
+		log.Fatal(err)
+	}
+
+	// --- Redis Streams ---
+	rdb := 0
+	if v := os.Getenv("REDIS_DB"); v != "" {
+		i, err := strconv.Atoi(v)
+		if err != nil {
+			log.Fatal(err)
+		}
+		rdb = i
+	}
+	var maxLen int64
+	if v := os.Getenv("OUTBOX_STREAM_MAXLEN"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			log.Fatalf("invalid OUTBOX_STREAM_MAXLEN: %v", err)
+		}
+		maxLen = n
+	}
+
+	pub := redisstream.New(os.Getenv("REDIS_ADDR"), os.Getenv("REDIS_PASSWORD"), rdb, os.Getenv("OUTBOX_STREAM"), maxLen)
+	defer func() {
+		if err := pub.Close(); err != nil {
+			log.Printf("warn: redis close: %v", err)
+		}
+	}()
+
+	pingCtx, cancelPing := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancelPing()
+	if err := pub.Ping(pingCtx); err != nil {
+		log.Fatalf("redis ping failed: %v", err)
+	}
+
+	txMgr := &db.TxManager{DB: sqlDB}
+
+	// 加盟店 Webhook: outbox から配信レコードを作り、別ループで送信する
+	merchantWebhookUC := &usecase.MerchantWebhookUsecase{
+		Endpoints:  db.NewPostgresWebhookEndpointRepository(sqlDB),
+		Deliveries: db.NewPostgresWebhookDeliveryRepository(sqlDB),
+		Orders:     db.NewPostgresOrderRepository(sqlDB),
+		Sender:     webhooksender.New(10 * time.Second),
+		Tx:         txMgr,
+		Clock:      clock.System{},
+		IDGen:      idgen.UUIDGen{},
+	}
+
+	relay := &usecase.OutboxRelay{
+		Outbox:      db.NewPostgresOutboxRepository(sqlDB),
+		Publisher:   usecase.MultiPublisher{pub, merchantWebhookUC},
+		Tx:          txMgr,
+		Clock:       clock.System{},
+		BatchSize:   *batch,
+		MaxAttempts: *maxAttempts,
+		Lease:       *lease,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go deliverWebhooksLoop(ctx, merchantWebhookUC, *webhookInterval)
+
+	log.Printf("relay started (interval=%s batch=%d)", *interval, *batch)
+	for {
+		n, err := relay.RelayOnce(ctx)
+		if err != nil {
+			log.Printf("warn: relay outbox: %v", err)
+		}
+		if n > 0 {
+			log.Printf("relayed %d messages", n)
+		}
+
+		// バッチが埋まっていれば残りがあるので待たずに続ける
+		if err == nil && n >= *batch {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Println("relay stopped")
+			return
+		case <-time.After(*interval):
+		}
+	}
+}
+
+// 一定間隔で送信期限が来た加盟店 Webhook を送る（1回あたり最大50件）
+func deliverWebhooksLoop(ctx context.Context, uc *usecase.MerchantWebhookUsecase, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+
+		n, err := uc.DeliverDue(ctx, 50)
+		if err != nil {
+			log.Printf("warn: deliver webhooks: %v", err)
+			continue
+		}
+		if n > 0 {
+			log.Printf("delivered %d webhooks", n)
+		}
+	}
+}
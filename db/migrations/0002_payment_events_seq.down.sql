@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_payment_events_order_created;
+CREATE INDEX idx_payment_events_order_created ON payment_events(order_id, created_at DESC);
+
+ALTER TABLE payment_events DROP COLUMN IF EXISTS seq;
@@ -0,0 +1,5 @@
+-- 同一時刻のイベントでも追記順に並べられるよう連番を持たせる
+ALTER TABLE payment_events ADD COLUMN seq BIGSERIAL NOT NULL;
+
+DROP INDEX IF EXISTS idx_payment_events_order_created;
+CREATE INDEX idx_payment_events_order_created ON payment_events(order_id, created_at, seq);
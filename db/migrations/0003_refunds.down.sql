@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_refunds_order_id;
+DROP TABLE IF EXISTS refunds;
+
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_status_check;
+ALTER TABLE orders ADD CONSTRAINT orders_status_check
+  CHECK (status IN ('PENDING','PAID','CANCELED'));
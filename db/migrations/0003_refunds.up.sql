@@ -0,0 +1,17 @@
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_status_check;
+ALTER TABLE orders ADD CONSTRAINT orders_status_check
+  CHECK (status IN ('PENDING','PAID','CANCELED','PARTIALLY_REFUNDED','REFUNDED'));
+
+CREATE TABLE refunds (
+  id                 TEXT        PRIMARY KEY,
+  order_id           TEXT        NOT NULL REFERENCES orders(id),
+  payment_id         TEXT        NOT NULL REFERENCES payments(id),
+  amount_jpy         BIGINT      NOT NULL CHECK (amount_jpy > 0),
+  reason             TEXT        NOT NULL DEFAULT '',
+  provider_refund_id TEXT        NOT NULL,
+  idempotency_key    TEXT        NOT NULL,
+  created_at         TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT uq_refunds_idempotency_key UNIQUE (idempotency_key)
+);
+
+CREATE INDEX idx_refunds_order_id ON refunds(order_id);
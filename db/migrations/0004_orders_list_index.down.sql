@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_orders_status_created;
+DROP INDEX IF EXISTS idx_orders_created;
+DROP INDEX IF EXISTS idx_orders_user_created;
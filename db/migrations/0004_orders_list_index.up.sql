@@ -0,0 +1,4 @@
+-- GET /orders のキーセットページング（created_at DESC, id DESC）用
+CREATE INDEX idx_orders_user_created ON orders(user_id, created_at DESC, id DESC);
+CREATE INDEX idx_orders_created ON orders(created_at DESC, id DESC);
+CREATE INDEX idx_orders_status_created ON orders(status, created_at DESC, id DESC);
@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_authorizations_active_expires;
+DROP INDEX IF EXISTS uq_authorizations_order_active;
+DROP TABLE IF EXISTS authorizations;
+
+ALTER TABLE payments DROP CONSTRAINT IF EXISTS payments_amount_jpy_check;
+ALTER TABLE payments DROP COLUMN IF EXISTS amount_jpy;
+
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_status_check;
+ALTER TABLE orders ADD CONSTRAINT orders_status_check
+  CHECK (status IN ('PENDING','PAID','CANCELED','PARTIALLY_REFUNDED','REFUNDED'));
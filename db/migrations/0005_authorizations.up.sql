@@ -0,0 +1,27 @@
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_status_check;
+ALTER TABLE orders ADD CONSTRAINT orders_status_check
+  CHECK (status IN ('PENDING','AUTHORIZED','PAID','CANCELED','PARTIALLY_REFUNDED','REFUNDED'));
+
+-- 部分売上確定があるため、確定額は注文金額ではなく決済ごとに持つ
+ALTER TABLE payments ADD COLUMN amount_jpy BIGINT;
+UPDATE payments p SET amount_jpy = o.amount_jpy FROM orders o WHERE o.id = p.order_id;
+ALTER TABLE payments ALTER COLUMN amount_jpy SET NOT NULL;
+ALTER TABLE payments ADD CONSTRAINT payments_amount_jpy_check CHECK (amount_jpy > 0);
+
+CREATE TABLE authorizations (
+  id               TEXT        PRIMARY KEY,
+  order_id         TEXT        NOT NULL REFERENCES orders(id),
+  provider         TEXT        NOT NULL,
+  provider_auth_id TEXT        NOT NULL,
+  amount_jpy       BIGINT      NOT NULL CHECK (amount_jpy > 0),
+  status           TEXT        NOT NULL CHECK (status IN ('AUTHORIZED','CAPTURED','VOIDED')),
+  expires_at       TIMESTAMPTZ NOT NULL,
+  created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT uq_authorizations_provider_auth UNIQUE (provider, provider_auth_id)
+);
+
+-- 1注文につき有効なオーソリは1件まで
+CREATE UNIQUE INDEX uq_authorizations_order_active ON authorizations(order_id) WHERE status = 'AUTHORIZED';
+-- 期限切れオーソリの自動取り消し用
+CREATE INDEX idx_authorizations_active_expires ON authorizations(expires_at) WHERE status = 'AUTHORIZED';
@@ -0,0 +1,10 @@
+-- PG から受信した Webhook（イベントID で重複排除）
+CREATE TABLE webhook_events (
+  provider     TEXT        NOT NULL,
+  event_id     TEXT        NOT NULL,
+  type         TEXT        NOT NULL,
+  payload      JSONB       NOT NULL,
+  received_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  processed_at TIMESTAMPTZ,
+  PRIMARY KEY (provider, event_id)
+);
@@ -0,0 +1,14 @@
+-- POST /orders の Idempotency-Key（ユーザ単位）
+CREATE TABLE idempotency_keys (
+  user_id          TEXT        NOT NULL,
+  key              TEXT        NOT NULL,
+  fingerprint      TEXT        NOT NULL,
+  status_code      INT         NOT NULL DEFAULT 0, -- 0 は処理中
+  response_headers JSONB       NOT NULL DEFAULT '{}'::jsonb,
+  response_body    BYTEA       NOT NULL DEFAULT ''::bytea,
+  created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+  expires_at       TIMESTAMPTZ NOT NULL,
+  PRIMARY KEY (user_id, key)
+);
+
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
@@ -0,0 +1,17 @@
+-- 下流システム向けの transactional outbox（状態変更と同じ Tx で書く）
+CREATE TABLE outbox (
+  id              TEXT        PRIMARY KEY,
+  aggregate_id    TEXT        NOT NULL,
+  type            TEXT        NOT NULL,
+  payload         JSONB       NOT NULL,
+  created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+  seq             BIGSERIAL   NOT NULL,
+  attempts        INT         NOT NULL DEFAULT 0,
+  next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  last_error      TEXT        NOT NULL DEFAULT '',
+  delivered_at    TIMESTAMPTZ,
+  dead_at         TIMESTAMPTZ
+);
+
+-- relay のポーリング用（未配信のみ）
+CREATE INDEX idx_outbox_pending ON outbox(next_attempt_at, seq) WHERE delivered_at IS NULL AND dead_at IS NULL;
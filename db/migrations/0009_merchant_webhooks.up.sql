@@ -0,0 +1,44 @@
+-- 加盟店向け Webhook の通知先
+CREATE TABLE webhook_endpoints (
+  id         TEXT        PRIMARY KEY,
+  user_id    TEXT        NOT NULL,
+  url        TEXT        NOT NULL,
+  events     TEXT[]      NOT NULL,
+  secret     TEXT        NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  deleted_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_webhook_endpoints_user ON webhook_endpoints(user_id) WHERE deleted_at IS NULL;
+
+-- イベント × 通知先ごとの配信
+CREATE TABLE webhook_deliveries (
+  id               TEXT        PRIMARY KEY,
+  endpoint_id      TEXT        NOT NULL REFERENCES webhook_endpoints(id),
+  event_id         TEXT        NOT NULL,
+  event_type       TEXT        NOT NULL,
+  payload          JSONB       NOT NULL,
+  status           TEXT        NOT NULL CHECK (status IN ('PENDING','SUCCEEDED','FAILED')),
+  attempts         INT         NOT NULL DEFAULT 0,
+  next_attempt_at  TIMESTAMPTZ NOT NULL,
+  last_status_code INT         NOT NULL DEFAULT 0,
+  last_error       TEXT        NOT NULL DEFAULT '',
+  created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT uq_webhook_deliveries_endpoint_event UNIQUE (endpoint_id, event_id)
+);
+
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at) WHERE status = 'PENDING';
+CREATE INDEX idx_webhook_deliveries_endpoint_created ON webhook_deliveries(endpoint_id, created_at DESC);
+
+-- 試行ごとの記録
+CREATE TABLE webhook_delivery_attempts (
+  id           TEXT        PRIMARY KEY,
+  delivery_id  TEXT        NOT NULL REFERENCES webhook_deliveries(id),
+  status_code  INT         NOT NULL DEFAULT 0,
+  error        TEXT        NOT NULL DEFAULT '',
+  duration_ms  BIGINT      NOT NULL DEFAULT 0,
+  attempted_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_webhook_delivery_attempts_delivery ON webhook_delivery_attempts(delivery_id, attempted_at);
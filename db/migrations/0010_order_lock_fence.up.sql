@@ -0,0 +1,3 @@
+-- 最後に状態を書き換えたロック保持者のフェンシングトークン
+-- これより小さいフェンスを持つ（リースを失った）保持者の更新は拒否する
+ALTER TABLE orders ADD COLUMN lock_fence BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,5 @@
+-- Postgres advisory lock 版 Locker のフェンシングトークン採番
+CREATE SEQUENCE lock_fence_seq;
+
+-- Redis 版から切り替えた場合でも既存の orders.lock_fence より大きい番号から始める
+SELECT setval('lock_fence_seq', GREATEST((SELECT MAX(lock_fence) FROM orders), 0) + 1, false);
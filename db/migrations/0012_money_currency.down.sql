@@ -0,0 +1,19 @@
+-- 円以外の金額が残っていると amount_jpy に戻せないため中断する
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM orders WHERE currency <> 'JPY') THEN
+    RAISE EXCEPTION 'non-JPY orders exist; cannot revert to amount_jpy';
+  END IF;
+END $$;
+
+ALTER TABLE refunds DROP COLUMN IF EXISTS currency;
+ALTER TABLE refunds RENAME COLUMN amount TO amount_jpy;
+
+ALTER TABLE authorizations DROP COLUMN IF EXISTS currency;
+ALTER TABLE authorizations RENAME COLUMN amount TO amount_jpy;
+
+ALTER TABLE payments DROP COLUMN IF EXISTS currency;
+ALTER TABLE payments RENAME COLUMN amount TO amount_jpy;
+
+ALTER TABLE orders DROP COLUMN IF EXISTS currency;
+ALTER TABLE orders RENAME COLUMN amount TO amount_jpy;
@@ -0,0 +1,13 @@
+-- 金額を通貨付き（最小単位の整数 + ISO 4217 コード）で保持する
+-- 既存の行はすべて日本円
+ALTER TABLE orders RENAME COLUMN amount_jpy TO amount;
+ALTER TABLE orders ADD COLUMN currency TEXT NOT NULL DEFAULT 'JPY' CHECK (currency ~ '^[A-Z]{3}$');
+
+ALTER TABLE payments RENAME COLUMN amount_jpy TO amount;
+ALTER TABLE payments ADD COLUMN currency TEXT NOT NULL DEFAULT 'JPY' CHECK (currency ~ '^[A-Z]{3}$');
+
+ALTER TABLE authorizations RENAME COLUMN amount_jpy TO amount;
+ALTER TABLE authorizations ADD COLUMN currency TEXT NOT NULL DEFAULT 'JPY' CHECK (currency ~ '^[A-Z]{3}$');
+
+ALTER TABLE refunds RENAME COLUMN amount_jpy TO amount;
+ALTER TABLE refunds ADD COLUMN currency TEXT NOT NULL DEFAULT 'JPY' CHECK (currency ~ '^[A-Z]{3}$');
@@ -0,0 +1,11 @@
+-- 注文明細。合計はサーバ側で計算して orders.amount に保存する
+CREATE TABLE order_items (
+  order_id   TEXT   NOT NULL REFERENCES orders(id),
+  line_no    INT    NOT NULL CHECK (line_no > 0),
+  sku        TEXT   NOT NULL,
+  name       TEXT   NOT NULL,
+  unit_price BIGINT NOT NULL CHECK (unit_price >= 0),
+  currency   TEXT   NOT NULL CHECK (currency ~ '^[A-Z]{3}$'),
+  quantity   BIGINT NOT NULL CHECK (quantity > 0),
+  PRIMARY KEY (order_id, line_no)
+);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS merchant_settings;
+DROP TABLE IF EXISTS order_tax_lines;
+ALTER TABLE order_items DROP COLUMN IF EXISTS tax_rate;
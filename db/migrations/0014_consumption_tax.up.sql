@@ -0,0 +1,19 @@
+-- 明細ごとの消費税率（単価は税抜）。既存の明細は標準税率とみなす
+ALTER TABLE order_items ADD COLUMN tax_rate SMALLINT NOT NULL DEFAULT 10 CHECK (tax_rate IN (8, 10));
+
+-- 注文ごと・税率ごとの消費税内訳（適格請求書の記載事項）
+CREATE TABLE order_tax_lines (
+  order_id TEXT     NOT NULL REFERENCES orders(id),
+  rate     SMALLINT NOT NULL CHECK (rate IN (8, 10)),
+  taxable  BIGINT   NOT NULL CHECK (taxable >= 0),
+  tax      BIGINT   NOT NULL CHECK (tax >= 0),
+  currency TEXT     NOT NULL CHECK (currency ~ '^[A-Z]{3}$'),
+  PRIMARY KEY (order_id, rate)
+);
+
+-- 加盟店（注文の所有ユーザ）ごとの設定。行がなければ既定値（切り捨て）
+CREATE TABLE merchant_settings (
+  user_id      TEXT        PRIMARY KEY,
+  tax_rounding TEXT        NOT NULL CHECK (tax_rounding IN ('floor', 'round', 'ceil')),
+  updated_at   TIMESTAMPTZ NOT NULL DEFAULT now()
+);
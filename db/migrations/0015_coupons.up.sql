@@ -0,0 +1,32 @@
+-- プロモーションコード。currency は amount_off / min_amount の通貨（どちらもなければ空）
+CREATE TABLE coupons (
+  id              TEXT        PRIMARY KEY,
+  code            TEXT        NOT NULL UNIQUE CHECK (code ~ '^[A-Z0-9_-]{3,32}$'),
+  kind            TEXT        NOT NULL CHECK (kind IN ('percentage', 'fixed')),
+  percent_off     BIGINT      NOT NULL DEFAULT 0 CHECK (percent_off BETWEEN 0 AND 100),
+  amount_off      BIGINT      NOT NULL DEFAULT 0 CHECK (amount_off >= 0),
+  min_amount      BIGINT      NOT NULL DEFAULT 0 CHECK (min_amount >= 0),
+  currency        TEXT        NOT NULL DEFAULT '' CHECK (currency = '' OR currency ~ '^[A-Z]{3}$'),
+  max_redemptions BIGINT      NOT NULL DEFAULT 0 CHECK (max_redemptions >= 0), -- 0 は無制限
+  max_per_user    BIGINT      NOT NULL DEFAULT 0 CHECK (max_per_user >= 0),    -- 0 は無制限
+  redeemed_count  BIGINT      NOT NULL DEFAULT 0,
+  valid_from      TIMESTAMPTZ NOT NULL,
+  valid_until     TIMESTAMPTZ,                                                 -- NULL は無期限
+  created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CHECK (max_redemptions = 0 OR redeemed_count <= max_redemptions),
+  CHECK (valid_until IS NULL OR valid_until > valid_from)
+);
+
+-- クーポンの利用履歴（1 注文に 1 つまで）
+CREATE TABLE coupon_redemptions (
+  id         TEXT        PRIMARY KEY,
+  coupon_id  TEXT        NOT NULL REFERENCES coupons(id),
+  order_id   TEXT        NOT NULL UNIQUE REFERENCES orders(id),
+  user_id    TEXT        NOT NULL,
+  discount   BIGINT      NOT NULL CHECK (discount > 0),
+  currency   TEXT        NOT NULL CHECK (currency ~ '^[A-Z]{3}$'),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- ユーザごとの利用回数の確認用
+CREATE INDEX idx_coupon_redemptions_coupon_user ON coupon_redemptions (coupon_id, user_id);
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS receipts;
+DROP TABLE IF EXISTS receipt_counters;
+ALTER TABLE merchant_settings
+  DROP COLUMN IF EXISTS registration_number,
+  DROP COLUMN IF EXISTS issuer_address,
+  DROP COLUMN IF EXISTS issuer_name;
@@ -0,0 +1,23 @@
+-- 領収書（適格請求書）に記載する発行者の情報。登録番号は "T" + 13 桁（未登録なら空）
+ALTER TABLE merchant_settings
+  ADD COLUMN issuer_name         TEXT NOT NULL DEFAULT '',
+  ADD COLUMN issuer_address      TEXT NOT NULL DEFAULT '',
+  ADD COLUMN registration_number TEXT NOT NULL DEFAULT ''
+    CHECK (registration_number = '' OR registration_number ~ '^T[0-9]{13}$');
+
+-- 発行者ごとの領収書番号の採番（欠番を出さないためシーケンスは使わない）
+CREATE TABLE receipt_counters (
+  user_id     TEXT   PRIMARY KEY,
+  last_number BIGINT NOT NULL CHECK (last_number > 0)
+);
+
+-- 注文ごとの領収書。番号は初回発行時に振り、2 回目以降は再発行として数える
+CREATE TABLE receipts (
+  order_id           TEXT        PRIMARY KEY REFERENCES orders(id),
+  user_id            TEXT        NOT NULL,
+  number             BIGINT      NOT NULL CHECK (number > 0),
+  issued_at          TIMESTAMPTZ NOT NULL,
+  download_count     BIGINT      NOT NULL DEFAULT 1 CHECK (download_count > 0),
+  last_downloaded_at TIMESTAMPTZ NOT NULL,
+  UNIQUE (user_id, number)
+);
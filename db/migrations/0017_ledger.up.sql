@@ -0,0 +1,80 @@
+-- 複式簿記の元帳。残高は postings の合計で求め、行を更新・削除しない
+
+-- 勘定科目（ドメインの ledger.Account と揃える）
+CREATE TABLE ledger_accounts (
+  code TEXT PRIMARY KEY,
+  type TEXT NOT NULL CHECK (type IN ('asset', 'revenue', 'expense')),
+  name TEXT NOT NULL
+);
+
+INSERT INTO ledger_accounts (code, type, name) VALUES
+  ('provider_receivable', 'asset',   '売掛金（決済代行）'),
+  ('sales',               'revenue', '売上'),
+  ('sales_refunds',       'expense', '売上返金'),
+  ('processing_fees',     'expense', '支払手数料');
+
+-- 仕訳。reference は元になった決済・返金の ID で、同じお金の動きを二重に計上しない
+CREATE TABLE journal_entries (
+  id         TEXT        PRIMARY KEY,
+  kind       TEXT        NOT NULL CHECK (kind IN ('charge', 'refund', 'fee')),
+  order_id   TEXT        NOT NULL REFERENCES orders(id),
+  reference  TEXT        NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL,
+  UNIQUE (kind, reference)
+);
+
+CREATE INDEX idx_journal_entries_order_id ON journal_entries(order_id);
+
+-- 仕訳の明細。amount は借方が正、貸方が負
+CREATE TABLE postings (
+  entry_id   TEXT        NOT NULL REFERENCES journal_entries(id),
+  line_no    INT         NOT NULL CHECK (line_no > 0),
+  account    TEXT        NOT NULL REFERENCES ledger_accounts(code),
+  amount     BIGINT      NOT NULL CHECK (amount <> 0),
+  currency   TEXT        NOT NULL CHECK (currency ~ '^[A-Z]{3}$'),
+  created_at TIMESTAMPTZ NOT NULL, -- 仕訳の日時（期間の残高を索引で引くため複製する）
+  PRIMARY KEY (entry_id, line_no)
+);
+
+CREATE INDEX idx_postings_account_time ON postings(account, currency, created_at);
+
+-- 仕訳ごと・通貨ごとに明細の合計が 0 であることをコミット時に検査する
+CREATE FUNCTION check_journal_entry_balanced() RETURNS trigger AS $$
+BEGIN
+  IF EXISTS (
+    SELECT 1 FROM postings WHERE entry_id = NEW.entry_id
+    GROUP BY currency HAVING SUM(amount) <> 0
+  ) THEN
+    RAISE EXCEPTION 'journal entry % does not balance', NEW.entry_id;
+  END IF;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER trg_postings_balanced
+  AFTER INSERT OR UPDATE ON postings
+  DEFERRABLE INITIALLY DEFERRED
+  FOR EACH ROW EXECUTE FUNCTION check_journal_entry_balanced();
+
+-- 既存の決済・返金を計上する（手数料は記録がないので計上しない）
+WITH e AS (
+  INSERT INTO journal_entries (id, kind, order_id, reference, created_at)
+  SELECT gen_random_uuid()::text, 'charge', order_id, id, created_at FROM payments
+  RETURNING id, reference, created_at
+)
+INSERT INTO postings (entry_id, line_no, account, amount, currency, created_at)
+SELECT e.id, l.line_no, l.account, l.sign * p.amount, p.currency, e.created_at
+FROM e
+JOIN payments p ON p.id = e.reference
+CROSS JOIN (VALUES (1, 'provider_receivable', 1), (2, 'sales', -1)) AS l(line_no, account, sign);
+
+WITH e AS (
+  INSERT INTO journal_entries (id, kind, order_id, reference, created_at)
+  SELECT gen_random_uuid()::text, 'refund', order_id, id, created_at FROM refunds
+  RETURNING id, reference, created_at
+)
+INSERT INTO postings (entry_id, line_no, account, amount, currency, created_at)
+SELECT e.id, l.line_no, l.account, l.sign * r.amount, r.currency, e.created_at
+FROM e
+JOIN refunds r ON r.id = e.reference
+CROSS JOIN (VALUES (1, 'sales_refunds', 1), (2, 'provider_receivable', -1)) AS l(line_no, account, sign);
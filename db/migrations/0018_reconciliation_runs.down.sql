@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS reconciliation_runs;
+DROP INDEX IF EXISTS idx_payments_provider_created;
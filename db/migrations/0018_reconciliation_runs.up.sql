@@ -0,0 +1,20 @@
+-- 精算ファイルとの突き合わせで期間内の決済を引く
+CREATE INDEX idx_payments_provider_created ON payments(provider, created_at);
+
+-- 精算ファイル（CSV）と payments の突き合わせ 1 回分。取引ごとの結果は items に持つ
+CREATE TABLE reconciliation_runs (
+  id                TEXT        PRIMARY KEY,
+  provider          TEXT        NOT NULL,
+  source            TEXT        NOT NULL, -- ファイル名
+  period_from       TIMESTAMPTZ NOT NULL,
+  period_to         TIMESTAMPTZ NOT NULL,
+  matched           INT         NOT NULL CHECK (matched >= 0),
+  amount_mismatch   INT         NOT NULL CHECK (amount_mismatch >= 0),
+  missing_in_ours   INT         NOT NULL CHECK (missing_in_ours >= 0),
+  missing_in_theirs INT         NOT NULL CHECK (missing_in_theirs >= 0),
+  items             JSONB       NOT NULL,
+  created_at        TIMESTAMPTZ NOT NULL,
+  CHECK (period_from < period_to)
+);
+
+CREATE INDEX idx_reconciliation_runs_created ON reconciliation_runs(created_at DESC, id DESC);
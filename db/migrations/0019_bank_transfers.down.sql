@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS bank_deposits;
+DROP TABLE IF EXISTS bank_transfers;
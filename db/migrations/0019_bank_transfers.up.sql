@@ -0,0 +1,55 @@
+-- 銀行振込の振込先（1 注文に 1 つ）。振込依頼人コード方式では口座を共有し、コードで注文を見分ける
+CREATE TABLE bank_transfers (
+  id             TEXT        PRIMARY KEY,
+  order_id       TEXT        NOT NULL REFERENCES orders(id),
+  mode           TEXT        NOT NULL CHECK (mode IN ('virtual_account','reference_code')),
+  bank_code      TEXT        NOT NULL,
+  bank_name      TEXT        NOT NULL,
+  branch_code    TEXT        NOT NULL,
+  branch_name    TEXT        NOT NULL,
+  account_type   TEXT        NOT NULL CHECK (account_type IN ('ordinary','current','savings')),
+  account_number TEXT        NOT NULL,
+  account_name   TEXT        NOT NULL,
+  reference_code TEXT        NOT NULL DEFAULT '',
+  payer_name     TEXT        NOT NULL DEFAULT '',
+  amount         BIGINT      NOT NULL CHECK (amount > 0),
+  currency       TEXT        NOT NULL CHECK (currency = 'JPY'),
+  status         TEXT        NOT NULL CHECK (status IN ('AWAITING','PAID')),
+  created_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT uq_bank_transfers_order UNIQUE (order_id),
+  -- 払い出した番号は使い回さない（遅れて届いた入金を別の注文に消し込まない）
+  CONSTRAINT uq_bank_transfers_destination UNIQUE (branch_code, account_number, reference_code),
+  CHECK ((mode = 'reference_code') = (reference_code <> ''))
+);
+
+-- 振込先で特定できない入金を名義で探す
+CREATE INDEX idx_bank_transfers_awaiting_payer ON bank_transfers(payer_name) WHERE status = 'AWAITING' AND payer_name <> '';
+
+-- 振込入金通知の明細。同じファイルを取り込み直しても二重に記録しない
+CREATE TABLE bank_deposits (
+  id               TEXT        PRIMARY KEY,
+  source           TEXT        NOT NULL,
+  line             INT         NOT NULL,
+  bank_code        TEXT        NOT NULL,
+  branch_code      TEXT        NOT NULL,
+  account_number   TEXT        NOT NULL,
+  inquiry_no       TEXT        NOT NULL,
+  value_date       DATE        NOT NULL,
+  amount           BIGINT      NOT NULL CHECK (amount >= 0),
+  currency         TEXT        NOT NULL CHECK (currency = 'JPY'),
+  payer_code       TEXT        NOT NULL,
+  payer_name       TEXT        NOT NULL,
+  remitting_bank   TEXT        NOT NULL,
+  remitting_branch TEXT        NOT NULL,
+  canceled         BOOLEAN     NOT NULL,
+  transfer_id      TEXT        REFERENCES bank_transfers(id),
+  order_id         TEXT        REFERENCES orders(id),
+  status           TEXT        NOT NULL CHECK (status IN ('MATCHED','REVIEW')),
+  reason           TEXT        NOT NULL DEFAULT '',
+  created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT uq_bank_deposits_entry UNIQUE (branch_code, account_number, value_date, inquiry_no),
+  CHECK ((status = 'REVIEW') = (reason <> ''))
+);
+
+CREATE INDEX idx_bank_deposits_status_created ON bank_deposits(status, created_at DESC);
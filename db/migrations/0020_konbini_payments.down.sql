@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_konbini_payments_awaiting_expires;
+DROP INDEX IF EXISTS uq_konbini_payments_order_awaiting;
+DROP TABLE IF EXISTS konbini_payments;
+
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_status_check;
+ALTER TABLE orders ADD CONSTRAINT orders_status_check
+  CHECK (status IN ('PENDING','AUTHORIZED','PAID','CANCELED','PARTIALLY_REFUNDED','REFUNDED'));
@@ -0,0 +1,25 @@
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_status_check;
+ALTER TABLE orders ADD CONSTRAINT orders_status_check
+  CHECK (status IN ('PENDING','AUTHORIZED','AWAITING_PAYMENT','PAID','CANCELED','PARTIALLY_REFUNDED','REFUNDED'));
+
+-- コンビニ払いの払込番号
+CREATE TABLE konbini_payments (
+  id                  TEXT        PRIMARY KEY,
+  order_id            TEXT        NOT NULL REFERENCES orders(id),
+  provider            TEXT        NOT NULL,
+  provider_payment_id TEXT        NOT NULL,
+  payment_code        TEXT        NOT NULL,
+  confirmation_number TEXT        NOT NULL DEFAULT '',
+  amount              BIGINT      NOT NULL CHECK (amount > 0),
+  currency            TEXT        NOT NULL CHECK (currency = 'JPY'),
+  status              TEXT        NOT NULL CHECK (status IN ('AWAITING','PAID','EXPIRED')),
+  expires_at          TIMESTAMPTZ NOT NULL,
+  created_at          TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at          TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT uq_konbini_payments_provider_payment UNIQUE (provider, provider_payment_id)
+);
+
+-- 1注文につき支払い待ちの払込番号は1件まで
+CREATE UNIQUE INDEX uq_konbini_payments_order_awaiting ON konbini_payments(order_id) WHERE status = 'AWAITING';
+-- 支払期限切れの自動取り消し用
+CREATE INDEX idx_konbini_payments_awaiting_expires ON konbini_payments(expires_at) WHERE status = 'AWAITING';
@@ -0,0 +1,10 @@
+UPDATE orders SET status = 'PENDING'
+WHERE status = 'AWAITING_PAYMENT'
+  AND id IN (SELECT order_id FROM bank_transfers WHERE status = 'AWAITING');
+
+-- 取り消し済みの注文への入金は消し込まれず REVIEW になる
+UPDATE bank_transfers SET status = 'AWAITING' WHERE status = 'CANCELED';
+
+ALTER TABLE bank_transfers DROP CONSTRAINT IF EXISTS bank_transfers_status_check;
+ALTER TABLE bank_transfers ADD CONSTRAINT bank_transfers_status_check
+  CHECK (status IN ('AWAITING','PAID'));
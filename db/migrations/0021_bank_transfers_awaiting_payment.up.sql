@@ -0,0 +1,12 @@
+-- 振込先の発行で注文を AWAITING_PAYMENT にする（カードとの二重払いを防ぐ）。取り消した注文の振込先は CANCELED
+ALTER TABLE bank_transfers DROP CONSTRAINT IF EXISTS bank_transfers_status_check;
+ALTER TABLE bank_transfers ADD CONSTRAINT bank_transfers_status_check
+  CHECK (status IN ('AWAITING','PAID','CANCELED'));
+
+UPDATE orders SET status = 'AWAITING_PAYMENT', updated_at = now()
+WHERE status = 'PENDING'
+  AND id IN (SELECT order_id FROM bank_transfers WHERE status = 'AWAITING');
+
+UPDATE bank_transfers SET status = 'CANCELED', updated_at = now()
+WHERE status = 'AWAITING'
+  AND order_id IN (SELECT id FROM orders WHERE status <> 'AWAITING_PAYMENT');
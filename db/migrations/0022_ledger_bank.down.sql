@@ -0,0 +1,2 @@
+UPDATE postings SET account = 'provider_receivable' WHERE account = 'bank';
+DELETE FROM ledger_accounts WHERE code = 'bank';
@@ -0,0 +1,11 @@
+-- 銀行振込の入金は PG の売掛金ではなく普通預金に計上する
+INSERT INTO ledger_accounts (code, type, name) VALUES ('bank', 'asset', '普通預金');
+
+-- 売掛金に計上済みの振込入金を付け替える（仕訳ごとの貸借は変わらない）
+UPDATE postings SET account = 'bank'
+WHERE account = 'provider_receivable'
+  AND entry_id IN (
+    SELECT e.id FROM journal_entries e
+    JOIN payments p ON p.id = e.reference
+    WHERE e.kind = 'charge' AND p.method = 'BANK_TRANSFER'
+  );
@@ -3,6 +3,7 @@ package db
 import (
 	"time"
 
+	"github.com/kazshi01/payment-system/internal/domain/money"
 	"github.com/kazshi01/payment-system/internal/domain/order"
 )
 
@@ -10,7 +11,8 @@ import (
 type OrderRecord struct {
 	ID        string    `db:"id"`
 	UserID    string    `db:"user_id"`
-	AmountJPY int64     `db:"amount_jpy"`
+	Amount    int64     `db:"amount"`
+	Currency  string    `db:"currency"`
 	Status    string    `db:"status"`
 	CreatedAt time.Time `db:"created_at"`
 	UpdatedAt time.Time `db:"updated_at"`
@@ -21,7 +23,7 @@ func (r OrderRecord) ToDomain() *order.Order {
 	return &order.Order{
 		ID:        order.ID(r.ID),
 		UserID:    r.UserID,
-		AmountJPY: r.AmountJPY,
+		Amount:    money.Money{Amount: r.Amount, Currency: money.Currency(r.Currency)},
 		Status:    order.Status(r.Status),
 		CreatedAt: r.CreatedAt,
 		UpdatedAt: r.UpdatedAt,
@@ -33,7 +35,8 @@ func FromDomain(o *order.Order) OrderRecord {
 	return OrderRecord{
 		ID:        string(o.ID),
 		UserID:    o.UserID,
-		AmountJPY: o.AmountJPY,
+		Amount:    o.Amount.Amount,
+		Currency:  string(o.Amount.Currency),
 		Status:    string(o.Status),
 		CreatedAt: o.CreatedAt,
 		UpdatedAt: o.UpdatedAt,
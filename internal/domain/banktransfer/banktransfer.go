@@ -0,0 +1,164 @@
+// Package banktransfer は銀行振込（振込先の発行と入金明細の消し込み）
+//
+// 振込先は注文ごとのバーチャル口座、または共通の口座 + 振込依頼人コード（振込名義の先頭に入れてもらう番号）。
+// 入金は全銀協フォーマットの振込入金通知で受け取り、振込先と金額で注文に消し込む。
+package banktransfer
+
+import (
+	"errors"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain/money"
+)
+
+var ErrInvalidStatement = errors.New("invalid zengin statement")
+
+// Provider は payments.provider に記録する名前
+const Provider = "bank_transfer"
+
+// Mode は振込先の発行方式
+type Mode string
+
+const (
+	ModeVirtualAccount Mode = "virtual_account" // 注文ごとの口座番号
+	ModeReferenceCode  Mode = "reference_code"  // 共通の口座 + 振込依頼人コード
+)
+
+// AccountType は預金種目
+type AccountType string
+
+const (
+	AccountOrdinary AccountType = "ordinary" // 普通
+	AccountCurrent  AccountType = "current"  // 当座
+	AccountSavings  AccountType = "savings"  // 貯蓄
+)
+
+// Destination は振込先。ReferenceCode は ModeReferenceCode のときだけ
+type Destination struct {
+	Mode          Mode
+	BankCode      string // 4 桁
+	BankName      string
+	BranchCode    string // 3 桁
+	BranchName    string
+	AccountType   AccountType
+	AccountNumber string // 7 桁
+	AccountName   string // 口座名義（半角カナ）
+	ReferenceCode string
+}
+
+type TransferStatus string
+
+const (
+	TransferAwaiting TransferStatus = "AWAITING" // 入金待ち
+	TransferPaid     TransferStatus = "PAID"     // 入金を消し込んだ
+	TransferCanceled TransferStatus = "CANCELED" // 注文を取り消した（届いた入金は手動確認）
+)
+
+// Transfer は注文に発行した振込先（1 注文に 1 つ）
+type Transfer struct {
+	ID      string
+	OrderID string
+	Destination
+	// 振込名義の申告（NormalizeName 済み、任意）。振込先で特定できない入金を名義で探す
+	PayerName string
+	Amount    money.Money // 請求額（JPY）
+	Status    TransferStatus
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type DepositStatus string
+
+const (
+	DepositMatched DepositStatus = "MATCHED" // 注文を支払い済みにした
+	DepositReview  DepositStatus = "REVIEW"  // 自動では消し込めない（手動で確認する）
+)
+
+// Reason は入金を手動確認に回した理由
+type Reason string
+
+const (
+	ReasonUnknownDestination Reason = "unknown_destination" // 振込先・名義から注文が分からない
+	ReasonNameOnly           Reason = "name_only"           // 名義と金額だけで注文の候補が 1 つ見つかった
+	ReasonOverpaid           Reason = "overpaid"            // 請求額より多い
+	ReasonUnderpaid          Reason = "underpaid"           // 請求額より少ない
+	ReasonOrderNotPending    Reason = "order_not_pending"   // 支払い済み・取り消し済みの注文への入金
+	ReasonCanceledEntry      Reason = "canceled_entry"      // 銀行が取り消した明細
+)
+
+// Deposit は振込入金通知の明細 1 件。(BranchCode, AccountNumber, ValueDate, InquiryNo) で一意
+type Deposit struct {
+	ID     string
+	Source string // 取り込んだファイル名
+	Line   int    // ファイル内のレコード番号（1 始まり）
+
+	// 入金先の口座（ヘッダレコード）
+	BankCode      string
+	BranchCode    string
+	AccountNumber string
+
+	InquiryNo       string    // 照会番号
+	ValueDate       time.Time // 勘定日
+	Amount          money.Money
+	PayerCode       string // 振込依頼人コード（なければ空）
+	PayerName       string // 振込依頼人名（半角カナのまま）
+	RemittingBank   string // 仕向銀行名
+	RemittingBranch string // 仕向店名
+	Canceled        bool   // 取消区分
+
+	// 消し込みの結果（ReasonUnknownDestination なら TransferID / OrderID は空）
+	TransferID string
+	OrderID    string
+	Status     DepositStatus
+	Reason     Reason
+	CreatedAt  time.Time
+}
+
+// Check は入金を振込先の請求額と比べる。過不足がなければ空（どちらも JPY）
+func (t *Transfer) Check(d *Deposit) Reason {
+	switch {
+	case d.Canceled:
+		return ReasonCanceledEntry
+	case d.Amount.Amount > t.Amount.Amount:
+		return ReasonOverpaid
+	case d.Amount.Amount < t.Amount.Amount:
+		return ReasonUnderpaid
+	}
+	return ""
+}
+
+// ReferenceLength は振込依頼人コードの桁数（末尾 1 桁はチェックディジット）
+const ReferenceLength = 7
+
+// WithCheckDigit は ReferenceLength-1 桁の数字に Luhn のチェックディジットを付ける
+func WithCheckDigit(body string) string {
+	return body + string(rune('0'+luhn(body+"0")))
+}
+
+// ValidReference は振込依頼人コードの桁数とチェックディジットを検証する（打ち間違いで別の注文に消し込まない）
+func ValidReference(s string) bool {
+	if len(s) != ReferenceLength {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return luhn(s) == 0
+}
+
+// luhn は右端を検査桁とみなしたときの (10 - 合計 mod 10) mod 10。正しい番号なら 0
+func luhn(s string) int {
+	sum := 0
+	for i := 0; i < len(s); i++ {
+		d := int(s[len(s)-1-i] - '0')
+		if i%2 == 1 {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return (10 - sum%10) % 10
+}
@@ -0,0 +1,160 @@
+package banktransfer_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain/banktransfer"
+	"github.com/kazshi01/payment-system/internal/domain/money"
+)
+
+// record は半角カナを JIS X 0201 のバイトにし、200 バイトまで空白で埋める
+func record(t *testing.T, s string) string {
+	t.Helper()
+	var b []byte
+	for _, r := range s {
+		switch {
+		case r < 0x80:
+			b = append(b, byte(r))
+		case r >= '｡' && r <= 'ﾟ':
+			b = append(b, byte(r-'｡'+0xa1))
+		default:
+			t.Fatalf("%q is not JIS X 0201", r)
+		}
+	}
+	if len(b) > 200 {
+		t.Fatalf("record is %d bytes", len(b))
+	}
+	return string(b) + strings.Repeat(" ", 200-len(b))
+}
+
+func header(t *testing.T, branch, account string) string {
+	return record(t, fmt.Sprintf("1010071001071001071001%-4s%-15s%-3s%-15s   1%-7s%-40s",
+		"0001", "ﾐｽﾞﾎ", branch, "ﾎﾝﾃﾝ", account, "ｶ)ﾍﾟｲﾒﾝﾄ"))
+}
+
+func data(t *testing.T, inquiry string, amount int64, payerCode, payerName, canceled string) string {
+	return record(t, fmt.Sprintf("2%-6s071001071001%010d%010d%010s%-48s%-15s%-15s%s",
+		inquiry, amount, 0, payerCode, payerName, "ﾐﾂｲｽﾐﾄﾓ", "ｼﾌﾞﾔ", canceled))
+}
+
+func trailer(t *testing.T, count int, sum int64) string {
+	return record(t, fmt.Sprintf("8%06d%012d", count, sum))
+}
+
+func TestParseZengin(t *testing.T) {
+	file := strings.Join([]string{
+		header(t, "001", "1234567"),
+		data(t, "000001", 12000, "", "1234566 ﾔﾏﾀﾞ ﾀﾛｳ", "0"),
+		data(t, "000002", 5000, "0001234566", "ｶ)ｻﾄｳｼﾖｳｼﾞ", "1"),
+		trailer(t, 2, 17000),
+		header(t, "002", "7654321"),
+		trailer(t, 0, 0),
+		record(t, "9"),
+	}, "\r\n") + "\r\n\x1a"
+
+	ds, err := banktransfer.ParseZengin(strings.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ds) != 2 {
+		t.Fatalf("len = %d; want 2", len(ds))
+	}
+	d := ds[0]
+	want := banktransfer.Deposit{
+		Line: 2, BankCode: "0001", BranchCode: "001", AccountNumber: "1234567",
+		InquiryNo: "000001", ValueDate: time.Date(2025, 10, 1, 0, 0, 0, 0, d.ValueDate.Location()),
+		Amount: money.Money{Amount: 12000, Currency: money.JPY}, PayerName: "1234566 ﾔﾏﾀﾞ ﾀﾛｳ",
+		RemittingBank: "ﾐﾂｲｽﾐﾄﾓ", RemittingBranch: "ｼﾌﾞﾔ",
+	}
+	if *d != want {
+		t.Fatalf("deposit = %+v\nwant %+v", *d, want)
+	}
+	if !ds[1].Canceled || ds[1].PayerCode != "1234566" {
+		t.Fatalf("second deposit = %+v; want canceled with payer code 1234566", *ds[1])
+	}
+
+	bad := map[string]string{
+		"trailer sum":   strings.Join([]string{header(t, "001", "1234567"), data(t, "1", 100, "", "ｱ", "0"), trailer(t, 1, 99), record(t, "9")}, ""),
+		"no end":        strings.Join([]string{header(t, "001", "1234567"), trailer(t, 0, 0)}, ""),
+		"short record":  header(t, "001", "1234567")[:199],
+		"invalid byte":  strings.Join([]string{header(t, "001", "1234567")[:199] + "\x81", trailer(t, 0, 0), record(t, "9")}, ""),
+		"transfer kind": strings.Join([]string{record(t, "103"), trailer(t, 0, 0), record(t, "9")}, ""),
+		"invalid date":  strings.Join([]string{header(t, "001", "1234567"), record(t, "2000001071332"), trailer(t, 1, 0), record(t, "9")}, ""),
+	}
+	for name, f := range bad {
+		if _, err := banktransfer.ParseZengin(strings.NewReader(f)); !errors.Is(err, banktransfer.ErrInvalidStatement) {
+			t.Errorf("%s: err = %v; want ErrInvalidStatement", name, err)
+		}
+	}
+}
+
+func TestNormalizeName(t *testing.T) {
+	cases := map[string]string{
+		"ﾔﾏﾀﾞ ﾀﾛｳ":            "ヤマダタロウ",
+		"やまだ　たろう":             "ヤマダタロウ",
+		"1234566ﾔﾏﾀﾞ ﾀﾛｳ":     "ヤマダタロウ",
+		"ｶ)ﾍﾟｲﾒﾝﾄｼｽﾃﾑ":        "ペイメントシステム",
+		"ﾍﾟｲﾒﾝﾄｼｽﾃﾑ(ｶ":        "ペイメントシステム",
+		"カブシキガイシャ　ペイメント":      "ペイメント",
+		"ｼﾔｰﾛｯｸ":              "シヤーロツク",
+		"シャーロック":              "シヤーロツク",
+		"ｳﾞｨｸﾄﾙ ABC":          "ヴイクトルABC",
+		"ｲ)ｹﾝｺｳｶｲ ﾔﾏﾀﾞﾋﾞｮｳｲﾝ": "ケンコウカイヤマダビヨウイン",
+	}
+	for in, want := range cases {
+		if got := banktransfer.NormalizeName(in); got != want {
+			t.Errorf("NormalizeName(%q) = %q; want %q", in, got, want)
+		}
+	}
+}
+
+func TestReference(t *testing.T) {
+	code := banktransfer.WithCheckDigit("123456")
+	if code != "1234566" || !banktransfer.ValidReference(code) {
+		t.Fatalf("WithCheckDigit = %q (valid %v); want 1234566", code, banktransfer.ValidReference(code))
+	}
+	// 1 桁違い・隣接桁の入れ替えは無効
+	for _, typo := range []string{"1234567", "1243566", "123456", "12345660"} {
+		if banktransfer.ValidReference(typo) {
+			t.Errorf("ValidReference(%q) = true", typo)
+		}
+	}
+
+	cases := []struct {
+		name, code, want string
+	}{
+		{"ﾔﾏﾀﾞ 1234566", "", "1234566"},
+		{"ﾔﾏﾀﾞ 1234567", "1234566", "1234566"}, // 名義の番号が無効なら依頼人コード欄
+		{"ﾔﾏﾀﾞ ﾀﾛｳ", "", ""},
+	}
+	for _, c := range cases {
+		d := &banktransfer.Deposit{PayerName: c.name, PayerCode: c.code}
+		if got := banktransfer.ExtractReference(d); got != c.want {
+			t.Errorf("ExtractReference(%q, %q) = %q; want %q", c.name, c.code, got, c.want)
+		}
+	}
+}
+
+func TestTransfer_Check(t *testing.T) {
+	tr := &banktransfer.Transfer{Amount: money.Money{Amount: 12000, Currency: money.JPY}}
+	cases := []struct {
+		amount   int64
+		canceled bool
+		want     banktransfer.Reason
+	}{
+		{12000, false, ""},
+		{12500, false, banktransfer.ReasonOverpaid},
+		{11560, false, banktransfer.ReasonUnderpaid}, // 振込手数料を差し引かれた
+		{12000, true, banktransfer.ReasonCanceledEntry},
+	}
+	for _, c := range cases {
+		d := &banktransfer.Deposit{Amount: money.Money{Amount: c.amount, Currency: money.JPY}, Canceled: c.canceled}
+		if got := tr.Check(d); got != c.want {
+			t.Errorf("Check(%d, canceled=%v) = %q; want %q", c.amount, c.canceled, got, c.want)
+		}
+	}
+}
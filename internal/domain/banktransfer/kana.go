@@ -0,0 +1,89 @@
+package banktransfer
+
+import (
+	"regexp"
+	"strings"
+)
+
+// 半角カナ（U+FF66〜U+FF9D）に対応する全角カタカナ
+var halfToFull = []rune("ヲァィゥェォャュョッーアイウエオカキクケコサシスセソタチツテトナニヌネノハヒフヘホマミムメモヤユヨラリルレロワン")
+
+// 振込名義では小さい文字が使えないため、申告された名義も大きい文字に揃える
+var smallToLarge = map[rune]rune{
+	'ァ': 'ア', 'ィ': 'イ', 'ゥ': 'ウ', 'ェ': 'エ', 'ォ': 'オ',
+	'ャ': 'ヤ', 'ュ': 'ユ', 'ョ': 'ヨ', 'ッ': 'ツ', 'ヮ': 'ワ',
+}
+
+// 法人格の略語。振込名義では "ｶ)ﾔﾏﾀﾞ"（株式会社ヤマダ）のように括弧と組み合わせて付く
+var entityAbbr = `(カ|ユ|ド|メ|シ|イ|ザイ|シヤ|ガク|シユウ|フク|トクヒ|イツパン)`
+
+var (
+	entityPattern = regexp.MustCompile(`^` + entityAbbr + `\)|\(` + entityAbbr + `\)|\(` + entityAbbr + `$`)
+	entityWords   = strings.NewReplacer("カブシキガイシヤ", "", "ユウゲンガイシヤ", "", "ゴウドウガイシヤ", "")
+	digitRuns     = regexp.MustCompile(`[0-9]+`)
+)
+
+// NormalizeName は振込名義を比較できる形にする。
+// 半角カナ・ひらがなを全角カタカナに、小さい文字を大きい文字にし、空白・記号・数字（振込依頼人コード）と法人格を除く
+func NormalizeName(s string) string {
+	var b strings.Builder
+	rs := []rune(s)
+	for i := 0; i < len(rs); i++ {
+		r := rs[i]
+		switch {
+		case r >= 'ｦ' && r <= 'ﾝ':
+			r = halfToFull[r-'ｦ']
+			// 濁点・半濁点は次の 1 文字
+			if i+1 < len(rs) {
+				if v, ok := voiced(r, rs[i+1]); ok {
+					r = v
+					i++
+				}
+			}
+		case r >= 'ぁ' && r <= 'ゖ':
+			r += 'ァ' - 'ぁ'
+		case r >= '！' && r <= '～': // 全角英数字・記号
+			r -= '！' - '!'
+		case r == '-' || r == 'ｰ' || r == '－':
+			r = 'ー'
+		}
+		if l, ok := smallToLarge[r]; ok {
+			r = l
+		}
+		b.WriteRune(r)
+	}
+
+	n := strings.ToUpper(b.String())
+	n = strings.Join(strings.Fields(strings.ReplaceAll(n, "　", " ")), "")
+	n = digitRuns.ReplaceAllString(n, "")
+	n = strings.ReplaceAll(n, ".", "")
+	n = entityPattern.ReplaceAllString(n, "")
+	n = entityWords.Replace(n)
+	return strings.Trim(n, "()")
+}
+
+func voiced(r, mark rune) (rune, bool) {
+	switch {
+	case mark == 'ﾞ' && r == 'ウ':
+		return 'ヴ', true
+	case mark == 'ﾞ' && strings.ContainsRune("カキクケコサシスセソタチツテトハヒフヘホ", r):
+		return r + 1, true
+	case mark == 'ﾟ' && strings.ContainsRune("ハヒフヘホ", r):
+		return r + 2, true
+	}
+	return 0, false
+}
+
+// ExtractReference は入金明細から振込依頼人コードを探す。
+// 振込名義に含まれる ReferenceLength 桁の数字（"1234567ﾔﾏﾀﾞ ﾀﾛｳ" など）を優先し、なければ振込依頼人コード欄を見る
+func ExtractReference(d *Deposit) string {
+	for _, s := range digitRuns.FindAllString(d.PayerName, -1) {
+		if ValidReference(s) {
+			return s
+		}
+	}
+	if ValidReference(d.PayerCode) {
+		return d.PayerCode
+	}
+	return ""
+}
@@ -0,0 +1,181 @@
+package banktransfer
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain/money"
+)
+
+// 全銀協フォーマット（振込入金通知）は 1 レコード 200 バイト固定長。
+// ヘッダ(1) → データ(2)… → トレーラ(8) の組が口座ごとに繰り返され、エンド(9) で終わる。
+// 文字は JIS X 0201（英数字と半角カナ）で、日付は和暦（令和）の YYMMDD
+const recordLen = 200
+
+const kindDepositNotice = "01" // 種別コード: 振込入金通知
+
+var jst = time.FixedZone("JST", 9*60*60)
+
+// field はレコード内の項目（位置は 0 始まりのバイト位置）
+type field struct{ pos, len int }
+
+var (
+	// ヘッダレコード
+	hdrKind          = field{1, 2}
+	hdrBankCode      = field{22, 4}
+	hdrBranchCode    = field{41, 3}
+	hdrAccountNumber = field{63, 7}
+
+	// データレコード
+	dataInquiryNo       = field{1, 6}
+	dataValueDate       = field{7, 6}
+	dataAmount          = field{19, 10}
+	dataPayerCode       = field{39, 10}
+	dataPayerName       = field{49, 48}
+	dataRemittingBank   = field{97, 15}
+	dataRemittingBranch = field{112, 15}
+	dataCanceled        = field{127, 1}
+
+	// トレーラレコード
+	trlCount  = field{1, 6}
+	trlAmount = field{7, 12}
+)
+
+// ParseZengin は振込入金通知のファイルを読み、データレコードを入金明細にする。
+// レコード間の改行はあってもなくてもよい。トレーラの件数・合計金額（取消分を含む）と合わなければエラー
+func ParseZengin(r io.Reader) ([]*Deposit, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	b = bytes.TrimRight(b, "\x1a") // 末尾の EOF 文字
+	b = bytes.ReplaceAll(b, []byte("\r"), nil)
+	b = bytes.ReplaceAll(b, []byte("\n"), nil)
+	if len(b) == 0 || len(b)%recordLen != 0 {
+		return nil, fmt.Errorf("%w: length %d is not a multiple of %d bytes", ErrInvalidStatement, len(b), recordLen)
+	}
+
+	var (
+		out     []*Deposit
+		header  *Deposit // 口座の情報だけを持つ
+		count   int64
+		sum     int64
+		hasEnd  bool
+		nRecord = len(b) / recordLen
+	)
+	for i := range nRecord {
+		line := i + 1
+		rec, err := decodeRecord(b[i*recordLen : (i+1)*recordLen])
+		if err != nil {
+			return nil, fmt.Errorf("%w: record %d: %w", ErrInvalidStatement, line, err)
+		}
+		if hasEnd {
+			return nil, fmt.Errorf("%w: record %d: after end record", ErrInvalidStatement, line)
+		}
+		get := func(f field) string { return strings.TrimSpace(string(rec[f.pos : f.pos+f.len])) }
+
+		switch rec[0] {
+		case '1':
+			if header != nil {
+				return nil, fmt.Errorf("%w: record %d: header without trailer", ErrInvalidStatement, line)
+			}
+			if k := get(hdrKind); k != kindDepositNotice {
+				return nil, fmt.Errorf("%w: record %d: kind %q is not a deposit notice", ErrInvalidStatement, line, k)
+			}
+			header = &Deposit{
+				BankCode:      get(hdrBankCode),
+				BranchCode:    get(hdrBranchCode),
+				AccountNumber: get(hdrAccountNumber),
+			}
+			count, sum = 0, 0
+
+		case '2':
+			if header == nil {
+				return nil, fmt.Errorf("%w: record %d: data before header", ErrInvalidStatement, line)
+			}
+			date, err := parseWareki(get(dataValueDate))
+			if err != nil {
+				return nil, fmt.Errorf("%w: record %d: value date: %w", ErrInvalidStatement, line, err)
+			}
+			amount, err := strconv.ParseInt(get(dataAmount), 10, 64)
+			if err != nil || amount < 0 {
+				return nil, fmt.Errorf("%w: record %d: invalid amount %q", ErrInvalidStatement, line, get(dataAmount))
+			}
+			d := *header
+			d.Line = line
+			d.InquiryNo = get(dataInquiryNo)
+			d.ValueDate = date
+			d.Amount = money.Money{Amount: amount, Currency: money.JPY}
+			d.PayerCode = strings.TrimLeft(get(dataPayerCode), "0")
+			d.PayerName = get(dataPayerName)
+			d.RemittingBank = get(dataRemittingBank)
+			d.RemittingBranch = get(dataRemittingBranch)
+			d.Canceled = get(dataCanceled) == "1"
+			out = append(out, &d)
+			count++
+			sum += amount
+
+		case '8':
+			if header == nil {
+				return nil, fmt.Errorf("%w: record %d: trailer before header", ErrInvalidStatement, line)
+			}
+			wantCount, err1 := strconv.ParseInt(get(trlCount), 10, 64)
+			wantSum, err2 := strconv.ParseInt(get(trlAmount), 10, 64)
+			if err1 != nil || err2 != nil || wantCount != count || wantSum != sum {
+				return nil, fmt.Errorf("%w: record %d: trailer says %s records / %s yen, read %d / %d",
+					ErrInvalidStatement, line, get(trlCount), get(trlAmount), count, sum)
+			}
+			header = nil
+
+		case '9':
+			if header != nil {
+				return nil, fmt.Errorf("%w: record %d: end without trailer", ErrInvalidStatement, line)
+			}
+			hasEnd = true
+
+		default:
+			return nil, fmt.Errorf("%w: record %d: unknown record type %q", ErrInvalidStatement, line, rec[0])
+		}
+	}
+	if !hasEnd {
+		return nil, fmt.Errorf("%w: missing end record", ErrInvalidStatement)
+	}
+	return out, nil
+}
+
+// decodeRecord は JIS X 0201 のレコードを 1 バイト 1 文字のまま rune にする（位置はバイト位置と同じ）
+func decodeRecord(b []byte) ([]rune, error) {
+	out := make([]rune, len(b))
+	for i, c := range b {
+		switch {
+		case c >= 0x20 && c <= 0x7e:
+			out[i] = rune(c)
+		case c >= 0xa1 && c <= 0xdf: // 半角カナ（Shift_JIS と同じ）
+			out[i] = rune(c) - 0xa1 + '｡'
+		default:
+			return nil, fmt.Errorf("byte 0x%02x at %d is not JIS X 0201", c, i+1)
+		}
+	}
+	return out, nil
+}
+
+// parseWareki は令和の YYMMDD を日付にする（令和元年 = 2019 年）
+func parseWareki(s string) (time.Time, error) {
+	if len(s) != 6 {
+		return time.Time{}, fmt.Errorf("%q is not YYMMDD", s)
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is not YYMMDD", s)
+	}
+	y, m, d := n/10000+2018, time.Month(n/100%100), n%100
+	t := time.Date(y, m, d, 0, 0, 0, 0, jst)
+	if n/10000 == 0 || t.Month() != m || t.Day() != d {
+		return time.Time{}, fmt.Errorf("%q is not a valid date", s)
+	}
+	return t, nil
+}
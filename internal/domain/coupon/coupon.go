@@ -0,0 +1,154 @@
+// Package coupon はプロモーションコードによる値引き
+package coupon
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain/money"
+)
+
+var (
+	ErrInvalidCoupon = errors.New("invalid coupon")
+	ErrNotActive     = errors.New("coupon is not active")          // 有効期間外
+	ErrNotApplicable = errors.New("coupon is not applicable")      // 通貨違い・最低注文金額未満
+	ErrUsageLimit    = errors.New("coupon usage limit reached")    // 全体またはユーザごとの上限
+	ErrCodeTaken     = errors.New("coupon code is already in use") // コード重複
+)
+
+type Kind string
+
+const (
+	KindPercentage Kind = "percentage" // 請求額の n% 引き
+	KindFixed      Kind = "fixed"      // 定額引き
+)
+
+var codePattern = regexp.MustCompile(`^[A-Z0-9_-]{3,32}$`)
+
+// NormalizeCode はコードの大文字小文字・前後の空白を区別しないための正規化
+func NormalizeCode(s string) string { return strings.ToUpper(strings.TrimSpace(s)) }
+
+type Coupon struct {
+	ID   string
+	Code string // NormalizeCode 済み
+	Kind Kind
+
+	PercentOff int64       // KindPercentage: 1〜100
+	AmountOff  money.Money // KindFixed: 値引き額
+
+	// 値引き前の請求額の下限（Amount が 0 なら制限なし）
+	MinAmount money.Money
+
+	MaxRedemptions int64 // 全体の利用上限（0 なら無制限）
+	MaxPerUser     int64 // 1 ユーザあたりの利用上限（0 なら無制限）
+	Redeemed       int64 // 利用済み回数
+
+	ValidFrom  time.Time
+	ValidUntil time.Time // この時刻を含まない。ゼロ値なら無期限
+	CreatedAt  time.Time
+}
+
+// Validate は登録時の検証
+func (c *Coupon) Validate() error {
+	if !codePattern.MatchString(c.Code) {
+		return fmt.Errorf("%w: code must be 3-32 characters of A-Z, 0-9, '_' or '-'", ErrInvalidCoupon)
+	}
+	switch c.Kind {
+	case KindPercentage:
+		if c.PercentOff < 1 || c.PercentOff > 100 {
+			return fmt.Errorf("%w: percent_off must be between 1 and 100", ErrInvalidCoupon)
+		}
+		if !c.AmountOff.IsZero() {
+			return fmt.Errorf("%w: amount_off is only for fixed coupons", ErrInvalidCoupon)
+		}
+	case KindFixed:
+		if !c.AmountOff.Currency.Valid() {
+			return fmt.Errorf("%w: %w", ErrInvalidCoupon, money.ErrUnknownCurrency)
+		}
+		if !c.AmountOff.IsPositive() {
+			return fmt.Errorf("%w: amount_off must be > 0", ErrInvalidCoupon)
+		}
+		if c.PercentOff != 0 {
+			return fmt.Errorf("%w: percent_off is only for percentage coupons", ErrInvalidCoupon)
+		}
+	default:
+		return fmt.Errorf("%w: unknown kind %q", ErrInvalidCoupon, c.Kind)
+	}
+
+	if c.MinAmount.Amount < 0 {
+		return fmt.Errorf("%w: min_amount must be >= 0", ErrInvalidCoupon)
+	}
+	if c.MinAmount.Amount > 0 && !c.MinAmount.Currency.Valid() {
+		return fmt.Errorf("%w: %w", ErrInvalidCoupon, money.ErrUnknownCurrency)
+	}
+	// 通貨は 1 クーポンに 1 つ
+	if c.Kind == KindFixed && c.MinAmount.Amount > 0 && c.MinAmount.Currency != c.AmountOff.Currency {
+		return fmt.Errorf("%w: min_amount and amount_off must be in the same currency", ErrInvalidCoupon)
+	}
+
+	if c.MaxRedemptions < 0 || c.MaxPerUser < 0 {
+		return fmt.Errorf("%w: usage limits must be >= 0", ErrInvalidCoupon)
+	}
+	if c.ValidFrom.IsZero() {
+		return fmt.Errorf("%w: valid_from is required", ErrInvalidCoupon)
+	}
+	if !c.ValidUntil.IsZero() && !c.ValidUntil.After(c.ValidFrom) {
+		return fmt.Errorf("%w: valid_until must be after valid_from", ErrInvalidCoupon)
+	}
+	return nil
+}
+
+// Currency はクーポンが使える通貨（空ならどの通貨でも使える）
+func (c *Coupon) Currency() money.Currency {
+	if c.Kind == KindFixed {
+		return c.AmountOff.Currency
+	}
+	if c.MinAmount.Amount > 0 {
+		return c.MinAmount.Currency
+	}
+	return ""
+}
+
+func (c *Coupon) ActiveAt(t time.Time) bool {
+	return !t.Before(c.ValidFrom) && (c.ValidUntil.IsZero() || t.Before(c.ValidUntil))
+}
+
+// Discount は値引き前の請求額 amount に対する値引き額（amount を超えない）。
+// 利用回数の上限は見ない（利用の記録時に確認する）
+func (c *Coupon) Discount(amount money.Money, now time.Time) (money.Money, error) {
+	if !c.ActiveAt(now) {
+		return money.Money{}, fmt.Errorf("%w: %s", ErrNotActive, c.Code)
+	}
+	if cur := c.Currency(); cur != "" && cur != amount.Currency {
+		return money.Money{}, fmt.Errorf("%w: %s is only for %s", ErrNotApplicable, c.Code, cur)
+	}
+	if c.MinAmount.Amount > 0 && amount.Amount < c.MinAmount.Amount {
+		return money.Money{}, fmt.Errorf("%w: %s requires an order of at least %s", ErrNotApplicable, c.Code, c.MinAmount)
+	}
+
+	var off int64
+	switch c.Kind {
+	case KindPercentage:
+		// 端数は切り捨て（値引きしすぎない）。amount × percent が溢れないよう分けて計算する
+		off = amount.Amount/100*c.PercentOff + amount.Amount%100*c.PercentOff/100
+	case KindFixed:
+		off = min(c.AmountOff.Amount, amount.Amount)
+	default:
+		return money.Money{}, fmt.Errorf("%w: unknown kind %q", ErrInvalidCoupon, c.Kind)
+	}
+	return money.Money{Amount: off, Currency: amount.Currency}, nil
+}
+
+// Redemption はクーポンの利用 1 回（1 注文に 1 つまで）
+type Redemption struct {
+	ID        string
+	CouponID  string
+	Code      string
+	OrderID   string
+	UserID    string
+	Discount  money.Money
+	CreatedAt time.Time
+}
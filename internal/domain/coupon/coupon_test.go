@@ -0,0 +1,85 @@
+package coupon_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain/coupon"
+	"github.com/kazshi01/payment-system/internal/domain/money"
+)
+
+func jpy(n int64) money.Money { return money.Money{Amount: n, Currency: money.JPY} }
+
+func TestCoupon_Discount(t *testing.T) {
+	now := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	pct := &coupon.Coupon{Code: "PCT15", Kind: coupon.KindPercentage, PercentOff: 15, ValidFrom: now}
+	fixed := &coupon.Coupon{Code: "OFF500", Kind: coupon.KindFixed, AmountOff: jpy(500), MinAmount: jpy(1000), ValidFrom: now, ValidUntil: now.Add(24 * time.Hour)}
+
+	tests := []struct {
+		name   string
+		c      *coupon.Coupon
+		amount money.Money
+		at     time.Time
+		want   money.Money
+		err    error
+	}{
+		{"percentage floors", pct, jpy(999), now, jpy(149), nil}, // 149.85
+		{"percentage any currency", pct, money.Money{Amount: 1999, Currency: money.USD}, now, money.Money{Amount: 299, Currency: money.USD}, nil},
+		{"fixed", fixed, jpy(1000), now, jpy(500), nil},
+		{"below min amount", fixed, jpy(999), now, money.Money{}, coupon.ErrNotApplicable},
+		{"other currency", fixed, money.Money{Amount: 5000, Currency: money.USD}, now, money.Money{}, coupon.ErrNotApplicable},
+		{"before window", fixed, jpy(1000), now.Add(-time.Second), money.Money{}, coupon.ErrNotActive},
+		{"valid_until is exclusive", fixed, jpy(1000), now.Add(24 * time.Hour), money.Money{}, coupon.ErrNotActive},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.c.Discount(tt.amount, tt.at)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v; want %v", err, tt.err)
+			}
+			if got != tt.want {
+				t.Fatalf("discount = %v; want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCoupon_Discount_capsAtAmount(t *testing.T) {
+	now := time.Now()
+	c := &coupon.Coupon{Code: "BIG", Kind: coupon.KindFixed, AmountOff: jpy(5000), ValidFrom: now}
+	got, err := c.Discount(jpy(1200), now)
+	if err != nil || got != jpy(1200) {
+		t.Fatalf("discount = %v, %v; want 1200 JPY", got, err)
+	}
+}
+
+func TestCoupon_Validate(t *testing.T) {
+	now := time.Now()
+	ok := coupon.Coupon{Code: "SPRING", Kind: coupon.KindPercentage, PercentOff: 10, ValidFrom: now}
+	if err := ok.Validate(); err != nil {
+		t.Fatalf("Validate err = %v", err)
+	}
+
+	bad := map[string]func(c *coupon.Coupon){
+		"code":    func(c *coupon.Coupon) { c.Code = "a b" },
+		"percent": func(c *coupon.Coupon) { c.PercentOff = 0 },
+		"kind":    func(c *coupon.Coupon) { c.Kind = "bogo" },
+		"fixed no curr": func(c *coupon.Coupon) {
+			c.Kind, c.PercentOff, c.AmountOff = coupon.KindFixed, 0, money.Money{Amount: 100}
+		},
+		"mixed curr": func(c *coupon.Coupon) {
+			c.Kind, c.PercentOff, c.AmountOff = coupon.KindFixed, 0, jpy(100)
+			c.MinAmount = money.Money{Amount: 1000, Currency: money.USD}
+		},
+		"limits": func(c *coupon.Coupon) { c.MaxPerUser = -1 },
+		"window": func(c *coupon.Coupon) { c.ValidUntil = c.ValidFrom },
+	}
+	for name, mutate := range bad {
+		c := ok
+		mutate(&c)
+		if err := c.Validate(); !errors.Is(err, coupon.ErrInvalidCoupon) {
+			t.Errorf("%s: err = %v; want ErrInvalidCoupon", name, err)
+		}
+	}
+}
@@ -8,4 +8,13 @@ var (
 	ErrNotFound        = errors.New("not found")
 	ErrInternal        = errors.New("internal error")
 	ErrUnauthorized    = errors.New("unauthorized")
+	ErrForbidden       = errors.New("forbidden")
+
+	// 同じ Idempotency-Key を別のリクエスト内容で再利用した
+	ErrIdempotencyKeyReused = errors.New("idempotency key reused with different request")
+
+	// 外部決済(PG)起因
+	ErrPaymentDeclined           = errors.New("payment declined")
+	ErrPaymentPending            = errors.New("payment pending") // 3-D Secure 等で結果が Webhook で届く
+	ErrPaymentGatewayUnavailable = errors.New("payment gateway unavailable")
 )
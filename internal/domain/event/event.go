@@ -0,0 +1,38 @@
+package event
+
+import "time"
+
+type ID string
+type Type string
+
+// 注文ライフサイクル上の出来事（payment_events.type）
+const (
+	TypeOrderCreated    Type = "ORDER_CREATED"
+	TypeChargeAttempted Type = "CHARGE_ATTEMPTED"
+	TypeChargeSucceeded Type = "CHARGE_SUCCEEDED"
+	TypeChargeFailed    Type = "CHARGE_FAILED"
+	TypeChargePending   Type = "CHARGE_PENDING"
+	TypeOrderAuthorized Type = "ORDER_AUTHORIZED"
+	TypeAuthFailed      Type = "AUTHORIZATION_FAILED"
+	TypeOrderCaptured   Type = "ORDER_CAPTURED"
+	TypeOrderVoided     Type = "ORDER_VOIDED"
+	TypeOrderPaid       Type = "ORDER_PAID"
+	TypeOrderCanceled   Type = "ORDER_CANCELED"
+	TypeOrderRefunded   Type = "ORDER_REFUNDED"
+	TypeDisputeOpened   Type = "DISPUTE_OPENED"
+	TypePaymentReview   Type = "PAYMENT_NEEDS_REVIEW" // 決済の通知を自動で反映できなかった（金額・通貨の不一致）
+
+	TypeBankTransferIssued Type = "BANK_TRANSFER_ISSUED" // 振込先を発行した
+	TypeDepositReview      Type = "DEPOSIT_NEEDS_REVIEW" // 入金を自動で消し込めなかった
+
+	TypeKonbiniIssued Type = "KONBINI_CODE_ISSUED" // コンビニ払いの払込番号を発行した
+)
+
+// Event は追記専用の監査ログ 1 件
+type Event struct {
+	ID        ID
+	OrderID   string
+	Type      Type
+	Payload   map[string]any // JSONB として保存
+	CreatedAt time.Time
+}
@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// IdempotencyRecord は Idempotency-Key ごとに保存する最初のレスポンス
+type IdempotencyRecord struct {
+	UserID      string
+	Key         string
+	Fingerprint string // リクエスト（メソッド・パス・ボディ）のハッシュ
+
+	// 処理中は StatusCode = 0
+	StatusCode int
+	Header     map[string][]string
+	Body       []byte
+
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+func (r *IdempotencyRecord) Completed() bool { return r.StatusCode != 0 }
+
+// IdempotencyStore はユーザ単位で Idempotency-Key を保持する
+type IdempotencyStore interface {
+	// Reserve はキーを処理中として確保する。既に有効なキーがあれば確保せずそれを返す
+	// （期限切れのキーは上書きして確保する）
+	Reserve(ctx context.Context, rec *IdempotencyRecord) (existing *IdempotencyRecord, err error)
+	// Complete は確保したキーにレスポンスを保存する
+	Complete(ctx context.Context, rec *IdempotencyRecord) error
+	// Release は処理中のキーを解放する（リトライ可能な失敗時）
+	Release(ctx context.Context, userID, key string) error
+	// DeleteExpired は期限切れのキーを最大 limit 件削除する
+	DeleteExpired(ctx context.Context, now time.Time, limit int) (int64, error)
+}
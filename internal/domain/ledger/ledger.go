@@ -0,0 +1,215 @@
+// Package ledger は複式簿記の元帳（勘定・仕訳・明細）
+//
+// 明細（Posting）の金額は借方を正、貸方を負で持ち、1 つの仕訳の明細は通貨ごとに合計 0 になる。
+package ledger
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain/money"
+)
+
+var (
+	ErrInvalidEntry   = errors.New("invalid journal entry")
+	ErrUnbalanced     = errors.New("journal entry does not balance")
+	ErrUnknownAccount = errors.New("unknown account")
+)
+
+// Account は勘定科目のコード
+type Account string
+
+const (
+	// PG に対する売掛金。決済で増え、返金・手数料で減る（入金されれば 0 に近づく）
+	AccountProviderReceivable Account = "provider_receivable"
+	AccountBank               Account = "bank"            // 普通預金（銀行振込の入金は PG を通らず口座に直接入る）
+	AccountSales              Account = "sales"           // 売上
+	AccountSalesRefunds       Account = "sales_refunds"   // 売上の返金（売上の控除）
+	AccountProcessingFees     Account = "processing_fees" // 決済手数料
+)
+
+// AccountType は勘定の分類。残高を借方・貸方のどちらで見るかが決まる
+type AccountType string
+
+const (
+	TypeAsset   AccountType = "asset"
+	TypeRevenue AccountType = "revenue"
+	TypeExpense AccountType = "expense"
+)
+
+var accounts = map[Account]AccountType{
+	AccountProviderReceivable: TypeAsset,
+	AccountBank:               TypeAsset,
+	AccountSales:              TypeRevenue,
+	AccountSalesRefunds:       TypeExpense, // 売上の控除なので借方残
+	AccountProcessingFees:     TypeExpense,
+}
+
+// ParseAccount は勘定コードを検証する
+func ParseAccount(s string) (Account, error) {
+	a := Account(s)
+	if _, ok := accounts[a]; !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownAccount, s)
+	}
+	return a, nil
+}
+
+func (a Account) Type() AccountType { return accounts[a] }
+
+// Kind は仕訳の種類（何のお金の動きか）
+type Kind string
+
+const (
+	KindCharge Kind = "charge" // 決済の確定
+	KindRefund Kind = "refund" // 返金
+	KindFee    Kind = "fee"    // 決済手数料
+)
+
+// Posting は仕訳の明細 1 行。Amount は借方なら正、貸方なら負
+type Posting struct {
+	Account Account
+	Amount  money.Money
+}
+
+// Entry は仕訳。Reference は元になった決済・返金の ID で、(Kind, Reference) で一意
+type Entry struct {
+	ID        string
+	Kind      Kind
+	OrderID   string
+	Reference string
+	Postings  []Posting
+	CreatedAt time.Time
+}
+
+// Validate は明細が 2 行以上あり、通貨ごとに貸借が一致することを検証する
+func (e *Entry) Validate() error {
+	switch e.Kind {
+	case KindCharge, KindRefund, KindFee:
+	default:
+		return fmt.Errorf("%w: unknown kind %q", ErrInvalidEntry, e.Kind)
+	}
+	if e.Reference == "" {
+		return fmt.Errorf("%w: reference is required", ErrInvalidEntry)
+	}
+	if len(e.Postings) < 2 {
+		return fmt.Errorf("%w: needs at least 2 postings", ErrInvalidEntry)
+	}
+	sums := map[money.Currency]int64{}
+	for _, p := range e.Postings {
+		if _, err := ParseAccount(string(p.Account)); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidEntry, err)
+		}
+		if !p.Amount.Currency.Valid() || p.Amount.IsZero() {
+			return fmt.Errorf("%w: posting to %s must be a non-zero amount in a known currency", ErrInvalidEntry, p.Account)
+		}
+		sums[p.Amount.Currency] += p.Amount.Amount
+	}
+	for c, sum := range sums {
+		if sum != 0 {
+			return fmt.Errorf("%w: %s is off by %s", ErrUnbalanced, c, money.Money{Amount: sum, Currency: c})
+		}
+	}
+	return nil
+}
+
+// 借方 debit / 貸方 credit に同額を計上する 2 行の仕訳
+func entry(kind Kind, orderID, ref string, debit, credit Account, amount money.Money, at time.Time) *Entry {
+	return &Entry{
+		Kind:      kind,
+		OrderID:   orderID,
+		Reference: ref,
+		Postings: []Posting{
+			{Account: debit, Amount: amount},
+			{Account: credit, Amount: money.Money{Amount: -amount.Amount, Currency: amount.Currency}},
+		},
+		CreatedAt: at,
+	}
+}
+
+// Charge は決済の確定（売掛金 / 売上）。ref は決済 ID
+func Charge(orderID, ref string, amount money.Money, at time.Time) *Entry {
+	return entry(KindCharge, orderID, ref, AccountProviderReceivable, AccountSales, amount, at)
+}
+
+// BankCharge は銀行振込の入金による決済の確定（普通預金 / 売上）。ref は決済 ID
+func BankCharge(orderID, ref string, amount money.Money, at time.Time) *Entry {
+	return entry(KindCharge, orderID, ref, AccountBank, AccountSales, amount, at)
+}
+
+// Refund は返金（売上返金 / 売掛金）。ref は返金 ID
+func Refund(orderID, ref string, amount money.Money, at time.Time) *Entry {
+	return entry(KindRefund, orderID, ref, AccountSalesRefunds, AccountProviderReceivable, amount, at)
+}
+
+// Fee は PG に差し引かれる手数料（支払手数料 / 売掛金）。ref は決済 ID
+func Fee(orderID, ref string, amount money.Money, at time.Time) *Entry {
+	return entry(KindFee, orderID, ref, AccountProcessingFees, AccountProviderReceivable, amount, at)
+}
+
+// FeeRate は決済額に対する手数料率（ベーシスポイント。360 = 3.6%）
+type FeeRate int64
+
+// Apply は手数料を最小単位未満切り捨てで計算する
+func (r FeeRate) Apply(m money.Money) money.Money {
+	return money.Money{Amount: m.Amount * int64(r) / 10_000, Currency: m.Currency}
+}
+
+// Balance は勘定の期間内の借方・貸方の合計
+type Balance struct {
+	Account  Account
+	Currency money.Currency
+	Debit    int64 // 借方合計（正）
+	Credit   int64 // 貸方合計（正）
+}
+
+// Net は勘定の種類に応じた残高（資産・費用は借方 - 貸方、収益は貸方 - 借方）
+func (b Balance) Net() money.Money {
+	n := b.Debit - b.Credit
+	if b.Account.Type() == TypeRevenue {
+		n = -n
+	}
+	return money.Money{Amount: n, Currency: b.Currency}
+}
+
+// ViolationKind は不変条件違反の種類
+type ViolationKind string
+
+const (
+	ViolationUnbalanced ViolationKind = "unbalanced_entry" // 貸借が一致しない仕訳
+	ViolationUnposted   ViolationKind = "unposted_order"   // 決済・返金額と仕訳が一致しない注文
+)
+
+// Violation は元帳の不変条件違反 1 件
+type Violation struct {
+	Kind    ViolationKind
+	EntryID string // ViolationUnbalanced のみ
+	OrderID string
+	Detail  string
+}
+
+// UnbalancedEntry は通貨 currency の明細の合計が total（明細 postings 行）の仕訳
+func UnbalancedEntry(entryID, orderID string, total money.Money, postings int64) Violation {
+	detail := fmt.Sprintf("%d postings in %s sum to %s", postings, total.Currency, total)
+	if postings == 0 {
+		detail = "no postings"
+	}
+	return Violation{Kind: ViolationUnbalanced, EntryID: entryID, OrderID: orderID, Detail: detail}
+}
+
+// UnpostedOrder は決済・返金の合計と元帳への計上額が一致しない注文（金額は最小単位）
+func UnpostedOrder(orderID, status string, paid, charged, refunded, refundPosted int64) Violation {
+	return Violation{
+		Kind:    ViolationUnposted,
+		OrderID: orderID,
+		Detail: fmt.Sprintf("%s order: paid %d / charged %d, refunded %d / posted %d",
+			status, paid, charged, refunded, refundPosted),
+	}
+}
+
+func (v Violation) String() string {
+	if v.Kind == ViolationUnbalanced {
+		return fmt.Sprintf("%s entry=%s: %s", v.Kind, v.EntryID, v.Detail)
+	}
+	return fmt.Sprintf("%s order=%s: %s", v.Kind, v.OrderID, v.Detail)
+}
@@ -0,0 +1,83 @@
+package ledger_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain/ledger"
+	"github.com/kazshi01/payment-system/internal/domain/money"
+)
+
+func jpy(n int64) money.Money { return money.Money{Amount: n, Currency: money.JPY} }
+
+func TestEntry_Validate(t *testing.T) {
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	usd := func(n int64) money.Money { return money.Money{Amount: n, Currency: money.USD} }
+
+	tests := []struct {
+		name string
+		e    *ledger.Entry
+		err  error
+	}{
+		{"charge", ledger.Charge("o1", "p1", jpy(1000), now), nil},
+		{"refund", ledger.Refund("o1", "r1", jpy(300), now), nil},
+		{"fee", ledger.Fee("o1", "p1", jpy(36), now), nil},
+		{"unbalanced", &ledger.Entry{Kind: ledger.KindCharge, Reference: "p1", Postings: []ledger.Posting{
+			{Account: ledger.AccountProviderReceivable, Amount: jpy(1000)},
+			{Account: ledger.AccountSales, Amount: jpy(-999)},
+		}}, ledger.ErrUnbalanced},
+		// 通貨をまたいで合計が 0 でも貸借は一致しない
+		{"balanced across currencies", &ledger.Entry{Kind: ledger.KindCharge, Reference: "p1", Postings: []ledger.Posting{
+			{Account: ledger.AccountProviderReceivable, Amount: jpy(100)},
+			{Account: ledger.AccountSales, Amount: usd(-100)},
+		}}, ledger.ErrUnbalanced},
+		{"single posting", &ledger.Entry{Kind: ledger.KindFee, Reference: "p1", Postings: []ledger.Posting{
+			{Account: ledger.AccountProcessingFees, Amount: jpy(0)},
+		}}, ledger.ErrInvalidEntry},
+		{"zero posting", ledger.Charge("o1", "p1", jpy(0), now), ledger.ErrInvalidEntry},
+		{"unknown account", &ledger.Entry{Kind: ledger.KindCharge, Reference: "p1", Postings: []ledger.Posting{
+			{Account: "cash", Amount: jpy(100)},
+			{Account: ledger.AccountSales, Amount: jpy(-100)},
+		}}, ledger.ErrUnknownAccount},
+		{"no reference", ledger.Charge("o1", "", jpy(100), now), ledger.ErrInvalidEntry},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.e.Validate(); !errors.Is(err, tt.err) {
+				t.Fatalf("Validate() = %v; want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestFeeRate_Apply(t *testing.T) {
+	tests := []struct {
+		rate ledger.FeeRate
+		m    money.Money
+		want money.Money
+	}{
+		{360, jpy(1000), jpy(36)},
+		{360, jpy(999), jpy(35)}, // 35.964 は切り捨て
+		{360, jpy(27), jpy(0)},
+		{0, jpy(1000), jpy(0)},
+		{290, money.Money{Amount: 1999, Currency: money.USD}, money.Money{Amount: 57, Currency: money.USD}},
+	}
+	for _, tt := range tests {
+		if got := tt.rate.Apply(tt.m); got != tt.want {
+			t.Fatalf("FeeRate(%d).Apply(%s) = %s; want %s", tt.rate, tt.m, got, tt.want)
+		}
+	}
+}
+
+func TestBalance_Net(t *testing.T) {
+	// 売掛金（資産）は借方残、売上（収益）は貸方残で正になる
+	recv := ledger.Balance{Account: ledger.AccountProviderReceivable, Currency: money.JPY, Debit: 1000, Credit: 336}
+	if got := recv.Net(); got != jpy(664) {
+		t.Fatalf("receivable Net() = %s; want 664 JPY", got)
+	}
+	sales := ledger.Balance{Account: ledger.AccountSales, Currency: money.JPY, Credit: 1000}
+	if got := sales.Net(); got != jpy(1000) {
+		t.Fatalf("sales Net() = %s; want 1000 JPY", got)
+	}
+}
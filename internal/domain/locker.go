@@ -1,10 +1,23 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"time"
+)
+
+// Lock は取得済みのロック
+type Lock struct {
+	Key   string
+	Token string // 延長・解放時の本人確認用
+	Fence int64  // 取得のたびに単調増加するフェンシングトークン（書き込み側で古い保持者を弾く）
+}
 
 type Locker interface {
-	TryLock(ctx context.Context, key string, ttlSeconds int) (ok bool, token string, err error)
-	Unlock(ctx context.Context, key, token string) error
+	// 他が保持中なら ok=false（エラーではない）
+	TryLock(ctx context.Context, key string, ttl time.Duration) (ok bool, lock Lock, err error)
+	// まだ保持していれば TTL を延長する。既に失っていれば ok=false
+	Extend(ctx context.Context, lock Lock, ttl time.Duration) (ok bool, err error)
+	Unlock(ctx context.Context, lock Lock) error
 	Ping(ctx context.Context) error
 	Close() error
 }
@@ -0,0 +1,69 @@
+// Package merchant は加盟店（注文の所有ユーザ）ごとの設定
+package merchant
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/kazshi01/payment-system/internal/domain/tax"
+)
+
+var (
+	ErrInvalidIssuer             = errors.New("invalid issuer")
+	ErrInvalidRegistrationNumber = errors.New("invalid registration number")
+)
+
+type Settings struct {
+	UserID      string
+	TaxRounding tax.Rounding // 消費税の端数処理
+	Issuer      Issuer       // 領収書の発行者
+	UpdatedAt   time.Time
+}
+
+// Default は未設定の加盟店に使う設定
+func Default(userID string) *Settings {
+	return &Settings{UserID: userID, TaxRounding: tax.DefaultRounding}
+}
+
+// Issuer は領収書（適格請求書）に記載する発行者の情報
+type Issuer struct {
+	Name               string
+	Address            string
+	RegistrationNumber string // 適格請求書発行事業者の登録番号（未登録なら空）
+}
+
+// 領収書の 1 行に収まる長さ（文字数）
+const (
+	MaxIssuerNameLen    = 60
+	MaxIssuerAddressLen = 100
+)
+
+// Validate は発行者の情報を検証する（未設定の項目は空のまま許す）
+func (i Issuer) Validate() error {
+	if n := utf8.RuneCountInString(i.Name); n > MaxIssuerNameLen {
+		return fmt.Errorf("%w: name must be at most %d characters", ErrInvalidIssuer, MaxIssuerNameLen)
+	}
+	if n := utf8.RuneCountInString(i.Address); n > MaxIssuerAddressLen {
+		return fmt.Errorf("%w: address must be at most %d characters", ErrInvalidIssuer, MaxIssuerAddressLen)
+	}
+	if strings.ContainsFunc(i.Name+i.Address, unicode.IsControl) {
+		return fmt.Errorf("%w: name and address must be a single line", ErrInvalidIssuer)
+	}
+	return ValidateRegistrationNumber(i.RegistrationNumber)
+}
+
+// 登録番号は "T" + 13 桁の数字
+var registrationNumber = regexp.MustCompile(`^T[0-9]{13}$`)
+
+// ValidateRegistrationNumber は登録番号の形式を検証する（空は未登録として許す）
+func ValidateRegistrationNumber(s string) error {
+	if s != "" && !registrationNumber.MatchString(s) {
+		return fmt.Errorf("%w: %q must be T followed by 13 digits", ErrInvalidRegistrationNumber, s)
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+// Package money は通貨付きの金額（最小単位の整数）を扱う
+package money
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrUnknownCurrency  = errors.New("unknown currency")
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	ErrOutOfRange       = errors.New("amount out of range")
+)
+
+// Currency は ISO 4217 の通貨コード（大文字）
+type Currency string
+
+const (
+	JPY Currency = "JPY"
+	USD Currency = "USD"
+	EUR Currency = "EUR"
+)
+
+type currencyInfo struct {
+	exponent int   // 小数点以下の桁数（JPY=0, USD=2）
+	min, max int64 // 1 回の決済で扱える金額（最小単位）
+}
+
+// 対応通貨。通貨を増やすときはここに追加する
+// 上下限は PG（Stripe）の制約に合わせる
+var currencies = map[Currency]currencyInfo{
+	JPY: {exponent: 0, min: 50, max: 99_999_999},
+	USD: {exponent: 2, min: 50, max: 99_999_999},
+	EUR: {exponent: 2, min: 50, max: 99_999_999},
+}
+
+// ParseCurrency は大文字小文字を区別せず通貨コードを解釈する
+func ParseCurrency(s string) (Currency, error) {
+	c := Currency(strings.ToUpper(strings.TrimSpace(s)))
+	if !c.Valid() {
+		return "", fmt.Errorf("%w: %q", ErrUnknownCurrency, s)
+	}
+	return c, nil
+}
+
+func (c Currency) Valid() bool {
+	_, ok := currencies[c]
+	return ok
+}
+
+// Exponent は最小単位の桁数（1 USD = 10^2 セント）
+func (c Currency) Exponent() int { return currencies[c].exponent }
+
+// Lower は PG の API に渡す小文字表記（"jpy"）
+func (c Currency) Lower() string { return strings.ToLower(string(c)) }
+
+// Money は最小単位（JPY は円、USD はセント）の金額
+type Money struct {
+	Amount   int64
+	Currency Currency
+}
+
+// New は通貨を検証して Money を作る（金額の上下限は見ない）
+func New(amount int64, c Currency) (Money, error) {
+	if !c.Valid() {
+		return Money{}, fmt.Errorf("%w: %q", ErrUnknownCurrency, c)
+	}
+	return Money{Amount: amount, Currency: c}, nil
+}
+
+// ParseMajor は "1234.5" のような主単位の 10 進表記を最小単位の金額にする。
+// 通貨の桁数より細かい端数はエラー（"12.345 USD" は丸めない）
+func ParseMajor(s string, c Currency) (Money, error) {
+	if !c.Valid() {
+		return Money{}, fmt.Errorf("%w: %q", ErrUnknownCurrency, c)
+	}
+	sign, digits := int64(1), strings.TrimSpace(s)
+	if rest, ok := strings.CutPrefix(digits, "-"); ok {
+		sign, digits = -1, rest
+	}
+	whole, frac, _ := strings.Cut(digits, ".")
+	exp := c.Exponent()
+	if whole == "" || len(frac) > exp || strings.ContainsAny(whole+frac, "+-") {
+		return Money{}, fmt.Errorf("invalid amount %q for %s", s, c)
+	}
+	frac += strings.Repeat("0", exp-len(frac))
+	n, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("invalid amount %q for %s", s, c)
+	}
+	return Money{Amount: sign * n, Currency: c}, nil
+}
+
+// Zero は指定通貨の 0
+func Zero(c Currency) Money { return Money{Currency: c} }
+
+func (m Money) IsZero() bool     { return m.Amount == 0 }
+func (m Money) IsPositive() bool { return m.Amount > 0 }
+
+func (m Money) sameCurrency(o Money) error {
+	if m.Currency != o.Currency {
+		return fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, o.Currency)
+	}
+	return nil
+}
+
+// Add は同じ通貨同士のみ足せる
+func (m Money) Add(o Money) (Money, error) {
+	if err := m.sameCurrency(o); err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: m.Amount + o.Amount, Currency: m.Currency}, nil
+}
+
+// Sub は同じ通貨同士のみ引ける
+func (m Money) Sub(o Money) (Money, error) {
+	if err := m.sameCurrency(o); err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: m.Amount - o.Amount, Currency: m.Currency}, nil
+}
+
+// Cmp は m < o なら -1、等しければ 0、m > o なら 1
+func (m Money) Cmp(o Money) (int, error) {
+	if err := m.sameCurrency(o); err != nil {
+		return 0, err
+	}
+	switch {
+	case m.Amount < o.Amount:
+		return -1, nil
+	case m.Amount > o.Amount:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// ValidateCharge は 1 回の決済として扱える金額か（通貨ごとの上下限）を検証する
+func (m Money) ValidateCharge() error {
+	info, ok := currencies[m.Currency]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownCurrency, m.Currency)
+	}
+	if m.Amount < info.min || m.Amount > info.max {
+		return fmt.Errorf("%w: %s must be between %s and %s", ErrOutOfRange,
+			m, Money{info.min, m.Currency}, Money{info.max, m.Currency})
+	}
+	return nil
+}
+
+// String は "1200 JPY" / "12.34 USD" 形式
+func (m Money) String() string {
+	exp := m.Currency.Exponent()
+	if exp == 0 {
+		return strconv.FormatInt(m.Amount, 10) + " " + string(m.Currency)
+	}
+
+	sign, a := "", m.Amount
+	if a < 0 {
+		sign, a = "-", -a
+	}
+	unit := int64(1)
+	for range exp {
+		unit *= 10
+	}
+	return fmt.Sprintf("%s%d.%0*d %s", sign, a/unit, exp, a%unit, m.Currency)
+}
@@ -0,0 +1,113 @@
+package money_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kazshi01/payment-system/internal/domain/money"
+)
+
+func TestParseCurrency(t *testing.T) {
+	c, err := money.ParseCurrency("usd")
+	if err != nil || c != money.USD {
+		t.Fatalf("ParseCurrency(usd) = %q, %v; want USD", c, err)
+	}
+	if _, err := money.ParseCurrency("XXX"); !errors.Is(err, money.ErrUnknownCurrency) {
+		t.Fatalf("err = %v; want ErrUnknownCurrency", err)
+	}
+	if money.JPY.Exponent() != 0 || money.USD.Exponent() != 2 || money.EUR.Exponent() != 2 {
+		t.Fatal("unexpected exponent")
+	}
+}
+
+func TestMoney_arithmetic(t *testing.T) {
+	a := money.Money{Amount: 1050, Currency: money.USD}
+	b := money.Money{Amount: 250, Currency: money.USD}
+
+	sum, err := a.Add(b)
+	if err != nil || sum != (money.Money{Amount: 1300, Currency: money.USD}) {
+		t.Fatalf("Add = %v, %v", sum, err)
+	}
+	diff, err := a.Sub(b)
+	if err != nil || diff.Amount != 800 {
+		t.Fatalf("Sub = %v, %v", diff, err)
+	}
+	if c, err := b.Cmp(a); err != nil || c != -1 {
+		t.Fatalf("Cmp = %d, %v; want -1", c, err)
+	}
+
+	yen := money.Money{Amount: 100, Currency: money.JPY}
+	if _, err := a.Add(yen); !errors.Is(err, money.ErrCurrencyMismatch) {
+		t.Fatalf("Add(mixed) err = %v; want ErrCurrencyMismatch", err)
+	}
+	if _, err := a.Sub(yen); !errors.Is(err, money.ErrCurrencyMismatch) {
+		t.Fatalf("Sub(mixed) err = %v; want ErrCurrencyMismatch", err)
+	}
+	if _, err := a.Cmp(yen); !errors.Is(err, money.ErrCurrencyMismatch) {
+		t.Fatalf("Cmp(mixed) err = %v; want ErrCurrencyMismatch", err)
+	}
+}
+
+func TestMoney_ValidateCharge(t *testing.T) {
+	tests := []struct {
+		m  money.Money
+		ok bool
+	}{
+		{money.Money{Amount: 1200, Currency: money.JPY}, true},
+		{money.Money{Amount: 49, Currency: money.JPY}, false},
+		{money.Money{Amount: 50, Currency: money.USD}, true},
+		{money.Money{Amount: 100_000_000, Currency: money.EUR}, false},
+		{money.Money{Amount: 1000, Currency: "XXX"}, false},
+	}
+	for _, tt := range tests {
+		err := tt.m.ValidateCharge()
+		if (err == nil) != tt.ok {
+			t.Errorf("ValidateCharge(%v) = %v; want ok=%v", tt.m, err, tt.ok)
+		}
+	}
+}
+
+func TestMoney_String(t *testing.T) {
+	tests := []struct {
+		m    money.Money
+		want string
+	}{
+		{money.Money{Amount: 1200, Currency: money.JPY}, "1200 JPY"},
+		{money.Money{Amount: 1234, Currency: money.USD}, "12.34 USD"},
+		{money.Money{Amount: 5, Currency: money.EUR}, "0.05 EUR"},
+		{money.Money{Amount: -150, Currency: money.USD}, "-1.50 USD"},
+	}
+	for _, tt := range tests {
+		if got := tt.m.String(); got != tt.want {
+			t.Errorf("String() = %q; want %q", got, tt.want)
+		}
+	}
+}
+
+func TestParseMajor(t *testing.T) {
+	tests := []struct {
+		s    string
+		c    money.Currency
+		want int64
+		ok   bool
+	}{
+		{"1200", money.JPY, 1200, true},
+		{"12.34", money.USD, 1234, true},
+		{"12.5", money.USD, 1250, true},
+		{"12", money.USD, 1200, true},
+		{"-0.05", money.EUR, -5, true},
+		{" 7.00 ", money.USD, 700, true},
+		{"12.345", money.USD, 0, false}, // 端数は丸めない
+		{"1200.5", money.JPY, 0, false},
+		{".5", money.USD, 0, false},
+		{"1,200", money.JPY, 0, false},
+		{"--1", money.JPY, 0, false},
+		{"", money.JPY, 0, false},
+	}
+	for _, tt := range tests {
+		got, err := money.ParseMajor(tt.s, tt.c)
+		if (err == nil) != tt.ok || (tt.ok && got != (money.Money{Amount: tt.want, Currency: tt.c})) {
+			t.Fatalf("ParseMajor(%q, %s) = %v, %v; want %d ok=%v", tt.s, tt.c, got, err, tt.want, tt.ok)
+		}
+	}
+}
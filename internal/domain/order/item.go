@@ -0,0 +1,96 @@
+package order
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/kazshi01/payment-system/internal/domain/money"
+	"github.com/kazshi01/payment-system/internal/domain/tax"
+)
+
+var ErrInvalidItem = errors.New("invalid order item")
+
+// 1 注文あたりの明細数の上限
+const MaxItems = 100
+
+// Item は注文明細 1 行。単価（税抜）と税率は購入時点の値を保存する
+type Item struct {
+	SKU       string
+	Name      string
+	UnitPrice money.Money
+	Quantity  int64
+	TaxRate   tax.Rate
+}
+
+// Subtotal は単価 × 数量（税抜）
+func (it Item) Subtotal() (money.Money, error) {
+	if it.SKU == "" || it.Name == "" {
+		return money.Money{}, fmt.Errorf("%w: sku and name are required", ErrInvalidItem)
+	}
+	if !it.UnitPrice.Currency.Valid() {
+		return money.Money{}, fmt.Errorf("%w: %s: %w", ErrInvalidItem, it.SKU, money.ErrUnknownCurrency)
+	}
+	if it.UnitPrice.Amount < 0 {
+		return money.Money{}, fmt.Errorf("%w: %s: unit price must be >= 0", ErrInvalidItem, it.SKU)
+	}
+	if !it.TaxRate.Valid() {
+		return money.Money{}, fmt.Errorf("%w: %s: %w", ErrInvalidItem, it.SKU, tax.ErrInvalidRate)
+	}
+	if it.Quantity <= 0 {
+		return money.Money{}, fmt.Errorf("%w: %s: quantity must be > 0", ErrInvalidItem, it.SKU)
+	}
+	if it.UnitPrice.Amount > math.MaxInt64/it.Quantity {
+		return money.Money{}, fmt.Errorf("%w: %s: subtotal overflows", ErrInvalidItem, it.SKU)
+	}
+	return money.Money{Amount: it.UnitPrice.Amount * it.Quantity, Currency: it.UnitPrice.Currency}, nil
+}
+
+// Totals は明細から計算した注文金額
+type Totals struct {
+	Subtotal money.Money  // 税抜合計
+	Tax      []tax.Bucket // 税率ごとの内訳
+	Total    money.Money  // 税込合計（請求額）
+}
+
+// ComputeTotals は明細の合計と消費税を計算する。明細はすべて同じ通貨でなければならない
+// 税額の端数処理は税率ごとに 1 回（rounding は加盟店の設定）
+func ComputeTotals(items []Item, rounding tax.Rounding) (Totals, error) {
+	if len(items) == 0 {
+		return Totals{}, fmt.Errorf("%w: no items", ErrInvalidItem)
+	}
+	if len(items) > MaxItems {
+		return Totals{}, fmt.Errorf("%w: too many items (max %d)", ErrInvalidItem, MaxItems)
+	}
+
+	c := items[0].UnitPrice.Currency
+	subtotal := money.Zero(c)
+	lines := make([]tax.Line, 0, len(items))
+	for _, it := range items {
+		sub, err := it.Subtotal()
+		if err != nil {
+			return Totals{}, err
+		}
+		if sub.Amount > math.MaxInt64-subtotal.Amount {
+			return Totals{}, fmt.Errorf("%w: total overflows", ErrInvalidItem)
+		}
+		if subtotal, err = subtotal.Add(sub); err != nil {
+			return Totals{}, fmt.Errorf("%w: %w", ErrInvalidItem, err)
+		}
+		lines = append(lines, tax.Line{Rate: it.TaxRate, Amount: sub})
+	}
+
+	buckets, err := tax.Compute(lines, rounding)
+	if err != nil {
+		return Totals{}, fmt.Errorf("%w: %w", ErrInvalidItem, err)
+	}
+	taxTotal, err := tax.Total(buckets, c)
+	if err != nil {
+		return Totals{}, fmt.Errorf("%w: %w", ErrInvalidItem, err)
+	}
+	if taxTotal.Amount > math.MaxInt64-subtotal.Amount {
+		return Totals{}, fmt.Errorf("%w: total overflows", ErrInvalidItem)
+	}
+	total, _ := subtotal.Add(taxTotal)
+	return Totals{Subtotal: subtotal, Tax: buckets, Total: total}, nil
+}
@@ -0,0 +1,57 @@
+package order_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/kazshi01/payment-system/internal/domain/money"
+	"github.com/kazshi01/payment-system/internal/domain/order"
+	"github.com/kazshi01/payment-system/internal/domain/tax"
+)
+
+func yen(n int64) money.Money { return money.Money{Amount: n, Currency: money.JPY} }
+
+func TestComputeTotals(t *testing.T) {
+	got, err := order.ComputeTotals([]order.Item{
+		{SKU: "A-1", Name: "コーヒー豆", UnitPrice: yen(1200), Quantity: 2, TaxRate: tax.RateReduced},
+		{SKU: "B-1", Name: "フィルター", UnitPrice: yen(300), Quantity: 1, TaxRate: tax.RateStandard},
+		{SKU: "C-1", Name: "おまけ", UnitPrice: yen(0), Quantity: 1, TaxRate: tax.RateStandard},
+	}, tax.RoundingFloor)
+	if err != nil {
+		t.Fatalf("ComputeTotals err = %v", err)
+	}
+	if got.Subtotal != yen(2700) || got.Total != yen(2922) {
+		t.Fatalf("totals = %+v; want subtotal 2700, total 2922", got)
+	}
+	want := []tax.Bucket{
+		{Rate: tax.RateStandard, Taxable: yen(300), Tax: yen(30)},
+		{Rate: tax.RateReduced, Taxable: yen(2400), Tax: yen(192)},
+	}
+	if len(got.Tax) != 2 || got.Tax[0] != want[0] || got.Tax[1] != want[1] {
+		t.Fatalf("tax = %+v; want %+v", got.Tax, want)
+	}
+}
+
+func TestComputeTotals_invalid(t *testing.T) {
+	ok := order.Item{SKU: "A-1", Name: "a", UnitPrice: yen(100), Quantity: 1, TaxRate: tax.RateStandard}
+	tests := []struct {
+		name  string
+		items []order.Item
+	}{
+		{"empty", nil},
+		{"no sku", []order.Item{{Name: "a", UnitPrice: yen(100), Quantity: 1}}},
+		{"bad tax rate", []order.Item{{SKU: "A-1", Name: "a", UnitPrice: yen(100), Quantity: 1, TaxRate: 5}}},
+		{"zero quantity", []order.Item{{SKU: "A-1", Name: "a", UnitPrice: yen(100), TaxRate: tax.RateStandard}}},
+		{"negative price", []order.Item{{SKU: "A-1", Name: "a", UnitPrice: yen(-1), Quantity: 1, TaxRate: tax.RateStandard}}},
+		{"unknown currency", []order.Item{{SKU: "A-1", Name: "a", UnitPrice: money.Money{Amount: 100, Currency: "XXX"}, Quantity: 1, TaxRate: tax.RateStandard}}},
+		{"mixed currency", []order.Item{ok, {SKU: "U-1", Name: "u", UnitPrice: money.Money{Amount: 100, Currency: money.USD}, Quantity: 1, TaxRate: tax.RateStandard}}},
+		{"overflow", []order.Item{{SKU: "A-1", Name: "a", UnitPrice: yen(math.MaxInt64 / 2), Quantity: 3, TaxRate: tax.RateStandard}}},
+		{"too many", make([]order.Item, order.MaxItems+1)},
+	}
+	for _, tt := range tests {
+		if _, err := order.ComputeTotals(tt.items, tax.RoundingFloor); !errors.Is(err, order.ErrInvalidItem) {
+			t.Errorf("%s: err = %v; want ErrInvalidItem", tt.name, err)
+		}
+	}
+}
@@ -1,24 +1,47 @@
 package order
 
-import "time"
+import (
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain/coupon"
+	"github.com/kazshi01/payment-system/internal/domain/money"
+	"github.com/kazshi01/payment-system/internal/domain/tax"
+)
 
 type ID string
 type Status string
 
 const (
-	StatusPending  Status = "PENDING"
-	StatusPaid     Status = "PAID"
-	StatusCanceled Status = "CANCELED"
+	StatusPending           Status = "PENDING"
+	StatusAuthorized        Status = "AUTHORIZED"
+	StatusAwaitingPayment   Status = "AWAITING_PAYMENT" // コンビニ払いの払込番号・銀行振込の振込先を発行し、支払いを待っている
+	StatusPaid              Status = "PAID"
+	StatusCanceled          Status = "CANCELED"
+	StatusPartiallyRefunded Status = "PARTIALLY_REFUNDED"
+	StatusRefunded          Status = "REFUNDED"
 )
 
+// 定義済みのステータスかどうか
+func (s Status) Valid() bool {
+	switch s {
+	case StatusPending, StatusAuthorized, StatusAwaitingPayment, StatusPaid, StatusCanceled, StatusPartiallyRefunded, StatusRefunded:
+		return true
+	}
+	return false
+}
+
 type Order struct {
 	ID        ID
 	UserID    string
-	AmountJPY int64
+	Amount    money.Money
 	Status    Status
 	CreatedAt time.Time
 	UpdatedAt time.Time
-}
 
-// OrderのStatusを"PAID"に切り替える
-func (o *Order) MarkPaid() { o.Status = StatusPaid }
+	// 明細と消費税の内訳（金額だけで作られた注文は空）。一覧では読み込まない
+	Items []Item
+	Tax   []tax.Bucket
+
+	// 適用したクーポン（なければ nil）。Amount は値引き後の請求額。一覧では読み込まない
+	Coupon *coupon.Redemption
+}
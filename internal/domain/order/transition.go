@@ -0,0 +1,60 @@
+package order
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// 遷移表にない状態遷移
+var ErrInvalidTransition = errors.New("invalid status transition")
+
+// TransitionError は拒否された遷移（errors.Is で ErrInvalidTransition に一致する）
+type TransitionError struct {
+	From Status
+	To   Status
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("invalid status transition: %s -> %s", e.From, e.To)
+}
+
+func (e *TransitionError) Unwrap() error { return ErrInvalidTransition }
+
+// 許可する状態遷移。ステータスを増やすときはここに追加する
+// CANCELED / REFUNDED は終端
+var transitions = map[Status][]Status{
+	StatusPending:           {StatusAuthorized, StatusAwaitingPayment, StatusPaid, StatusCanceled},
+	StatusAuthorized:        {StatusPaid, StatusCanceled},
+	StatusAwaitingPayment:   {StatusPaid, StatusCanceled}, // 支払期限を過ぎたら CANCELED
+	StatusPaid:              {StatusPartiallyRefunded, StatusRefunded},
+	StatusPartiallyRefunded: {StatusPartiallyRefunded, StatusRefunded}, // 部分返金は複数回できる
+}
+
+// from から to へ遷移できるか
+func (s Status) CanTransitionTo(to Status) bool {
+	for _, next := range transitions[s] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// 遷移できなければ *TransitionError を返す
+func CheckTransition(from, to Status) error {
+	if !to.Valid() || !from.CanTransitionTo(to) {
+		return &TransitionError{From: from, To: to}
+	}
+	return nil
+}
+
+// 遷移表に従ってステータスを変更する。拒否した場合は何も変更しない
+func (o *Order) Transition(to Status, at time.Time) error {
+	if err := CheckTransition(o.Status, to); err != nil {
+		return err
+	}
+	o.Status = to
+	o.UpdatedAt = at
+	return nil
+}
@@ -0,0 +1,69 @@
+package order_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain/order"
+)
+
+func TestCheckTransition(t *testing.T) {
+	tests := []struct {
+		from, to order.Status
+		ok       bool
+	}{
+		{order.StatusPending, order.StatusPaid, true},
+		{order.StatusPending, order.StatusAuthorized, true},
+		{order.StatusPending, order.StatusCanceled, true},
+		{order.StatusAuthorized, order.StatusPaid, true},
+		{order.StatusAuthorized, order.StatusCanceled, true},
+		{order.StatusPending, order.StatusAwaitingPayment, true},
+		{order.StatusAwaitingPayment, order.StatusPaid, true},
+		{order.StatusAwaitingPayment, order.StatusCanceled, true},
+		{order.StatusPaid, order.StatusPartiallyRefunded, true},
+		{order.StatusPaid, order.StatusRefunded, true},
+		{order.StatusPartiallyRefunded, order.StatusPartiallyRefunded, true},
+		{order.StatusPartiallyRefunded, order.StatusRefunded, true},
+
+		{order.StatusPending, order.StatusRefunded, false},
+		{order.StatusPaid, order.StatusCanceled, false},
+		{order.StatusPaid, order.StatusPaid, false},
+		{order.StatusCanceled, order.StatusPaid, false},
+		{order.StatusAwaitingPayment, order.StatusAuthorized, false},
+		{order.StatusRefunded, order.StatusPartiallyRefunded, false},
+		{order.StatusPending, order.Status("UNKNOWN"), false},
+	}
+	for _, tt := range tests {
+		err := order.CheckTransition(tt.from, tt.to)
+		if tt.ok && err != nil {
+			t.Errorf("%s -> %s: unexpected err: %v", tt.from, tt.to, err)
+		}
+		if !tt.ok && !errors.Is(err, order.ErrInvalidTransition) {
+			t.Errorf("%s -> %s: err = %v; want ErrInvalidTransition", tt.from, tt.to, err)
+		}
+	}
+}
+
+func TestOrder_Transition(t *testing.T) {
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := created.Add(time.Minute)
+	o := &order.Order{ID: "o1", Status: order.StatusPending, UpdatedAt: created}
+
+	if err := o.Transition(order.StatusPaid, at); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if o.Status != order.StatusPaid || !o.UpdatedAt.Equal(at) {
+		t.Fatalf("order = %+v; want PAID at %v", o, at)
+	}
+
+	// 拒否した場合は変更しない
+	err := o.Transition(order.StatusCanceled, at.Add(time.Minute))
+	var te *order.TransitionError
+	if !errors.As(err, &te) || te.From != order.StatusPaid || te.To != order.StatusCanceled {
+		t.Fatalf("err = %v; want TransitionError PAID -> CANCELED", err)
+	}
+	if o.Status != order.StatusPaid || !o.UpdatedAt.Equal(at) {
+		t.Fatalf("order changed on rejected transition: %+v", o)
+	}
+}
@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// OutboxMessage は下流システムへ配信するメッセージ 1 件。
+// 状態変更と同じ Tx で書き、cmd/relay が EventPublisher に配信する
+type OutboxMessage struct {
+	ID          string // 下流での重複排除キー（payment_events.id と同じ）
+	AggregateID string // 注文ID（配信先のパーティションキー）
+	Type        string // 例: ORDER_PAID
+	Payload     []byte // JSON
+	CreatedAt   time.Time
+
+	Attempts int // 配信失敗回数
+}
+
+type OutboxRepository interface {
+	Enqueue(ctx context.Context, m *OutboxMessage) error
+
+	// ClaimPending は配信期限が来た未配信メッセージを最大 limit 件取り出し、leaseUntil まで
+	// 他の relay から見えなくする（次の配信期限を leaseUntil にする）。
+	// 取り出しは 1 文で完結するので長い Tx は要らない。期限までに結果を記録しなければ再配信される
+	ClaimPending(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*OutboxMessage, error)
+	MarkDelivered(ctx context.Context, id string, at time.Time) error
+	// MarkFailed は失敗を記録し nextAttemptAt まで再配信を延期する。
+	// MarkFailed / MarkDead は配信済み・dead のメッセージには何もしない（リース切れ後の別 relay の結果を上書きしない）
+	MarkFailed(ctx context.Context, id string, lastErr string, nextAttemptAt time.Time) error
+	// MarkDead はリトライ上限に達したメッセージを以後配信しないようにする
+	MarkDead(ctx context.Context, id string, lastErr string, at time.Time) error
+}
+
+// EventPublisher は outbox のメッセージを外部（Redis Streams 等）へ配信する
+type EventPublisher interface {
+	Publish(ctx context.Context, m *OutboxMessage) error
+}
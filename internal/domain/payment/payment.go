@@ -1,16 +1,72 @@
 package payment
 
+import (
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain/money"
+)
+
 type ID string
 type Method string
 
 const (
-	MethodCard Method = "CARD"
+	MethodCard         Method = "CARD"
+	MethodBankTransfer Method = "BANK_TRANSFER"
+	MethodKonbini      Method = "KONBINI"
 )
 
 type Payment struct {
-	ID       ID
-	OrderID  string
-	Method   Method
-	Provider string // e.g. "stripe"
-	TxID     string // プロバイダ側のトランザクションID
+	ID        ID
+	OrderID   string
+	Method    Method
+	Provider  string      // e.g. "stripe"
+	TxID      string      // プロバイダ側のトランザクションID
+	Amount    money.Money // 確定（売上計上）した金額
+	CreatedAt time.Time
+}
+
+type AuthorizationID string
+type AuthorizationStatus string
+
+const (
+	AuthorizationAuthorized AuthorizationStatus = "AUTHORIZED"
+	AuthorizationCaptured   AuthorizationStatus = "CAPTURED"
+	AuthorizationVoided     AuthorizationStatus = "VOIDED"
+)
+
+// Authorization は売上確定前の与信（オーソリ）。ExpiresAt までに確定されなければ取り消す
+type Authorization struct {
+	ID             AuthorizationID
+	OrderID        string
+	Provider       string
+	ProviderAuthID string      // プロバイダ側のオーソリID
+	Amount         money.Money // 与信額（確定額の上限）
+	Status         AuthorizationStatus
+	ExpiresAt      time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+type KonbiniID string
+type KonbiniStatus string
+
+const (
+	KonbiniAwaiting KonbiniStatus = "AWAITING" // 店頭での支払い待ち
+	KonbiniPaid     KonbiniStatus = "PAID"
+	KonbiniExpired  KonbiniStatus = "EXPIRED" // 支払期限切れ・取り消し
+)
+
+// Konbini はコンビニ払いの払込番号。ExpiresAt までに支払われなければ注文を取り消す
+type Konbini struct {
+	ID                 KonbiniID
+	OrderID            string
+	Provider           string
+	ProviderPaymentID  string // プロバイダ側の決済ID（支払い完了の Webhook で届く）
+	PaymentCode        string // お支払い番号（受付番号）
+	ConfirmationNumber string // 確認番号（店舗によって不要。なければ空）
+	Amount             money.Money
+	Status             KonbiniStatus
+	ExpiresAt          time.Time
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
 }
@@ -1,16 +1,100 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain/banktransfer"
+)
 
 type PaymentIntent struct {
 	OrderID        string
 	Amount         int64
-	Currency       string // "jpy"
+	Currency       string // ISO 4217 の小文字（"jpy", "usd"）。Amount はその最小単位
+	PaymentMethod  string // 顧客の支払い手段（フロントでトークン化した PG 上の ID。Stripe なら pm_...）
 	IdempotencyKey string // 外部PGに渡して二重請求を防ぐ
 }
 
+// RefundRequest は確定済み決済（ProviderTxID）に対する返金要求
+type RefundRequest struct {
+	OrderID        string
+	ProviderTxID   string
+	Amount         int64
+	Currency       string // PaymentIntent と同じ
+	Reason         string
+	IdempotencyKey string // "refund:" prefix
+}
+
+// CaptureRequest はオーソリ（ProviderAuthID）の売上確定要求。Amount はオーソリ額以下
+type CaptureRequest struct {
+	OrderID        string
+	ProviderAuthID string
+	Amount         int64
+	Currency       string // PaymentIntent と同じ
+	IdempotencyKey string // "capture:" prefix
+}
+
+// VoidRequest は未確定オーソリの取り消し要求
+type VoidRequest struct {
+	OrderID        string
+	ProviderAuthID string
+	IdempotencyKey string // "void:" prefix
+}
+
 type PaymentGateway interface {
 	Charge(ctx context.Context, intent PaymentIntent) (providerTxID string, err error)
+	Refund(ctx context.Context, req RefundRequest) (providerRefundID string, err error)
+
+	// 2段階決済（与信 → 売上確定 / 取り消し）
+	Authorize(ctx context.Context, intent PaymentIntent) (providerAuthID string, err error)
+	Capture(ctx context.Context, req CaptureRequest) (providerTxID string, err error)
+	Void(ctx context.Context, req VoidRequest) error
+}
+
+// BankTransferRequest は注文の振込先の発行要求
+type BankTransferRequest struct {
+	OrderID  string
+	Amount   int64
+	Currency string // PaymentIntent と同じ（銀行振込は "jpy" のみ）
+}
+
+// BankTransferGateway は銀行振込の振込先（バーチャル口座・振込依頼人コード）を発行する。
+// 入金は振込入金通知の取り込みで分かるため、Charge に当たる呼び出しはない
+type BankTransferGateway interface {
+	IssueDestination(ctx context.Context, req BankTransferRequest) (banktransfer.Destination, error)
+}
+
+// KonbiniRequest はコンビニ払いの払込番号の発行要求
+type KonbiniRequest struct {
+	OrderID  string
+	Amount   int64
+	Currency string // PaymentIntent と同じ（コンビニ払いは "jpy" のみ）
+	// 支払期限は発行日から数えた日数（その日の 23:59:59 JST まで）。
+	// 時刻ではなく日数で送るので、同じ Idempotency-Key の再試行でもパラメータが変わらない
+	ExpiresAfterDays int
+	IdempotencyKey   string // "konbini:" prefix
+}
+
+// KonbiniVoucher は発行された払込番号。ExpiresAt はプロバイダが丸めた期限（日付単位など）
+type KonbiniVoucher struct {
+	ProviderPaymentID  string
+	PaymentCode        string
+	ConfirmationNumber string
+	ExpiresAt          time.Time
+}
+
+// KonbiniCancelRequest は未払いの払込番号の無効化要求（期限切れ・注文の取り消し）
+type KonbiniCancelRequest struct {
+	OrderID           string
+	ProviderPaymentID string
+	IdempotencyKey    string // "konbini-cancel:" prefix
+}
+
+// KonbiniGateway はコンビニ払いの払込番号を発行する。
+// 支払いは店頭で行われ、結果は Webhook（ProviderPaymentSucceeded）で届く
+type KonbiniGateway interface {
+	IssueKonbini(ctx context.Context, req KonbiniRequest) (KonbiniVoucher, error)
+	CancelKonbini(ctx context.Context, req KonbiniCancelRequest) error
 }
 
 /**
@@ -0,0 +1,25 @@
+package domain
+
+// ProviderEventType は PG から非同期に届く通知をプロバイダ非依存に正規化した種別
+type ProviderEventType string
+
+const (
+	ProviderPaymentSucceeded ProviderEventType = "payment.succeeded"
+	ProviderPaymentFailed    ProviderEventType = "payment.failed"
+	ProviderPaymentCanceled  ProviderEventType = "payment.canceled"
+	ProviderDisputeCreated   ProviderEventType = "dispute.created"
+)
+
+// ProviderEvent は署名検証済みの Webhook 1 件
+type ProviderEvent struct {
+	Provider     string
+	ID           string            // プロバイダ側のイベントID（重複排除キー）
+	Type         ProviderEventType // 未対応の種別は空
+	RawType      string            // プロバイダ側の種別（例: "payment_intent.succeeded"）
+	OrderID      string            // metadata に載せた注文ID（分かる場合のみ）
+	ProviderTxID string            // 決済（PaymentIntent 等）のID
+	Amount       int64             // 最小単位
+	Currency     string            // プロバイダの表記そのまま（例: "jpy"）
+	Reason       string            // 失敗理由・チャージバック理由
+	Payload      []byte            // 受信したボディそのもの
+}
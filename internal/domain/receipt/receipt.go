@@ -0,0 +1,33 @@
+// Package receipt は注文の領収書（適格請求書）
+package receipt
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain/merchant"
+	"github.com/kazshi01/payment-system/internal/domain/order"
+)
+
+// Receipt は注文ごとに 1 つ発行する領収書。番号は初回発行時に発行者ごとの連番で振る
+type Receipt struct {
+	OrderID   order.ID
+	IssuerID  string // 発行者（注文の所有ユーザ）
+	Number    int64
+	IssuedAt  time.Time // 初回発行日時
+	Downloads int64     // 今回を含むダウンロード回数
+}
+
+// Reissued は 2 回目以降のダウンロード（「再発行」と表示する）
+func (r *Receipt) Reissued() bool { return r.Downloads > 1 }
+
+// NumberString は印字用の領収書番号（"R-00000042"）
+func (r *Receipt) NumberString() string { return fmt.Sprintf("R-%08d", r.Number) }
+
+// Document は領収書の描画に必要な情報
+type Document struct {
+	Receipt Receipt
+	Issuer  merchant.Issuer
+	Order   *order.Order // 明細・消費税内訳・クーポンを読み込み済み
+	PaidAt  time.Time
+}
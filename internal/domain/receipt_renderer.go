@@ -0,0 +1,8 @@
+package domain
+
+import "github.com/kazshi01/payment-system/internal/domain/receipt"
+
+// ReceiptRenderer は領収書を PDF に描画する
+type ReceiptRenderer interface {
+	Render(d *receipt.Document) ([]byte, error)
+}
@@ -0,0 +1,21 @@
+package refund
+
+import (
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain/money"
+)
+
+type ID string
+
+// Refund は確定済み決済に対する返金 1 件（部分返金は複数件になる）
+type Refund struct {
+	ID               ID
+	OrderID          string
+	PaymentID        string
+	Amount           money.Money
+	Reason           string
+	ProviderRefundID string // プロバイダ側の返金ID
+	IdempotencyKey   string
+	CreatedAt        time.Time
+}
@@ -4,7 +4,19 @@ import (
 	"context"
 	"time"
 
+	"github.com/kazshi01/payment-system/internal/domain/banktransfer"
+	"github.com/kazshi01/payment-system/internal/domain/coupon"
+	"github.com/kazshi01/payment-system/internal/domain/event"
+	"github.com/kazshi01/payment-system/internal/domain/ledger"
+	"github.com/kazshi01/payment-system/internal/domain/merchant"
+	"github.com/kazshi01/payment-system/internal/domain/money"
 	"github.com/kazshi01/payment-system/internal/domain/order"
+	"github.com/kazshi01/payment-system/internal/domain/payment"
+	"github.com/kazshi01/payment-system/internal/domain/receipt"
+	"github.com/kazshi01/payment-system/internal/domain/refund"
+	"github.com/kazshi01/payment-system/internal/domain/settlement"
+	"github.com/kazshi01/payment-system/internal/domain/tax"
+	"github.com/kazshi01/payment-system/internal/domain/webhook"
 )
 
 type OrderRepository interface {
@@ -12,10 +24,180 @@ type OrderRepository interface {
 	FindByID(ctx context.Context, id order.ID) (*order.Order, error)
 	FindByIDForUser(ctx context.Context, id order.ID, userID string) (*order.Order, error)
 	Update(ctx context.Context, o *order.Order) error
-	UpdateStatusIfPending(ctx context.Context, id order.ID, newStatus order.Status, updatedAt time.Time) (int64, error)
-	UpdateStatusIfPendingForUser(ctx context.Context, id order.ID, userID string, newStatus order.Status, updatedAt time.Time) (int64, error)
+	// fence はロック取得時のフェンシングトークン。より新しいフェンスで更新済みなら 0 件（0 は検査しない）
+	UpdateStatusIf(ctx context.Context, id order.ID, from, to order.Status, fence int64, updatedAt time.Time) (int64, error)
+	List(ctx context.Context, f OrderListFilter) ([]*order.Order, error)
+}
+
+// OrderListFilter は注文一覧の絞り込み条件（ゼロ値の項目は条件なし）
+// 並び順は created_at DESC, id DESC 固定で、After より後ろを Limit 件返す
+type OrderListFilter struct {
+	UserID      string
+	Status      order.Status
+	CreatedFrom time.Time // 以上
+	CreatedTo   time.Time // 未満
+	After       *OrderCursor
+	Limit       int
+}
+
+// OrderCursor はキーセットページングの位置（最後に返した注文）
+type OrderCursor struct {
+	CreatedAt time.Time
+	ID        order.ID
+}
+
+// OrderItemRepository は注文明細（order_items）。明細は注文作成時に一度だけ書く
+type OrderItemRepository interface {
+	CreateBatch(ctx context.Context, orderID order.ID, items []order.Item) error
+	// 明細を行番号順に返す（明細のない注文は空）
+	ListByOrderID(ctx context.Context, orderID order.ID) ([]order.Item, error)
+}
+
+// OrderTaxRepository は注文の消費税内訳（order_tax_lines）。注文作成時に一度だけ書く
+type OrderTaxRepository interface {
+	CreateBatch(ctx context.Context, orderID order.ID, buckets []tax.Bucket) error
+	// 税率の高い順に返す（明細のない注文は空）
+	ListByOrderID(ctx context.Context, orderID order.ID) ([]tax.Bucket, error)
+}
+
+// MerchantSettingsRepository は加盟店ごとの設定
+type MerchantSettingsRepository interface {
+	// 未設定なら ErrNotFound
+	Get(ctx context.Context, userID string) (*merchant.Settings, error)
+	Upsert(ctx context.Context, s *merchant.Settings) error
+}
+
+// CouponRepository はクーポンと利用履歴
+type CouponRepository interface {
+	// コードが使用済みなら coupon.ErrCodeTaken
+	Create(ctx context.Context, c *coupon.Coupon) error
+	// code は NormalizeCode 済み。なければ ErrNotFound
+	FindByCode(ctx context.Context, code string) (*coupon.Coupon, error)
+	// Redeem は全体・ユーザごとの利用上限を超えない場合のみ利用を記録し、利用回数を数える。
+	// 超える場合は coupon.ErrUsageLimit。同時に呼ばれても上限を超えない。Tx 内で呼ぶ
+	Redeem(ctx context.Context, r *coupon.Redemption) error
+	// Release は注文のクーポン利用を取り消して利用回数を戻す。利用がなければ何もしない。Tx 内で呼ぶ
+	Release(ctx context.Context, orderID order.ID) error
+	// なければ ErrNotFound
+	FindRedemptionByOrderID(ctx context.Context, orderID order.ID) (*coupon.Redemption, error)
+}
+
+// ReceiptRepository は領収書の発行記録
+type ReceiptRepository interface {
+	// Issue は初回なら発行者ごとの次の番号で領収書を作り、2 回目以降はダウンロード回数を数える。
+	// 番号は重複・欠番しない（Tx が戻れば番号も戻る）。Tx 内で呼ぶ
+	// 同じ注文の初回発行が同時に走った場合、後の方は ErrConflict
+	Issue(ctx context.Context, orderID order.ID, issuerID string, at time.Time) (*receipt.Receipt, error)
+}
+
+type PaymentRepository interface {
+	Create(ctx context.Context, p *payment.Payment) error
+	ListByOrderID(ctx context.Context, orderID order.ID) ([]*payment.Payment, error)
+	// プロバイダのトランザクションIDから逆引き。なければ ErrNotFound
+	FindByProviderTxID(ctx context.Context, provider, txID string) (*payment.Payment, error)
+	// プロバイダの決済のうち created_at が [from, to) のものを古い順に
+	ListByProvider(ctx context.Context, provider string, from, to time.Time) ([]*payment.Payment, error)
+}
+
+type AuthorizationRepository interface {
+	Create(ctx context.Context, a *payment.Authorization) error
+	// 注文の有効な（AUTHORIZED の）オーソリ。なければ ErrNotFound
+	FindActiveByOrderID(ctx context.Context, orderID order.ID) (*payment.Authorization, error)
+	UpdateStatusIf(ctx context.Context, id payment.AuthorizationID, from, to payment.AuthorizationStatus, updatedAt time.Time) (int64, error)
+	// 期限切れの AUTHORIZED を古い順に最大 limit 件
+	ListExpired(ctx context.Context, now time.Time, limit int) ([]*payment.Authorization, error)
+}
+
+// KonbiniRepository はコンビニ払いの払込番号（konbini_payments）
+type KonbiniRepository interface {
+	// 注文に AWAITING の払込番号があれば ErrConflict
+	Create(ctx context.Context, k *payment.Konbini) error
+	// 注文の AWAITING の払込番号。なければ ErrNotFound
+	FindAwaitingByOrderID(ctx context.Context, orderID order.ID) (*payment.Konbini, error)
+	UpdateStatusIf(ctx context.Context, id payment.KonbiniID, from, to payment.KonbiniStatus, updatedAt time.Time) (int64, error)
+	// 支払期限を過ぎた AWAITING を期限の古い順に最大 limit 件
+	ListExpired(ctx context.Context, now time.Time, limit int) ([]*payment.Konbini, error)
+}
+
+type RefundRepository interface {
+	// 返金累計が確定済み決済額を超えない場合のみ作成する（超える場合は 0 を返す）
+	CreateWithinCaptured(ctx context.Context, r *refund.Refund) (int64, error)
+	ListByOrderID(ctx context.Context, orderID order.ID) ([]*refund.Refund, error)
+}
+
+// BankTransferRepository は銀行振込の振込先（bank_transfers）と入金明細（bank_deposits）
+type BankTransferRepository interface {
+	// 注文に発行済み、または振込先（口座番号・振込依頼人コード）が使用済みなら ErrConflict
+	Create(ctx context.Context, t *banktransfer.Transfer) error
+	// なければ ErrNotFound
+	FindByOrderID(ctx context.Context, orderID order.ID) (*banktransfer.Transfer, error)
+	// 入金先の口座と振込依頼人コード（バーチャル口座なら空）から引く。なければ ErrNotFound
+	FindByDestination(ctx context.Context, branchCode, accountNumber, referenceCode string) (*banktransfer.Transfer, error)
+	// 申告された振込名義（NormalizeName 済み）と請求額が一致する入金待ちの振込先を最大 limit 件
+	ListAwaitingByPayer(ctx context.Context, payerName string, amount money.Money, limit int) ([]*banktransfer.Transfer, error)
+	UpdateStatusIf(ctx context.Context, id string, from, to banktransfer.TransferStatus, updatedAt time.Time) (int64, error)
+
+	// 同じ明細（口座・勘定日・照会番号）が記録済みなら何もせず false
+	CreateDepositIfAbsent(ctx context.Context, d *banktransfer.Deposit) (bool, error)
+	// 新しい順に最大 limit 件（status が空なら全件）
+	ListDeposits(ctx context.Context, status banktransfer.DepositStatus, limit int) ([]*banktransfer.Deposit, error)
+}
+
+// LedgerRepository は複式簿記の元帳（追記のみ）
+type LedgerRepository interface {
+	// Record は仕訳と明細を書く（e.ID は呼び出し側で振る）。Tx 内で呼ぶ
+	// 同じ (Kind, Reference) の仕訳が計上済みなら ErrConflict
+	Record(ctx context.Context, e *ledger.Entry) error
+	// Balance は勘定の [from, to) の借方・貸方の合計（ゼロ値の from / to は制限なし）
+	Balance(ctx context.Context, account ledger.Account, c money.Currency, from, to time.Time) (*ledger.Balance, error)
+	// 貸借が一致しない仕訳を最大 limit 件
+	ListUnbalancedEntries(ctx context.Context, limit int) ([]ledger.Violation, error)
+	// 支払い済みなのに決済・返金額どおりに計上されていない注文を最大 limit 件
+	ListUnpostedOrders(ctx context.Context, limit int) ([]ledger.Violation, error)
+}
+
+// ReconciliationRunRepository は精算ファイルとの突き合わせ結果
+type ReconciliationRunRepository interface {
+	Create(ctx context.Context, r *settlement.Run) error
+	// 明細（Items）ごと返す。なければ ErrNotFound
+	FindByID(ctx context.Context, id string) (*settlement.Run, error)
+	// 新しい順に最大 limit 件（Items は読まない）
+	List(ctx context.Context, limit int) ([]*settlement.Run, error)
+}
+
+// EventRepository は payment_events への追記専用リポジトリ
+type EventRepository interface {
+	Append(ctx context.Context, e *event.Event) error
+	ListByOrderID(ctx context.Context, orderID order.ID) ([]*event.Event, error)
+}
+
+// WebhookInbox は受信した Webhook の記録（イベントID で重複排除する）
+type WebhookInbox interface {
+	// 初回受信なら記録する。処理済みのイベントなら processed=true を返す
+	Receive(ctx context.Context, ev *ProviderEvent, at time.Time) (processed bool, err error)
+	MarkProcessed(ctx context.Context, provider, eventID string, at time.Time) error
 }
 
 type Tx interface {
 	Do(ctx context.Context, fn func(ctx context.Context) error) error
 }
+
+// WebhookEndpointRepository は加盟店向け Webhook の通知先を保持する（削除は論理削除）
+type WebhookEndpointRepository interface {
+	Create(ctx context.Context, e *webhook.Endpoint) error
+	FindByID(ctx context.Context, id webhook.EndpointID) (*webhook.Endpoint, error)
+	ListByUserID(ctx context.Context, userID string) ([]*webhook.Endpoint, error)
+	Delete(ctx context.Context, id webhook.EndpointID, at time.Time) (int64, error)
+}
+
+type WebhookDeliveryRepository interface {
+	// CreateIfAbsent は (endpoint_id, event_id) が未登録のときだけ作成する
+	CreateIfAbsent(ctx context.Context, d *webhook.Delivery) (bool, error)
+	FindByID(ctx context.Context, id webhook.DeliveryID) (*webhook.Delivery, error)
+	ListByEndpointID(ctx context.Context, id webhook.EndpointID, limit int) ([]*webhook.Delivery, error)
+	// ClaimDue は配信期限が来た PENDING を最大 limit 件取り出し、leaseUntil まで他の worker から隠す
+	ClaimDue(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*webhook.Delivery, error)
+	// RecordAttempt は試行を記録し、配信の状態（Status / Attempts / NextAttemptAt / Last*）を更新する
+	RecordAttempt(ctx context.Context, d *webhook.Delivery, a *webhook.Attempt) error
+	ListAttempts(ctx context.Context, id webhook.DeliveryID) ([]*webhook.Attempt, error)
+}
@@ -0,0 +1,112 @@
+package settlement
+
+import (
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain/money"
+	"github.com/kazshi01/payment-system/internal/domain/payment"
+)
+
+// Status は突き合わせの結果
+type Status string
+
+const (
+	StatusMatched         Status = "matched"
+	StatusAmountMismatch  Status = "amount_mismatch"   // 金額・通貨が違う
+	StatusMissingInOurs   Status = "missing_in_ours"   // PG にあって自社の決済にない
+	StatusMissingInTheirs Status = "missing_in_theirs" // 期間内の自社の決済が精算ファイルにない
+)
+
+// Item は突き合わせた取引 1 件。Ours / Theirs はない側がゼロ値
+type Item struct {
+	Status    Status
+	TxID      string
+	Line      int // 精算ファイルの行番号（StatusMissingInTheirs は 0）
+	PaymentID string
+	OrderID   string
+	Ours      money.Money
+	Theirs    money.Money
+}
+
+// Summary は結果ごとの件数
+type Summary struct {
+	Matched         int
+	AmountMismatch  int
+	MissingInOurs   int
+	MissingInTheirs int
+}
+
+// Clean は差異がないか
+func (s Summary) Clean() bool {
+	return s.AmountMismatch == 0 && s.MissingInOurs == 0 && s.MissingInTheirs == 0
+}
+
+func (s *Summary) count(st Status) {
+	switch st {
+	case StatusMatched:
+		s.Matched++
+	case StatusAmountMismatch:
+		s.AmountMismatch++
+	case StatusMissingInOurs:
+		s.MissingInOurs++
+	case StatusMissingInTheirs:
+		s.MissingInTheirs++
+	}
+}
+
+// Run は突き合わせ 1 回分の記録。[From, To) は自社の決済を比べる期間
+type Run struct {
+	ID        string
+	Provider  string
+	Source    string // 精算ファイル名
+	From      time.Time
+	To        time.Time
+	Summary   Summary
+	Items     []Item // 一覧では読まない
+	CreatedAt time.Time
+}
+
+// Reconcile は精算ファイルの行 theirs を、期間内の自社の決済 ours と provider_tx_id で突き合わせる。
+// ours にない取引は lookup で期間外から探す（PG の精算日と自社の決済日はずれることがある）。
+// 結果はファイルの行順、続けて精算ファイルにない自社の決済を ours の順に並べる
+func Reconcile(theirs []Row, ours []*payment.Payment, lookup func(txID string) (*payment.Payment, error)) ([]Item, Summary, error) {
+	byTx := make(map[string]*payment.Payment, len(ours))
+	for _, p := range ours {
+		byTx[p.TxID] = p
+	}
+
+	var (
+		items []Item
+		sum   Summary
+		seen  = map[string]bool{}
+	)
+	add := func(it Item) {
+		items = append(items, it)
+		sum.count(it.Status)
+	}
+	for _, r := range theirs {
+		seen[r.TxID] = true
+		p, ok := byTx[r.TxID]
+		if !ok {
+			var err error
+			if p, err = lookup(r.TxID); err != nil {
+				return nil, Summary{}, err
+			}
+		}
+		if p == nil {
+			add(Item{Status: StatusMissingInOurs, TxID: r.TxID, Line: r.Line, Theirs: r.Amount})
+			continue
+		}
+		st := StatusMatched
+		if p.Amount != r.Amount {
+			st = StatusAmountMismatch
+		}
+		add(Item{Status: st, TxID: r.TxID, Line: r.Line, PaymentID: string(p.ID), OrderID: p.OrderID, Ours: p.Amount, Theirs: r.Amount})
+	}
+	for _, p := range ours {
+		if !seen[p.TxID] {
+			add(Item{Status: StatusMissingInTheirs, TxID: p.TxID, PaymentID: string(p.ID), OrderID: p.OrderID, Ours: p.Amount})
+		}
+	}
+	return items, sum, nil
+}
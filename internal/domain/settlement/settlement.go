@@ -0,0 +1,169 @@
+// Package settlement は PG の精算レポート（CSV）と自社の決済記録の突き合わせ
+package settlement
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/kazshi01/payment-system/internal/domain/money"
+)
+
+var ErrInvalidFile = errors.New("invalid settlement file")
+
+// AmountUnit は CSV の金額の単位
+type AmountUnit string
+
+const (
+	UnitMajor AmountUnit = "major" // "12.34"（ドル・円）
+	UnitMinor AmountUnit = "minor" // "1234"（セント・円）
+)
+
+// Mapping は CSV の列名（ヘッダ行）と項目の対応。PG ごとにレポートの形式が違うので設定で渡す
+type Mapping struct {
+	TxID     string // provider_tx_id の列（必須）
+	Amount   string // 金額の列（必須）
+	Currency string // 通貨の列。空なら DefaultCurrency
+
+	DefaultCurrency money.Currency
+	Unit            AmountUnit
+
+	// Category の列が CategoryValue の行だけを読む（返金・手数料の行を除く）。空なら全行
+	Category      string
+	CategoryValue string
+}
+
+func (m Mapping) Validate() error {
+	if m.TxID == "" || m.Amount == "" {
+		return fmt.Errorf("%w: tx id and amount columns are required", ErrInvalidFile)
+	}
+	if m.Currency == "" && !m.DefaultCurrency.Valid() {
+		return fmt.Errorf("%w: currency column or a valid default currency is required", ErrInvalidFile)
+	}
+	if m.Unit != UnitMajor && m.Unit != UnitMinor {
+		return fmt.Errorf("%w: amount unit must be %s or %s", ErrInvalidFile, UnitMajor, UnitMinor)
+	}
+	if (m.Category == "") != (m.CategoryValue == "") {
+		return fmt.Errorf("%w: category column and value go together", ErrInvalidFile)
+	}
+	return nil
+}
+
+// Row は精算ファイルの 1 取引
+type Row struct {
+	Line   int // CSV の行番号（ヘッダが 1 行目）
+	TxID   string
+	Amount money.Money
+}
+
+// Parse はヘッダ付きの CSV を読む。同じ取引 ID が 2 回出てきたらエラー
+func Parse(r io.Reader, m Mapping) ([]Row, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1 // 列数の検査は必要な列の有無だけ見る
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: read header: %w", ErrInvalidFile, err)
+	}
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff") // Excel が付ける BOM
+	}
+	cols := map[string]int{}
+	for i, h := range header {
+		cols[strings.TrimSpace(h)] = i
+	}
+	col := func(name string) (int, error) {
+		if name == "" {
+			return -1, nil
+		}
+		i, ok := cols[name]
+		if !ok {
+			return 0, fmt.Errorf("%w: column %q not found", ErrInvalidFile, name)
+		}
+		return i, nil
+	}
+	txCol, err := col(m.TxID)
+	if err != nil {
+		return nil, err
+	}
+	amountCol, err := col(m.Amount)
+	if err != nil {
+		return nil, err
+	}
+	currencyCol, err := col(m.Currency)
+	if err != nil {
+		return nil, err
+	}
+	categoryCol, err := col(m.Category)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []Row
+	seen := map[string]int{}
+	for {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+		}
+		line, _ := cr.FieldPos(0)
+		field := func(i int) string {
+			if i < 0 || i >= len(rec) {
+				return ""
+			}
+			return strings.TrimSpace(rec[i])
+		}
+
+		if categoryCol >= 0 && field(categoryCol) != m.CategoryValue {
+			continue
+		}
+		row, err := m.row(line, field(txCol), field(amountCol), field(currencyCol))
+		if err != nil {
+			return nil, err
+		}
+		if prev, ok := seen[row.TxID]; ok {
+			return nil, fmt.Errorf("%w: line %d: transaction %s already appears on line %d", ErrInvalidFile, line, row.TxID, prev)
+		}
+		seen[row.TxID] = line
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func (m Mapping) row(line int, txID, amount, currency string) (Row, error) {
+	if txID == "" {
+		return Row{}, fmt.Errorf("%w: line %d: empty transaction id", ErrInvalidFile, line)
+	}
+	c := m.DefaultCurrency
+	if m.Currency != "" {
+		var err error
+		if c, err = money.ParseCurrency(currency); err != nil {
+			return Row{}, fmt.Errorf("%w: line %d: %w", ErrInvalidFile, line, err)
+		}
+	}
+
+	var (
+		a   money.Money
+		err error
+	)
+	if m.Unit == UnitMajor {
+		a, err = money.ParseMajor(amount, c)
+	} else {
+		var n int64
+		n, err = strconv.ParseInt(amount, 10, 64)
+		a = money.Money{Amount: n, Currency: c}
+	}
+	if err != nil {
+		return Row{}, fmt.Errorf("%w: line %d: %w", ErrInvalidFile, line, err)
+	}
+	return Row{Line: line, TxID: txID, Amount: a}, nil
+}
@@ -0,0 +1,114 @@
+package settlement_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/kazshi01/payment-system/internal/domain/money"
+	"github.com/kazshi01/payment-system/internal/domain/payment"
+	"github.com/kazshi01/payment-system/internal/domain/settlement"
+)
+
+func jpy(n int64) money.Money { return money.Money{Amount: n, Currency: money.JPY} }
+
+var stripeMapping = settlement.Mapping{
+	TxID:          "payment_intent_id",
+	Amount:        "gross",
+	Currency:      "currency",
+	Unit:          settlement.UnitMajor,
+	Category:      "reporting_category",
+	CategoryValue: "charge",
+}
+
+func TestParse(t *testing.T) {
+	csv := "\ufeffbalance_transaction_id,payment_intent_id,reporting_category,gross,currency\n" +
+		"txn_1,pi_1,charge,1200,jpy\n" +
+		"txn_2,pi_1,refund,-300,jpy\n" + // 返金の行は読まない
+		"txn_3,pi_2,charge,12.34,usd\n"
+
+	rows, err := settlement.Parse(strings.NewReader(csv), stripeMapping)
+	if err != nil {
+		t.Fatalf("Parse err = %v", err)
+	}
+	want := []settlement.Row{
+		{Line: 2, TxID: "pi_1", Amount: jpy(1200)},
+		{Line: 4, TxID: "pi_2", Amount: money.Money{Amount: 1234, Currency: money.USD}},
+	}
+	if len(rows) != len(want) || rows[0] != want[0] || rows[1] != want[1] {
+		t.Fatalf("rows = %+v; want %+v", rows, want)
+	}
+
+	// 通貨の列がなければ既定の通貨、金額は最小単位
+	minor := settlement.Mapping{TxID: "id", Amount: "amount", DefaultCurrency: money.USD, Unit: settlement.UnitMinor}
+	rows, err = settlement.Parse(strings.NewReader("id,amount\nch_1,1234\n"), minor)
+	if err != nil || len(rows) != 1 || rows[0].Amount != (money.Money{Amount: 1234, Currency: money.USD}) {
+		t.Fatalf("Parse(minor) = %+v, %v; want 12.34 USD", rows, err)
+	}
+
+	for name, in := range map[string]string{
+		"missing column":  "payment_intent_id,gross\npi_1,1200\n",
+		"duplicate tx":    "payment_intent_id,reporting_category,gross,currency\npi_1,charge,1200,jpy\npi_1,charge,1200,jpy\n",
+		"bad amount":      "payment_intent_id,reporting_category,gross,currency\npi_1,charge,12.345,usd\n",
+		"bad currency":    "payment_intent_id,reporting_category,gross,currency\npi_1,charge,1200,xxx\n",
+		"empty tx id":     "payment_intent_id,reporting_category,gross,currency\n,charge,1200,jpy\n",
+		"no header (EOF)": "",
+	} {
+		if _, err := settlement.Parse(strings.NewReader(in), stripeMapping); !errors.Is(err, settlement.ErrInvalidFile) {
+			t.Errorf("%s: err = %v; want ErrInvalidFile", name, err)
+		}
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	pay := func(id, tx string, amount money.Money) *payment.Payment {
+		return &payment.Payment{ID: payment.ID(id), OrderID: "order-" + id, TxID: tx, Amount: amount}
+	}
+	ours := []*payment.Payment{
+		pay("p1", "pi_1", jpy(1200)),
+		pay("p2", "pi_2", jpy(500)),
+		pay("p3", "pi_3", jpy(800)), // 精算ファイルにない
+	}
+	earlier := pay("p0", "pi_0", jpy(300)) // 前日の決済がこの日に精算された
+	theirs := []settlement.Row{
+		{Line: 2, TxID: "pi_1", Amount: jpy(1200)},
+		{Line: 3, TxID: "pi_2", Amount: jpy(480)},
+		{Line: 4, TxID: "pi_0", Amount: jpy(300)},
+		{Line: 5, TxID: "pi_9", Amount: jpy(100)},
+	}
+	lookup := func(tx string) (*payment.Payment, error) {
+		if tx == earlier.TxID {
+			return earlier, nil
+		}
+		return nil, nil
+	}
+
+	items, sum, err := settlement.Reconcile(theirs, ours, lookup)
+	if err != nil {
+		t.Fatalf("Reconcile err = %v", err)
+	}
+	want := []struct {
+		status settlement.Status
+		tx     string
+	}{
+		{settlement.StatusMatched, "pi_1"},
+		{settlement.StatusAmountMismatch, "pi_2"},
+		{settlement.StatusMatched, "pi_0"},
+		{settlement.StatusMissingInOurs, "pi_9"},
+		{settlement.StatusMissingInTheirs, "pi_3"},
+	}
+	if len(items) != len(want) {
+		t.Fatalf("items = %+v; want %d items", items, len(want))
+	}
+	for i, w := range want {
+		if items[i].Status != w.status || items[i].TxID != w.tx {
+			t.Errorf("items[%d] = %s %s; want %s %s", i, items[i].Status, items[i].TxID, w.status, w.tx)
+		}
+	}
+	if items[1].Ours != jpy(500) || items[1].Theirs != jpy(480) || items[1].OrderID != "order-p2" {
+		t.Errorf("mismatch item = %+v; want both amounts and the order", items[1])
+	}
+	if sum != (settlement.Summary{Matched: 2, AmountMismatch: 1, MissingInOurs: 1, MissingInTheirs: 1}) || sum.Clean() {
+		t.Errorf("summary = %+v", sum)
+	}
+}
@@ -0,0 +1,212 @@
+// Package tax は消費税（標準税率 10%・軽減税率 8%）の計算
+//
+// 適格請求書の要件に合わせ、端数処理は明細ごとではなく
+// 1 請求書（注文）につき税率ごとに 1 回だけ行う。
+package tax
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"slices"
+	"strings"
+
+	"github.com/kazshi01/payment-system/internal/domain/money"
+)
+
+var (
+	ErrInvalidRate     = errors.New("invalid tax rate")
+	ErrInvalidRounding = errors.New("invalid tax rounding")
+)
+
+// Rate は税率（%）
+type Rate int
+
+const (
+	RateStandard Rate = 10 // 標準税率
+	RateReduced  Rate = 8  // 軽減税率（飲食料品等）
+)
+
+func (r Rate) Valid() bool { return r == RateStandard || r == RateReduced }
+
+// Rounding は税額の端数処理
+type Rounding string
+
+const (
+	RoundingFloor Rounding = "floor" // 切り捨て
+	RoundingRound Rounding = "round" // 四捨五入
+	RoundingCeil  Rounding = "ceil"  // 切り上げ
+
+	DefaultRounding = RoundingFloor
+)
+
+func ParseRounding(s string) (Rounding, error) {
+	r := Rounding(strings.ToLower(strings.TrimSpace(s)))
+	switch r {
+	case RoundingFloor, RoundingRound, RoundingCeil:
+		return r, nil
+	}
+	return "", fmt.Errorf("%w: %q (floor, round or ceil)", ErrInvalidRounding, s)
+}
+
+// Line は課税対象（税抜）の金額 1 件
+type Line struct {
+	Rate   Rate
+	Amount money.Money
+}
+
+// Bucket は税率ごとの内訳
+type Bucket struct {
+	Rate    Rate
+	Taxable money.Money // 税抜の対象額
+	Tax     money.Money // 端数処理済みの税額
+}
+
+// Compute は税率ごとに対象額を合計し、税額を 1 回だけ端数処理する
+// 内訳は税率の高い順
+func Compute(lines []Line, rounding Rounding) ([]Bucket, error) {
+	if _, err := ParseRounding(string(rounding)); err != nil {
+		return nil, err
+	}
+
+	taxable := map[Rate]money.Money{}
+	for _, l := range lines {
+		if !l.Rate.Valid() {
+			return nil, fmt.Errorf("%w: %d%%", ErrInvalidRate, l.Rate)
+		}
+		sum, ok := taxable[l.Rate]
+		if !ok {
+			sum = money.Zero(l.Amount.Currency)
+		}
+		if l.Amount.Amount > math.MaxInt64/100-sum.Amount {
+			return nil, fmt.Errorf("taxable amount overflows")
+		}
+		var err error
+		if taxable[l.Rate], err = sum.Add(l.Amount); err != nil {
+			return nil, err
+		}
+	}
+
+	out := make([]Bucket, 0, len(taxable))
+	for r, base := range taxable {
+		out = append(out, Bucket{
+			Rate:    r,
+			Taxable: base,
+			Tax:     money.Money{Amount: apply(base.Amount, r, rounding), Currency: base.Currency},
+		})
+	}
+	slices.SortFunc(out, func(a, b Bucket) int { return int(b.Rate - a.Rate) })
+	return out, nil
+}
+
+// Total は内訳の税額合計
+func Total(buckets []Bucket, c money.Currency) (money.Money, error) {
+	total := money.Zero(c)
+	for _, b := range buckets {
+		var err error
+		if total, err = total.Add(b.Tax); err != nil {
+			return money.Money{}, err
+		}
+	}
+	return total, nil
+}
+
+// Discount は税込の値引き額 off を税率ごとの税込額で按分し、値引き後の税込額から
+// 対象額と税額を計算し直す（税額の端数処理は税率ごとに 1 回）
+// 按分の端数は余りの大きい内訳に 1 ずつ寄せる（同じなら税率の高い方）
+// 値引き後の対象額と税額の合計は、値引き前の税込合計 − off に一致する
+func Discount(buckets []Bucket, off money.Money, rounding Rounding) ([]Bucket, error) {
+	if _, err := ParseRounding(string(rounding)); err != nil {
+		return nil, err
+	}
+	if len(buckets) == 0 || off.Amount == 0 {
+		return slices.Clone(buckets), nil
+	}
+
+	gross := make([]int64, len(buckets))
+	var total int64
+	for i, b := range buckets {
+		if b.Taxable.Currency != off.Currency || b.Tax.Currency != off.Currency {
+			return nil, money.ErrCurrencyMismatch
+		}
+		gross[i] = b.Taxable.Amount + b.Tax.Amount
+		total += gross[i]
+	}
+	if off.Amount < 0 || off.Amount > total {
+		return nil, fmt.Errorf("discount %s exceeds total", off)
+	}
+
+	// off × 税込額 / 税込合計 は int64 を超えうるので 128 bit で計算する
+	share := make([]int64, len(buckets))
+	rem := make([]uint64, len(buckets))
+	left := off.Amount
+	for i, g := range gross {
+		hi, lo := bits.Mul64(uint64(off.Amount), uint64(g))
+		q, r := bits.Div64(hi, lo, uint64(total))
+		share[i], rem[i] = int64(q), r
+		left -= share[i]
+	}
+	idx := make([]int, len(buckets))
+	for i := range idx {
+		idx[i] = i
+	}
+	slices.SortStableFunc(idx, func(a, b int) int {
+		if rem[a] != rem[b] {
+			if rem[a] > rem[b] {
+				return -1
+			}
+			return 1
+		}
+		return int(buckets[b].Rate - buckets[a].Rate)
+	})
+	for _, i := range idx[:left] {
+		share[i]++
+	}
+
+	out := make([]Bucket, len(buckets))
+	for i, b := range buckets {
+		g := gross[i] - share[i]
+		t := applyIncluded(g, b.Rate, rounding)
+		out[i] = Bucket{
+			Rate:    b.Rate,
+			Taxable: money.Money{Amount: g - t, Currency: off.Currency},
+			Tax:     money.Money{Amount: t, Currency: off.Currency},
+		}
+	}
+	return out, nil
+}
+
+// 対象額 × 税率 を端数処理する（対象額は 0 以上）
+func apply(base int64, r Rate, rounding Rounding) int64 {
+	x := base * int64(r)
+	q, rem := x/100, x%100
+	switch rounding {
+	case RoundingCeil:
+		if rem > 0 {
+			q++
+		}
+	case RoundingRound:
+		if rem >= 50 {
+			q++
+		}
+	}
+	return q
+}
+
+// 税込額に含まれる税額（税込額 × 税率 / (100 + 税率)）を端数処理する
+func applyIncluded(gross int64, r Rate, rounding Rounding) int64 {
+	x, d := gross*int64(r), 100+int64(r)
+	q, rem := x/d, x%d
+	switch rounding {
+	case RoundingCeil:
+		if rem > 0 {
+			q++
+		}
+	case RoundingRound:
+		if 2*rem >= d {
+			q++
+		}
+	}
+	return q
+}
@@ -0,0 +1,98 @@
+package tax_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kazshi01/payment-system/internal/domain/money"
+	"github.com/kazshi01/payment-system/internal/domain/tax"
+)
+
+func yen(n int64) money.Money { return money.Money{Amount: n, Currency: money.JPY} }
+
+// 明細ごとに端数処理すると 1 円ずつずれる例
+func TestCompute_roundsOncePerRate(t *testing.T) {
+	lines := []tax.Line{
+		{Rate: tax.RateReduced, Amount: yen(105)},
+		{Rate: tax.RateReduced, Amount: yen(105)},
+		{Rate: tax.RateReduced, Amount: yen(105)},
+		{Rate: tax.RateStandard, Amount: yen(1999)},
+	}
+	tests := []struct {
+		rounding          tax.Rounding
+		standard, reduced int64
+	}{
+		{tax.RoundingFloor, 199, 25}, // 199.9 / 25.2
+		{tax.RoundingRound, 200, 25},
+		{tax.RoundingCeil, 200, 26},
+	}
+	for _, tt := range tests {
+		got, err := tax.Compute(lines, tt.rounding)
+		if err != nil {
+			t.Fatalf("%s: Compute err = %v", tt.rounding, err)
+		}
+		want := []tax.Bucket{
+			{Rate: tax.RateStandard, Taxable: yen(1999), Tax: yen(tt.standard)},
+			{Rate: tax.RateReduced, Taxable: yen(315), Tax: yen(tt.reduced)},
+		}
+		if len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+			t.Fatalf("%s: Compute = %+v; want %+v", tt.rounding, got, want)
+		}
+		total, _ := tax.Total(got, money.JPY)
+		if total.Amount != tt.standard+tt.reduced {
+			t.Fatalf("%s: Total = %v", tt.rounding, total)
+		}
+	}
+}
+
+func TestCompute_invalid(t *testing.T) {
+	if _, err := tax.Compute([]tax.Line{{Rate: 5, Amount: yen(100)}}, tax.RoundingFloor); !errors.Is(err, tax.ErrInvalidRate) {
+		t.Fatalf("err = %v; want ErrInvalidRate", err)
+	}
+	if _, err := tax.Compute(nil, "bankers"); !errors.Is(err, tax.ErrInvalidRounding) {
+		t.Fatalf("err = %v; want ErrInvalidRounding", err)
+	}
+	mixed := []tax.Line{{Rate: tax.RateStandard, Amount: yen(100)}, {Rate: tax.RateStandard, Amount: money.Money{Amount: 100, Currency: money.USD}}}
+	if _, err := tax.Compute(mixed, tax.RoundingFloor); !errors.Is(err, money.ErrCurrencyMismatch) {
+		t.Fatalf("err = %v; want ErrCurrencyMismatch", err)
+	}
+}
+
+// 値引きは税率ごとの税込額で按分し、値引き後の税込額から税額を計算し直す
+func TestDiscount(t *testing.T) {
+	buckets := []tax.Bucket{
+		{Rate: tax.RateStandard, Taxable: yen(3900), Tax: yen(390)}, // 税込 4,290
+		{Rate: tax.RateReduced, Taxable: yen(100), Tax: yen(8)},     // 税込 108
+	}
+
+	// 398 × 4290/4398 = 388.2、398 × 108/4398 = 9.8 → 端数の 1 円は余りの大きい軽減税率へ
+	got, err := tax.Discount(buckets, yen(398), tax.RoundingFloor)
+	if err != nil {
+		t.Fatalf("Discount err = %v", err)
+	}
+	want := []tax.Bucket{
+		{Rate: tax.RateStandard, Taxable: yen(3548), Tax: yen(354)}, // 税込 3,902
+		{Rate: tax.RateReduced, Taxable: yen(91), Tax: yen(7)},      // 税込 98
+	}
+	if len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("Discount = %+v; want %+v", got, want)
+	}
+
+	// 全額値引きなら税額も 0
+	got, err = tax.Discount(buckets, yen(4398), tax.RoundingCeil)
+	if err != nil {
+		t.Fatalf("Discount err = %v", err)
+	}
+	for _, b := range got {
+		if b.Taxable.Amount != 0 || b.Tax.Amount != 0 {
+			t.Fatalf("Discount (full) = %+v", got)
+		}
+	}
+
+	if _, err := tax.Discount(buckets, yen(4399), tax.RoundingFloor); err == nil {
+		t.Fatalf("discount over total: want error")
+	}
+	if _, err := tax.Discount(buckets, money.Money{Amount: 1, Currency: money.USD}, tax.RoundingFloor); !errors.Is(err, money.ErrCurrencyMismatch) {
+		t.Fatalf("err = %v; want ErrCurrencyMismatch", err)
+	}
+}
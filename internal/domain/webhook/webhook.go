@@ -0,0 +1,126 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/netip"
+	"strconv"
+	"time"
+)
+
+type EndpointID string
+type DeliveryID string
+
+// EventType は加盟店に通知するイベント種別（payment_events の種別とは別の公開 API）
+type EventType string
+
+const (
+	EventOrderPaid       EventType = "order.paid"
+	EventOrderCanceled   EventType = "order.canceled"
+	EventRefundSucceeded EventType = "refund.succeeded"
+)
+
+func (t EventType) Valid() bool {
+	switch t {
+	case EventOrderPaid, EventOrderCanceled, EventRefundSucceeded:
+		return true
+	}
+	return false
+}
+
+// Endpoint は加盟店（ユーザ）が登録した通知先
+type Endpoint struct {
+	ID        EndpointID
+	UserID    string
+	URL       string
+	Events    []EventType // 購読するイベント
+	Secret    string      // 署名用シークレット（作成時のみ返す）
+	CreatedAt time.Time
+}
+
+func (e *Endpoint) Subscribes(t EventType) bool {
+	for _, x := range e.Events {
+		if x == t {
+			return true
+		}
+	}
+	return false
+}
+
+// 通知先に使えないアドレス（RFC 1918 等で IsPrivate に含まれないもの）
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),     // this network
+	netip.MustParsePrefix("100.64.0.0/10"), // CGNAT
+	netip.MustParsePrefix("192.0.0.0/24"),  // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"), // benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),   // reserved
+	netip.MustParsePrefix("64:ff9b::/96"),  // NAT64（内部の IPv4 に届く）
+}
+
+// IsPublicAddr は通知先として POST してよいアドレスか（ループバック・プライベート・リンクローカル・
+// メタデータサービス 169.254.169.254 などの内部アドレスは false）
+func IsPublicAddr(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if !ip.IsValid() || ip.IsUnspecified() || ip.IsLoopback() || ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	for _, p := range nonPublicPrefixes {
+		if p.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "PENDING"
+	DeliverySucceeded DeliveryStatus = "SUCCEEDED"
+	DeliveryFailed    DeliveryStatus = "FAILED" // リトライ期限切れ
+)
+
+// Delivery はイベント 1 件 × 通知先 1 件の配信
+type Delivery struct {
+	ID             DeliveryID
+	EndpointID     EndpointID
+	EventID        string // 受信側の重複排除キー（Webhook-Id ヘッダ）
+	EventType      EventType
+	Payload        []byte // 送信する JSON
+	Status         DeliveryStatus
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastStatusCode int
+	LastError      string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// Attempt は配信の試行 1 回分の記録
+type Attempt struct {
+	ID          string
+	DeliveryID  DeliveryID
+	StatusCode  int // 応答が無ければ 0
+	Error       string
+	Duration    time.Duration
+	AttemptedAt time.Time
+}
+
+func (a *Attempt) Succeeded() bool { return a.Error == "" && a.StatusCode >= 200 && a.StatusCode < 300 }
+
+const (
+	IDHeader        = "Webhook-Id"
+	SignatureHeader = "Webhook-Signature"
+)
+
+// Sign は "t=<unix>,v1=<hex(HMAC-SHA256(secret, "<unix>.<body>"))>" 形式の署名を返す
+func Sign(secret string, at time.Time, body []byte) string {
+	ts := strconv.FormatInt(at.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "t=" + ts + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,17 @@
+package domain
+
+import (
+	"context"
+	"net/netip"
+)
+
+// WebhookSender は署名済みの Webhook を送信し、HTTP ステータスを返す。
+// 内部アドレス（webhook.IsPublicAddr が false）には接続しない
+type WebhookSender interface {
+	Send(ctx context.Context, url string, header map[string]string, body []byte) (statusCode int, err error)
+}
+
+// HostResolver は通知先のホスト名を引く（*net.Resolver が満たす）
+type HostResolver interface {
+	LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error)
+}
@@ -0,0 +1,379 @@
+package db
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+	sqlcdb "github.com/kazshi01/payment-system/internal/infra/db/sqlc"
+)
+
+// 既定の期限切れロック回収間隔
+const defaultReapInterval = time.Second
+
+// セッション上の 1 文あたりの上限
+const sessionTimeout = 3 * time.Second
+
+// PostgresLocker implements domain.Locker with session-level advisory locks.
+//
+// Advisory locks live as long as the session, so all locks are taken on one
+// dedicated connection and released by a reaper once their TTL passes.
+// If the connection breaks, Postgres drops every lock it held; Extend pings
+// the session, so the holder notices on the next Extend.
+//
+// mu only guards the bookkeeping below and is never held across a DB call.
+// Statements on the session are serialized by sess and run on a context
+// detached from the caller, so a caller giving up mid-statement does not
+// break the session and drop locks held by others.
+type PostgresLocker struct {
+	DB *sql.DB
+
+	sess chan struct{} // セッションの文を 1 つずつ流すためのセマフォ
+
+	mu   sync.Mutex
+	conn *sql.Conn
+	held map[int64]advisoryLock // key のハッシュ → 保持中（取得中）のロック
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+type advisoryLock struct {
+	lock      domain.Lock
+	conn      *sql.Conn // ロックを取ったセッション
+	expiresAt time.Time
+	pending   bool // DB で取得中（他の呼び出しからは保持中に見える）
+}
+
+// NewPostgresLocker starts the reaper. reapInterval <= 0 uses defaultReapInterval.
+func NewPostgresLocker(db *sql.DB, reapInterval time.Duration) *PostgresLocker {
+	if reapInterval <= 0 {
+		reapInterval = defaultReapInterval
+	}
+	l := &PostgresLocker{
+		DB:   db,
+		sess: make(chan struct{}, 1),
+		held: map[int64]advisoryLock{},
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	go l.reapLoop(reapInterval)
+	return l
+}
+
+// 文字列キーを advisory lock の bigint キーにする
+func advisoryKey(key string) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	return int64(h.Sum64())
+}
+
+func randLockToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// TryLock takes the advisory lock for key if nobody (including this process) holds it.
+func (l *PostgresLocker) TryLock(ctx context.Context, key string, ttl time.Duration) (bool, domain.Lock, error) {
+	token, err := randLockToken()
+	if err != nil {
+		return false, domain.Lock{}, err
+	}
+	k := advisoryKey(key)
+
+	// advisory lock は同一セッションだと再入できてしまうため、プロセス内の保持はここで弾く
+	// （ハッシュが衝突した別キーも保持中として扱う）。DB 呼び出しの間はキーを予約しておく
+	l.mu.Lock()
+	stale, hasStale := l.held[k]
+	if hasStale && (stale.pending || time.Now().Before(stale.expiresAt)) {
+		l.mu.Unlock()
+		return false, domain.Lock{}, nil
+	}
+	l.held[k] = advisoryLock{lock: domain.Lock{Token: token}, pending: true}
+	l.mu.Unlock()
+
+	ok, conn, fence, err := l.tryLock(ctx, k, stale, hasStale)
+	if err != nil || !ok {
+		l.mu.Lock()
+		if h, found := l.held[k]; found && h.pending && h.lock.Token == token {
+			delete(l.held, k)
+		}
+		l.mu.Unlock()
+		return false, domain.Lock{}, err
+	}
+
+	lock := domain.Lock{Key: key, Token: token, Fence: fence}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// 取得中に接続が捨てられていればロックは残っていない
+	h, found := l.held[k]
+	if !found || !h.pending || h.lock.Token != token || l.conn != conn {
+		return false, domain.Lock{}, nil
+	}
+	l.held[k] = advisoryLock{lock: lock, conn: conn, expiresAt: time.Now().Add(ttl)}
+	return true, lock, nil
+}
+
+// 期限切れのロックが残っていれば解放してから取り直す（キーは予約済みで呼ぶ）
+func (l *PostgresLocker) tryLock(ctx context.Context, k int64, stale advisoryLock, hasStale bool) (bool, *sql.Conn, int64, error) {
+	conn, err := l.session(ctx)
+	if err != nil {
+		return false, nil, 0, err
+	}
+
+	var (
+		ok    bool
+		fence int64
+	)
+	err = l.onSession(ctx, func(sctx context.Context) error {
+		q := sqlcdb.New(conn)
+		if hasStale && stale.conn == conn {
+			if _, err := q.AdvisoryUnlock(sctx, k); err != nil {
+				return fmt.Errorf("advisory unlock: %w", err)
+			}
+		}
+
+		var err error
+		if ok, err = q.TryAdvisoryLock(sctx, k); err != nil {
+			return fmt.Errorf("try advisory lock: %w", err)
+		}
+		if !ok {
+			return nil
+		} // 他のプロセスが保持中
+
+		if fence, err = q.NextLockFence(sctx); err != nil {
+			return fmt.Errorf("next lock fence: %w", err)
+		}
+		return nil
+	})
+	if errors.Is(err, errSessionWait) {
+		return false, nil, 0, ctx.Err()
+	}
+	if err != nil {
+		// ロックを取れたか・解放できたか分からないため、接続ごと捨ててセッションのロックを全て手放す
+		l.discardConn(conn)
+		return false, nil, 0, err
+	}
+	if !ok {
+		return false, nil, 0, nil
+	}
+	return true, conn, fence, nil
+}
+
+// Extend pushes the local expiry forward while lock is still held and its session is alive.
+func (l *PostgresLocker) Extend(ctx context.Context, lock domain.Lock, ttl time.Duration) (bool, error) {
+	k := advisoryKey(lock.Key)
+
+	l.mu.Lock()
+	h, ok := l.held[k]
+	l.mu.Unlock()
+	if !ok || h.pending || h.lock.Token != lock.Token || !time.Now().Before(h.expiresAt) {
+		return false, nil
+	}
+
+	// セッションが切れていれば Postgres 側でロックは解放済み（他のインスタンスが取れる）
+	err := l.onSession(ctx, func(sctx context.Context) error { return h.conn.PingContext(sctx) })
+	if errors.Is(err, errSessionWait) {
+		return false, ctx.Err()
+	}
+	if err != nil {
+		l.discardConn(h.conn)
+		log.Printf("warn: advisory lock session lost: key=%s: %v", lock.Key, err)
+		return false, nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	cur, ok := l.held[k]
+	if !ok || cur.pending || cur.lock.Token != lock.Token || cur.conn != l.conn {
+		return false, nil
+	}
+	cur.expiresAt = time.Now().Add(ttl)
+	l.held[k] = cur
+	return true, nil
+}
+
+// Unlock releases lock if the token still matches.
+func (l *PostgresLocker) Unlock(ctx context.Context, lock domain.Lock) error {
+	k := advisoryKey(lock.Key)
+
+	l.mu.Lock()
+	h, ok := l.held[k]
+	if !ok || h.pending || h.lock.Token != lock.Token {
+		l.mu.Unlock()
+		return nil
+	}
+	delete(l.held, k)
+	l.mu.Unlock()
+
+	return l.release(ctx, h.conn, k)
+}
+
+// Ping checks the database connection.
+func (l *PostgresLocker) Ping(ctx context.Context) error {
+	return l.DB.PingContext(ctx)
+}
+
+// Close stops the reaper and drops the dedicated connection, which releases every lock.
+func (l *PostgresLocker) Close() error {
+	close(l.stop)
+	<-l.done
+
+	l.mu.Lock()
+	conn := l.conn
+	l.mu.Unlock()
+	l.discardConn(conn)
+	return nil
+}
+
+// ロック用の専用接続。無ければプールから取り出す
+func (l *PostgresLocker) session(ctx context.Context) (*sql.Conn, error) {
+	l.mu.Lock()
+	conn := l.conn
+	l.mu.Unlock()
+	if conn != nil {
+		return conn, nil
+	}
+
+	conn, err := l.DB.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("locker conn: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.conn != nil {
+		// 同時に取り出した別の呼び出しの接続を使う
+		_ = conn.Close()
+		return l.conn, nil
+	}
+	l.conn = conn
+	return conn, nil
+}
+
+// 保持を外した後で呼ぶ
+func (l *PostgresLocker) release(ctx context.Context, conn *sql.Conn, k int64) error {
+	l.mu.Lock()
+	current := conn != nil && conn == l.conn
+	l.mu.Unlock()
+	if !current {
+		return nil
+	} // セッションごと解放済み
+
+	// 保持は外してあるので、呼び出し元が諦めても解放は最後まで流す（残すと誰も解放しない）
+	err := l.onSession(context.WithoutCancel(ctx), func(sctx context.Context) error {
+		_, err := sqlcdb.New(conn).AdvisoryUnlock(sctx, k)
+		return err
+	})
+	if err != nil {
+		// 解放できたか分からないため、接続ごと捨ててセッションのロックを全て手放す
+		l.discardConn(conn)
+		return fmt.Errorf("advisory unlock: %w", err)
+	}
+	return nil
+}
+
+// 呼び出し元が順番待ちの間に諦めた（文は流していない）
+var errSessionWait = errors.New("advisory locker: gave up waiting for session")
+
+// セッション上の文を直列に流す。文は呼び出し元の ctx から切り離し、専用の上限で実行する
+// （途中で切られると結果が分からず、接続ごと捨てて他の保持者のロックまで失うため）
+func (l *PostgresLocker) onSession(ctx context.Context, fn func(ctx context.Context) error) error {
+	select {
+	case l.sess <- struct{}{}:
+	case <-ctx.Done():
+		return errSessionWait
+	}
+	defer func() { <-l.sess }()
+
+	// ---- セッション操作は 3s ----
+	sctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), sessionTimeout)
+	defer cancel()
+	return fn(sctx)
+}
+
+// 接続をプールに戻さず破棄する（セッションが終わり advisory lock は全て解放される）。
+// 既に別の接続に替わっていれば何もしない
+func (l *PostgresLocker) discardConn(conn *sql.Conn) {
+	if conn == nil {
+		return
+	}
+
+	l.mu.Lock()
+	if l.conn != conn {
+		l.mu.Unlock()
+		return
+	}
+	l.conn = nil
+	for k, h := range l.held {
+		if !h.pending {
+			delete(l.held, k)
+		}
+	} // 取得中のものは取得側が接続の入れ替わりに気付いて外す
+	l.mu.Unlock()
+
+	_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+	_ = conn.Close()
+}
+
+// TTL を過ぎたロックを解放する（保持者が延長しなくなった = 死んだとみなす）
+func (l *PostgresLocker) reapLoop(interval time.Duration) {
+	defer close(l.done)
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-t.C:
+		}
+
+		if err := l.reap(); err != nil {
+			log.Printf("warn: reap advisory locks: %v", err)
+		}
+	}
+}
+
+func (l *PostgresLocker) reap() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	var expired []advisoryLock
+	var keys []int64
+
+	l.mu.Lock()
+	for k, h := range l.held {
+		if h.pending || now.Before(h.expiresAt) {
+			continue
+		}
+		delete(l.held, k)
+		expired, keys = append(expired, h), append(keys, k)
+	}
+	l.mu.Unlock()
+
+	var errs []error
+	for i, h := range expired {
+		if err := l.release(ctx, h.conn, keys[i]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
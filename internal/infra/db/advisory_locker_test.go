@@ -0,0 +1,116 @@
+package db_test
+
+import (
+	"database/sql"
+	"os"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/infra/db"
+	"github.com/kazshi01/payment-system/internal/infra/lockertest"
+)
+
+// TEST_POSTGRES_DSN（マイグレーション適用済みの DB）がある場合のみ実行する
+func TestPostgresLocker_conformance(t *testing.T) {
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN not set")
+	}
+
+	sqlDB, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	if err := sqlDB.Ping(); err != nil {
+		t.Fatalf("postgres ping: %v", err)
+	}
+
+	lockertest.Run(t, func(t *testing.T) domain.Locker {
+		l := db.NewPostgresLocker(sqlDB, 50*time.Millisecond)
+		t.Cleanup(func() { _ = l.Close() })
+		return l
+	})
+}
+
+// 同じ DB を使う別プロセス（別セッション）同士でも排他になる
+func TestPostgresLocker_acrossSessions(t *testing.T) {
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN not set")
+	}
+
+	sqlDB, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	a := db.NewPostgresLocker(sqlDB, 50*time.Millisecond)
+	b := db.NewPostgresLocker(sqlDB, 50*time.Millisecond)
+	t.Cleanup(func() { _ = a.Close(); _ = b.Close() })
+
+	ctx := t.Context()
+	key := "lockertest:sessions:" + time.Now().Format(time.RFC3339Nano)
+
+	ok, lock, err := a.TryLock(ctx, key, time.Minute)
+	if err != nil || !ok {
+		t.Fatalf("a.TryLock = %v, %v; want true", ok, err)
+	}
+	if ok, _, err := b.TryLock(ctx, key, time.Minute); err != nil || ok {
+		t.Fatalf("b.TryLock = %v, %v; want false", ok, err)
+	}
+	if err := a.Unlock(ctx, lock); err != nil {
+		t.Fatal(err)
+	}
+	ok, lock, err = b.TryLock(ctx, key, time.Minute)
+	if err != nil || !ok {
+		t.Fatalf("b.TryLock after unlock = %v, %v; want true", ok, err)
+	}
+	_ = b.Unlock(ctx, lock)
+}
+
+// 専用接続が切れたら Extend は false を返し、別のセッションが取れる
+func TestPostgresLocker_sessionLost(t *testing.T) {
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN not set")
+	}
+
+	sqlDB, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	a := db.NewPostgresLocker(sqlDB, 50*time.Millisecond)
+	b := db.NewPostgresLocker(sqlDB, 50*time.Millisecond)
+	t.Cleanup(func() { _ = a.Close(); _ = b.Close() })
+
+	ctx := t.Context()
+	key := "lockertest:lost:" + time.Now().Format(time.RFC3339Nano)
+
+	ok, lock, err := a.TryLock(ctx, key, time.Minute)
+	if err != nil || !ok {
+		t.Fatalf("a.TryLock = %v, %v; want true", ok, err)
+	}
+
+	// a のセッション（advisory lock を持つ唯一のバックエンド）を切る
+	if _, err := sqlDB.ExecContext(ctx, `
+		SELECT pg_terminate_backend(pid) FROM pg_locks
+		WHERE locktype = 'advisory' AND pid <> pg_backend_pid()`); err != nil {
+		t.Fatal(err)
+	}
+
+	if ok, err := a.Extend(ctx, lock, time.Minute); err != nil || ok {
+		t.Fatalf("a.Extend after session loss = %v, %v; want false", ok, err)
+	}
+	ok, lock, err = b.TryLock(ctx, key, time.Minute)
+	if err != nil || !ok {
+		t.Fatalf("b.TryLock after session loss = %v, %v; want true", ok, err)
+	}
+	_ = b.Unlock(ctx, lock)
+}
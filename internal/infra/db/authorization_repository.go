@@ -0,0 +1,118 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/money"
+	"github.com/kazshi01/payment-system/internal/domain/order"
+	"github.com/kazshi01/payment-system/internal/domain/payment"
+	sqlcdb "github.com/kazshi01/payment-system/internal/infra/db/sqlc"
+)
+
+// PostgresAuthorizationRepository implements domain.AuthorizationRepository using sqlc.
+type PostgresAuthorizationRepository struct {
+	DB *sql.DB
+	Q  *sqlcdb.Queries
+}
+
+func NewPostgresAuthorizationRepository(db *sql.DB) *PostgresAuthorizationRepository {
+	return &PostgresAuthorizationRepository{
+		DB: db,
+		Q:  sqlcdb.New(db),
+	}
+}
+
+// Tx が ctx に乗っていればその Tx にバインドした Queries を返す
+func (r *PostgresAuthorizationRepository) getQ(ctx context.Context) *sqlcdb.Queries {
+	if tx := getTx(ctx); tx != nil {
+		return r.Q.WithTx(tx)
+	}
+	return r.Q
+}
+
+func authorizationToDomain(rec sqlcdb.Authorization) *payment.Authorization {
+	return &payment.Authorization{
+		ID:             payment.AuthorizationID(rec.ID),
+		OrderID:        rec.OrderID,
+		Provider:       rec.Provider,
+		ProviderAuthID: rec.ProviderAuthID,
+		Amount:         money.Money{Amount: rec.Amount, Currency: money.Currency(rec.Currency)},
+		Status:         payment.AuthorizationStatus(rec.Status),
+		ExpiresAt:      rec.ExpiresAt,
+		CreatedAt:      rec.CreatedAt,
+		UpdatedAt:      rec.UpdatedAt,
+	}
+}
+
+// Create inserts a new authorization.
+func (r *PostgresAuthorizationRepository) Create(ctx context.Context, a *payment.Authorization) error {
+	params := sqlcdb.CreateAuthorizationParams{
+		ID:             string(a.ID),
+		OrderID:        a.OrderID,
+		Provider:       a.Provider,
+		ProviderAuthID: a.ProviderAuthID,
+		Amount:         a.Amount.Amount,
+		Currency:       string(a.Amount.Currency),
+		Status:         string(a.Status),
+		ExpiresAt:      a.ExpiresAt,
+		CreatedAt:      a.CreatedAt,
+		UpdatedAt:      a.UpdatedAt,
+	}
+	if err := r.getQ(ctx).CreateAuthorization(ctx, params); err != nil {
+		return fmt.Errorf("create authorization: %w", err)
+	}
+	return nil
+}
+
+// FindActiveByOrderID fetches the AUTHORIZED authorization of an order.
+func (r *PostgresAuthorizationRepository) FindActiveByOrderID(ctx context.Context, orderID order.ID) (*payment.Authorization, error) {
+	rec, err := r.getQ(ctx).GetActiveAuthorizationByOrderID(ctx, string(orderID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("get active authorization: %w", err)
+	}
+	return authorizationToDomain(rec), nil
+}
+
+// UpdateStatusIf updates the status of an authorization only if its current status is from.
+func (r *PostgresAuthorizationRepository) UpdateStatusIf(
+	ctx context.Context,
+	id payment.AuthorizationID,
+	from, to payment.AuthorizationStatus,
+	updatedAt time.Time,
+) (int64, error) {
+	n, err := r.getQ(ctx).UpdateAuthorizationStatusIf(ctx, sqlcdb.UpdateAuthorizationStatusIfParams{
+		ToStatus:   string(to),
+		UpdatedAt:  updatedAt,
+		ID:         string(id),
+		FromStatus: string(from),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("update authorization status if %s: %w", from, err)
+	}
+	return n, nil
+}
+
+// ListExpired lists AUTHORIZED authorizations whose expires_at has passed.
+func (r *PostgresAuthorizationRepository) ListExpired(ctx context.Context, now time.Time, limit int) ([]*payment.Authorization, error) {
+	recs, err := r.getQ(ctx).ListExpiredAuthorizations(ctx, sqlcdb.ListExpiredAuthorizationsParams{
+		ExpiresAt: now,
+		Limit:     int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list expired authorizations: %w", err)
+	}
+
+	as := make([]*payment.Authorization, 0, len(recs))
+	for _, rec := range recs {
+		as = append(as, authorizationToDomain(rec))
+	}
+	return as, nil
+}
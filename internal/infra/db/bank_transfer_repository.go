@@ -0,0 +1,239 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/banktransfer"
+	"github.com/kazshi01/payment-system/internal/domain/money"
+	"github.com/kazshi01/payment-system/internal/domain/order"
+	sqlcdb "github.com/kazshi01/payment-system/internal/infra/db/sqlc"
+)
+
+// PostgresBankTransferRepository implements domain.BankTransferRepository using sqlc.
+type PostgresBankTransferRepository struct {
+	DB *sql.DB
+	Q  *sqlcdb.Queries
+}
+
+func NewPostgresBankTransferRepository(db *sql.DB) *PostgresBankTransferRepository {
+	return &PostgresBankTransferRepository{
+		DB: db,
+		Q:  sqlcdb.New(db),
+	}
+}
+
+// Tx が ctx に乗っていればその Tx にバインドした Queries を返す
+func (r *PostgresBankTransferRepository) getQ(ctx context.Context) *sqlcdb.Queries {
+	if tx := getTx(ctx); tx != nil {
+		return r.Q.WithTx(tx)
+	}
+	return r.Q
+}
+
+func bankTransferToDomain(rec sqlcdb.BankTransfer) *banktransfer.Transfer {
+	return &banktransfer.Transfer{
+		ID:      rec.ID,
+		OrderID: rec.OrderID,
+		Destination: banktransfer.Destination{
+			Mode:          banktransfer.Mode(rec.Mode),
+			BankCode:      rec.BankCode,
+			BankName:      rec.BankName,
+			BranchCode:    rec.BranchCode,
+			BranchName:    rec.BranchName,
+			AccountType:   banktransfer.AccountType(rec.AccountType),
+			AccountNumber: rec.AccountNumber,
+			AccountName:   rec.AccountName,
+			ReferenceCode: rec.ReferenceCode,
+		},
+		PayerName: rec.PayerName,
+		Amount:    money.Money{Amount: rec.Amount, Currency: money.Currency(rec.Currency)},
+		Status:    banktransfer.TransferStatus(rec.Status),
+		CreatedAt: rec.CreatedAt,
+		UpdatedAt: rec.UpdatedAt,
+	}
+}
+
+// 勘定日は DATE 列。セッションのタイムゾーンで日付がずれないよう UTC の 0 時で渡し、日本時間の 0 時で返す
+func toDate(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+var jst = time.FixedZone("JST", 9*60*60)
+
+func fromDate(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, jst)
+}
+
+func bankDepositToDomain(rec sqlcdb.BankDeposit) *banktransfer.Deposit {
+	return &banktransfer.Deposit{
+		ID:              rec.ID,
+		Source:          rec.Source,
+		Line:            int(rec.Line),
+		BankCode:        rec.BankCode,
+		BranchCode:      rec.BranchCode,
+		AccountNumber:   rec.AccountNumber,
+		InquiryNo:       rec.InquiryNo,
+		ValueDate:       fromDate(rec.ValueDate),
+		Amount:          money.Money{Amount: rec.Amount, Currency: money.Currency(rec.Currency)},
+		PayerCode:       rec.PayerCode,
+		PayerName:       rec.PayerName,
+		RemittingBank:   rec.RemittingBank,
+		RemittingBranch: rec.RemittingBranch,
+		Canceled:        rec.Canceled,
+		TransferID:      rec.TransferID.String,
+		OrderID:         rec.OrderID.String,
+		Status:          banktransfer.DepositStatus(rec.Status),
+		Reason:          banktransfer.Reason(rec.Reason),
+		CreatedAt:       rec.CreatedAt,
+	}
+}
+
+// Create returns domain.ErrConflict if the order already has a transfer or the destination is taken.
+func (r *PostgresBankTransferRepository) Create(ctx context.Context, t *banktransfer.Transfer) error {
+	rows, err := r.getQ(ctx).CreateBankTransfer(ctx, sqlcdb.CreateBankTransferParams{
+		ID:            t.ID,
+		OrderID:       t.OrderID,
+		Mode:          string(t.Mode),
+		BankCode:      t.BankCode,
+		BankName:      t.BankName,
+		BranchCode:    t.BranchCode,
+		BranchName:    t.BranchName,
+		AccountType:   string(t.AccountType),
+		AccountNumber: t.AccountNumber,
+		AccountName:   t.AccountName,
+		ReferenceCode: t.ReferenceCode,
+		PayerName:     t.PayerName,
+		Amount:        t.Amount.Amount,
+		Currency:      string(t.Amount.Currency),
+		Status:        string(t.Status),
+		CreatedAt:     t.CreatedAt,
+		UpdatedAt:     t.UpdatedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("create bank transfer: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("create bank transfer for order %s: %w", t.OrderID, domain.ErrConflict)
+	}
+	return nil
+}
+
+// FindByOrderID fetches the transfer issued for an order.
+func (r *PostgresBankTransferRepository) FindByOrderID(ctx context.Context, orderID order.ID) (*banktransfer.Transfer, error) {
+	rec, err := r.getQ(ctx).GetBankTransferByOrderID(ctx, string(orderID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("get bank transfer by order: %w", err)
+	}
+	return bankTransferToDomain(rec), nil
+}
+
+// FindByDestination fetches the transfer issued with an account number and reference code.
+func (r *PostgresBankTransferRepository) FindByDestination(ctx context.Context, branchCode, accountNumber, referenceCode string) (*banktransfer.Transfer, error) {
+	rec, err := r.getQ(ctx).GetBankTransferByDestination(ctx, sqlcdb.GetBankTransferByDestinationParams{
+		BranchCode:    branchCode,
+		AccountNumber: accountNumber,
+		ReferenceCode: referenceCode,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("get bank transfer by destination: %w", err)
+	}
+	return bankTransferToDomain(rec), nil
+}
+
+// ListAwaitingByPayer lists awaiting transfers declared with the payer name and amount.
+func (r *PostgresBankTransferRepository) ListAwaitingByPayer(ctx context.Context, payerName string, amount money.Money, limit int) ([]*banktransfer.Transfer, error) {
+	recs, err := r.getQ(ctx).ListAwaitingBankTransfersByPayer(ctx, sqlcdb.ListAwaitingBankTransfersByPayerParams{
+		PayerName: payerName,
+		Amount:    amount.Amount,
+		Currency:  string(amount.Currency),
+		Limit:     int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list awaiting bank transfers by payer: %w", err)
+	}
+
+	ts := make([]*banktransfer.Transfer, 0, len(recs))
+	for _, rec := range recs {
+		ts = append(ts, bankTransferToDomain(rec))
+	}
+	return ts, nil
+}
+
+// UpdateStatusIf updates the status of a transfer only if its current status is from.
+func (r *PostgresBankTransferRepository) UpdateStatusIf(
+	ctx context.Context,
+	id string,
+	from, to banktransfer.TransferStatus,
+	updatedAt time.Time,
+) (int64, error) {
+	n, err := r.getQ(ctx).UpdateBankTransferStatusIf(ctx, sqlcdb.UpdateBankTransferStatusIfParams{
+		ToStatus:   string(to),
+		UpdatedAt:  updatedAt,
+		ID:         id,
+		FromStatus: string(from),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("update bank transfer status if %s: %w", from, err)
+	}
+	return n, nil
+}
+
+// CreateDepositIfAbsent inserts a deposit unless the same statement entry is already recorded.
+func (r *PostgresBankTransferRepository) CreateDepositIfAbsent(ctx context.Context, d *banktransfer.Deposit) (bool, error) {
+	rows, err := r.getQ(ctx).CreateBankDeposit(ctx, sqlcdb.CreateBankDepositParams{
+		ID:              d.ID,
+		Source:          d.Source,
+		Line:            int32(d.Line),
+		BankCode:        d.BankCode,
+		BranchCode:      d.BranchCode,
+		AccountNumber:   d.AccountNumber,
+		InquiryNo:       d.InquiryNo,
+		ValueDate:       toDate(d.ValueDate),
+		Amount:          d.Amount.Amount,
+		Currency:        string(d.Amount.Currency),
+		PayerCode:       d.PayerCode,
+		PayerName:       d.PayerName,
+		RemittingBank:   d.RemittingBank,
+		RemittingBranch: d.RemittingBranch,
+		Canceled:        d.Canceled,
+		TransferID:      sql.NullString{String: d.TransferID, Valid: d.TransferID != ""},
+		OrderID:         sql.NullString{String: d.OrderID, Valid: d.OrderID != ""},
+		Status:          string(d.Status),
+		Reason:          string(d.Reason),
+		CreatedAt:       d.CreatedAt,
+	})
+	if err != nil {
+		return false, fmt.Errorf("create bank deposit: %w", err)
+	}
+	return rows == 1, nil
+}
+
+// ListDeposits lists deposits newest first, optionally filtered by status.
+func (r *PostgresBankTransferRepository) ListDeposits(ctx context.Context, status banktransfer.DepositStatus, limit int) ([]*banktransfer.Deposit, error) {
+	recs, err := r.getQ(ctx).ListBankDeposits(ctx, sqlcdb.ListBankDepositsParams{
+		Status:  sql.NullString{String: string(status), Valid: status != ""},
+		MaxRows: int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list bank deposits: %w", err)
+	}
+
+	ds := make([]*banktransfer.Deposit, 0, len(recs))
+	for _, rec := range recs {
+		ds = append(ds, bankDepositToDomain(rec))
+	}
+	return ds, nil
+}
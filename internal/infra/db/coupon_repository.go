@@ -0,0 +1,154 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/coupon"
+	"github.com/kazshi01/payment-system/internal/domain/money"
+	"github.com/kazshi01/payment-system/internal/domain/order"
+	sqlcdb "github.com/kazshi01/payment-system/internal/infra/db/sqlc"
+)
+
+// PostgresCouponRepository implements domain.CouponRepository using sqlc.
+type PostgresCouponRepository struct {
+	DB *sql.DB
+	Q  *sqlcdb.Queries
+}
+
+func NewPostgresCouponRepository(db *sql.DB) *PostgresCouponRepository {
+	return &PostgresCouponRepository{
+		DB: db,
+		Q:  sqlcdb.New(db),
+	}
+}
+
+// Tx が ctx に乗っていればその Tx にバインドした Queries を返す
+func (r *PostgresCouponRepository) getQ(ctx context.Context) *sqlcdb.Queries {
+	if tx := getTx(ctx); tx != nil {
+		return r.Q.WithTx(tx)
+	}
+	return r.Q
+}
+
+// Create returns coupon.ErrCodeTaken if the code already exists.
+func (r *PostgresCouponRepository) Create(ctx context.Context, c *coupon.Coupon) error {
+	cur := c.Currency()
+	rows, err := r.getQ(ctx).CreateCoupon(ctx, sqlcdb.CreateCouponParams{
+		ID:             c.ID,
+		Code:           c.Code,
+		Kind:           string(c.Kind),
+		PercentOff:     c.PercentOff,
+		AmountOff:      c.AmountOff.Amount,
+		MinAmount:      c.MinAmount.Amount,
+		Currency:       string(cur),
+		MaxRedemptions: c.MaxRedemptions,
+		MaxPerUser:     c.MaxPerUser,
+		ValidFrom:      c.ValidFrom,
+		ValidUntil:     sql.NullTime{Time: c.ValidUntil, Valid: !c.ValidUntil.IsZero()},
+		CreatedAt:      c.CreatedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("create coupon: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("create coupon %s: %w", c.Code, coupon.ErrCodeTaken)
+	}
+	return nil
+}
+
+// FindByCode returns domain.ErrNotFound if no coupon has the code.
+func (r *PostgresCouponRepository) FindByCode(ctx context.Context, code string) (*coupon.Coupon, error) {
+	rec, err := r.getQ(ctx).GetCouponByCode(ctx, code)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("get coupon: %w", err)
+	}
+
+	cur := money.Currency(rec.Currency)
+	c := &coupon.Coupon{
+		ID:             rec.ID,
+		Code:           rec.Code,
+		Kind:           coupon.Kind(rec.Kind),
+		PercentOff:     rec.PercentOff,
+		MaxRedemptions: rec.MaxRedemptions,
+		MaxPerUser:     rec.MaxPerUser,
+		Redeemed:       rec.RedeemedCount,
+		ValidFrom:      rec.ValidFrom,
+		CreatedAt:      rec.CreatedAt,
+	}
+	if rec.AmountOff > 0 {
+		c.AmountOff = money.Money{Amount: rec.AmountOff, Currency: cur}
+	}
+	if rec.MinAmount > 0 {
+		c.MinAmount = money.Money{Amount: rec.MinAmount, Currency: cur}
+	}
+	if rec.ValidUntil.Valid {
+		c.ValidUntil = rec.ValidUntil.Time
+	}
+	return c, nil
+}
+
+// Redeem counts the redemption against both usage limits. The coupon row stays
+// locked until the Tx ends, so concurrent redemptions of one coupon run one at a time.
+func (r *PostgresCouponRepository) Redeem(ctx context.Context, red *coupon.Redemption) error {
+	q := r.getQ(ctx)
+
+	rows, err := q.IncrementCouponRedemptions(ctx, red.CouponID)
+	if err != nil {
+		return fmt.Errorf("increment coupon redemptions: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("redeem coupon %s: %w", red.Code, coupon.ErrUsageLimit)
+	}
+
+	rows, err = q.CreateCouponRedemption(ctx, sqlcdb.CreateCouponRedemptionParams{
+		ID:        red.ID,
+		CouponID:  red.CouponID,
+		OrderID:   red.OrderID,
+		UserID:    red.UserID,
+		Discount:  red.Discount.Amount,
+		Currency:  string(red.Discount.Currency),
+		CreatedAt: red.CreatedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("create coupon redemption: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("redeem coupon %s for user: %w", red.Code, coupon.ErrUsageLimit)
+	}
+	return nil
+}
+
+// Release deletes the order's redemption and gives the use back to the coupon.
+func (r *PostgresCouponRepository) Release(ctx context.Context, orderID order.ID) error {
+	if _, err := r.getQ(ctx).ReleaseCouponRedemption(ctx, string(orderID)); err != nil {
+		return fmt.Errorf("release coupon redemption: %w", err)
+	}
+	return nil
+}
+
+// FindRedemptionByOrderID returns domain.ErrNotFound if the order used no coupon.
+func (r *PostgresCouponRepository) FindRedemptionByOrderID(ctx context.Context, orderID order.ID) (*coupon.Redemption, error) {
+	rec, err := r.getQ(ctx).GetCouponRedemptionByOrderID(ctx, string(orderID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("get coupon redemption: %w", err)
+	}
+	return &coupon.Redemption{
+		ID:        rec.ID,
+		CouponID:  rec.CouponID,
+		Code:      rec.Code,
+		OrderID:   rec.OrderID,
+		UserID:    rec.UserID,
+		Discount:  money.Money{Amount: rec.Discount, Currency: money.Currency(rec.Currency)},
+		CreatedAt: rec.CreatedAt,
+	}, nil
+}
@@ -1,6 +1,7 @@
 package dbmodel
 
 import (
+	"github.com/kazshi01/payment-system/internal/domain/money"
 	"github.com/kazshi01/payment-system/internal/domain/order"
 	sqlcdb "github.com/kazshi01/payment-system/internal/infra/db/sqlc"
 )
@@ -10,7 +11,7 @@ func OrderToDomain(r sqlcdb.Order) *order.Order {
 	return &order.Order{
 		ID:        order.ID(r.ID),
 		UserID:    r.UserID,
-		AmountJPY: r.AmountJpy,            // BIGINT → int64
+		Amount:    money.Money{Amount: r.Amount, Currency: money.Currency(r.Currency)},
 		Status:    order.Status(r.Status), // string → domain.Status
 		CreatedAt: r.CreatedAt,
 		UpdatedAt: r.UpdatedAt,
@@ -22,7 +23,8 @@ func CreateOrderParamsFromDomain(o *order.Order) sqlcdb.CreateOrderParams {
 	return sqlcdb.CreateOrderParams{
 		ID:        string(o.ID),
 		UserID:    o.UserID,
-		AmountJpy: o.AmountJPY,
+		Amount:    o.Amount.Amount,
+		Currency:  string(o.Amount.Currency),
 		Status:    string(o.Status),
 		CreatedAt: o.CreatedAt,
 		UpdatedAt: o.UpdatedAt,
@@ -33,7 +35,8 @@ func CreateOrderParamsFromDomain(o *order.Order) sqlcdb.CreateOrderParams {
 func UpdateOrderParamsFromDomain(o *order.Order) sqlcdb.UpdateOrderParams {
 	return sqlcdb.UpdateOrderParams{
 		ID:        string(o.ID),
-		AmountJpy: o.AmountJPY,
+		Amount:    o.Amount.Amount,
+		Currency:  string(o.Amount.Currency),
 		Status:    string(o.Status),
 		UpdatedAt: o.UpdatedAt,
 	}
@@ -0,0 +1,81 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/kazshi01/payment-system/internal/domain/event"
+	"github.com/kazshi01/payment-system/internal/domain/order"
+	sqlcdb "github.com/kazshi01/payment-system/internal/infra/db/sqlc"
+)
+
+// PostgresEventRepository implements domain.EventRepository using sqlc.
+type PostgresEventRepository struct {
+	DB *sql.DB
+	Q  *sqlcdb.Queries
+}
+
+func NewPostgresEventRepository(db *sql.DB) *PostgresEventRepository {
+	return &PostgresEventRepository{
+		DB: db,
+		Q:  sqlcdb.New(db),
+	}
+}
+
+// Tx が ctx に乗っていればその Tx にバインドした Queries を返す
+func (r *PostgresEventRepository) getQ(ctx context.Context) *sqlcdb.Queries {
+	if tx := getTx(ctx); tx != nil {
+		return r.Q.WithTx(tx)
+	}
+	return r.Q
+}
+
+// Append inserts a new event. Events are never updated or deleted.
+func (r *PostgresEventRepository) Append(ctx context.Context, e *event.Event) error {
+	payload := e.Payload
+	if payload == nil {
+		payload = map[string]any{}
+	}
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal event payload: %w", err)
+	}
+
+	params := sqlcdb.CreatePaymentEventParams{
+		ID:        string(e.ID),
+		OrderID:   e.OrderID,
+		Type:      string(e.Type),
+		Payload:   b,
+		CreatedAt: e.CreatedAt,
+	}
+	if err := r.getQ(ctx).CreatePaymentEvent(ctx, params); err != nil {
+		return fmt.Errorf("create payment event: %w", err)
+	}
+	return nil
+}
+
+// ListByOrderID lists events of an order in chronological order.
+func (r *PostgresEventRepository) ListByOrderID(ctx context.Context, orderID order.ID) ([]*event.Event, error) {
+	recs, err := r.getQ(ctx).ListPaymentEventsByOrderID(ctx, string(orderID))
+	if err != nil {
+		return nil, fmt.Errorf("list payment events: %w", err)
+	}
+
+	es := make([]*event.Event, 0, len(recs))
+	for _, rec := range recs {
+		var payload map[string]any
+		if err := json.Unmarshal(rec.Payload, &payload); err != nil {
+			return nil, fmt.Errorf("unmarshal event payload: %w", err)
+		}
+		es = append(es, &event.Event{
+			ID:        event.ID(rec.ID),
+			OrderID:   rec.OrderID,
+			Type:      event.Type(rec.Type),
+			Payload:   payload,
+			CreatedAt: rec.CreatedAt,
+		})
+	}
+	return es, nil
+}
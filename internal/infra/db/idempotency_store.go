@@ -0,0 +1,115 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+	sqlcdb "github.com/kazshi01/payment-system/internal/infra/db/sqlc"
+)
+
+// PostgresIdempotencyStore implements domain.IdempotencyStore using sqlc.
+type PostgresIdempotencyStore struct {
+	DB *sql.DB
+	Q  *sqlcdb.Queries
+}
+
+func NewPostgresIdempotencyStore(db *sql.DB) *PostgresIdempotencyStore {
+	return &PostgresIdempotencyStore{
+		DB: db,
+		Q:  sqlcdb.New(db),
+	}
+}
+
+// Reserve inserts the key as in-progress, or returns the live record holding it.
+func (s *PostgresIdempotencyStore) Reserve(ctx context.Context, rec *domain.IdempotencyRecord) (*domain.IdempotencyRecord, error) {
+	rows, err := s.Q.ReserveIdempotencyKey(ctx, sqlcdb.ReserveIdempotencyKeyParams{
+		UserID:      rec.UserID,
+		Key:         rec.Key,
+		Fingerprint: rec.Fingerprint,
+		CreatedAt:   rec.CreatedAt,
+		ExpiresAt:   rec.ExpiresAt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reserve idempotency key: %w", err)
+	}
+	if rows == 1 {
+		return nil, nil
+	}
+
+	got, err := s.Q.GetIdempotencyKey(ctx, sqlcdb.GetIdempotencyKeyParams{UserID: rec.UserID, Key: rec.Key})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// 確保と取得の間に解放された。呼び出し側はリトライ扱いにする
+			return nil, domain.ErrConflict
+		}
+		return nil, fmt.Errorf("get idempotency key: %w", err)
+	}
+
+	existing := &domain.IdempotencyRecord{
+		UserID:      got.UserID,
+		Key:         got.Key,
+		Fingerprint: got.Fingerprint,
+		StatusCode:  int(got.StatusCode),
+		Body:        got.ResponseBody,
+		CreatedAt:   got.CreatedAt,
+		ExpiresAt:   got.ExpiresAt,
+	}
+	if err := json.Unmarshal(got.ResponseHeaders, &existing.Header); err != nil {
+		return nil, fmt.Errorf("unmarshal idempotency response headers: %w", err)
+	}
+	return existing, nil
+}
+
+// Complete stores the first response for the reserved key.
+func (s *PostgresIdempotencyStore) Complete(ctx context.Context, rec *domain.IdempotencyRecord) error {
+	header, err := json.Marshal(rec.Header)
+	if err != nil {
+		return fmt.Errorf("marshal idempotency response headers: %w", err)
+	}
+	if rec.Header == nil {
+		header = []byte(`{}`)
+	}
+
+	rows, err := s.Q.CompleteIdempotencyKey(ctx, sqlcdb.CompleteIdempotencyKeyParams{
+		UserID:          rec.UserID,
+		Key:             rec.Key,
+		StatusCode:      int32(rec.StatusCode),
+		ResponseHeaders: header,
+		ResponseBody:    rec.Body,
+	})
+	if err != nil {
+		return fmt.Errorf("complete idempotency key: %w", err)
+	}
+	if rows == 0 {
+		return domain.ErrConflict
+	}
+	return nil
+}
+
+// Release deletes the key while it is still in progress.
+func (s *PostgresIdempotencyStore) Release(ctx context.Context, userID, key string) error {
+	if err := s.Q.DeleteInProgressIdempotencyKey(ctx, sqlcdb.DeleteInProgressIdempotencyKeyParams{
+		UserID: userID,
+		Key:    key,
+	}); err != nil {
+		return fmt.Errorf("release idempotency key: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpired deletes up to limit expired keys.
+func (s *PostgresIdempotencyStore) DeleteExpired(ctx context.Context, now time.Time, limit int) (int64, error) {
+	n, err := s.Q.DeleteExpiredIdempotencyKeys(ctx, sqlcdb.DeleteExpiredIdempotencyKeysParams{
+		ExpiresAt: now,
+		Limit:     int32(limit),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("delete expired idempotency keys: %w", err)
+	}
+	return n, nil
+}
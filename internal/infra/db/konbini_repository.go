@@ -0,0 +1,125 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/money"
+	"github.com/kazshi01/payment-system/internal/domain/order"
+	"github.com/kazshi01/payment-system/internal/domain/payment"
+	sqlcdb "github.com/kazshi01/payment-system/internal/infra/db/sqlc"
+)
+
+// PostgresKonbiniRepository implements domain.KonbiniRepository using sqlc.
+type PostgresKonbiniRepository struct {
+	DB *sql.DB
+	Q  *sqlcdb.Queries
+}
+
+func NewPostgresKonbiniRepository(db *sql.DB) *PostgresKonbiniRepository {
+	return &PostgresKonbiniRepository{
+		DB: db,
+		Q:  sqlcdb.New(db),
+	}
+}
+
+// Tx が ctx に乗っていればその Tx にバインドした Queries を返す
+func (r *PostgresKonbiniRepository) getQ(ctx context.Context) *sqlcdb.Queries {
+	if tx := getTx(ctx); tx != nil {
+		return r.Q.WithTx(tx)
+	}
+	return r.Q
+}
+
+func konbiniToDomain(rec sqlcdb.KonbiniPayment) *payment.Konbini {
+	return &payment.Konbini{
+		ID:                 payment.KonbiniID(rec.ID),
+		OrderID:            rec.OrderID,
+		Provider:           rec.Provider,
+		ProviderPaymentID:  rec.ProviderPaymentID,
+		PaymentCode:        rec.PaymentCode,
+		ConfirmationNumber: rec.ConfirmationNumber,
+		Amount:             money.Money{Amount: rec.Amount, Currency: money.Currency(rec.Currency)},
+		Status:             payment.KonbiniStatus(rec.Status),
+		ExpiresAt:          rec.ExpiresAt,
+		CreatedAt:          rec.CreatedAt,
+		UpdatedAt:          rec.UpdatedAt,
+	}
+}
+
+// Create returns domain.ErrConflict if the order already awaits a konbini payment.
+func (r *PostgresKonbiniRepository) Create(ctx context.Context, k *payment.Konbini) error {
+	rows, err := r.getQ(ctx).CreateKonbiniPayment(ctx, sqlcdb.CreateKonbiniPaymentParams{
+		ID:                 string(k.ID),
+		OrderID:            k.OrderID,
+		Provider:           k.Provider,
+		ProviderPaymentID:  k.ProviderPaymentID,
+		PaymentCode:        k.PaymentCode,
+		ConfirmationNumber: k.ConfirmationNumber,
+		Amount:             k.Amount.Amount,
+		Currency:           string(k.Amount.Currency),
+		Status:             string(k.Status),
+		ExpiresAt:          k.ExpiresAt,
+		CreatedAt:          k.CreatedAt,
+		UpdatedAt:          k.UpdatedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("create konbini payment: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("create konbini payment for order %s: %w", k.OrderID, domain.ErrConflict)
+	}
+	return nil
+}
+
+// FindAwaitingByOrderID fetches the AWAITING konbini payment of an order.
+func (r *PostgresKonbiniRepository) FindAwaitingByOrderID(ctx context.Context, orderID order.ID) (*payment.Konbini, error) {
+	rec, err := r.getQ(ctx).GetAwaitingKonbiniPaymentByOrderID(ctx, string(orderID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("get awaiting konbini payment: %w", err)
+	}
+	return konbiniToDomain(rec), nil
+}
+
+// UpdateStatusIf updates the status of a konbini payment only if its current status is from.
+func (r *PostgresKonbiniRepository) UpdateStatusIf(
+	ctx context.Context,
+	id payment.KonbiniID,
+	from, to payment.KonbiniStatus,
+	updatedAt time.Time,
+) (int64, error) {
+	n, err := r.getQ(ctx).UpdateKonbiniPaymentStatusIf(ctx, sqlcdb.UpdateKonbiniPaymentStatusIfParams{
+		ToStatus:   string(to),
+		UpdatedAt:  updatedAt,
+		ID:         string(id),
+		FromStatus: string(from),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("update konbini payment status if %s: %w", from, err)
+	}
+	return n, nil
+}
+
+// ListExpired lists AWAITING konbini payments whose deadline has passed.
+func (r *PostgresKonbiniRepository) ListExpired(ctx context.Context, now time.Time, limit int) ([]*payment.Konbini, error) {
+	recs, err := r.getQ(ctx).ListExpiredKonbiniPayments(ctx, sqlcdb.ListExpiredKonbiniPaymentsParams{
+		ExpiresAt: now,
+		Limit:     int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list expired konbini payments: %w", err)
+	}
+
+	ks := make([]*payment.Konbini, 0, len(recs))
+	for _, rec := range recs {
+		ks = append(ks, konbiniToDomain(rec))
+	}
+	return ks, nil
+}
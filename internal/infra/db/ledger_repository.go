@@ -0,0 +1,112 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/ledger"
+	"github.com/kazshi01/payment-system/internal/domain/money"
+	sqlcdb "github.com/kazshi01/payment-system/internal/infra/db/sqlc"
+)
+
+// PostgresLedgerRepository implements domain.LedgerRepository using sqlc.
+type PostgresLedgerRepository struct {
+	DB *sql.DB
+	Q  *sqlcdb.Queries
+}
+
+func NewPostgresLedgerRepository(db *sql.DB) *PostgresLedgerRepository {
+	return &PostgresLedgerRepository{
+		DB: db,
+		Q:  sqlcdb.New(db),
+	}
+}
+
+// Tx が ctx に乗っていればその Tx にバインドした Queries を返す
+func (r *PostgresLedgerRepository) getQ(ctx context.Context) *sqlcdb.Queries {
+	if tx := getTx(ctx); tx != nil {
+		return r.Q.WithTx(tx)
+	}
+	return r.Q
+}
+
+// Record writes a journal entry and its postings. It must run inside Tx:
+// the balance trigger on postings is deferred to commit, so a partially
+// written entry never becomes visible.
+func (r *PostgresLedgerRepository) Record(ctx context.Context, e *ledger.Entry) error {
+	if err := e.Validate(); err != nil {
+		return fmt.Errorf("%w: %w", domain.ErrInvalidArgument, err)
+	}
+	q := r.getQ(ctx)
+	n, err := q.CreateJournalEntry(ctx, sqlcdb.CreateJournalEntryParams{
+		ID:        e.ID,
+		Kind:      string(e.Kind),
+		OrderID:   e.OrderID,
+		Reference: e.Reference,
+		CreatedAt: e.CreatedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("create journal entry: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%w: %s entry for %s is already recorded", domain.ErrConflict, e.Kind, e.Reference)
+	}
+	for i, p := range e.Postings {
+		err := q.CreatePosting(ctx, sqlcdb.CreatePostingParams{
+			EntryID:   e.ID,
+			LineNo:    int32(i + 1),
+			Account:   string(p.Account),
+			Amount:    p.Amount.Amount,
+			Currency:  string(p.Amount.Currency),
+			CreatedAt: e.CreatedAt,
+		})
+		if err != nil {
+			return fmt.Errorf("create posting %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// Balance sums the debits and credits posted to an account in [from, to).
+func (r *PostgresLedgerRepository) Balance(ctx context.Context, account ledger.Account, c money.Currency, from, to time.Time) (*ledger.Balance, error) {
+	rec, err := r.getQ(ctx).SumPostings(ctx, sqlcdb.SumPostingsParams{
+		Account:     string(account),
+		Currency:    string(c),
+		CreatedFrom: sql.NullTime{Time: from, Valid: !from.IsZero()},
+		CreatedTo:   sql.NullTime{Time: to, Valid: !to.IsZero()},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("sum postings: %w", err)
+	}
+	return &ledger.Balance{Account: account, Currency: c, Debit: rec.Debit, Credit: rec.Credit}, nil
+}
+
+// ListUnbalancedEntries lists journal entries whose postings do not sum to zero.
+func (r *PostgresLedgerRepository) ListUnbalancedEntries(ctx context.Context, limit int) ([]ledger.Violation, error) {
+	recs, err := r.getQ(ctx).ListUnbalancedEntries(ctx, int32(limit))
+	if err != nil {
+		return nil, fmt.Errorf("list unbalanced entries: %w", err)
+	}
+	vs := make([]ledger.Violation, 0, len(recs))
+	for _, rec := range recs {
+		total := money.Money{Amount: rec.Total, Currency: money.Currency(rec.Currency)}
+		vs = append(vs, ledger.UnbalancedEntry(rec.ID, rec.OrderID, total, rec.Postings))
+	}
+	return vs, nil
+}
+
+// ListUnpostedOrders lists paid orders whose payments or refunds are not posted as recorded.
+func (r *PostgresLedgerRepository) ListUnpostedOrders(ctx context.Context, limit int) ([]ledger.Violation, error) {
+	recs, err := r.getQ(ctx).ListUnpostedOrders(ctx, int32(limit))
+	if err != nil {
+		return nil, fmt.Errorf("list unposted orders: %w", err)
+	}
+	vs := make([]ledger.Violation, 0, len(recs))
+	for _, rec := range recs {
+		vs = append(vs, ledger.UnpostedOrder(rec.ID, rec.Status, rec.Paid, rec.Charged, rec.Refunded, rec.RefundPosted))
+	}
+	return vs, nil
+}
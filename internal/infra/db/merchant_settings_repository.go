@@ -0,0 +1,71 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/merchant"
+	"github.com/kazshi01/payment-system/internal/domain/tax"
+	sqlcdb "github.com/kazshi01/payment-system/internal/infra/db/sqlc"
+)
+
+// PostgresMerchantSettingsRepository implements domain.MerchantSettingsRepository using sqlc.
+type PostgresMerchantSettingsRepository struct {
+	DB *sql.DB
+	Q  *sqlcdb.Queries
+}
+
+func NewPostgresMerchantSettingsRepository(db *sql.DB) *PostgresMerchantSettingsRepository {
+	return &PostgresMerchantSettingsRepository{
+		DB: db,
+		Q:  sqlcdb.New(db),
+	}
+}
+
+// Tx が ctx に乗っていればその Tx にバインドした Queries を返す
+func (r *PostgresMerchantSettingsRepository) getQ(ctx context.Context) *sqlcdb.Queries {
+	if tx := getTx(ctx); tx != nil {
+		return r.Q.WithTx(tx)
+	}
+	return r.Q
+}
+
+// Get returns domain.ErrNotFound if the merchant has no settings yet.
+func (r *PostgresMerchantSettingsRepository) Get(ctx context.Context, userID string) (*merchant.Settings, error) {
+	rec, err := r.getQ(ctx).GetMerchantSettings(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("get merchant settings: %w", err)
+	}
+	return &merchant.Settings{
+		UserID:      rec.UserID,
+		TaxRounding: tax.Rounding(rec.TaxRounding),
+		Issuer: merchant.Issuer{
+			Name:               rec.IssuerName,
+			Address:            rec.IssuerAddress,
+			RegistrationNumber: rec.RegistrationNumber,
+		},
+		UpdatedAt: rec.UpdatedAt,
+	}, nil
+}
+
+// Upsert creates or replaces the settings of s.UserID.
+func (r *PostgresMerchantSettingsRepository) Upsert(ctx context.Context, s *merchant.Settings) error {
+	err := r.getQ(ctx).UpsertMerchantSettings(ctx, sqlcdb.UpsertMerchantSettingsParams{
+		UserID:             s.UserID,
+		TaxRounding:        string(s.TaxRounding),
+		IssuerName:         s.Issuer.Name,
+		IssuerAddress:      s.Issuer.Address,
+		RegistrationNumber: s.Issuer.RegistrationNumber,
+		UpdatedAt:          s.UpdatedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("upsert merchant settings: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/kazshi01/payment-system/internal/domain/money"
+	"github.com/kazshi01/payment-system/internal/domain/order"
+	"github.com/kazshi01/payment-system/internal/domain/tax"
+	sqlcdb "github.com/kazshi01/payment-system/internal/infra/db/sqlc"
+)
+
+// PostgresOrderItemRepository implements domain.OrderItemRepository using sqlc.
+type PostgresOrderItemRepository struct {
+	DB *sql.DB
+	Q  *sqlcdb.Queries
+}
+
+func NewPostgresOrderItemRepository(db *sql.DB) *PostgresOrderItemRepository {
+	return &PostgresOrderItemRepository{
+		DB: db,
+		Q:  sqlcdb.New(db),
+	}
+}
+
+// Tx が ctx に乗っていればその Tx にバインドした Queries を返す
+func (r *PostgresOrderItemRepository) getQ(ctx context.Context) *sqlcdb.Queries {
+	if tx := getTx(ctx); tx != nil {
+		return r.Q.WithTx(tx)
+	}
+	return r.Q
+}
+
+// CreateBatch inserts the items of an order numbered from 1. Call it inside a Tx.
+func (r *PostgresOrderItemRepository) CreateBatch(ctx context.Context, orderID order.ID, items []order.Item) error {
+	q := r.getQ(ctx)
+	for i, it := range items {
+		err := q.CreateOrderItem(ctx, sqlcdb.CreateOrderItemParams{
+			OrderID:   string(orderID),
+			LineNo:    int32(i + 1),
+			Sku:       it.SKU,
+			Name:      it.Name,
+			UnitPrice: it.UnitPrice.Amount,
+			Currency:  string(it.UnitPrice.Currency),
+			Quantity:  it.Quantity,
+			TaxRate:   int16(it.TaxRate),
+		})
+		if err != nil {
+			return fmt.Errorf("create order item %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// ListByOrderID lists the items of an order by line number.
+func (r *PostgresOrderItemRepository) ListByOrderID(ctx context.Context, orderID order.ID) ([]order.Item, error) {
+	recs, err := r.getQ(ctx).ListOrderItemsByOrderID(ctx, string(orderID))
+	if err != nil {
+		return nil, fmt.Errorf("list order items: %w", err)
+	}
+
+	items := make([]order.Item, 0, len(recs))
+	for _, rec := range recs {
+		items = append(items, order.Item{
+			SKU:       rec.Sku,
+			Name:      rec.Name,
+			UnitPrice: money.Money{Amount: rec.UnitPrice, Currency: money.Currency(rec.Currency)},
+			Quantity:  rec.Quantity,
+			TaxRate:   tax.Rate(rec.TaxRate),
+		})
+	}
+	return items, nil
+}
@@ -8,7 +8,9 @@ import (
 	"time"
 
 	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/money"
 	"github.com/kazshi01/payment-system/internal/domain/order"
+	"github.com/kazshi01/payment-system/internal/infra/db/dbmodel"
 	sqlcdb "github.com/kazshi01/payment-system/internal/infra/db/sqlc"
 )
 
@@ -38,7 +40,8 @@ func (r *PostgresOrderRepository) Create(ctx context.Context, o *order.Order) er
 	params := sqlcdb.CreateOrderParams{
 		ID:        string(o.ID),
 		UserID:    o.UserID,
-		AmountJpy: o.AmountJPY,
+		Amount:    o.Amount.Amount,
+		Currency:  string(o.Amount.Currency),
 		Status:    string(o.Status),
 		CreatedAt: o.CreatedAt,
 		UpdatedAt: o.UpdatedAt,
@@ -53,11 +56,15 @@ func (r *PostgresOrderRepository) Create(ctx context.Context, o *order.Order) er
 func (r *PostgresOrderRepository) FindByID(ctx context.Context, id order.ID) (*order.Order, error) {
 	rec, err := r.getQ(ctx).GetOrder(ctx, string(id))
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
 		return nil, fmt.Errorf("get order: %w", err)
 	}
 	return &order.Order{
 		ID:        order.ID(rec.ID),
-		AmountJPY: rec.AmountJpy,
+		UserID:    rec.UserID,
+		Amount:    money.Money{Amount: rec.Amount, Currency: money.Currency(rec.Currency)},
 		Status:    order.Status(rec.Status),
 		CreatedAt: rec.CreatedAt,
 		UpdatedAt: rec.UpdatedAt,
@@ -79,7 +86,7 @@ func (r *PostgresOrderRepository) FindByIDForUser(ctx context.Context, id order.
 	return &order.Order{
 		ID:        order.ID(rec.ID),
 		UserID:    rec.UserID,
-		AmountJPY: rec.AmountJpy,
+		Amount:    money.Money{Amount: rec.Amount, Currency: money.Currency(rec.Currency)},
 		Status:    order.Status(rec.Status),
 		CreatedAt: rec.CreatedAt,
 		UpdatedAt: rec.UpdatedAt,
@@ -90,7 +97,8 @@ func (r *PostgresOrderRepository) FindByIDForUser(ctx context.Context, id order.
 func (r *PostgresOrderRepository) Update(ctx context.Context, o *order.Order) error {
 	params := sqlcdb.UpdateOrderParams{
 		ID:        string(o.ID),
-		AmountJpy: o.AmountJPY,
+		Amount:    o.Amount.Amount,
+		Currency:  string(o.Amount.Currency),
 		Status:    string(o.Status),
 		UpdatedAt: o.UpdatedAt,
 	}
@@ -100,41 +108,50 @@ func (r *PostgresOrderRepository) Update(ctx context.Context, o *order.Order) er
 	return nil
 }
 
-// UpdateStatusIfPending updates the status of an order to the given status if it is pending.
-func (r *PostgresOrderRepository) UpdateStatusIfPending(
+// UpdateStatusIf updates the status of an order only if its current status is from
+// and no holder with a newer fencing token has written it.
+func (r *PostgresOrderRepository) UpdateStatusIf(
 	ctx context.Context,
 	id order.ID,
-	newStatus order.Status,
+	from, to order.Status,
+	fence int64,
 	updatedAt time.Time,
 ) (int64, error) {
-	n, err := r.getQ(ctx).UpdateOrderStatusIfPending(ctx, sqlcdb.UpdateOrderStatusIfPendingParams{
-		ID:        string(id),
-		Status:    string(newStatus),
-		UpdatedAt: updatedAt,
+	n, err := r.getQ(ctx).UpdateOrderStatusIf(ctx, sqlcdb.UpdateOrderStatusIfParams{
+		ToStatus:   string(to),
+		UpdatedAt:  updatedAt,
+		Fence:      fence,
+		ID:         string(id),
+		FromStatus: string(from),
 	})
 	if err != nil {
-		return 0, fmt.Errorf("update status if pending: %w", err)
+		return 0, fmt.Errorf("update status if %s: %w", from, err)
 	}
 	return n, nil
 }
 
-// UpdateStatusIfPendingForUser updates the status of an order to the given status if it is pending.
-func (r *PostgresOrderRepository) UpdateStatusIfPendingForUser(
-	ctx context.Context,
-	id order.ID,
-	userID string,
-	newStatus order.Status,
-	updatedAt time.Time,
-) (int64, error) {
-	n, err := r.getQ(ctx).UpdateOrderStatusIfPendingForUser(ctx, sqlcdb.UpdateOrderStatusIfPendingForUserParams{
-		ID:        string(id),
-		UserID:    userID,
-		Status:    string(newStatus),
-		UpdatedAt: updatedAt,
-	})
+// List lists orders matching the filter, newest first.
+func (r *PostgresOrderRepository) List(ctx context.Context, f domain.OrderListFilter) ([]*order.Order, error) {
+	params := sqlcdb.ListOrdersParams{
+		UserID:      sql.NullString{String: f.UserID, Valid: f.UserID != ""},
+		Status:      sql.NullString{String: string(f.Status), Valid: f.Status != ""},
+		CreatedFrom: sql.NullTime{Time: f.CreatedFrom, Valid: !f.CreatedFrom.IsZero()},
+		CreatedTo:   sql.NullTime{Time: f.CreatedTo, Valid: !f.CreatedTo.IsZero()},
+		MaxRows:     int32(f.Limit),
+	}
+	if f.After != nil {
+		params.AfterCreatedAt = sql.NullTime{Time: f.After.CreatedAt, Valid: true}
+		params.AfterID = sql.NullString{String: string(f.After.ID), Valid: true}
+	}
+
+	recs, err := r.getQ(ctx).ListOrders(ctx, params)
 	if err != nil {
-		return 0, fmt.Errorf("update status if pending (user): %w", err)
+		return nil, fmt.Errorf("list orders: %w", err)
 	}
 
-	return n, nil
+	orders := make([]*order.Order, 0, len(recs))
+	for _, rec := range recs {
+		orders = append(orders, dbmodel.OrderToDomain(rec))
+	}
+	return orders, nil
 }
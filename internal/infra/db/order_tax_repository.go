@@ -0,0 +1,70 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/kazshi01/payment-system/internal/domain/money"
+	"github.com/kazshi01/payment-system/internal/domain/order"
+	"github.com/kazshi01/payment-system/internal/domain/tax"
+	sqlcdb "github.com/kazshi01/payment-system/internal/infra/db/sqlc"
+)
+
+// PostgresOrderTaxRepository implements domain.OrderTaxRepository using sqlc.
+type PostgresOrderTaxRepository struct {
+	DB *sql.DB
+	Q  *sqlcdb.Queries
+}
+
+func NewPostgresOrderTaxRepository(db *sql.DB) *PostgresOrderTaxRepository {
+	return &PostgresOrderTaxRepository{
+		DB: db,
+		Q:  sqlcdb.New(db),
+	}
+}
+
+// Tx が ctx に乗っていればその Tx にバインドした Queries を返す
+func (r *PostgresOrderTaxRepository) getQ(ctx context.Context) *sqlcdb.Queries {
+	if tx := getTx(ctx); tx != nil {
+		return r.Q.WithTx(tx)
+	}
+	return r.Q
+}
+
+// CreateBatch inserts one row per tax rate. Call it inside a Tx.
+func (r *PostgresOrderTaxRepository) CreateBatch(ctx context.Context, orderID order.ID, buckets []tax.Bucket) error {
+	q := r.getQ(ctx)
+	for _, b := range buckets {
+		err := q.CreateOrderTaxLine(ctx, sqlcdb.CreateOrderTaxLineParams{
+			OrderID:  string(orderID),
+			Rate:     int16(b.Rate),
+			Taxable:  b.Taxable.Amount,
+			Tax:      b.Tax.Amount,
+			Currency: string(b.Taxable.Currency),
+		})
+		if err != nil {
+			return fmt.Errorf("create order tax line %d%%: %w", b.Rate, err)
+		}
+	}
+	return nil
+}
+
+// ListByOrderID lists the tax breakdown of an order, highest rate first.
+func (r *PostgresOrderTaxRepository) ListByOrderID(ctx context.Context, orderID order.ID) ([]tax.Bucket, error) {
+	recs, err := r.getQ(ctx).ListOrderTaxLinesByOrderID(ctx, string(orderID))
+	if err != nil {
+		return nil, fmt.Errorf("list order tax lines: %w", err)
+	}
+
+	out := make([]tax.Bucket, 0, len(recs))
+	for _, rec := range recs {
+		c := money.Currency(rec.Currency)
+		out = append(out, tax.Bucket{
+			Rate:    tax.Rate(rec.Rate),
+			Taxable: money.Money{Amount: rec.Taxable, Currency: c},
+			Tax:     money.Money{Amount: rec.Tax, Currency: c},
+		})
+	}
+	return out, nil
+}
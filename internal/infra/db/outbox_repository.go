@@ -0,0 +1,111 @@
+package db
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+	sqlcdb "github.com/kazshi01/payment-system/internal/infra/db/sqlc"
+)
+
+// PostgresOutboxRepository implements domain.OutboxRepository using sqlc.
+type PostgresOutboxRepository struct {
+	DB *sql.DB
+	Q  *sqlcdb.Queries
+}
+
+func NewPostgresOutboxRepository(db *sql.DB) *PostgresOutboxRepository {
+	return &PostgresOutboxRepository{
+		DB: db,
+		Q:  sqlcdb.New(db),
+	}
+}
+
+// Tx が ctx に乗っていればその Tx にバインドした Queries を返す
+func (r *PostgresOutboxRepository) getQ(ctx context.Context) *sqlcdb.Queries {
+	if tx := getTx(ctx); tx != nil {
+		return r.Q.WithTx(tx)
+	}
+	return r.Q
+}
+
+// Enqueue inserts a message. Call it inside the Tx of the state change.
+func (r *PostgresOutboxRepository) Enqueue(ctx context.Context, m *domain.OutboxMessage) error {
+	if err := r.getQ(ctx).CreateOutboxMessage(ctx, sqlcdb.CreateOutboxMessageParams{
+		ID:          m.ID,
+		AggregateID: m.AggregateID,
+		Type:        m.Type,
+		Payload:     m.Payload,
+		CreatedAt:   m.CreatedAt,
+	}); err != nil {
+		return fmt.Errorf("create outbox message: %w", err)
+	}
+	return nil
+}
+
+// ClaimPending leases due messages until leaseUntil. Rows are picked with FOR UPDATE SKIP LOCKED,
+// so concurrent relays never claim the same message.
+func (r *PostgresOutboxRepository) ClaimPending(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*domain.OutboxMessage, error) {
+	recs, err := r.getQ(ctx).ClaimPendingOutboxMessages(ctx, sqlcdb.ClaimPendingOutboxMessagesParams{
+		LeaseUntil: leaseUntil,
+		Now:        now,
+		MaxRows:    int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("claim outbox messages: %w", err)
+	}
+	// RETURNING の順序は保証されないので書いた順に戻す
+	slices.SortFunc(recs, func(a, b sqlcdb.Outbox) int { return cmp.Compare(a.Seq, b.Seq) })
+
+	ms := make([]*domain.OutboxMessage, 0, len(recs))
+	for _, rec := range recs {
+		ms = append(ms, &domain.OutboxMessage{
+			ID:          rec.ID,
+			AggregateID: rec.AggregateID,
+			Type:        rec.Type,
+			Payload:     rec.Payload,
+			CreatedAt:   rec.CreatedAt,
+			Attempts:    int(rec.Attempts),
+		})
+	}
+	return ms, nil
+}
+
+// MarkDelivered records successful delivery.
+func (r *PostgresOutboxRepository) MarkDelivered(ctx context.Context, id string, at time.Time) error {
+	if err := r.getQ(ctx).MarkOutboxMessageDelivered(ctx, sqlcdb.MarkOutboxMessageDeliveredParams{
+		ID:          id,
+		DeliveredAt: sql.NullTime{Time: at, Valid: true},
+	}); err != nil {
+		return fmt.Errorf("mark outbox message delivered: %w", err)
+	}
+	return nil
+}
+
+// MarkFailed records a failed attempt and postpones the next one.
+func (r *PostgresOutboxRepository) MarkFailed(ctx context.Context, id string, lastErr string, nextAttemptAt time.Time) error {
+	if err := r.getQ(ctx).MarkOutboxMessageFailed(ctx, sqlcdb.MarkOutboxMessageFailedParams{
+		ID:            id,
+		LastError:     lastErr,
+		NextAttemptAt: nextAttemptAt,
+	}); err != nil {
+		return fmt.Errorf("mark outbox message failed: %w", err)
+	}
+	return nil
+}
+
+// MarkDead records the final failed attempt. The message is never retried.
+func (r *PostgresOutboxRepository) MarkDead(ctx context.Context, id string, lastErr string, at time.Time) error {
+	if err := r.getQ(ctx).MarkOutboxMessageDead(ctx, sqlcdb.MarkOutboxMessageDeadParams{
+		ID:        id,
+		LastError: lastErr,
+		DeadAt:    sql.NullTime{Time: at, Valid: true},
+	}); err != nil {
+		return fmt.Errorf("mark outbox message dead: %w", err)
+	}
+	return nil
+}
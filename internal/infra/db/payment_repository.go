@@ -0,0 +1,113 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/money"
+	"github.com/kazshi01/payment-system/internal/domain/order"
+	"github.com/kazshi01/payment-system/internal/domain/payment"
+	sqlcdb "github.com/kazshi01/payment-system/internal/infra/db/sqlc"
+)
+
+// PostgresPaymentRepository implements domain.PaymentRepository using sqlc.
+type PostgresPaymentRepository struct {
+	DB *sql.DB
+	Q  *sqlcdb.Queries
+}
+
+func NewPostgresPaymentRepository(db *sql.DB) *PostgresPaymentRepository {
+	return &PostgresPaymentRepository{
+		DB: db,
+		Q:  sqlcdb.New(db),
+	}
+}
+
+// Tx が ctx に乗っていればその Tx にバインドした Queries を返す
+func (r *PostgresPaymentRepository) getQ(ctx context.Context) *sqlcdb.Queries {
+	if tx := getTx(ctx); tx != nil {
+		return r.Q.WithTx(tx)
+	}
+	return r.Q
+}
+
+// Create inserts a new payment.
+func (r *PostgresPaymentRepository) Create(ctx context.Context, p *payment.Payment) error {
+	params := sqlcdb.CreatePaymentParams{
+		ID:           string(p.ID),
+		OrderID:      p.OrderID,
+		Method:       string(p.Method),
+		Provider:     p.Provider,
+		ProviderTxID: p.TxID,
+		Amount:       p.Amount.Amount,
+		Currency:     string(p.Amount.Currency),
+		CreatedAt:    p.CreatedAt,
+	}
+	if err := r.getQ(ctx).CreatePayment(ctx, params); err != nil {
+		return fmt.Errorf("create payment: %w", err)
+	}
+	return nil
+}
+
+// ListByOrderID lists payments of an order in creation order.
+func (r *PostgresPaymentRepository) ListByOrderID(ctx context.Context, orderID order.ID) ([]*payment.Payment, error) {
+	recs, err := r.getQ(ctx).ListPaymentsByOrderID(ctx, string(orderID))
+	if err != nil {
+		return nil, fmt.Errorf("list payments: %w", err)
+	}
+
+	ps := make([]*payment.Payment, 0, len(recs))
+	for _, rec := range recs {
+		ps = append(ps, paymentToDomain(rec))
+	}
+	return ps, nil
+}
+
+// FindByProviderTxID fetches a payment by the provider's transaction ID.
+func (r *PostgresPaymentRepository) FindByProviderTxID(ctx context.Context, provider, txID string) (*payment.Payment, error) {
+	rec, err := r.getQ(ctx).GetPaymentByProviderTxID(ctx, sqlcdb.GetPaymentByProviderTxIDParams{
+		Provider:     provider,
+		ProviderTxID: txID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("get payment by provider tx id: %w", err)
+	}
+	return paymentToDomain(rec), nil
+}
+
+// ListByProvider lists a provider's payments created in [from, to), oldest first.
+func (r *PostgresPaymentRepository) ListByProvider(ctx context.Context, provider string, from, to time.Time) ([]*payment.Payment, error) {
+	recs, err := r.getQ(ctx).ListPaymentsByProvider(ctx, sqlcdb.ListPaymentsByProviderParams{
+		Provider:    provider,
+		CreatedFrom: from,
+		CreatedTo:   to,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list payments by provider: %w", err)
+	}
+
+	ps := make([]*payment.Payment, 0, len(recs))
+	for _, rec := range recs {
+		ps = append(ps, paymentToDomain(rec))
+	}
+	return ps, nil
+}
+
+func paymentToDomain(rec sqlcdb.Payment) *payment.Payment {
+	return &payment.Payment{
+		ID:        payment.ID(rec.ID),
+		OrderID:   rec.OrderID,
+		Method:    payment.Method(rec.Method),
+		Provider:  rec.Provider,
+		TxID:      rec.ProviderTxID,
+		Amount:    money.Money{Amount: rec.Amount, Currency: money.Currency(rec.Currency)},
+		CreatedAt: rec.CreatedAt,
+	}
+}
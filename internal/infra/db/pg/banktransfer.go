@@ -0,0 +1,75 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"regexp"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/banktransfer"
+)
+
+type BankTransferConfig struct {
+	// 入金先の銀行・支店・口座。振込依頼人コード方式ではこの口座を全注文で共有する
+	Account banktransfer.Destination
+
+	// バーチャル口座として払い出せる口座番号の範囲（両端を含む）。0 なら振込依頼人コード方式
+	VirtualAccountFrom int
+	VirtualAccountTo   int
+}
+
+// BankTransfer は domain.BankTransferGateway 実装。番号は乱数で選び、重複は bank_transfers の一意制約で弾く
+type BankTransfer struct {
+	cfg  BankTransferConfig
+	intN func(n int) int
+}
+
+var (
+	bankCodePattern    = regexp.MustCompile(`^[0-9]{4}$`)
+	branchCodePattern  = regexp.MustCompile(`^[0-9]{3}$`)
+	accountCodePattern = regexp.MustCompile(`^[0-9]{7}$`)
+)
+
+func NewBankTransfer(cfg BankTransferConfig) (*BankTransfer, error) {
+	a := cfg.Account
+	if !bankCodePattern.MatchString(a.BankCode) || !branchCodePattern.MatchString(a.BranchCode) {
+		return nil, errors.New("bank transfer: bank code must be 4 digits and branch code 3 digits")
+	}
+	if a.AccountName == "" {
+		return nil, errors.New("bank transfer: account name is required")
+	}
+	switch a.AccountType {
+	case "":
+		cfg.Account.AccountType = banktransfer.AccountOrdinary
+	case banktransfer.AccountOrdinary, banktransfer.AccountCurrent, banktransfer.AccountSavings:
+	default:
+		return nil, fmt.Errorf("bank transfer: invalid account type %q (ordinary, current or savings)", a.AccountType)
+	}
+	if cfg.VirtualAccountFrom == 0 && cfg.VirtualAccountTo == 0 {
+		if !accountCodePattern.MatchString(a.AccountNumber) {
+			return nil, errors.New("bank transfer: account number must be 7 digits")
+		}
+	} else if cfg.VirtualAccountFrom <= 0 || cfg.VirtualAccountTo > 9_999_999 || cfg.VirtualAccountFrom > cfg.VirtualAccountTo {
+		return nil, fmt.Errorf("bank transfer: invalid virtual account range %d-%d", cfg.VirtualAccountFrom, cfg.VirtualAccountTo)
+	}
+	return &BankTransfer{cfg: cfg, intN: rand.IntN}, nil
+}
+
+func (b *BankTransfer) IssueDestination(ctx context.Context, req domain.BankTransferRequest) (banktransfer.Destination, error) {
+	if req.Currency != "jpy" {
+		return banktransfer.Destination{}, fmt.Errorf("%w: bank transfer accepts only JPY", domain.ErrInvalidArgument)
+	}
+
+	d := b.cfg.Account
+	if b.cfg.VirtualAccountFrom > 0 {
+		d.Mode = banktransfer.ModeVirtualAccount
+		d.AccountNumber = fmt.Sprintf("%07d", b.cfg.VirtualAccountFrom+b.intN(b.cfg.VirtualAccountTo-b.cfg.VirtualAccountFrom+1))
+		d.ReferenceCode = ""
+		return d, nil
+	}
+	d.Mode = banktransfer.ModeReferenceCode
+	d.ReferenceCode = banktransfer.WithCheckDigit(fmt.Sprintf("%0*d", banktransfer.ReferenceLength-1, b.intN(1_000_000)))
+	return d, nil
+}
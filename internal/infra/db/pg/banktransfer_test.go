@@ -0,0 +1,65 @@
+package pg_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/banktransfer"
+	"github.com/kazshi01/payment-system/internal/infra/db/pg"
+)
+
+var account = banktransfer.Destination{
+	BankCode: "0001", BankName: "みずほ銀行", BranchCode: "001", BranchName: "本店",
+	AccountNumber: "1234567", AccountName: "ｶ)ﾍﾟｲﾒﾝﾄ",
+}
+
+func TestBankTransfer_IssueDestination(t *testing.T) {
+	ctx := context.Background()
+	req := domain.BankTransferRequest{OrderID: "o1", Amount: 12000, Currency: "jpy"}
+
+	b, err := pg.NewBankTransfer(pg.BankTransferConfig{Account: account})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := b.IssueDestination(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Mode != banktransfer.ModeReferenceCode || d.AccountNumber != "1234567" || d.AccountType != banktransfer.AccountOrdinary || !banktransfer.ValidReference(d.ReferenceCode) {
+		t.Fatalf("destination = %+v", d)
+	}
+
+	va, err := pg.NewBankTransfer(pg.BankTransferConfig{Account: account, VirtualAccountFrom: 2000000, VirtualAccountTo: 2000009})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err = va.IssueDestination(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := strconv.Atoi(d.AccountNumber); d.Mode != banktransfer.ModeVirtualAccount || n < 2000000 || n > 2000009 || d.ReferenceCode != "" {
+		t.Fatalf("virtual account = %+v", d)
+	}
+
+	req.Currency = "usd"
+	if _, err := b.IssueDestination(ctx, req); !errors.Is(err, domain.ErrInvalidArgument) {
+		t.Fatalf("usd err = %v; want ErrInvalidArgument", err)
+	}
+}
+
+func TestNewBankTransfer_invalidConfig(t *testing.T) {
+	bad := map[string]pg.BankTransferConfig{
+		"bank code":    {Account: banktransfer.Destination{BankCode: "1", BranchCode: "001", AccountNumber: "1234567", AccountName: "ｱ"}},
+		"account":      {Account: banktransfer.Destination{BankCode: "0001", BranchCode: "001", AccountNumber: "123", AccountName: "ｱ"}},
+		"account type": {Account: banktransfer.Destination{BankCode: "0001", BranchCode: "001", AccountNumber: "1234567", AccountName: "ｱ", AccountType: "checking"}},
+		"va range":     {Account: account, VirtualAccountFrom: 3000000, VirtualAccountTo: 2000000},
+	}
+	for name, cfg := range bad {
+		if _, err := pg.NewBankTransfer(cfg); err == nil {
+			t.Errorf("%s: err = nil", name)
+		}
+	}
+}
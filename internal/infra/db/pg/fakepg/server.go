@@ -0,0 +1,597 @@
+// Package fakepg は Stripe 互換 API（PaymentIntents / Refunds）のインメモリ実装。
+// テストとローカル開発で pg.Stripe の接続先として使う。
+//
+// 結果の指定方法:
+//   - payment_method に pm_card_chargeDeclined / pm_card_chargeDeclinedInsufficientFunds /
+//     pm_card_timeout を渡す（Stripe のテストカードと同じ考え方）
+//   - POST /__fake/script で次の N リクエストの結果を予約する
+//
+// コンビニ払い（payment_method_types[]=konbini）は requires_action で払込番号を返し、
+// POST /__fake/payment_intents/{id}/pay で店頭での支払いを再現する
+package fakepg
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/infra/db/pg"
+)
+
+// Outcome は予約できる結果
+type Outcome string
+
+const (
+	OutcomeDecline     Outcome = "decline"      // 402 card_error
+	OutcomeTimeout     Outcome = "timeout"      // TimeoutDelay だけ待ってから処理する
+	OutcomeServerError Outcome = "server_error" // 500 api_error
+)
+
+// Script は POST /__fake/script のボディ
+type Script struct {
+	Outcome Outcome `json:"outcome"`
+	Code    string  `json:"code,omitempty"` // decline 時の decline_code（既定 generic_decline）
+	Times   int     `json:"times,omitempty"`
+}
+
+type Config struct {
+	SecretKey    string        // 空なら Authorization ヘッダの値は検証しない
+	TimeoutDelay time.Duration // timeout 時の待ち時間（既定 30s）
+
+	// 店頭支払いの結果を送る Webhook 先と署名シークレット。空なら送らない
+	WebhookURL    string
+	WebhookSecret string
+}
+
+type intent struct {
+	ID             string            `json:"id"`
+	Object         string            `json:"object"`
+	Amount         int64             `json:"amount"`
+	AmountReceived int64             `json:"amount_received"`
+	AmountRefunded int64             `json:"-"`
+	Currency       string            `json:"currency"`
+	CaptureMethod  string            `json:"capture_method"`
+	Status         string            `json:"status"`
+	Metadata       map[string]string `json:"metadata"`
+	NextAction     *nextAction       `json:"next_action"`
+	Created        int64             `json:"created"`
+}
+
+type nextAction struct {
+	Type                  string          `json:"type"`
+	KonbiniDisplayDetails *konbiniDetails `json:"konbini_display_details,omitempty"`
+}
+
+type konbiniStore struct {
+	ConfirmationNumber string `json:"confirmation_number,omitempty"`
+	PaymentCode        string `json:"payment_code"`
+}
+
+type konbiniDetails struct {
+	ExpiresAt int64                   `json:"expires_at"`
+	Stores    map[string]konbiniStore `json:"stores"`
+}
+
+type refund struct {
+	ID            string            `json:"id"`
+	Object        string            `json:"object"`
+	Amount        int64             `json:"amount"`
+	PaymentIntent string            `json:"payment_intent"`
+	Status        string            `json:"status"`
+	Metadata      map[string]string `json:"metadata"`
+	Created       int64             `json:"created"`
+}
+
+type cached struct {
+	fingerprint string
+	status      int
+	body        []byte
+}
+
+type Server struct {
+	cfg Config
+	mux *http.ServeMux
+
+	mu      sync.Mutex
+	intents map[string]*intent
+	refunds map[string]*refund
+	idem    map[string]cached
+	scripts []Script
+}
+
+func New(cfg Config) *Server {
+	if cfg.TimeoutDelay <= 0 {
+		cfg.TimeoutDelay = 30 * time.Second
+	}
+	s := &Server{
+		cfg:     cfg,
+		mux:     http.NewServeMux(),
+		intents: map[string]*intent{},
+		refunds: map[string]*refund{},
+		idem:    map[string]cached{},
+	}
+
+	s.mux.HandleFunc("POST /v1/payment_intents", s.api(s.createIntent))
+	s.mux.HandleFunc("GET /v1/payment_intents/{id}", s.api(s.getIntent))
+	s.mux.HandleFunc("POST /v1/payment_intents/{id}/capture", s.api(s.captureIntent))
+	s.mux.HandleFunc("POST /v1/payment_intents/{id}/cancel", s.api(s.cancelIntent))
+	s.mux.HandleFunc("POST /v1/refunds", s.api(s.createRefund))
+
+	s.mux.HandleFunc("POST /__fake/payment_intents/{id}/pay", s.payIntent)
+	s.mux.HandleFunc("POST /__fake/script", s.pushScript)
+	s.mux.HandleFunc("DELETE /__fake/script", s.clearScripts)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) { s.mux.ServeHTTP(w, r) }
+
+// Push は次のリクエストの結果を予約する（テストから直接呼ぶ用）
+func (s *Server) Push(sc Script) {
+	if sc.Times <= 0 {
+		sc.Times = 1
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scripts = append(s.scripts, sc)
+}
+
+// --- middleware ---
+
+type apiFunc func(r *http.Request, form url.Values) (int, any)
+
+// 認証・予約結果・Idempotency-Key の再生をまとめて扱う
+func (s *Server) api(fn apiFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.authorized(r) {
+			writeErr(w, http.StatusUnauthorized, "invalid_request_error", "", "", "Invalid API Key provided")
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			writeErr(w, http.StatusBadRequest, "invalid_request_error", "parameter_invalid", "", err.Error())
+			return
+		}
+		form := r.PostForm
+
+		sc, ok := s.popScript()
+		if !ok && form.Get("payment_method") == "pm_card_timeout" {
+			sc, ok = Script{Outcome: OutcomeTimeout}, true
+		}
+		if ok {
+			switch sc.Outcome {
+			case OutcomeTimeout:
+				select {
+				case <-time.After(s.cfg.TimeoutDelay):
+				case <-r.Context().Done():
+					return
+				}
+			case OutcomeServerError:
+				writeErr(w, http.StatusInternalServerError, "api_error", "", "", "An unknown error occurred")
+				return
+			case OutcomeDecline:
+				code := sc.Code
+				if code == "" {
+					code = "generic_decline"
+				}
+				writeErr(w, http.StatusPaymentRequired, "card_error", "card_declined", code, "Your card was declined.")
+				return
+			}
+		}
+
+		key := r.Header.Get("Idempotency-Key")
+		fp := fingerprint(r.Method, r.URL.Path, form)
+		if key != "" {
+			s.mu.Lock()
+			c, hit := s.idem[key]
+			s.mu.Unlock()
+			if hit {
+				if c.fingerprint != fp {
+					writeErr(w, http.StatusBadRequest, "idempotency_error", "", "",
+						"Keys for idempotent requests can only be used with the same parameters they were first used with.")
+					return
+				}
+				w.Header().Set("Idempotent-Replayed", "true")
+				writeRaw(w, c.status, c.body)
+				return
+			}
+		}
+
+		status, v := fn(r, form)
+		b, _ := json.Marshal(v)
+
+		// Stripe と同様に 5xx 以外は結果を保存して再生する
+		if key != "" && status < 500 {
+			s.mu.Lock()
+			s.idem[key] = cached{fingerprint: fp, status: status, body: b}
+			s.mu.Unlock()
+		}
+		writeRaw(w, status, b)
+	}
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	tok, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || tok == "" {
+		return false
+	}
+	return s.cfg.SecretKey == "" || tok == s.cfg.SecretKey
+}
+
+func (s *Server) popScript() (Script, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.scripts) == 0 {
+		return Script{}, false
+	}
+	sc := s.scripts[0]
+	s.scripts[0].Times--
+	if s.scripts[0].Times <= 0 {
+		s.scripts = s.scripts[1:]
+	}
+	return sc, true
+}
+
+// --- handlers ---
+
+func (s *Server) createIntent(r *http.Request, form url.Values) (int, any) {
+	amount, err := strconv.ParseInt(form.Get("amount"), 10, 64)
+	if err != nil || amount <= 0 {
+		return errBody(http.StatusBadRequest, "invalid_request_error", "parameter_invalid_integer", "", "Invalid positive integer: amount")
+	}
+	currency := form.Get("currency")
+	if currency == "" {
+		return errBody(http.StatusBadRequest, "invalid_request_error", "parameter_missing", "", "Missing required param: currency.")
+	}
+
+	switch form.Get("payment_method") {
+	case "pm_card_chargeDeclined":
+		return errBody(http.StatusPaymentRequired, "card_error", "card_declined", "generic_decline", "Your card was declined.")
+	case "pm_card_chargeDeclinedInsufficientFunds":
+		return errBody(http.StatusPaymentRequired, "card_error", "card_declined", "insufficient_funds", "Your card has insufficient funds.")
+	}
+
+	pi := &intent{
+		ID:            newID("pi"),
+		Object:        "payment_intent",
+		Amount:        amount,
+		Currency:      currency,
+		CaptureMethod: form.Get("capture_method"),
+		Status:        "requires_payment_method",
+		Metadata:      metadata(form),
+		Created:       time.Now().Unix(),
+	}
+	if pi.CaptureMethod == "" {
+		pi.CaptureMethod = "automatic"
+	}
+	if form.Get("confirm") == "true" {
+		if form.Get("payment_method_types[]") == "konbini" {
+			details, err := newKonbiniDetails(form)
+			if err != nil {
+				return errBody(http.StatusBadRequest, "invalid_request_error", "parameter_invalid_integer", "", err.Error())
+			}
+			pi.Status = "requires_action"
+			pi.NextAction = &nextAction{Type: "konbini_display_details", KonbiniDisplayDetails: details}
+		} else if pi.CaptureMethod == "manual" {
+			pi.Status = "requires_capture"
+		} else {
+			pi.Status = "succeeded"
+			pi.AmountReceived = amount
+		}
+	}
+
+	s.mu.Lock()
+	s.intents[pi.ID] = pi
+	s.mu.Unlock()
+	return http.StatusOK, pi
+}
+
+func (s *Server) getIntent(r *http.Request, form url.Values) (int, any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pi, ok := s.intents[r.PathValue("id")]
+	if !ok {
+		return missing("payment_intent", r.PathValue("id"))
+	}
+	return http.StatusOK, pi
+}
+
+func (s *Server) captureIntent(r *http.Request, form url.Values) (int, any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pi, ok := s.intents[r.PathValue("id")]
+	if !ok {
+		return missing("payment_intent", r.PathValue("id"))
+	}
+	if pi.Status != "requires_capture" {
+		return unexpectedState(pi)
+	}
+
+	amount := pi.Amount
+	if v := form.Get("amount_to_capture"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n <= 0 || n > pi.Amount {
+			return errBody(http.StatusBadRequest, "invalid_request_error", "amount_too_large", "", "amount_to_capture must be <= amount")
+		}
+		amount = n
+	}
+	pi.Status = "succeeded"
+	pi.AmountReceived = amount
+	return http.StatusOK, pi
+}
+
+func (s *Server) cancelIntent(r *http.Request, form url.Values) (int, any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pi, ok := s.intents[r.PathValue("id")]
+	if !ok {
+		return missing("payment_intent", r.PathValue("id"))
+	}
+	switch pi.Status {
+	case "requires_capture", "requires_payment_method", "requires_confirmation", "requires_action":
+		pi.Status = "canceled"
+		return http.StatusOK, pi
+	}
+	return unexpectedState(pi)
+}
+
+func (s *Server) createRefund(r *http.Request, form url.Values) (int, any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pi, ok := s.intents[form.Get("payment_intent")]
+	if !ok {
+		return missing("payment_intent", form.Get("payment_intent"))
+	}
+	if pi.Status != "succeeded" {
+		return unexpectedState(pi)
+	}
+
+	remaining := pi.AmountReceived - pi.AmountRefunded
+	if remaining <= 0 {
+		return errBody(http.StatusBadRequest, "invalid_request_error", "charge_already_refunded", "", "Charge has already been refunded.")
+	}
+	amount := remaining
+	if v := form.Get("amount"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n <= 0 {
+			return errBody(http.StatusBadRequest, "invalid_request_error", "parameter_invalid_integer", "", "Invalid positive integer: amount")
+		}
+		if n > remaining {
+			return errBody(http.StatusBadRequest, "invalid_request_error", "amount_too_large", "", "Refund amount is greater than unrefunded amount.")
+		}
+		amount = n
+	}
+
+	pi.AmountRefunded += amount
+	re := &refund{
+		ID:            newID("re"),
+		Object:        "refund",
+		Amount:        amount,
+		PaymentIntent: pi.ID,
+		Status:        "succeeded",
+		Metadata:      metadata(form),
+		Created:       time.Now().Unix(),
+	}
+	s.refunds[re.ID] = re
+	return http.StatusOK, re
+}
+
+// Pay はコンビニ払いの intent を支払い済みにし、設定があれば payment_intent.succeeded を送る
+func (s *Server) Pay(id string) error {
+	s.mu.Lock()
+	pi, ok := s.intents[id]
+	if !ok {
+		s.mu.Unlock()
+		return fmt.Errorf("%w: %s", errNoIntent, id)
+	}
+	if pi.Status != "requires_action" || pi.NextAction == nil || pi.NextAction.KonbiniDisplayDetails == nil {
+		s.mu.Unlock()
+		return fmt.Errorf("%w: %s is %s", errNotPayable, id, pi.Status)
+	}
+	pi.Status = "succeeded"
+	pi.AmountReceived = pi.Amount
+	pi.NextAction = nil
+	snapshot := *pi
+	s.mu.Unlock()
+
+	if s.cfg.WebhookURL == "" {
+		return nil
+	}
+	return s.sendEvent("payment_intent.succeeded", &snapshot)
+}
+
+var (
+	errNoIntent   = errors.New("no such payment_intent")
+	errNotPayable = errors.New("payment_intent is not awaiting konbini payment")
+)
+
+// Stripe と同じ形の event を署名付きで POST する
+func (s *Server) sendEvent(typ string, obj any) error {
+	body, err := json.Marshal(map[string]any{
+		"id":      newID("evt"),
+		"object":  "event",
+		"type":    typ,
+		"created": time.Now().Unix(),
+		"data":    map[string]any{"object": obj},
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.cfg.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(pg.StripeSignatureHeader, pg.SignStripePayload(s.cfg.WebhookSecret, time.Now(), body))
+
+	cli := &http.Client{Timeout: 10 * time.Second}
+	resp, err := cli.Do(req)
+	if err != nil {
+		return fmt.Errorf("send %s: %w", typ, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("send %s: webhook responded %d", typ, resp.StatusCode)
+	}
+	return nil
+}
+
+// --- script endpoints ---
+
+func (s *Server) payIntent(w http.ResponseWriter, r *http.Request) {
+	err := s.Pay(r.PathValue("id"))
+	switch {
+	case err == nil:
+		w.WriteHeader(http.StatusNoContent)
+	case errors.Is(err, errNoIntent):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, errNotPayable):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusBadGateway)
+	}
+}
+
+func (s *Server) pushScript(w http.ResponseWriter, r *http.Request) {
+	var sc Script
+	if err := json.NewDecoder(r.Body).Decode(&sc); err != nil {
+		http.Error(w, "invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	switch sc.Outcome {
+	case OutcomeDecline, OutcomeTimeout, OutcomeServerError:
+	default:
+		http.Error(w, "unknown outcome", http.StatusBadRequest)
+		return
+	}
+	s.Push(sc)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) clearScripts(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.scripts = nil
+	s.mu.Unlock()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// --- helpers ---
+
+type errorBody struct {
+	Error struct {
+		Type        string `json:"type"`
+		Code        string `json:"code,omitempty"`
+		DeclineCode string `json:"decline_code,omitempty"`
+		Message     string `json:"message"`
+	} `json:"error"`
+}
+
+func errBody(status int, typ, code, declineCode, msg string) (int, any) {
+	var e errorBody
+	e.Error.Type = typ
+	e.Error.Code = code
+	e.Error.DeclineCode = declineCode
+	e.Error.Message = msg
+	return status, e
+}
+
+func missing(object, id string) (int, any) {
+	return errBody(http.StatusNotFound, "invalid_request_error", "resource_missing", "", "No such "+object+": '"+id+"'")
+}
+
+func unexpectedState(pi *intent) (int, any) {
+	return errBody(http.StatusBadRequest, "invalid_request_error", "payment_intent_unexpected_state", "",
+		"This PaymentIntent's status is "+pi.Status+".")
+}
+
+func writeErr(w http.ResponseWriter, status int, typ, code, declineCode, msg string) {
+	_, v := errBody(status, typ, code, declineCode, msg)
+	b, _ := json.Marshal(v)
+	writeRaw(w, status, b)
+}
+
+func writeRaw(w http.ResponseWriter, status int, b []byte) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(b)
+}
+
+// metadata[key]=value を取り出す
+func metadata(form url.Values) map[string]string {
+	m := map[string]string{}
+	for k, v := range form {
+		if name, ok := strings.CutPrefix(k, "metadata["); ok && strings.HasSuffix(name, "]") && len(v) > 0 {
+			m[strings.TrimSuffix(name, "]")] = v[0]
+		}
+	}
+	return m
+}
+
+// 払込番号は店舗共通の 12 桁、確認番号はセイコーマート以外の店舗で使う 6 桁。
+// 支払期限は expires_after_days 日後の 23:59:59 JST（省略時 3 日）
+func newKonbiniDetails(form url.Values) (*konbiniDetails, error) {
+	days := 3
+	if v := form.Get("payment_method_options[konbini][expires_after_days]"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 60 {
+			return nil, errors.New("Invalid integer: payment_method_options[konbini][expires_after_days]")
+		}
+		days = n
+	}
+	d := time.Now().In(jst).AddDate(0, 0, days)
+	expiresAt := time.Date(d.Year(), d.Month(), d.Day(), 23, 59, 59, 0, jst).Unix()
+
+	code, confirm := randomDigits(12), randomDigits(6)
+	return &konbiniDetails{
+		ExpiresAt: expiresAt,
+		Stores: map[string]konbiniStore{
+			"familymart": {PaymentCode: code, ConfirmationNumber: confirm},
+			"lawson":     {PaymentCode: code, ConfirmationNumber: confirm},
+			"ministop":   {PaymentCode: code, ConfirmationNumber: confirm},
+			"seicomart":  {PaymentCode: code},
+		},
+	}, nil
+}
+
+var jst = time.FixedZone("JST", 9*60*60)
+
+func randomDigits(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	for i := range b {
+		b[i] = '0' + b[i]%10
+	}
+	return string(b)
+}
+
+// 同じ Idempotency-Key で別パラメータが来たか判定するための指紋
+func fingerprint(method, path string, form url.Values) string {
+	keys := make([]string, 0, len(form))
+	for k := range form {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	h.Write([]byte(method + " " + path + "\n"))
+	for _, k := range keys {
+		h.Write([]byte(k + "=" + strings.Join(form[k], ",") + "\n"))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func newID(prefix string) string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return prefix + "_" + hex.EncodeToString(b)
+}
@@ -2,12 +2,45 @@ package pg
 
 import (
 	"context"
+	"time"
 
 	"github.com/kazshi01/payment-system/internal/domain"
 )
 
+// NopProvider は payments.provider に記録される名前
+const NopProvider = "nop"
+
 type Nop struct{}
 
 func (Nop) Charge(ctx context.Context, p domain.PaymentIntent) (string, error) {
 	return "tx_mock", nil
 }
+
+func (Nop) Authorize(ctx context.Context, p domain.PaymentIntent) (string, error) {
+	return "auth_mock", nil
+}
+
+func (Nop) Capture(ctx context.Context, c domain.CaptureRequest) (string, error) {
+	return "tx_mock", nil
+}
+
+func (Nop) Void(ctx context.Context, v domain.VoidRequest) error {
+	return nil
+}
+
+func (Nop) Refund(ctx context.Context, r domain.RefundRequest) (string, error) {
+	return "re_mock", nil
+}
+
+func (Nop) IssueKonbini(ctx context.Context, r domain.KonbiniRequest) (domain.KonbiniVoucher, error) {
+	// 注文ごとに別の決済ID にする（konbini_payments の一意制約）
+	return domain.KonbiniVoucher{
+		ProviderPaymentID: "kb_mock_" + r.OrderID,
+		PaymentCode:       "123456789012",
+		ExpiresAt:         time.Now().AddDate(0, 0, r.ExpiresAfterDays),
+	}, nil
+}
+
+func (Nop) CancelKonbini(ctx context.Context, r domain.KonbiniCancelRequest) error {
+	return nil
+}
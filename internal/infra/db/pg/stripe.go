@@ -0,0 +1,304 @@
+package pg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+)
+
+// StripeProvider は payments.provider に記録される名前
+const StripeProvider = "stripe"
+
+type StripeConfig struct {
+	BaseURL   string        // 例: https://api.stripe.com, ローカルは cmd/fakepg
+	SecretKey string        // sk_live_... / sk_test_...
+	Timeout   time.Duration // 1リクエストの上限（未設定なら 10s）
+}
+
+// Stripe は Stripe 互換 REST API（PaymentIntents / Refunds）の domain.PaymentGateway 実装
+type Stripe struct {
+	cfg StripeConfig
+	cli *http.Client
+}
+
+func NewStripe(cfg StripeConfig) (*Stripe, error) {
+	if cfg.SecretKey == "" {
+		return nil, errors.New("stripe: secret key is required")
+	}
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = "https://api.stripe.com"
+	}
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	return &Stripe{cfg: cfg, cli: &http.Client{Timeout: cfg.Timeout}}, nil
+}
+
+type stripeObject struct {
+	ID         string `json:"id"`
+	Status     string `json:"status"`
+	NextAction *struct {
+		KonbiniDisplayDetails *stripeKonbiniDetails `json:"konbini_display_details"`
+	} `json:"next_action"`
+}
+
+// 店舗ごとの番号。店舗によって確認番号がない
+type stripeKonbiniStore struct {
+	ConfirmationNumber string `json:"confirmation_number"`
+	PaymentCode        string `json:"payment_code"`
+}
+
+type stripeKonbiniDetails struct {
+	ExpiresAt int64 `json:"expires_at"`
+	Stores    struct {
+		FamilyMart stripeKonbiniStore `json:"familymart"`
+		Lawson     stripeKonbiniStore `json:"lawson"`
+		Ministop   stripeKonbiniStore `json:"ministop"`
+		Seicomart  stripeKonbiniStore `json:"seicomart"`
+	} `json:"stores"`
+}
+
+type stripeError struct {
+	Error struct {
+		Type        string `json:"type"`
+		Code        string `json:"code"`
+		DeclineCode string `json:"decline_code"`
+		Message     string `json:"message"`
+	} `json:"error"`
+}
+
+// --- domain.PaymentGateway ---
+
+func (s *Stripe) Charge(ctx context.Context, intent domain.PaymentIntent) (string, error) {
+	form, err := intentForm(intent)
+	if err != nil {
+		return "", err
+	}
+	form.Set("capture_method", "automatic")
+
+	obj, err := s.post(ctx, "/v1/payment_intents", intent.IdempotencyKey, form)
+	if err != nil {
+		return "", err
+	}
+	switch obj.Status {
+	case "succeeded":
+		return obj.ID, nil
+	case "requires_action", "processing":
+		// 3-D Secure 等。結果は Webhook（payment_intent.succeeded / payment_failed）で届く
+		return "", fmt.Errorf("%w: payment intent %s is %s", domain.ErrPaymentPending, obj.ID, obj.Status)
+	}
+	return "", fmt.Errorf("%w: payment intent %s is %s", domain.ErrPaymentDeclined, obj.ID, obj.Status)
+}
+
+func (s *Stripe) Authorize(ctx context.Context, intent domain.PaymentIntent) (string, error) {
+	form, err := intentForm(intent)
+	if err != nil {
+		return "", err
+	}
+	form.Set("capture_method", "manual")
+
+	obj, err := s.post(ctx, "/v1/payment_intents", intent.IdempotencyKey, form)
+	if err != nil {
+		return "", err
+	}
+	if obj.Status != "requires_capture" {
+		return "", fmt.Errorf("%w: payment intent %s is %s", domain.ErrPaymentDeclined, obj.ID, obj.Status)
+	}
+	return obj.ID, nil
+}
+
+func (s *Stripe) Capture(ctx context.Context, req domain.CaptureRequest) (string, error) {
+	form := url.Values{}
+	form.Set("amount_to_capture", strconv.FormatInt(req.Amount, 10))
+
+	obj, err := s.post(ctx, "/v1/payment_intents/"+url.PathEscape(req.ProviderAuthID)+"/capture", req.IdempotencyKey, form)
+	if err != nil {
+		return "", err
+	}
+	if obj.Status != "succeeded" {
+		return "", fmt.Errorf("%w: payment intent %s is %s", domain.ErrConflict, obj.ID, obj.Status)
+	}
+	return obj.ID, nil
+}
+
+func (s *Stripe) Void(ctx context.Context, req domain.VoidRequest) error {
+	form := url.Values{}
+	form.Set("cancellation_reason", "abandoned")
+
+	_, err := s.post(ctx, "/v1/payment_intents/"+url.PathEscape(req.ProviderAuthID)+"/cancel", req.IdempotencyKey, form)
+	return err
+}
+
+func (s *Stripe) Refund(ctx context.Context, req domain.RefundRequest) (string, error) {
+	form := url.Values{}
+	form.Set("payment_intent", req.ProviderTxID)
+	form.Set("amount", strconv.FormatInt(req.Amount, 10))
+	form.Set("metadata[order_id]", req.OrderID)
+	if req.Reason != "" {
+		form.Set("metadata[reason]", req.Reason)
+	}
+
+	obj, err := s.post(ctx, "/v1/refunds", req.IdempotencyKey, form)
+	if err != nil {
+		return "", err
+	}
+	if obj.Status == "failed" || obj.Status == "canceled" {
+		return "", fmt.Errorf("%w: refund %s is %s", domain.ErrPaymentDeclined, obj.ID, obj.Status)
+	}
+	return obj.ID, nil
+}
+
+// --- domain.KonbiniGateway ---
+
+func (s *Stripe) IssueKonbini(ctx context.Context, req domain.KonbiniRequest) (domain.KonbiniVoucher, error) {
+	form := url.Values{}
+	form.Set("amount", strconv.FormatInt(req.Amount, 10))
+	form.Set("currency", req.Currency)
+	form.Set("confirm", "true")
+	form.Set("payment_method_types[]", "konbini")
+	form.Set("payment_method_data[type]", "konbini")
+	form.Set("payment_method_options[konbini][expires_after_days]", strconv.Itoa(req.ExpiresAfterDays))
+	form.Set("metadata[order_id]", req.OrderID)
+
+	obj, err := s.post(ctx, "/v1/payment_intents", req.IdempotencyKey, form)
+	if err != nil {
+		return domain.KonbiniVoucher{}, err
+	}
+	if obj.Status != "requires_action" || obj.NextAction == nil || obj.NextAction.KonbiniDisplayDetails == nil {
+		return domain.KonbiniVoucher{}, fmt.Errorf("%w: payment intent %s is %s without konbini details", domain.ErrPaymentDeclined, obj.ID, obj.Status)
+	}
+
+	// どの店舗でも払えるよう、番号は最初に見つかった店舗のものを案内する
+	d := obj.NextAction.KonbiniDisplayDetails
+	v := domain.KonbiniVoucher{ProviderPaymentID: obj.ID, ExpiresAt: time.Unix(d.ExpiresAt, 0)}
+	for _, st := range []stripeKonbiniStore{d.Stores.FamilyMart, d.Stores.Lawson, d.Stores.Ministop, d.Stores.Seicomart} {
+		if st.PaymentCode != "" {
+			v.PaymentCode, v.ConfirmationNumber = st.PaymentCode, st.ConfirmationNumber
+			break
+		}
+	}
+	if v.PaymentCode == "" {
+		return domain.KonbiniVoucher{}, fmt.Errorf("%w: payment intent %s has no konbini payment code", domain.ErrInternal, obj.ID)
+	}
+	if d.ExpiresAt == 0 {
+		return domain.KonbiniVoucher{}, fmt.Errorf("%w: payment intent %s has no konbini expiry", domain.ErrInternal, obj.ID)
+	}
+	return v, nil
+}
+
+func (s *Stripe) CancelKonbini(ctx context.Context, req domain.KonbiniCancelRequest) error {
+	form := url.Values{}
+	form.Set("cancellation_reason", "abandoned")
+
+	_, err := s.post(ctx, "/v1/payment_intents/"+url.PathEscape(req.ProviderPaymentID)+"/cancel", req.IdempotencyKey, form)
+	return err
+}
+
+// --- helpers ---
+
+// 支払い手段は顧客ごとに渡す（テストカードなどの既定値は使わない）
+func intentForm(intent domain.PaymentIntent) (url.Values, error) {
+	if intent.PaymentMethod == "" {
+		return nil, fmt.Errorf("%w: stripe: payment method is required", domain.ErrInvalidArgument)
+	}
+	form := url.Values{}
+	form.Set("amount", strconv.FormatInt(intent.Amount, 10))
+	form.Set("currency", intent.Currency)
+	form.Set("confirm", "true")
+	form.Set("payment_method", intent.PaymentMethod)
+	form.Set("metadata[order_id]", intent.OrderID)
+	return form, nil
+}
+
+// フォームを POST し、成功時は id/status を、失敗時は domain エラーを返す
+func (s *Stripe) post(ctx context.Context, path, idempotencyKey string, form url.Values) (*stripeObject, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.BaseURL+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("stripe: build request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+s.cfg.SecretKey)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+
+	resp, err := s.cli.Do(req)
+	if err != nil {
+		if isTimeout(err) {
+			return nil, fmt.Errorf("%w: stripe %s: timeout: %v", domain.ErrPaymentGatewayUnavailable, path, err)
+		}
+		return nil, fmt.Errorf("%w: stripe %s: %v", domain.ErrPaymentGatewayUnavailable, path, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("%w: stripe %s: read body: %v", domain.ErrPaymentGatewayUnavailable, path, err)
+	}
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		var obj stripeObject
+		if err := json.Unmarshal(body, &obj); err != nil {
+			return nil, fmt.Errorf("%w: stripe %s: decode response: %v", domain.ErrInternal, path, err)
+		}
+		return &obj, nil
+	}
+
+	return nil, mapStripeError(path, resp.StatusCode, body)
+}
+
+// Stripe のエラー（HTTP ステータス + error.type / code）を domain エラーに変換する
+func mapStripeError(path string, status int, body []byte) error {
+	var se stripeError
+	_ = json.Unmarshal(body, &se)
+	e := se.Error
+
+	detail := e.Message
+	if e.Code != "" {
+		detail = e.Code + ": " + detail
+	}
+	if e.DeclineCode != "" {
+		detail += " (" + e.DeclineCode + ")"
+	}
+
+	var kind error
+	switch {
+	case e.Type == "card_error" || status == http.StatusPaymentRequired:
+		kind = domain.ErrPaymentDeclined
+	case e.Type == "idempotency_error" || status == http.StatusConflict:
+		kind = domain.ErrConflict
+	case status == http.StatusNotFound || e.Code == "resource_missing":
+		kind = domain.ErrNotFound
+	case status == http.StatusTooManyRequests || status >= 500 || e.Type == "api_error":
+		kind = domain.ErrPaymentGatewayUnavailable
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		// API キーの設定ミスは利用者の責任ではないので内部エラー扱い
+		kind = domain.ErrInternal
+	case e.Code == "payment_intent_unexpected_state" || e.Code == "charge_already_refunded":
+		kind = domain.ErrConflict
+	case status == http.StatusBadRequest:
+		kind = domain.ErrInvalidArgument
+	default:
+		kind = domain.ErrInternal
+	}
+	return fmt.Errorf("%w: stripe %s: %d %s", kind, path, status, detail)
+}
+
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var ne net.Error
+	return errors.As(err, &ne) && ne.Timeout()
+}
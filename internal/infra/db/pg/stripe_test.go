@@ -0,0 +1,233 @@
+package pg_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/infra/db/pg"
+	"github.com/kazshi01/payment-system/internal/infra/db/pg/fakepg"
+)
+
+func newStripe(t *testing.T, cfg pg.StripeConfig) (*pg.Stripe, *fakepg.Server) {
+	t.Helper()
+
+	fake := fakepg.New(fakepg.Config{SecretKey: "sk_test_fake", TimeoutDelay: time.Second})
+	ts := httptest.NewServer(fake)
+	t.Cleanup(ts.Close)
+
+	cfg.BaseURL = ts.URL
+	if cfg.SecretKey == "" {
+		cfg.SecretKey = "sk_test_fake"
+	}
+	s, err := pg.NewStripe(cfg)
+	if err != nil {
+		t.Fatalf("NewStripe err = %v", err)
+	}
+	return s, fake
+}
+
+func intent(key string) domain.PaymentIntent {
+	return domain.PaymentIntent{OrderID: "order-1", Amount: 1200, Currency: "jpy", PaymentMethod: "pm_card_visa", IdempotencyKey: key}
+}
+
+func TestStripe_Charge_idempotent(t *testing.T) {
+	s, _ := newStripe(t, pg.StripeConfig{})
+	ctx := context.Background()
+
+	tx1, err := s.Charge(ctx, intent("pay:order-1"))
+	if err != nil {
+		t.Fatalf("Charge err = %v", err)
+	}
+	// 同じキーのリトライは同じ決済を返す
+	tx2, err := s.Charge(ctx, intent("pay:order-1"))
+	if err != nil {
+		t.Fatalf("Charge (retry) err = %v", err)
+	}
+	if tx1 == "" || tx1 != tx2 {
+		t.Fatalf("tx ids = %q, %q; want same non-empty id", tx1, tx2)
+	}
+
+	// 同じキーで別の金額は衝突
+	other := intent("pay:order-1")
+	other.Amount = 999
+	if _, err := s.Charge(ctx, other); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("err = %v; want ErrConflict", err)
+	}
+}
+
+func TestStripe_Charge_declined(t *testing.T) {
+	s, fake := newStripe(t, pg.StripeConfig{})
+
+	fake.Push(fakepg.Script{Outcome: fakepg.OutcomeDecline, Code: "insufficient_funds"})
+
+	_, err := s.Charge(context.Background(), intent("pay:order-1"))
+	if !errors.Is(err, domain.ErrPaymentDeclined) {
+		t.Fatalf("err = %v; want ErrPaymentDeclined", err)
+	}
+}
+
+func TestStripe_Charge_declinedByPaymentMethod(t *testing.T) {
+	s, _ := newStripe(t, pg.StripeConfig{})
+
+	in := intent("pay:order-1")
+	in.PaymentMethod = "pm_card_chargeDeclined"
+	_, err := s.Charge(context.Background(), in)
+	if !errors.Is(err, domain.ErrPaymentDeclined) {
+		t.Fatalf("err = %v; want ErrPaymentDeclined", err)
+	}
+}
+
+func TestStripe_missingPaymentMethod(t *testing.T) {
+	s, _ := newStripe(t, pg.StripeConfig{})
+	ctx := context.Background()
+
+	// テスト用カードで代替せず、送信前に弾く
+	in := intent("pay:order-1")
+	in.PaymentMethod = ""
+	if _, err := s.Charge(ctx, in); !errors.Is(err, domain.ErrInvalidArgument) {
+		t.Fatalf("Charge err = %v; want ErrInvalidArgument", err)
+	}
+	if _, err := s.Authorize(ctx, in); !errors.Is(err, domain.ErrInvalidArgument) {
+		t.Fatalf("Authorize err = %v; want ErrInvalidArgument", err)
+	}
+}
+
+func TestStripe_Charge_timeoutAndServerError(t *testing.T) {
+	s, fake := newStripe(t, pg.StripeConfig{Timeout: 50 * time.Millisecond})
+	ctx := context.Background()
+
+	fake.Push(fakepg.Script{Outcome: fakepg.OutcomeTimeout})
+	if _, err := s.Charge(ctx, intent("pay:order-1")); !errors.Is(err, domain.ErrPaymentGatewayUnavailable) {
+		t.Fatalf("err = %v; want ErrPaymentGatewayUnavailable", err)
+	}
+
+	fake.Push(fakepg.Script{Outcome: fakepg.OutcomeServerError})
+	if _, err := s.Charge(ctx, intent("pay:order-2")); !errors.Is(err, domain.ErrPaymentGatewayUnavailable) {
+		t.Fatalf("err = %v; want ErrPaymentGatewayUnavailable", err)
+	}
+}
+
+func TestStripe_invalidKey(t *testing.T) {
+	s, _ := newStripe(t, pg.StripeConfig{SecretKey: "sk_test_wrong"})
+
+	if _, err := s.Charge(context.Background(), intent("pay:order-1")); !errors.Is(err, domain.ErrInternal) {
+		t.Fatalf("err = %v; want ErrInternal", err)
+	}
+}
+
+func TestStripe_AuthorizeCaptureRefund(t *testing.T) {
+	s, _ := newStripe(t, pg.StripeConfig{})
+	ctx := context.Background()
+
+	authID, err := s.Authorize(ctx, intent("authorize:order-1"))
+	if err != nil {
+		t.Fatalf("Authorize err = %v", err)
+	}
+
+	txID, err := s.Capture(ctx, domain.CaptureRequest{
+		OrderID: "order-1", ProviderAuthID: authID, Amount: 1000, Currency: "jpy", IdempotencyKey: "capture:order-1",
+	})
+	if err != nil {
+		t.Fatalf("Capture err = %v", err)
+	}
+
+	// 取り消しは確定後は不可
+	if err := s.Void(ctx, domain.VoidRequest{OrderID: "order-1", ProviderAuthID: authID, IdempotencyKey: "void:order-1"}); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("Void err = %v; want ErrConflict", err)
+	}
+
+	if _, err := s.Refund(ctx, domain.RefundRequest{
+		OrderID: "order-1", ProviderTxID: txID, Amount: 600, Currency: "jpy", IdempotencyKey: "refund:order-1:1",
+	}); err != nil {
+		t.Fatalf("Refund err = %v", err)
+	}
+	// 確定額(1000)を超える返金は拒否される
+	if _, err := s.Refund(ctx, domain.RefundRequest{
+		OrderID: "order-1", ProviderTxID: txID, Amount: 500, Currency: "jpy", IdempotencyKey: "refund:order-1:2",
+	}); !errors.Is(err, domain.ErrInvalidArgument) {
+		t.Fatalf("Refund err = %v; want ErrInvalidArgument", err)
+	}
+}
+
+func TestStripe_Void(t *testing.T) {
+	s, _ := newStripe(t, pg.StripeConfig{})
+	ctx := context.Background()
+
+	authID, err := s.Authorize(ctx, intent("authorize:order-1"))
+	if err != nil {
+		t.Fatalf("Authorize err = %v", err)
+	}
+	if err := s.Void(ctx, domain.VoidRequest{OrderID: "order-1", ProviderAuthID: authID, IdempotencyKey: "void:order-1"}); err != nil {
+		t.Fatalf("Void err = %v", err)
+	}
+	if _, err := s.Capture(ctx, domain.CaptureRequest{
+		OrderID: "order-1", ProviderAuthID: authID, Amount: 1200, Currency: "jpy", IdempotencyKey: "capture:order-1",
+	}); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("Capture err = %v; want ErrConflict", err)
+	}
+}
+
+func TestStripe_Konbini(t *testing.T) {
+	// fakepg の店頭支払いは署名付き Webhook で届く
+	wh, _ := pg.NewStripeWebhook("whsec_test")
+	got := make(chan *domain.ProviderEvent, 1)
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		ev, err := wh.Verify(r.Header, body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		got <- ev
+	}))
+	t.Cleanup(hook.Close)
+
+	fake := fakepg.New(fakepg.Config{WebhookURL: hook.URL, WebhookSecret: "whsec_test"})
+	ts := httptest.NewServer(fake)
+	t.Cleanup(ts.Close)
+	s, err := pg.NewStripe(pg.StripeConfig{BaseURL: ts.URL, SecretKey: "sk_test_fake"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	// 期限は 3 日後の 23:59:59 JST
+	jst := time.FixedZone("JST", 9*60*60)
+	d := time.Now().In(jst).AddDate(0, 0, 3)
+	expiresAt := time.Date(d.Year(), d.Month(), d.Day(), 23, 59, 59, 0, jst)
+
+	req := domain.KonbiniRequest{OrderID: "order-1", Amount: 3980, Currency: "jpy", ExpiresAfterDays: 3, IdempotencyKey: "konbini:order-1"}
+	v, err := s.IssueKonbini(ctx, req)
+	if err != nil {
+		t.Fatalf("IssueKonbini err = %v", err)
+	}
+	if v.ProviderPaymentID == "" || len(v.PaymentCode) != 12 || !v.ExpiresAt.Equal(expiresAt) {
+		t.Fatalf("voucher = %+v; want expires at %s", v, expiresAt)
+	}
+
+	// DB 反映に失敗した後の再試行は同じ番号
+	again, err := s.IssueKonbini(ctx, req)
+	if err != nil || again != v {
+		t.Fatalf("retry = %+v, %v; want %+v", again, err, v)
+	}
+
+	if err := fake.Pay(v.ProviderPaymentID); err != nil {
+		t.Fatalf("Pay err = %v", err)
+	}
+	ev := <-got
+	if ev.Type != domain.ProviderPaymentSucceeded || ev.OrderID != "order-1" || ev.ProviderTxID != v.ProviderPaymentID || ev.Amount != 3980 || ev.Currency != "jpy" {
+		t.Fatalf("event = %+v", ev)
+	}
+
+	// 支払い済みの払込番号は無効化できない
+	err = s.CancelKonbini(ctx, domain.KonbiniCancelRequest{OrderID: "order-1", ProviderPaymentID: v.ProviderPaymentID, IdempotencyKey: "konbini-cancel:order-1"})
+	if !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("CancelKonbini err = %v; want ErrConflict", err)
+	}
+}
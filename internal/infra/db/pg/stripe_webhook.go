@@ -0,0 +1,175 @@
+package pg
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+)
+
+// StripeSignatureHeader は Stripe が Webhook に付与する署名ヘッダ
+const StripeSignatureHeader = "Stripe-Signature"
+
+// 署名タイムスタンプの許容ずれ（リプレイ対策）の既定値
+const defaultWebhookTolerance = 5 * time.Minute
+
+// StripeWebhook は Stripe 互換 Webhook の署名検証とイベントの正規化を行う
+type StripeWebhook struct {
+	Secret    string           // whsec_...
+	Tolerance time.Duration    // 0 なら 5 分
+	Now       func() time.Time // テスト用（nil なら time.Now）
+}
+
+func NewStripeWebhook(secret string) (*StripeWebhook, error) {
+	if secret == "" {
+		return nil, errors.New("stripe: webhook secret is required")
+	}
+	return &StripeWebhook{Secret: secret}, nil
+}
+
+// SignStripePayload は "t=<unix>,v1=<hex(HMAC-SHA256(secret, "<unix>.<body>"))>" 形式の署名を返す
+func SignStripePayload(secret string, at time.Time, body []byte) string {
+	ts := strconv.FormatInt(at.Unix(), 10)
+	return "t=" + ts + ",v1=" + stripeSignature(secret, ts, body)
+}
+
+func stripeSignature(secret, ts string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify は署名と時刻を検証し、ボディを domain.ProviderEvent に変換する。
+// 署名不正・期限外・形式不正はいずれも domain.ErrInvalidArgument を返す
+func (w *StripeWebhook) Verify(header http.Header, body []byte) (*domain.ProviderEvent, error) {
+	sig := header.Get(StripeSignatureHeader)
+	if sig == "" {
+		return nil, fmt.Errorf("%w: missing %s header", domain.ErrInvalidArgument, StripeSignatureHeader)
+	}
+
+	var (
+		ts         string
+		signatures []string
+	)
+	for _, part := range strings.Split(sig, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch k {
+		case "t":
+			ts = v
+		case "v1":
+			signatures = append(signatures, v)
+		}
+	}
+	if ts == "" || len(signatures) == 0 {
+		return nil, fmt.Errorf("%w: malformed signature header", domain.ErrInvalidArgument)
+	}
+
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature timestamp", domain.ErrInvalidArgument)
+	}
+
+	// 署名はローテーション中に複数並ぶことがあるので、どれか1つ一致すればよい
+	expected := stripeSignature(w.Secret, ts, body)
+	matched := false
+	for _, s := range signatures {
+		if hmac.Equal([]byte(s), []byte(expected)) {
+			matched = true
+			break
+		}
+	}
+	if !matched {
+		return nil, fmt.Errorf("%w: signature mismatch", domain.ErrInvalidArgument)
+	}
+
+	now := time.Now()
+	if w.Now != nil {
+		now = w.Now()
+	}
+	tol := w.Tolerance
+	if tol <= 0 {
+		tol = defaultWebhookTolerance
+	}
+	if d := now.Sub(time.Unix(sec, 0)); d > tol || d < -tol {
+		return nil, fmt.Errorf("%w: signature timestamp outside tolerance", domain.ErrInvalidArgument)
+	}
+
+	return parseStripeEvent(body)
+}
+
+type stripeEvent struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Data struct {
+		Object struct {
+			ID               string            `json:"id"`
+			Amount           int64             `json:"amount"`
+			AmountReceived   int64             `json:"amount_received"`
+			Currency         string            `json:"currency"`
+			PaymentIntent    string            `json:"payment_intent"`
+			Reason           string            `json:"reason"`
+			Metadata         map[string]string `json:"metadata"`
+			LastPaymentError *struct {
+				Message string `json:"message"`
+			} `json:"last_payment_error"`
+			CancellationReason string `json:"cancellation_reason"`
+		} `json:"object"`
+	} `json:"data"`
+}
+
+func parseStripeEvent(body []byte) (*domain.ProviderEvent, error) {
+	var se stripeEvent
+	if err := json.Unmarshal(body, &se); err != nil {
+		return nil, fmt.Errorf("%w: invalid event body", domain.ErrInvalidArgument)
+	}
+	if se.ID == "" || se.Type == "" {
+		return nil, fmt.Errorf("%w: event id and type are required", domain.ErrInvalidArgument)
+	}
+
+	obj := se.Data.Object
+	ev := &domain.ProviderEvent{
+		Provider:     StripeProvider,
+		ID:           se.ID,
+		RawType:      se.Type,
+		OrderID:      obj.Metadata["order_id"],
+		ProviderTxID: obj.ID,
+		Amount:       obj.Amount,
+		Currency:     obj.Currency,
+		Payload:      body,
+	}
+
+	switch se.Type {
+	case "payment_intent.succeeded":
+		ev.Type = domain.ProviderPaymentSucceeded
+		if obj.AmountReceived > 0 {
+			ev.Amount = obj.AmountReceived
+		}
+	case "payment_intent.payment_failed":
+		ev.Type = domain.ProviderPaymentFailed
+		if obj.LastPaymentError != nil {
+			ev.Reason = obj.LastPaymentError.Message
+		}
+	case "payment_intent.canceled":
+		ev.Type = domain.ProviderPaymentCanceled
+		ev.Reason = obj.CancellationReason
+	case "charge.dispute.created":
+		// dispute の object.id は dp_...。決済は payment_intent で引く
+		ev.Type = domain.ProviderDisputeCreated
+		ev.ProviderTxID = obj.PaymentIntent
+		ev.Reason = obj.Reason
+	}
+	return ev, nil
+}
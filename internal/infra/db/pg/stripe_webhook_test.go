@@ -0,0 +1,57 @@
+package pg_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/infra/db/pg"
+)
+
+const webhookBody = `{"id":"evt_1","type":"payment_intent.succeeded","data":{"object":{"id":"pi_1","amount":1200,"amount_received":1200,"currency":"jpy","metadata":{"order_id":"order-1"}}}}`
+
+func signedHeader(secret string, at time.Time, body []byte) http.Header {
+	h := http.Header{}
+	h.Set(pg.StripeSignatureHeader, pg.SignStripePayload(secret, at, body))
+	return h
+}
+
+func TestStripeWebhook_Verify_ok(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	wh := &pg.StripeWebhook{Secret: "whsec_test", Now: func() time.Time { return now }}
+
+	ev, err := wh.Verify(signedHeader("whsec_test", now.Add(-time.Minute), []byte(webhookBody)), []byte(webhookBody))
+	if err != nil {
+		t.Fatalf("Verify err = %v", err)
+	}
+	if ev.ID != "evt_1" || ev.Type != domain.ProviderPaymentSucceeded || ev.OrderID != "order-1" ||
+		ev.ProviderTxID != "pi_1" || ev.Amount != 1200 || ev.Currency != "jpy" || ev.Provider != pg.StripeProvider {
+		t.Fatalf("event mismatch: %+v", ev)
+	}
+}
+
+func TestStripeWebhook_Verify_rejects(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	wh := &pg.StripeWebhook{Secret: "whsec_test", Now: func() time.Time { return now }}
+	body := []byte(webhookBody)
+
+	cases := map[string]http.Header{
+		"missing header": {},
+		"wrong secret":   signedHeader("whsec_other", now, body),
+		"too old":        signedHeader("whsec_test", now.Add(-6*time.Minute), body),
+		"future":         signedHeader("whsec_test", now.Add(6*time.Minute), body),
+	}
+	for name, h := range cases {
+		if _, err := wh.Verify(h, body); !errors.Is(err, domain.ErrInvalidArgument) {
+			t.Errorf("%s: err = %v; want ErrInvalidArgument", name, err)
+		}
+	}
+
+	// ボディ改ざん
+	h := signedHeader("whsec_test", now, body)
+	if _, err := wh.Verify(h, []byte(`{"id":"evt_1","type":"payment_intent.succeeded"}`)); !errors.Is(err, domain.ErrInvalidArgument) {
+		t.Errorf("tampered body: err = %v; want ErrInvalidArgument", err)
+	}
+}
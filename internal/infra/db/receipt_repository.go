@@ -0,0 +1,84 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/order"
+	"github.com/kazshi01/payment-system/internal/domain/receipt"
+	sqlcdb "github.com/kazshi01/payment-system/internal/infra/db/sqlc"
+)
+
+// PostgresReceiptRepository implements domain.ReceiptRepository using sqlc.
+type PostgresReceiptRepository struct {
+	DB *sql.DB
+	Q  *sqlcdb.Queries
+}
+
+func NewPostgresReceiptRepository(db *sql.DB) *PostgresReceiptRepository {
+	return &PostgresReceiptRepository{
+		DB: db,
+		Q:  sqlcdb.New(db),
+	}
+}
+
+// Tx が ctx に乗っていればその Tx にバインドした Queries を返す
+func (r *PostgresReceiptRepository) getQ(ctx context.Context) *sqlcdb.Queries {
+	if tx := getTx(ctx); tx != nil {
+		return r.Q.WithTx(tx)
+	}
+	return r.Q
+}
+
+// Issue counts a download of an issued receipt, or numbers a new one.
+// It must run inside Tx so that a rolled-back issue gives its number back.
+// If another transaction issues the same order's first receipt concurrently,
+// the later one returns domain.ErrConflict.
+func (r *PostgresReceiptRepository) Issue(ctx context.Context, orderID order.ID, issuerID string, at time.Time) (*receipt.Receipt, error) {
+	q := r.getQ(ctx)
+
+	rec, err := q.IncrementReceiptDownloads(ctx, sqlcdb.IncrementReceiptDownloadsParams{
+		OrderID:          string(orderID),
+		LastDownloadedAt: at,
+	})
+	if err == nil {
+		return &receipt.Receipt{
+			OrderID:   order.ID(rec.OrderID),
+			IssuerID:  rec.UserID,
+			Number:    rec.Number,
+			IssuedAt:  rec.IssuedAt,
+			Downloads: rec.DownloadCount,
+		}, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("increment receipt downloads: %w", err)
+	}
+
+	number, err := q.NextReceiptNumber(ctx, issuerID)
+	if err != nil {
+		return nil, fmt.Errorf("next receipt number: %w", err)
+	}
+	rows, err := q.CreateReceipt(ctx, sqlcdb.CreateReceiptParams{
+		OrderID:  string(orderID),
+		UserID:   issuerID,
+		Number:   number,
+		IssuedAt: at,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create receipt: %w", err)
+	}
+	if rows == 0 {
+		return nil, domain.ErrConflict
+	}
+	return &receipt.Receipt{
+		OrderID:   orderID,
+		IssuerID:  issuerID,
+		Number:    number,
+		IssuedAt:  at,
+		Downloads: 1,
+	}, nil
+}
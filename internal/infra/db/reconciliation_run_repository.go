@@ -0,0 +1,168 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/money"
+	"github.com/kazshi01/payment-system/internal/domain/settlement"
+	sqlcdb "github.com/kazshi01/payment-system/internal/infra/db/sqlc"
+)
+
+// PostgresReconciliationRunRepository implements domain.ReconciliationRunRepository using sqlc.
+type PostgresReconciliationRunRepository struct {
+	DB *sql.DB
+	Q  *sqlcdb.Queries
+}
+
+func NewPostgresReconciliationRunRepository(db *sql.DB) *PostgresReconciliationRunRepository {
+	return &PostgresReconciliationRunRepository{
+		DB: db,
+		Q:  sqlcdb.New(db),
+	}
+}
+
+// Tx が ctx に乗っていればその Tx にバインドした Queries を返す
+func (r *PostgresReconciliationRunRepository) getQ(ctx context.Context) *sqlcdb.Queries {
+	if tx := getTx(ctx); tx != nil {
+		return r.Q.WithTx(tx)
+	}
+	return r.Q
+}
+
+// reconciliation_runs.items の要素。金額は最小単位で、ない側は省略する
+type reconciliationItemJSON struct {
+	Status         string `json:"status"`
+	TxID           string `json:"provider_tx_id"`
+	Line           int    `json:"line,omitempty"`
+	PaymentID      string `json:"payment_id,omitempty"`
+	OrderID        string `json:"order_id,omitempty"`
+	OursAmount     *int64 `json:"ours_amount,omitempty"`
+	OursCurrency   string `json:"ours_currency,omitempty"`
+	TheirsAmount   *int64 `json:"theirs_amount,omitempty"`
+	TheirsCurrency string `json:"theirs_currency,omitempty"`
+}
+
+// Create stores a reconciliation run with its items.
+func (r *PostgresReconciliationRunRepository) Create(ctx context.Context, run *settlement.Run) error {
+	items := make([]reconciliationItemJSON, 0, len(run.Items))
+	for _, it := range run.Items {
+		j := reconciliationItemJSON{
+			Status:    string(it.Status),
+			TxID:      it.TxID,
+			Line:      it.Line,
+			PaymentID: it.PaymentID,
+			OrderID:   it.OrderID,
+		}
+		if it.Ours.Currency != "" {
+			a := it.Ours.Amount
+			j.OursAmount, j.OursCurrency = &a, string(it.Ours.Currency)
+		}
+		if it.Theirs.Currency != "" {
+			a := it.Theirs.Amount
+			j.TheirsAmount, j.TheirsCurrency = &a, string(it.Theirs.Currency)
+		}
+		items = append(items, j)
+	}
+	b, err := json.Marshal(items)
+	if err != nil {
+		return fmt.Errorf("marshal reconciliation items: %w", err)
+	}
+
+	err = r.getQ(ctx).CreateReconciliationRun(ctx, sqlcdb.CreateReconciliationRunParams{
+		ID:              run.ID,
+		Provider:        run.Provider,
+		Source:          run.Source,
+		PeriodFrom:      run.From,
+		PeriodTo:        run.To,
+		Matched:         int32(run.Summary.Matched),
+		AmountMismatch:  int32(run.Summary.AmountMismatch),
+		MissingInOurs:   int32(run.Summary.MissingInOurs),
+		MissingInTheirs: int32(run.Summary.MissingInTheirs),
+		Items:           b,
+		CreatedAt:       run.CreatedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("create reconciliation run: %w", err)
+	}
+	return nil
+}
+
+// FindByID fetches a run with its items.
+func (r *PostgresReconciliationRunRepository) FindByID(ctx context.Context, id string) (*settlement.Run, error) {
+	rec, err := r.getQ(ctx).GetReconciliationRun(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("get reconciliation run: %w", err)
+	}
+
+	var items []reconciliationItemJSON
+	if err := json.Unmarshal(rec.Items, &items); err != nil {
+		return nil, fmt.Errorf("unmarshal reconciliation items: %w", err)
+	}
+	run := &settlement.Run{
+		ID:       rec.ID,
+		Provider: rec.Provider,
+		Source:   rec.Source,
+		From:     rec.PeriodFrom,
+		To:       rec.PeriodTo,
+		Summary: settlement.Summary{
+			Matched:         int(rec.Matched),
+			AmountMismatch:  int(rec.AmountMismatch),
+			MissingInOurs:   int(rec.MissingInOurs),
+			MissingInTheirs: int(rec.MissingInTheirs),
+		},
+		Items:     make([]settlement.Item, 0, len(items)),
+		CreatedAt: rec.CreatedAt,
+	}
+	for _, j := range items {
+		it := settlement.Item{
+			Status:    settlement.Status(j.Status),
+			TxID:      j.TxID,
+			Line:      j.Line,
+			PaymentID: j.PaymentID,
+			OrderID:   j.OrderID,
+		}
+		if j.OursAmount != nil {
+			it.Ours = money.Money{Amount: *j.OursAmount, Currency: money.Currency(j.OursCurrency)}
+		}
+		if j.TheirsAmount != nil {
+			it.Theirs = money.Money{Amount: *j.TheirsAmount, Currency: money.Currency(j.TheirsCurrency)}
+		}
+		run.Items = append(run.Items, it)
+	}
+	return run, nil
+}
+
+// List lists runs newest first without their items.
+func (r *PostgresReconciliationRunRepository) List(ctx context.Context, limit int) ([]*settlement.Run, error) {
+	recs, err := r.getQ(ctx).ListReconciliationRuns(ctx, int32(limit))
+	if err != nil {
+		return nil, fmt.Errorf("list reconciliation runs: %w", err)
+	}
+
+	runs := make([]*settlement.Run, 0, len(recs))
+	for _, rec := range recs {
+		runs = append(runs, &settlement.Run{
+			ID:       rec.ID,
+			Provider: rec.Provider,
+			Source:   rec.Source,
+			From:     rec.PeriodFrom,
+			To:       rec.PeriodTo,
+			Summary: settlement.Summary{
+				Matched:         int(rec.Matched),
+				AmountMismatch:  int(rec.AmountMismatch),
+				MissingInOurs:   int(rec.MissingInOurs),
+				MissingInTheirs: int(rec.MissingInTheirs),
+			},
+			CreatedAt: rec.CreatedAt,
+		})
+	}
+	return runs, nil
+}
@@ -0,0 +1,75 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/kazshi01/payment-system/internal/domain/money"
+	"github.com/kazshi01/payment-system/internal/domain/order"
+	"github.com/kazshi01/payment-system/internal/domain/refund"
+	sqlcdb "github.com/kazshi01/payment-system/internal/infra/db/sqlc"
+)
+
+// PostgresRefundRepository implements domain.RefundRepository using sqlc.
+type PostgresRefundRepository struct {
+	DB *sql.DB
+	Q  *sqlcdb.Queries
+}
+
+func NewPostgresRefundRepository(db *sql.DB) *PostgresRefundRepository {
+	return &PostgresRefundRepository{
+		DB: db,
+		Q:  sqlcdb.New(db),
+	}
+}
+
+// Tx が ctx に乗っていればその Tx にバインドした Queries を返す
+func (r *PostgresRefundRepository) getQ(ctx context.Context) *sqlcdb.Queries {
+	if tx := getTx(ctx); tx != nil {
+		return r.Q.WithTx(tx)
+	}
+	return r.Q
+}
+
+// CreateWithinCaptured inserts a refund unless the total refunded would exceed the captured amount.
+func (r *PostgresRefundRepository) CreateWithinCaptured(ctx context.Context, rf *refund.Refund) (int64, error) {
+	n, err := r.getQ(ctx).CreateRefundWithinCaptured(ctx, sqlcdb.CreateRefundWithinCapturedParams{
+		ID:               string(rf.ID),
+		PaymentID:        rf.PaymentID,
+		Amount:           rf.Amount.Amount,
+		Currency:         string(rf.Amount.Currency),
+		Reason:           rf.Reason,
+		ProviderRefundID: rf.ProviderRefundID,
+		IdempotencyKey:   rf.IdempotencyKey,
+		CreatedAt:        rf.CreatedAt,
+		OrderID:          rf.OrderID,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("create refund: %w", err)
+	}
+	return n, nil
+}
+
+// ListByOrderID lists refunds of an order in creation order.
+func (r *PostgresRefundRepository) ListByOrderID(ctx context.Context, orderID order.ID) ([]*refund.Refund, error) {
+	recs, err := r.getQ(ctx).ListRefundsByOrderID(ctx, string(orderID))
+	if err != nil {
+		return nil, fmt.Errorf("list refunds: %w", err)
+	}
+
+	rs := make([]*refund.Refund, 0, len(recs))
+	for _, rec := range recs {
+		rs = append(rs, &refund.Refund{
+			ID:               refund.ID(rec.ID),
+			OrderID:          rec.OrderID,
+			PaymentID:        rec.PaymentID,
+			Amount:           money.Money{Amount: rec.Amount, Currency: money.Currency(rec.Currency)},
+			Reason:           rec.Reason,
+			ProviderRefundID: rec.ProviderRefundID,
+			IdempotencyKey:   rec.IdempotencyKey,
+			CreatedAt:        rec.CreatedAt,
+		})
+	}
+	return rs, nil
+}
@@ -5,17 +5,184 @@
 package sqlcdb
 
 import (
+	"database/sql"
 	"encoding/json"
 	"time"
 )
 
+type Authorization struct {
+	ID             string
+	OrderID        string
+	Provider       string
+	ProviderAuthID string
+	Amount         int64
+	Status         string
+	ExpiresAt      time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	Currency       string
+}
+
+type BankDeposit struct {
+	ID              string
+	Source          string
+	Line            int32
+	BankCode        string
+	BranchCode      string
+	AccountNumber   string
+	InquiryNo       string
+	ValueDate       time.Time
+	Amount          int64
+	Currency        string
+	PayerCode       string
+	PayerName       string
+	RemittingBank   string
+	RemittingBranch string
+	Canceled        bool
+	TransferID      sql.NullString
+	OrderID         sql.NullString
+	Status          string
+	Reason          string
+	CreatedAt       time.Time
+}
+
+type BankTransfer struct {
+	ID            string
+	OrderID       string
+	Mode          string
+	BankCode      string
+	BankName      string
+	BranchCode    string
+	BranchName    string
+	AccountType   string
+	AccountNumber string
+	AccountName   string
+	ReferenceCode string
+	PayerName     string
+	Amount        int64
+	Currency      string
+	Status        string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+type Coupon struct {
+	ID             string
+	Code           string
+	Kind           string
+	PercentOff     int64
+	AmountOff      int64
+	MinAmount      int64
+	Currency       string
+	MaxRedemptions int64
+	MaxPerUser     int64
+	RedeemedCount  int64
+	ValidFrom      time.Time
+	ValidUntil     sql.NullTime
+	CreatedAt      time.Time
+}
+
+type CouponRedemption struct {
+	ID        string
+	CouponID  string
+	OrderID   string
+	UserID    string
+	Discount  int64
+	Currency  string
+	CreatedAt time.Time
+}
+
+type IdempotencyKey struct {
+	UserID          string
+	Key             string
+	Fingerprint     string
+	StatusCode      int32
+	ResponseHeaders json.RawMessage
+	ResponseBody    []byte
+	CreatedAt       time.Time
+	ExpiresAt       time.Time
+}
+
+type JournalEntry struct {
+	ID        string
+	Kind      string
+	OrderID   string
+	Reference string
+	CreatedAt time.Time
+}
+
+type KonbiniPayment struct {
+	ID                 string
+	OrderID            string
+	Provider           string
+	ProviderPaymentID  string
+	PaymentCode        string
+	ConfirmationNumber string
+	Amount             int64
+	Currency           string
+	Status             string
+	ExpiresAt          time.Time
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+type LedgerAccount struct {
+	Code string
+	Type string
+	Name string
+}
+
+type MerchantSetting struct {
+	UserID             string
+	TaxRounding        string
+	UpdatedAt          time.Time
+	IssuerName         string
+	IssuerAddress      string
+	RegistrationNumber string
+}
+
 type Order struct {
 	ID        string
 	UserID    string
-	AmountJpy int64
+	Amount    int64
 	Status    string
 	CreatedAt time.Time
 	UpdatedAt time.Time
+	LockFence int64
+	Currency  string
+}
+
+type OrderItem struct {
+	OrderID   string
+	LineNo    int32
+	Sku       string
+	Name      string
+	UnitPrice int64
+	Currency  string
+	Quantity  int64
+	TaxRate   int16
+}
+
+type OrderTaxLine struct {
+	OrderID  string
+	Rate     int16
+	Taxable  int64
+	Tax      int64
+	Currency string
+}
+
+type Outbox struct {
+	ID            string
+	AggregateID   string
+	Type          string
+	Payload       json.RawMessage
+	CreatedAt     time.Time
+	Seq           int64
+	Attempts      int32
+	NextAttemptAt time.Time
+	LastError     string
+	DeliveredAt   sql.NullTime
+	DeadAt        sql.NullTime
 }
 
 type Payment struct {
@@ -25,6 +192,8 @@ type Payment struct {
 	Provider     string
 	ProviderTxID string
 	CreatedAt    time.Time
+	Amount       int64
+	Currency     string
 }
 
 type PaymentEvent struct {
@@ -33,4 +202,97 @@ type PaymentEvent struct {
 	Type      string
 	Payload   json.RawMessage
 	CreatedAt time.Time
+	Seq       int64
+}
+
+type Posting struct {
+	EntryID   string
+	LineNo    int32
+	Account   string
+	Amount    int64
+	Currency  string
+	CreatedAt time.Time
+}
+
+type Receipt struct {
+	OrderID          string
+	UserID           string
+	Number           int64
+	IssuedAt         time.Time
+	DownloadCount    int64
+	LastDownloadedAt time.Time
+}
+
+type ReceiptCounter struct {
+	UserID     string
+	LastNumber int64
+}
+
+type ReconciliationRun struct {
+	ID              string
+	Provider        string
+	Source          string
+	PeriodFrom      time.Time
+	PeriodTo        time.Time
+	Matched         int32
+	AmountMismatch  int32
+	MissingInOurs   int32
+	MissingInTheirs int32
+	Items           json.RawMessage
+	CreatedAt       time.Time
+}
+
+type Refund struct {
+	ID               string
+	OrderID          string
+	PaymentID        string
+	Amount           int64
+	Reason           string
+	ProviderRefundID string
+	IdempotencyKey   string
+	CreatedAt        time.Time
+	Currency         string
+}
+
+type WebhookDelivery struct {
+	ID             string
+	EndpointID     string
+	EventID        string
+	EventType      string
+	Payload        json.RawMessage
+	Status         string
+	Attempts       int32
+	NextAttemptAt  time.Time
+	LastStatusCode int32
+	LastError      string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+type WebhookDeliveryAttempt struct {
+	ID          string
+	DeliveryID  string
+	StatusCode  int32
+	Error       string
+	DurationMs  int64
+	AttemptedAt time.Time
+}
+
+type WebhookEndpoint struct {
+	ID        string
+	UserID    string
+	Url       string
+	Events    []string
+	Secret    string
+	CreatedAt time.Time
+	DeletedAt sql.NullTime
+}
+
+type WebhookEvent struct {
+	Provider    string
+	EventID     string
+	Type        string
+	Payload     json.RawMessage
+	ReceivedAt  time.Time
+	ProcessedAt sql.NullTime
 }
@@ -7,18 +7,20 @@ package sqlcdb
 
 import (
 	"context"
+	"database/sql"
 	"time"
 )
 
 const createOrder = `-- name: CreateOrder :exec
-INSERT INTO orders (id, user_id, amount_jpy, status, created_at, updated_at)
-VALUES ($1, $2, $3, $4, $5, $6)
+INSERT INTO orders (id, user_id, amount, currency, status, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
 `
 
 type CreateOrderParams struct {
 	ID        string
 	UserID    string
-	AmountJpy int64
+	Amount    int64
+	Currency  string
 	Status    string
 	CreatedAt time.Time
 	UpdatedAt time.Time
@@ -28,7 +30,8 @@ func (q *Queries) CreateOrder(ctx context.Context, arg CreateOrderParams) error
 	_, err := q.db.ExecContext(ctx, createOrder,
 		arg.ID,
 		arg.UserID,
-		arg.AmountJpy,
+		arg.Amount,
+		arg.Currency,
 		arg.Status,
 		arg.CreatedAt,
 		arg.UpdatedAt,
@@ -37,25 +40,19 @@ func (q *Queries) CreateOrder(ctx context.Context, arg CreateOrderParams) error
 }
 
 const getOrder = `-- name: GetOrder :one
-SELECT id, amount_jpy, status, created_at, updated_at
+SELECT id, user_id, amount, currency, status, created_at, updated_at
 FROM orders
 WHERE id = $1
 `
 
-type GetOrderRow struct {
-	ID        string
-	AmountJpy int64
-	Status    string
-	CreatedAt time.Time
-	UpdatedAt time.Time
-}
-
-func (q *Queries) GetOrder(ctx context.Context, id string) (GetOrderRow, error) {
+func (q *Queries) GetOrder(ctx context.Context, id string) (Order, error) {
 	row := q.db.QueryRowContext(ctx, getOrder, id)
-	var i GetOrderRow
+	var i Order
 	err := row.Scan(
 		&i.ID,
-		&i.AmountJpy,
+		&i.UserID,
+		&i.Amount,
+		&i.Currency,
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
@@ -64,7 +61,7 @@ func (q *Queries) GetOrder(ctx context.Context, id string) (GetOrderRow, error)
 }
 
 const getOrderForUser = `-- name: GetOrderForUser :one
-SELECT id, user_id, amount_jpy, status, created_at, updated_at
+SELECT id, user_id, amount, currency, status, created_at, updated_at
 FROM orders
 WHERE id = $1 AND user_id = $2
 `
@@ -80,7 +77,8 @@ func (q *Queries) GetOrderForUser(ctx context.Context, arg GetOrderForUserParams
 	err := row.Scan(
 		&i.ID,
 		&i.UserID,
-		&i.AmountJpy,
+		&i.Amount,
+		&i.Currency,
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
@@ -90,13 +88,14 @@ func (q *Queries) GetOrderForUser(ctx context.Context, arg GetOrderForUserParams
 
 const updateOrder = `-- name: UpdateOrder :exec
 UPDATE orders
-SET amount_jpy = $2, status = $3, updated_at = $4
+SET amount = $2, currency = $3, status = $4, updated_at = $5
 WHERE id = $1
 `
 
 type UpdateOrderParams struct {
 	ID        string
-	AmountJpy int64
+	Amount    int64
+	Currency  string
 	Status    string
 	UpdatedAt time.Time
 }
@@ -104,55 +103,103 @@ type UpdateOrderParams struct {
 func (q *Queries) UpdateOrder(ctx context.Context, arg UpdateOrderParams) error {
 	_, err := q.db.ExecContext(ctx, updateOrder,
 		arg.ID,
-		arg.AmountJpy,
+		arg.Amount,
+		arg.Currency,
 		arg.Status,
 		arg.UpdatedAt,
 	)
 	return err
 }
 
-const updateOrderStatusIfPending = `-- name: UpdateOrderStatusIfPending :execrows
+const updateOrderStatusIf = `-- name: UpdateOrderStatusIf :execrows
 UPDATE orders
-SET status = $2, updated_at = $3
-WHERE id = $1 AND status = 'PENDING'
+SET status = $1, updated_at = $2,
+    lock_fence = GREATEST(lock_fence, $3::bigint)
+WHERE id = $4 AND status = $5
+  AND ($3::bigint = 0 OR lock_fence <= $3::bigint)
 `
 
-type UpdateOrderStatusIfPendingParams struct {
-	ID        string
-	Status    string
-	UpdatedAt time.Time
+type UpdateOrderStatusIfParams struct {
+	ToStatus   string
+	UpdatedAt  time.Time
+	Fence      int64
+	ID         string
+	FromStatus string
 }
 
-func (q *Queries) UpdateOrderStatusIfPending(ctx context.Context, arg UpdateOrderStatusIfPendingParams) (int64, error) {
-	result, err := q.db.ExecContext(ctx, updateOrderStatusIfPending, arg.ID, arg.Status, arg.UpdatedAt)
+// fence が 0 のときはフェンシングを検査しない
+func (q *Queries) UpdateOrderStatusIf(ctx context.Context, arg UpdateOrderStatusIfParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateOrderStatusIf,
+		arg.ToStatus,
+		arg.UpdatedAt,
+		arg.Fence,
+		arg.ID,
+		arg.FromStatus,
+	)
 	if err != nil {
 		return 0, err
 	}
 	return result.RowsAffected()
 }
 
-const updateOrderStatusIfPendingForUser = `-- name: UpdateOrderStatusIfPendingForUser :execrows
-UPDATE orders
-SET status = $3, updated_at = $4
-WHERE id = $1 AND user_id = $2 AND status = 'PENDING'
+const listOrders = `-- name: ListOrders :many
+SELECT id, user_id, amount, currency, status, created_at, updated_at
+FROM orders
+WHERE ($1::text IS NULL OR user_id = $1::text)
+  AND ($2::text IS NULL OR status = $2::text)
+  AND ($3::timestamptz IS NULL OR created_at >= $3::timestamptz)
+  AND ($4::timestamptz IS NULL OR created_at < $4::timestamptz)
+  AND ($5::timestamptz IS NULL
+       OR (created_at, id) < ($5::timestamptz, $6::text))
+ORDER BY created_at DESC, id DESC
+LIMIT $7
 `
 
-type UpdateOrderStatusIfPendingForUserParams struct {
-	ID        string
-	UserID    string
-	Status    string
-	UpdatedAt time.Time
+type ListOrdersParams struct {
+	UserID         sql.NullString
+	Status         sql.NullString
+	CreatedFrom    sql.NullTime
+	CreatedTo      sql.NullTime
+	AfterCreatedAt sql.NullTime
+	AfterID        sql.NullString
+	MaxRows        int32
 }
 
-func (q *Queries) UpdateOrderStatusIfPendingForUser(ctx context.Context, arg UpdateOrderStatusIfPendingForUserParams) (int64, error) {
-	result, err := q.db.ExecContext(ctx, updateOrderStatusIfPendingForUser,
-		arg.ID,
+func (q *Queries) ListOrders(ctx context.Context, arg ListOrdersParams) ([]Order, error) {
+	rows, err := q.db.QueryContext(ctx, listOrders,
 		arg.UserID,
 		arg.Status,
-		arg.UpdatedAt,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.MaxRows,
 	)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
-	return result.RowsAffected()
+	defer rows.Close()
+	items := []Order{}
+	for rows.Next() {
+		var i Order
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Amount,
+			&i.Currency,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
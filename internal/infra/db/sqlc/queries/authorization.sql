@@ -0,0 +1,20 @@
+-- name: CreateAuthorization :exec
+INSERT INTO authorizations (id, order_id, provider, provider_auth_id, amount, currency, status, expires_at, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);
+
+-- name: GetActiveAuthorizationByOrderID :one
+SELECT id, order_id, provider, provider_auth_id, amount, currency, status, expires_at, created_at, updated_at
+FROM authorizations
+WHERE order_id = $1 AND status = 'AUTHORIZED';
+
+-- name: UpdateAuthorizationStatusIf :execrows
+UPDATE authorizations
+SET status = sqlc.arg(to_status), updated_at = sqlc.arg(updated_at)
+WHERE id = sqlc.arg(id) AND status = sqlc.arg(from_status);
+
+-- name: ListExpiredAuthorizations :many
+SELECT id, order_id, provider, provider_auth_id, amount, currency, status, expires_at, created_at, updated_at
+FROM authorizations
+WHERE status = 'AUTHORIZED' AND expires_at <= $1
+ORDER BY expires_at
+LIMIT $2;
@@ -0,0 +1,55 @@
+-- name: GetBankTransferByOrderID :one
+SELECT id, order_id, mode, bank_code, bank_name, branch_code, branch_name,
+       account_type, account_number, account_name, reference_code, payer_name,
+       amount, currency, status, created_at, updated_at
+FROM bank_transfers
+WHERE order_id = $1;
+
+-- name: CreateBankTransfer :execrows
+-- 注文に発行済み・振込先が使用済みなら 0 件
+INSERT INTO bank_transfers (
+  id, order_id, mode, bank_code, bank_name, branch_code, branch_name,
+  account_type, account_number, account_name, reference_code, payer_name,
+  amount, currency, status, created_at, updated_at
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+ON CONFLICT DO NOTHING;
+
+-- name: GetBankTransferByDestination :one
+SELECT id, order_id, mode, bank_code, bank_name, branch_code, branch_name,
+       account_type, account_number, account_name, reference_code, payer_name,
+       amount, currency, status, created_at, updated_at
+FROM bank_transfers
+WHERE branch_code = $1 AND account_number = $2 AND reference_code = $3;
+
+-- name: ListAwaitingBankTransfersByPayer :many
+SELECT id, order_id, mode, bank_code, bank_name, branch_code, branch_name,
+       account_type, account_number, account_name, reference_code, payer_name,
+       amount, currency, status, created_at, updated_at
+FROM bank_transfers
+WHERE status = 'AWAITING' AND payer_name = $1 AND amount = $2 AND currency = $3
+ORDER BY created_at
+LIMIT $4;
+
+-- name: UpdateBankTransferStatusIf :execrows
+UPDATE bank_transfers
+SET status = sqlc.arg(to_status), updated_at = sqlc.arg(updated_at)
+WHERE id = sqlc.arg(id) AND status = sqlc.arg(from_status);
+
+-- name: CreateBankDeposit :execrows
+INSERT INTO bank_deposits (
+  id, source, line, bank_code, branch_code, account_number, inquiry_no, value_date,
+  amount, currency, payer_code, payer_name, remitting_bank, remitting_branch,
+  canceled, transfer_id, order_id, status, reason, created_at
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)
+ON CONFLICT (branch_code, account_number, value_date, inquiry_no) DO NOTHING;
+
+-- name: ListBankDeposits :many
+SELECT id, source, line, bank_code, branch_code, account_number, inquiry_no, value_date,
+       amount, currency, payer_code, payer_name, remitting_bank, remitting_branch,
+       canceled, transfer_id, order_id, status, reason, created_at
+FROM bank_deposits
+WHERE (sqlc.narg(status)::text IS NULL OR status = sqlc.narg(status)::text)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(max_rows);
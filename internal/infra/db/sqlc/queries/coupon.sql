@@ -0,0 +1,47 @@
+-- name: CreateCoupon :execrows
+INSERT INTO coupons (
+  id, code, kind, percent_off, amount_off, min_amount, currency,
+  max_redemptions, max_per_user, valid_from, valid_until, created_at
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+ON CONFLICT (code) DO NOTHING;
+
+-- name: GetCouponByCode :one
+SELECT id, code, kind, percent_off, amount_off, min_amount, currency,
+       max_redemptions, max_per_user, redeemed_count, valid_from, valid_until, created_at
+FROM coupons
+WHERE code = $1;
+
+-- name: IncrementCouponRedemptions :execrows
+-- 全体の上限に達していなければ利用回数を 1 増やす（行ロックで同時利用を直列化する）
+UPDATE coupons
+SET redeemed_count = redeemed_count + 1
+WHERE id = $1
+  AND (max_redemptions = 0 OR redeemed_count < max_redemptions);
+
+-- name: CreateCouponRedemption :execrows
+-- ユーザごとの上限に達していなければ利用を記録する
+-- IncrementCouponRedemptions と同じ Tx で、その後に実行する
+INSERT INTO coupon_redemptions (id, coupon_id, order_id, user_id, discount, currency, created_at)
+SELECT $1, $2, $3, $4, $5, $6, $7
+FROM coupons c
+WHERE c.id = $2
+  AND (c.max_per_user = 0
+       OR (SELECT count(*) FROM coupon_redemptions r WHERE r.coupon_id = c.id AND r.user_id = $4) < c.max_per_user);
+
+-- name: ReleaseCouponRedemption :execrows
+-- 注文の利用記録を消して利用回数を 1 戻す（利用がなければ何もしない）
+WITH released AS (
+  DELETE FROM coupon_redemptions WHERE order_id = $1
+  RETURNING coupon_id
+)
+UPDATE coupons
+SET redeemed_count = redeemed_count - 1
+WHERE id IN (SELECT coupon_id FROM released)
+  AND redeemed_count > 0;
+
+-- name: GetCouponRedemptionByOrderID :one
+SELECT r.id, r.coupon_id, c.code, r.order_id, r.user_id, r.discount, r.currency, r.created_at
+FROM coupon_redemptions r
+JOIN coupons c ON c.id = r.coupon_id
+WHERE r.order_id = $1;
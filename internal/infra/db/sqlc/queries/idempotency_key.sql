@@ -0,0 +1,36 @@
+-- name: ReserveIdempotencyKey :execrows
+INSERT INTO idempotency_keys (user_id, key, fingerprint, created_at, expires_at)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (user_id, key) DO UPDATE
+SET fingerprint      = EXCLUDED.fingerprint,
+    status_code      = 0,
+    response_headers = '{}'::jsonb,
+    response_body    = ''::bytea,
+    created_at       = EXCLUDED.created_at,
+    expires_at       = EXCLUDED.expires_at
+WHERE idempotency_keys.expires_at <= EXCLUDED.created_at;
+
+-- name: GetIdempotencyKey :one
+SELECT user_id, key, fingerprint, status_code, response_headers, response_body, created_at, expires_at
+FROM idempotency_keys
+WHERE user_id = $1 AND key = $2;
+
+-- name: CompleteIdempotencyKey :execrows
+UPDATE idempotency_keys
+SET status_code      = $3,
+    response_headers = $4,
+    response_body    = $5
+WHERE user_id = $1 AND key = $2 AND status_code = 0;
+
+-- name: DeleteInProgressIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE user_id = $1 AND key = $2 AND status_code = 0;
+
+-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys
+WHERE (user_id, key) IN (
+  SELECT user_id, key FROM idempotency_keys
+  WHERE expires_at <= $1
+  ORDER BY expires_at
+  LIMIT $2
+);
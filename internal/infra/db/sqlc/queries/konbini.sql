@@ -0,0 +1,27 @@
+-- name: CreateKonbiniPayment :execrows
+-- 注文に支払い待ちの払込番号がある・決済ID が登録済みなら 0 件
+INSERT INTO konbini_payments (
+  id, order_id, provider, provider_payment_id, payment_code, confirmation_number,
+  amount, currency, status, expires_at, created_at, updated_at
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+ON CONFLICT DO NOTHING;
+
+-- name: GetAwaitingKonbiniPaymentByOrderID :one
+SELECT id, order_id, provider, provider_payment_id, payment_code, confirmation_number,
+       amount, currency, status, expires_at, created_at, updated_at
+FROM konbini_payments
+WHERE order_id = $1 AND status = 'AWAITING';
+
+-- name: UpdateKonbiniPaymentStatusIf :execrows
+UPDATE konbini_payments
+SET status = sqlc.arg(to_status), updated_at = sqlc.arg(updated_at)
+WHERE id = sqlc.arg(id) AND status = sqlc.arg(from_status);
+
+-- name: ListExpiredKonbiniPayments :many
+SELECT id, order_id, provider, provider_payment_id, payment_code, confirmation_number,
+       amount, currency, status, expires_at, created_at, updated_at
+FROM konbini_payments
+WHERE status = 'AWAITING' AND expires_at <= $1
+ORDER BY expires_at
+LIMIT $2;
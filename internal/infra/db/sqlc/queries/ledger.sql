@@ -0,0 +1,61 @@
+-- name: CreateJournalEntry :execrows
+INSERT INTO journal_entries (id, kind, order_id, reference, created_at)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (kind, reference) DO NOTHING;
+
+-- name: CreatePosting :exec
+INSERT INTO postings (entry_id, line_no, account, amount, currency, created_at)
+VALUES ($1, $2, $3, $4, $5, $6);
+
+-- name: SumPostings :one
+-- 勘定の期間内の借方・貸方の合計（期間の指定がなければ全期間）
+SELECT COALESCE(SUM(amount) FILTER (WHERE amount > 0), 0)::bigint AS debit,
+       COALESCE(-SUM(amount) FILTER (WHERE amount < 0), 0)::bigint AS credit
+FROM postings
+WHERE account = sqlc.arg(account)
+  AND currency = sqlc.arg(currency)
+  AND (sqlc.narg(created_from)::timestamptz IS NULL OR created_at >= sqlc.narg(created_from)::timestamptz)
+  AND (sqlc.narg(created_to)::timestamptz IS NULL OR created_at < sqlc.narg(created_to)::timestamptz);
+
+-- name: ListUnbalancedEntries :many
+-- 通貨ごとの明細の合計が 0 でない、または明細が 2 行未満の仕訳
+SELECT e.id, e.order_id,
+       COALESCE(p.currency, '')::text AS currency,
+       COALESCE(SUM(p.amount), 0)::bigint AS total,
+       COUNT(p.entry_id) AS postings
+FROM journal_entries e
+LEFT JOIN postings p ON p.entry_id = e.id
+GROUP BY e.id, e.order_id, p.currency
+HAVING COALESCE(SUM(p.amount), 0) <> 0 OR COUNT(p.entry_id) < 2
+ORDER BY e.id
+LIMIT $1;
+
+-- name: ListUnpostedOrders :many
+-- 支払い済み（返金済みを含む）の注文のうち、決済額と売掛金・普通預金への計上額、返金額と売上返金への計上額が一致しないもの
+WITH paid AS (
+  SELECT order_id, SUM(amount)::bigint AS amount FROM payments GROUP BY order_id
+), refunded AS (
+  SELECT order_id, SUM(amount)::bigint AS amount FROM refunds GROUP BY order_id
+), posted AS (
+  SELECT e.order_id,
+         COALESCE(SUM(p.amount) FILTER (WHERE e.kind = 'charge' AND p.account IN ('provider_receivable', 'bank')), 0)::bigint AS charged,
+         COALESCE(SUM(p.amount) FILTER (WHERE e.kind = 'refund' AND p.account = 'sales_refunds'), 0)::bigint AS refunded
+  FROM journal_entries e
+  JOIN postings p ON p.entry_id = e.id
+  GROUP BY e.order_id
+)
+SELECT o.id, o.status,
+       COALESCE(paid.amount, 0)::bigint AS paid,
+       COALESCE(posted.charged, 0)::bigint AS charged,
+       COALESCE(refunded.amount, 0)::bigint AS refunded,
+       COALESCE(posted.refunded, 0)::bigint AS refund_posted
+FROM orders o
+LEFT JOIN paid ON paid.order_id = o.id
+LEFT JOIN refunded ON refunded.order_id = o.id
+LEFT JOIN posted ON posted.order_id = o.id
+WHERE o.status IN ('PAID', 'PARTIALLY_REFUNDED', 'REFUNDED')
+  AND (COALESCE(posted.charged, 0) = 0
+       OR COALESCE(paid.amount, 0) <> COALESCE(posted.charged, 0)
+       OR COALESCE(refunded.amount, 0) <> COALESCE(posted.refunded, 0))
+ORDER BY o.id
+LIMIT $1;
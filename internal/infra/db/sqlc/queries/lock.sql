@@ -0,0 +1,8 @@
+-- name: TryAdvisoryLock :one
+SELECT pg_try_advisory_lock(sqlc.arg(key)::bigint) AS locked;
+
+-- name: AdvisoryUnlock :one
+SELECT pg_advisory_unlock(sqlc.arg(key)::bigint) AS unlocked;
+
+-- name: NextLockFence :one
+SELECT nextval('lock_fence_seq')::bigint AS fence;
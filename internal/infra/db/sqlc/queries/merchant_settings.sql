@@ -0,0 +1,14 @@
+-- name: GetMerchantSettings :one
+SELECT user_id, tax_rounding, issuer_name, issuer_address, registration_number, updated_at
+FROM merchant_settings
+WHERE user_id = $1;
+
+-- name: UpsertMerchantSettings :exec
+INSERT INTO merchant_settings (user_id, tax_rounding, issuer_name, issuer_address, registration_number, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (user_id) DO UPDATE
+SET tax_rounding = EXCLUDED.tax_rounding,
+    issuer_name = EXCLUDED.issuer_name,
+    issuer_address = EXCLUDED.issuer_address,
+    registration_number = EXCLUDED.registration_number,
+    updated_at = EXCLUDED.updated_at;
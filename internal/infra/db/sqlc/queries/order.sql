@@ -1,28 +1,38 @@
 -- name: CreateOrder :exec
-INSERT INTO orders (id, user_id, amount_jpy, status, created_at, updated_at)
-VALUES ($1, $2, $3, $4, $5, $6);
+INSERT INTO orders (id, user_id, amount, currency, status, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7);
 
 -- name: GetOrder :one
-SELECT id, amount_jpy, status, created_at, updated_at
+SELECT id, user_id, amount, currency, status, created_at, updated_at
 FROM orders
 WHERE id = $1;
 
 -- name: GetOrderForUser :one
-SELECT id, user_id, amount_jpy, status, created_at, updated_at
+SELECT id, user_id, amount, currency, status, created_at, updated_at
 FROM orders
 WHERE id = $1 AND user_id = $2;
 
 -- name: UpdateOrder :exec
 UPDATE orders
-SET amount_jpy = $2, status = $3, updated_at = $4
+SET amount = $2, currency = $3, status = $4, updated_at = $5
 WHERE id = $1;
 
--- name: UpdateOrderStatusIfPending :execrows
+-- name: UpdateOrderStatusIf :execrows
+-- fence が 0 のときはフェンシングを検査しない
 UPDATE orders
-SET status = $2, updated_at = $3
-WHERE id = $1 AND status = 'PENDING';
+SET status = sqlc.arg(to_status), updated_at = sqlc.arg(updated_at),
+    lock_fence = GREATEST(lock_fence, sqlc.arg(fence)::bigint)
+WHERE id = sqlc.arg(id) AND status = sqlc.arg(from_status)
+  AND (sqlc.arg(fence)::bigint = 0 OR lock_fence <= sqlc.arg(fence)::bigint);
 
--- name: UpdateOrderStatusIfPendingForUser :execrows
-UPDATE orders
-SET status = $3, updated_at = $4
-WHERE id = $1 AND user_id = $2 AND status = 'PENDING';
+-- name: ListOrders :many
+SELECT id, user_id, amount, currency, status, created_at, updated_at
+FROM orders
+WHERE (sqlc.narg(user_id)::text IS NULL OR user_id = sqlc.narg(user_id)::text)
+  AND (sqlc.narg(status)::text IS NULL OR status = sqlc.narg(status)::text)
+  AND (sqlc.narg(created_from)::timestamptz IS NULL OR created_at >= sqlc.narg(created_from)::timestamptz)
+  AND (sqlc.narg(created_to)::timestamptz IS NULL OR created_at < sqlc.narg(created_to)::timestamptz)
+  AND (sqlc.narg(after_created_at)::timestamptz IS NULL
+       OR (created_at, id) < (sqlc.narg(after_created_at)::timestamptz, sqlc.narg(after_id)::text))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(max_rows);
@@ -0,0 +1,9 @@
+-- name: CreateOrderItem :exec
+INSERT INTO order_items (order_id, line_no, sku, name, unit_price, currency, quantity, tax_rate)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+
+-- name: ListOrderItemsByOrderID :many
+SELECT order_id, line_no, sku, name, unit_price, currency, quantity, tax_rate
+FROM order_items
+WHERE order_id = $1
+ORDER BY line_no;
@@ -0,0 +1,9 @@
+-- name: CreateOrderTaxLine :exec
+INSERT INTO order_tax_lines (order_id, rate, taxable, tax, currency)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: ListOrderTaxLinesByOrderID :many
+SELECT order_id, rate, taxable, tax, currency
+FROM order_tax_lines
+WHERE order_id = $1
+ORDER BY rate DESC;
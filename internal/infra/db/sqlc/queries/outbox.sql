@@ -0,0 +1,36 @@
+-- name: CreateOutboxMessage :exec
+INSERT INTO outbox (id, aggregate_id, type, payload, created_at, next_attempt_at)
+VALUES ($1, $2, $3, $4, $5, $5);
+
+-- name: ClaimPendingOutboxMessages :many
+-- 期限が来た行を lease_until まで借りる（配信は Tx の外で行う）
+UPDATE outbox
+SET next_attempt_at = sqlc.arg(lease_until)
+WHERE id IN (
+    SELECT o.id
+    FROM outbox o
+    WHERE o.delivered_at IS NULL AND o.dead_at IS NULL AND o.next_attempt_at <= sqlc.arg(now)
+    ORDER BY o.next_attempt_at, o.seq
+    LIMIT sqlc.arg(max_rows)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, aggregate_id, type, payload, created_at, seq, attempts, next_attempt_at, last_error, delivered_at, dead_at;
+
+-- name: MarkOutboxMessageDelivered :exec
+UPDATE outbox
+SET delivered_at = $2
+WHERE id = $1;
+
+-- name: MarkOutboxMessageFailed :exec
+UPDATE outbox
+SET attempts        = attempts + 1,
+    last_error      = $2,
+    next_attempt_at = $3
+WHERE id = $1 AND delivered_at IS NULL AND dead_at IS NULL;
+
+-- name: MarkOutboxMessageDead :exec
+UPDATE outbox
+SET attempts   = attempts + 1,
+    last_error = $2,
+    dead_at    = $3
+WHERE id = $1 AND delivered_at IS NULL AND dead_at IS NULL;
@@ -0,0 +1,22 @@
+-- name: CreatePayment :exec
+INSERT INTO payments (id, order_id, method, provider, provider_tx_id, amount, currency, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+
+-- name: ListPaymentsByOrderID :many
+SELECT id, order_id, method, provider, provider_tx_id, created_at, amount, currency
+FROM payments
+WHERE order_id = $1
+ORDER BY created_at, id;
+
+-- name: GetPaymentByProviderTxID :one
+SELECT id, order_id, method, provider, provider_tx_id, created_at, amount, currency
+FROM payments
+WHERE provider = $1 AND provider_tx_id = $2;
+
+-- name: ListPaymentsByProvider :many
+SELECT id, order_id, method, provider, provider_tx_id, created_at, amount, currency
+FROM payments
+WHERE provider = sqlc.arg(provider)
+  AND created_at >= sqlc.arg(created_from)
+  AND created_at < sqlc.arg(created_to)
+ORDER BY created_at, id;
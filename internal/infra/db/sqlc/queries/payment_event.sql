@@ -0,0 +1,9 @@
+-- name: CreatePaymentEvent :exec
+INSERT INTO payment_events (id, order_id, type, payload, created_at)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: ListPaymentEventsByOrderID :many
+SELECT id, order_id, type, payload, created_at, seq
+FROM payment_events
+WHERE order_id = $1
+ORDER BY created_at, seq;
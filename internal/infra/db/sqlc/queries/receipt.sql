@@ -0,0 +1,19 @@
+-- name: IncrementReceiptDownloads :one
+-- 発行済みの領収書のダウンロード回数を数える（2 回目以降は再発行）
+UPDATE receipts
+SET download_count = download_count + 1, last_downloaded_at = $2
+WHERE order_id = $1
+RETURNING order_id, user_id, number, issued_at, download_count, last_downloaded_at;
+
+-- name: NextReceiptNumber :one
+-- 発行者の次の番号を採番する。行ロックで同じ発行者の採番を直列化し、Tx が戻れば番号も戻る
+INSERT INTO receipt_counters (user_id, last_number)
+VALUES ($1, 1)
+ON CONFLICT (user_id) DO UPDATE
+SET last_number = receipt_counters.last_number + 1
+RETURNING last_number;
+
+-- name: CreateReceipt :execrows
+INSERT INTO receipts (order_id, user_id, number, issued_at, download_count, last_downloaded_at)
+VALUES ($1, $2, $3, $4, 1, $4)
+ON CONFLICT (order_id) DO NOTHING;
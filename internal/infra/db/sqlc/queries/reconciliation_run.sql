@@ -0,0 +1,19 @@
+-- name: CreateReconciliationRun :exec
+INSERT INTO reconciliation_runs (
+  id, provider, source, period_from, period_to,
+  matched, amount_mismatch, missing_in_ours, missing_in_theirs, items, created_at
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11);
+
+-- name: GetReconciliationRun :one
+SELECT id, provider, source, period_from, period_to,
+       matched, amount_mismatch, missing_in_ours, missing_in_theirs, items, created_at
+FROM reconciliation_runs
+WHERE id = $1;
+
+-- name: ListReconciliationRuns :many
+-- 一覧では取引ごとの結果（items）を読まない
+SELECT id, provider, source, period_from, period_to,
+       matched, amount_mismatch, missing_in_ours, missing_in_theirs, created_at
+FROM reconciliation_runs
+ORDER BY created_at DESC, id DESC
+LIMIT $1;
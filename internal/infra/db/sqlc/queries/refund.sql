@@ -0,0 +1,15 @@
+-- name: CreateRefundWithinCaptured :execrows
+INSERT INTO refunds (id, order_id, payment_id, amount, currency, reason, provider_refund_id, idempotency_key, created_at)
+SELECT sqlc.arg(id)::text, o.id, sqlc.arg(payment_id)::text, sqlc.arg(amount)::bigint, o.currency,
+       sqlc.arg(reason)::text, sqlc.arg(provider_refund_id)::text, sqlc.arg(idempotency_key)::text,
+       sqlc.arg(created_at)::timestamptz
+FROM orders o
+WHERE o.id = sqlc.arg(order_id)::text AND o.currency = sqlc.arg(currency)::text
+  AND (SELECT COALESCE(SUM(r.amount), 0) FROM refunds r WHERE r.order_id = o.id)
+      + sqlc.arg(amount)::bigint <= (SELECT COALESCE(SUM(p.amount), 0) FROM payments p WHERE p.order_id = o.id);
+
+-- name: ListRefundsByOrderID :many
+SELECT id, order_id, payment_id, amount, currency, reason, provider_refund_id, idempotency_key, created_at
+FROM refunds
+WHERE order_id = $1
+ORDER BY created_at, id;
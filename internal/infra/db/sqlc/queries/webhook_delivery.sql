@@ -0,0 +1,48 @@
+-- name: CreateWebhookDelivery :execrows
+INSERT INTO webhook_deliveries (id, endpoint_id, event_id, event_type, payload, status, next_attempt_at, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8)
+ON CONFLICT (endpoint_id, event_id) DO NOTHING;
+
+-- name: GetWebhookDelivery :one
+SELECT id, endpoint_id, event_id, event_type, payload, status, attempts, next_attempt_at, last_status_code, last_error, created_at, updated_at
+FROM webhook_deliveries
+WHERE id = $1;
+
+-- name: ListWebhookDeliveriesByEndpointID :many
+SELECT id, endpoint_id, event_id, event_type, payload, status, attempts, next_attempt_at, last_status_code, last_error, created_at, updated_at
+FROM webhook_deliveries
+WHERE endpoint_id = $1
+ORDER BY created_at DESC, id DESC
+LIMIT $2;
+
+-- name: ClaimDueWebhookDeliveries :many
+UPDATE webhook_deliveries
+SET next_attempt_at = sqlc.arg(lease_until)
+WHERE id IN (
+  SELECT d.id FROM webhook_deliveries d
+  WHERE d.status = 'PENDING' AND d.next_attempt_at <= sqlc.arg(now)
+  ORDER BY d.next_attempt_at
+  LIMIT sqlc.arg(max_rows)
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING id, endpoint_id, event_id, event_type, payload, status, attempts, next_attempt_at, last_status_code, last_error, created_at, updated_at;
+
+-- name: UpdateWebhookDeliveryAfterAttempt :exec
+UPDATE webhook_deliveries
+SET status           = $2,
+    attempts         = $3,
+    next_attempt_at  = $4,
+    last_status_code = $5,
+    last_error       = $6,
+    updated_at       = $7
+WHERE id = $1;
+
+-- name: CreateWebhookDeliveryAttempt :exec
+INSERT INTO webhook_delivery_attempts (id, delivery_id, status_code, error, duration_ms, attempted_at)
+VALUES ($1, $2, $3, $4, $5, $6);
+
+-- name: ListWebhookDeliveryAttempts :many
+SELECT id, delivery_id, status_code, error, duration_ms, attempted_at
+FROM webhook_delivery_attempts
+WHERE delivery_id = $1
+ORDER BY attempted_at, id;
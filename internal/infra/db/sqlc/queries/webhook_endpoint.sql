@@ -0,0 +1,19 @@
+-- name: CreateWebhookEndpoint :exec
+INSERT INTO webhook_endpoints (id, user_id, url, events, secret, created_at)
+VALUES ($1, $2, $3, $4, $5, $6);
+
+-- name: GetWebhookEndpoint :one
+SELECT id, user_id, url, events, secret, created_at, deleted_at
+FROM webhook_endpoints
+WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: ListWebhookEndpointsByUserID :many
+SELECT id, user_id, url, events, secret, created_at, deleted_at
+FROM webhook_endpoints
+WHERE user_id = $1 AND deleted_at IS NULL
+ORDER BY created_at, id;
+
+-- name: DeleteWebhookEndpoint :execrows
+UPDATE webhook_endpoints
+SET deleted_at = $2
+WHERE id = $1 AND deleted_at IS NULL;
@@ -0,0 +1,14 @@
+-- name: InsertWebhookEvent :exec
+INSERT INTO webhook_events (provider, event_id, type, payload, received_at)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (provider, event_id) DO NOTHING;
+
+-- name: GetWebhookEvent :one
+SELECT provider, event_id, type, payload, received_at, processed_at
+FROM webhook_events
+WHERE provider = $1 AND event_id = $2;
+
+-- name: MarkWebhookEventProcessed :exec
+UPDATE webhook_events
+SET processed_at = $3
+WHERE provider = $1 AND event_id = $2 AND processed_at IS NULL;
@@ -0,0 +1,161 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/webhook"
+	sqlcdb "github.com/kazshi01/payment-system/internal/infra/db/sqlc"
+)
+
+// PostgresWebhookDeliveryRepository implements domain.WebhookDeliveryRepository using sqlc.
+type PostgresWebhookDeliveryRepository struct {
+	DB *sql.DB
+	Q  *sqlcdb.Queries
+}
+
+func NewPostgresWebhookDeliveryRepository(db *sql.DB) *PostgresWebhookDeliveryRepository {
+	return &PostgresWebhookDeliveryRepository{
+		DB: db,
+		Q:  sqlcdb.New(db),
+	}
+}
+
+// Tx が ctx に乗っていればその Tx にバインドした Queries を返す
+func (r *PostgresWebhookDeliveryRepository) getQ(ctx context.Context) *sqlcdb.Queries {
+	if tx := getTx(ctx); tx != nil {
+		return r.Q.WithTx(tx)
+	}
+	return r.Q
+}
+
+// CreateIfAbsent inserts a delivery unless the endpoint already has one for the event.
+func (r *PostgresWebhookDeliveryRepository) CreateIfAbsent(ctx context.Context, d *webhook.Delivery) (bool, error) {
+	rows, err := r.getQ(ctx).CreateWebhookDelivery(ctx, sqlcdb.CreateWebhookDeliveryParams{
+		ID:            string(d.ID),
+		EndpointID:    string(d.EndpointID),
+		EventID:       d.EventID,
+		EventType:     string(d.EventType),
+		Payload:       d.Payload,
+		Status:        string(d.Status),
+		NextAttemptAt: d.NextAttemptAt,
+		CreatedAt:     d.CreatedAt,
+	})
+	if err != nil {
+		return false, fmt.Errorf("create webhook delivery: %w", err)
+	}
+	return rows == 1, nil
+}
+
+// FindByID fetches a delivery.
+func (r *PostgresWebhookDeliveryRepository) FindByID(ctx context.Context, id webhook.DeliveryID) (*webhook.Delivery, error) {
+	rec, err := r.getQ(ctx).GetWebhookDelivery(ctx, string(id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("get webhook delivery: %w", err)
+	}
+	return webhookDeliveryToDomain(rec), nil
+}
+
+// ListByEndpointID lists recent deliveries of an endpoint, newest first.
+func (r *PostgresWebhookDeliveryRepository) ListByEndpointID(ctx context.Context, id webhook.EndpointID, limit int) ([]*webhook.Delivery, error) {
+	recs, err := r.getQ(ctx).ListWebhookDeliveriesByEndpointID(ctx, sqlcdb.ListWebhookDeliveriesByEndpointIDParams{
+		EndpointID: string(id),
+		Limit:      int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list webhook deliveries: %w", err)
+	}
+	return webhookDeliveriesToDomain(recs), nil
+}
+
+// ClaimDue leases due deliveries with FOR UPDATE SKIP LOCKED.
+func (r *PostgresWebhookDeliveryRepository) ClaimDue(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*webhook.Delivery, error) {
+	recs, err := r.getQ(ctx).ClaimDueWebhookDeliveries(ctx, sqlcdb.ClaimDueWebhookDeliveriesParams{
+		LeaseUntil: leaseUntil,
+		Now:        now,
+		MaxRows:    int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("claim webhook deliveries: %w", err)
+	}
+	return webhookDeliveriesToDomain(recs), nil
+}
+
+// RecordAttempt inserts the attempt and updates the delivery. Call it inside a Tx.
+func (r *PostgresWebhookDeliveryRepository) RecordAttempt(ctx context.Context, d *webhook.Delivery, a *webhook.Attempt) error {
+	q := r.getQ(ctx)
+	if err := q.CreateWebhookDeliveryAttempt(ctx, sqlcdb.CreateWebhookDeliveryAttemptParams{
+		ID:          a.ID,
+		DeliveryID:  string(a.DeliveryID),
+		StatusCode:  int32(a.StatusCode),
+		Error:       a.Error,
+		DurationMs:  a.Duration.Milliseconds(),
+		AttemptedAt: a.AttemptedAt,
+	}); err != nil {
+		return fmt.Errorf("create webhook delivery attempt: %w", err)
+	}
+	if err := q.UpdateWebhookDeliveryAfterAttempt(ctx, sqlcdb.UpdateWebhookDeliveryAfterAttemptParams{
+		ID:             string(d.ID),
+		Status:         string(d.Status),
+		Attempts:       int32(d.Attempts),
+		NextAttemptAt:  d.NextAttemptAt,
+		LastStatusCode: int32(d.LastStatusCode),
+		LastError:      d.LastError,
+		UpdatedAt:      d.UpdatedAt,
+	}); err != nil {
+		return fmt.Errorf("update webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// ListAttempts lists attempts of a delivery, oldest first.
+func (r *PostgresWebhookDeliveryRepository) ListAttempts(ctx context.Context, id webhook.DeliveryID) ([]*webhook.Attempt, error) {
+	recs, err := r.getQ(ctx).ListWebhookDeliveryAttempts(ctx, string(id))
+	if err != nil {
+		return nil, fmt.Errorf("list webhook delivery attempts: %w", err)
+	}
+	as := make([]*webhook.Attempt, 0, len(recs))
+	for _, rec := range recs {
+		as = append(as, &webhook.Attempt{
+			ID:          rec.ID,
+			DeliveryID:  webhook.DeliveryID(rec.DeliveryID),
+			StatusCode:  int(rec.StatusCode),
+			Error:       rec.Error,
+			Duration:    time.Duration(rec.DurationMs) * time.Millisecond,
+			AttemptedAt: rec.AttemptedAt,
+		})
+	}
+	return as, nil
+}
+
+func webhookDeliveriesToDomain(recs []sqlcdb.WebhookDelivery) []*webhook.Delivery {
+	ds := make([]*webhook.Delivery, 0, len(recs))
+	for _, rec := range recs {
+		ds = append(ds, webhookDeliveryToDomain(rec))
+	}
+	return ds
+}
+
+func webhookDeliveryToDomain(rec sqlcdb.WebhookDelivery) *webhook.Delivery {
+	return &webhook.Delivery{
+		ID:             webhook.DeliveryID(rec.ID),
+		EndpointID:     webhook.EndpointID(rec.EndpointID),
+		EventID:        rec.EventID,
+		EventType:      webhook.EventType(rec.EventType),
+		Payload:        rec.Payload,
+		Status:         webhook.DeliveryStatus(rec.Status),
+		Attempts:       int(rec.Attempts),
+		NextAttemptAt:  rec.NextAttemptAt,
+		LastStatusCode: int(rec.LastStatusCode),
+		LastError:      rec.LastError,
+		CreatedAt:      rec.CreatedAt,
+		UpdatedAt:      rec.UpdatedAt,
+	}
+}
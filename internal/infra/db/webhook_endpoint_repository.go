@@ -0,0 +1,105 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/webhook"
+	sqlcdb "github.com/kazshi01/payment-system/internal/infra/db/sqlc"
+)
+
+// PostgresWebhookEndpointRepository implements domain.WebhookEndpointRepository using sqlc.
+type PostgresWebhookEndpointRepository struct {
+	DB *sql.DB
+	Q  *sqlcdb.Queries
+}
+
+func NewPostgresWebhookEndpointRepository(db *sql.DB) *PostgresWebhookEndpointRepository {
+	return &PostgresWebhookEndpointRepository{
+		DB: db,
+		Q:  sqlcdb.New(db),
+	}
+}
+
+// Tx が ctx に乗っていればその Tx にバインドした Queries を返す
+func (r *PostgresWebhookEndpointRepository) getQ(ctx context.Context) *sqlcdb.Queries {
+	if tx := getTx(ctx); tx != nil {
+		return r.Q.WithTx(tx)
+	}
+	return r.Q
+}
+
+// Create inserts a new endpoint.
+func (r *PostgresWebhookEndpointRepository) Create(ctx context.Context, e *webhook.Endpoint) error {
+	events := make([]string, 0, len(e.Events))
+	for _, t := range e.Events {
+		events = append(events, string(t))
+	}
+	if err := r.getQ(ctx).CreateWebhookEndpoint(ctx, sqlcdb.CreateWebhookEndpointParams{
+		ID:        string(e.ID),
+		UserID:    e.UserID,
+		Url:       e.URL,
+		Events:    events,
+		Secret:    e.Secret,
+		CreatedAt: e.CreatedAt,
+	}); err != nil {
+		return fmt.Errorf("create webhook endpoint: %w", err)
+	}
+	return nil
+}
+
+// FindByID fetches a live (not deleted) endpoint.
+func (r *PostgresWebhookEndpointRepository) FindByID(ctx context.Context, id webhook.EndpointID) (*webhook.Endpoint, error) {
+	rec, err := r.getQ(ctx).GetWebhookEndpoint(ctx, string(id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("get webhook endpoint: %w", err)
+	}
+	return webhookEndpointToDomain(rec), nil
+}
+
+// ListByUserID lists live endpoints of a user, oldest first.
+func (r *PostgresWebhookEndpointRepository) ListByUserID(ctx context.Context, userID string) ([]*webhook.Endpoint, error) {
+	recs, err := r.getQ(ctx).ListWebhookEndpointsByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list webhook endpoints: %w", err)
+	}
+	es := make([]*webhook.Endpoint, 0, len(recs))
+	for _, rec := range recs {
+		es = append(es, webhookEndpointToDomain(rec))
+	}
+	return es, nil
+}
+
+// Delete soft-deletes an endpoint. Past deliveries are kept for the log.
+func (r *PostgresWebhookEndpointRepository) Delete(ctx context.Context, id webhook.EndpointID, at time.Time) (int64, error) {
+	rows, err := r.getQ(ctx).DeleteWebhookEndpoint(ctx, sqlcdb.DeleteWebhookEndpointParams{
+		ID:        string(id),
+		DeletedAt: sql.NullTime{Time: at, Valid: true},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("delete webhook endpoint: %w", err)
+	}
+	return rows, nil
+}
+
+func webhookEndpointToDomain(rec sqlcdb.WebhookEndpoint) *webhook.Endpoint {
+	events := make([]webhook.EventType, 0, len(rec.Events))
+	for _, t := range rec.Events {
+		events = append(events, webhook.EventType(t))
+	}
+	return &webhook.Endpoint{
+		ID:        webhook.EndpointID(rec.ID),
+		UserID:    rec.UserID,
+		URL:       rec.Url,
+		Events:    events,
+		Secret:    rec.Secret,
+		CreatedAt: rec.CreatedAt,
+	}
+}
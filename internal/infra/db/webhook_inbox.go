@@ -0,0 +1,70 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+	sqlcdb "github.com/kazshi01/payment-system/internal/infra/db/sqlc"
+)
+
+// PostgresWebhookInbox implements domain.WebhookInbox using sqlc.
+type PostgresWebhookInbox struct {
+	DB *sql.DB
+	Q  *sqlcdb.Queries
+}
+
+func NewPostgresWebhookInbox(db *sql.DB) *PostgresWebhookInbox {
+	return &PostgresWebhookInbox{
+		DB: db,
+		Q:  sqlcdb.New(db),
+	}
+}
+
+// Tx が ctx に乗っていればその Tx にバインドした Queries を返す
+func (r *PostgresWebhookInbox) getQ(ctx context.Context) *sqlcdb.Queries {
+	if tx := getTx(ctx); tx != nil {
+		return r.Q.WithTx(tx)
+	}
+	return r.Q
+}
+
+// Receive records the event on first delivery and reports whether it was already processed.
+func (r *PostgresWebhookInbox) Receive(ctx context.Context, ev *domain.ProviderEvent, at time.Time) (bool, error) {
+	payload := json.RawMessage(ev.Payload)
+	if !json.Valid(payload) {
+		payload = json.RawMessage(`{}`)
+	}
+
+	q := r.getQ(ctx)
+	if err := q.InsertWebhookEvent(ctx, sqlcdb.InsertWebhookEventParams{
+		Provider:   ev.Provider,
+		EventID:    ev.ID,
+		Type:       ev.RawType,
+		Payload:    payload,
+		ReceivedAt: at,
+	}); err != nil {
+		return false, fmt.Errorf("insert webhook event: %w", err)
+	}
+
+	rec, err := q.GetWebhookEvent(ctx, sqlcdb.GetWebhookEventParams{Provider: ev.Provider, EventID: ev.ID})
+	if err != nil {
+		return false, fmt.Errorf("get webhook event: %w", err)
+	}
+	return rec.ProcessedAt.Valid, nil
+}
+
+// MarkProcessed sets processed_at once.
+func (r *PostgresWebhookInbox) MarkProcessed(ctx context.Context, provider, eventID string, at time.Time) error {
+	if err := r.getQ(ctx).MarkWebhookEventProcessed(ctx, sqlcdb.MarkWebhookEventProcessedParams{
+		Provider:    provider,
+		EventID:     eventID,
+		ProcessedAt: sql.NullTime{Time: at, Valid: true},
+	}); err != nil {
+		return fmt.Errorf("mark webhook event processed: %w", err)
+	}
+	return nil
+}
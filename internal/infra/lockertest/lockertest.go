@@ -0,0 +1,286 @@
+// Package lockertest は domain.Locker の実装が満たすべき振る舞いを検証する共通テスト
+package lockertest
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+)
+
+// Run は newLocker が返す Locker に対して共通テストを実行する
+// 期限切れの検証があるため、TTL の回収は 100ms 程度で行われる設定で渡すこと
+func Run(t *testing.T, newLocker func(t *testing.T) domain.Locker) {
+	t.Run("Exclusive", func(t *testing.T) { testExclusive(t, newLocker(t)) })
+	t.Run("UnlockRequiresToken", func(t *testing.T) { testUnlockRequiresToken(t, newLocker(t)) })
+	t.Run("FenceIncreases", func(t *testing.T) { testFenceIncreases(t, newLocker(t)) })
+	t.Run("Extend", func(t *testing.T) { testExtend(t, newLocker(t)) })
+	t.Run("Expires", func(t *testing.T) { testExpires(t, newLocker(t)) })
+	t.Run("Concurrent", func(t *testing.T) { testConcurrent(t, newLocker(t)) })
+	t.Run("ConcurrentExtend", func(t *testing.T) { testConcurrentExtend(t, newLocker(t)) })
+}
+
+// 実装間・テスト間で衝突しないキー
+func uniqueKey(t *testing.T) string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		t.Fatal(err)
+	}
+	return "lockertest:" + hex.EncodeToString(b)
+}
+
+func mustLock(t *testing.T, l domain.Locker, key string, ttl time.Duration) domain.Lock {
+	t.Helper()
+	ok, lock, err := l.TryLock(context.Background(), key, ttl)
+	if err != nil {
+		t.Fatalf("TryLock(%s): %v", key, err)
+	}
+	if !ok {
+		t.Fatalf("TryLock(%s) = false; want true", key)
+	}
+	return lock
+}
+
+func mustNotLock(t *testing.T, l domain.Locker, key string) {
+	t.Helper()
+	ok, _, err := l.TryLock(context.Background(), key, time.Minute)
+	if err != nil {
+		t.Fatalf("TryLock(%s): %v", key, err)
+	}
+	if ok {
+		t.Fatalf("TryLock(%s) = true; want false (held)", key)
+	}
+}
+
+func testExclusive(t *testing.T, l domain.Locker) {
+	ctx := context.Background()
+	key, other := uniqueKey(t), uniqueKey(t)
+
+	lock := mustLock(t, l, key, time.Minute)
+	if lock.Key != key || lock.Token == "" || lock.Fence <= 0 {
+		t.Fatalf("lock = %+v; want key, token and positive fence", lock)
+	}
+	mustNotLock(t, l, key)
+
+	// 別キーは独立
+	o := mustLock(t, l, other, time.Minute)
+
+	if err := l.Unlock(ctx, lock); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+	if err := l.Unlock(ctx, o); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+	relock := mustLock(t, l, key, time.Minute)
+	_ = l.Unlock(ctx, relock)
+}
+
+func testUnlockRequiresToken(t *testing.T, l domain.Locker) {
+	ctx := context.Background()
+	key := uniqueKey(t)
+
+	lock := mustLock(t, l, key, time.Minute)
+
+	forged := lock
+	forged.Token = "not-the-token"
+	if err := l.Unlock(ctx, forged); err != nil {
+		t.Fatalf("Unlock(forged): %v", err)
+	}
+	mustNotLock(t, l, key)
+
+	if err := l.Unlock(ctx, lock); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+	// 解放済みのロックを再度 Unlock しても新しい保持者には影響しない
+	next := mustLock(t, l, key, time.Minute)
+	if err := l.Unlock(ctx, lock); err != nil {
+		t.Fatalf("Unlock(stale): %v", err)
+	}
+	mustNotLock(t, l, key)
+	_ = l.Unlock(ctx, next)
+}
+
+func testFenceIncreases(t *testing.T, l domain.Locker) {
+	ctx := context.Background()
+	key := uniqueKey(t)
+
+	var prev int64
+	for i := 0; i < 3; i++ {
+		lock := mustLock(t, l, key, time.Minute)
+		if lock.Fence <= prev {
+			t.Fatalf("fence = %d after %d; want increasing", lock.Fence, prev)
+		}
+		prev = lock.Fence
+		if err := l.Unlock(ctx, lock); err != nil {
+			t.Fatalf("Unlock: %v", err)
+		}
+	}
+
+	// 別キーでも巻き戻らない
+	o := mustLock(t, l, uniqueKey(t), time.Minute)
+	if o.Fence <= prev {
+		t.Fatalf("fence = %d after %d; want increasing across keys", o.Fence, prev)
+	}
+	_ = l.Unlock(ctx, o)
+}
+
+func testExtend(t *testing.T, l domain.Locker) {
+	ctx := context.Background()
+	key := uniqueKey(t)
+
+	lock := mustLock(t, l, key, 300*time.Millisecond)
+
+	// TTL を過ぎても延長し続けていれば保持できる
+	for i := 0; i < 4; i++ {
+		time.Sleep(150 * time.Millisecond)
+		ok, err := l.Extend(ctx, lock, 300*time.Millisecond)
+		if err != nil {
+			t.Fatalf("Extend: %v", err)
+		}
+		if !ok {
+			t.Fatalf("Extend #%d = false; want true", i)
+		}
+	}
+	mustNotLock(t, l, key)
+
+	forged := lock
+	forged.Token = "not-the-token"
+	if ok, err := l.Extend(ctx, forged, time.Minute); err != nil || ok {
+		t.Fatalf("Extend(forged) = %v, %v; want false", ok, err)
+	}
+
+	if err := l.Unlock(ctx, lock); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+	if ok, err := l.Extend(ctx, lock, time.Minute); err != nil || ok {
+		t.Fatalf("Extend(after unlock) = %v, %v; want false", ok, err)
+	}
+}
+
+func testExpires(t *testing.T, l domain.Locker) {
+	ctx := context.Background()
+	key := uniqueKey(t)
+
+	lock := mustLock(t, l, key, 200*time.Millisecond)
+
+	// 延長しない保持者は TTL 後に失う
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		ok, next, err := l.TryLock(ctx, key, time.Minute)
+		if err != nil {
+			t.Fatalf("TryLock: %v", err)
+		}
+		if ok {
+			if next.Fence <= lock.Fence {
+				t.Fatalf("fence = %d after %d; want increasing", next.Fence, lock.Fence)
+			}
+			if ok, err := l.Extend(ctx, lock, time.Minute); err != nil || ok {
+				t.Fatalf("Extend(expired) = %v, %v; want false", ok, err)
+			}
+			_ = l.Unlock(ctx, next)
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("lock did not expire")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func testConcurrent(t *testing.T, l domain.Locker) {
+	ctx := context.Background()
+	key := uniqueKey(t)
+
+	const n = 16
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		won   []domain.Lock
+		start = make(chan struct{})
+	)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			ok, lock, err := l.TryLock(ctx, key, time.Minute)
+			if err != nil {
+				t.Errorf("TryLock: %v", err)
+				return
+			}
+			if ok {
+				mu.Lock()
+				won = append(won, lock)
+				mu.Unlock()
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	if len(won) != 1 {
+		t.Fatalf("winners = %d; want 1", len(won))
+	}
+	_ = l.Unlock(ctx, won[0])
+}
+
+// 保持中のロックの延長と、別キーの取得・解放（途中で諦める呼び出しを含む）が並行しても、
+// 保持者はロックを失わない
+func testConcurrentExtend(t *testing.T, l domain.Locker) {
+	ctx := context.Background()
+	key := uniqueKey(t)
+	lock := mustLock(t, l, key, time.Minute)
+
+	const n = 8
+	var (
+		wg   sync.WaitGroup
+		stop = make(chan struct{})
+	)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				cctx, cancel := context.WithCancel(ctx)
+				if i%2 == 1 {
+					cancel() // 呼び出し元が先に諦める
+				}
+				ok, other, err := l.TryLock(cctx, uniqueKey(t), time.Minute)
+				cancel()
+				if err == nil && ok {
+					if err := l.Unlock(ctx, other); err != nil {
+						t.Errorf("Unlock: %v", err)
+					}
+				} // 諦めた呼び出しのエラーは問わない
+			}
+		}(i)
+	}
+	stopAll := sync.OnceFunc(func() { close(stop); wg.Wait() })
+	defer stopAll()
+
+	for i := 0; i < 20; i++ {
+		ok, err := l.Extend(ctx, lock, time.Minute)
+		if err != nil {
+			t.Fatalf("Extend: %v", err)
+		}
+		if !ok {
+			t.Fatalf("Extend #%d = false; want true", i)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	stopAll()
+
+	mustNotLock(t, l, key)
+	if err := l.Unlock(ctx, lock); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+}
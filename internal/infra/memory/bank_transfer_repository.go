@@ -0,0 +1,142 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/banktransfer"
+	"github.com/kazshi01/payment-system/internal/domain/money"
+	"github.com/kazshi01/payment-system/internal/domain/order"
+)
+
+// BankTransferRepository implements domain.BankTransferRepository.
+type BankTransferRepository struct{ s *Store }
+
+func NewBankTransferRepository(s *Store) *BankTransferRepository {
+	return &BankTransferRepository{s: s}
+}
+
+func (r *BankTransferRepository) Create(ctx context.Context, bt *banktransfer.Transfer) error {
+	return r.s.update(ctx, func(t *tx) error {
+		if _, ok := t.d.transfers[bt.ID]; ok {
+			return fmt.Errorf("create bank transfer %s: %w", bt.ID, domain.ErrConflict)
+		}
+		// uq_bank_transfers_order / uq_bank_transfers_destination
+		for _, x := range t.d.transfers {
+			sameDest := x.v.BranchCode == bt.BranchCode && x.v.AccountNumber == bt.AccountNumber && x.v.ReferenceCode == bt.ReferenceCode
+			if x.v.OrderID == bt.OrderID || sameDest {
+				return fmt.Errorf("create bank transfer for order %s: %w", bt.OrderID, domain.ErrConflict)
+			}
+		}
+		own(t, &t.d.transfers)
+		t.d.transfers[bt.ID] = row[banktransfer.Transfer]{v: *bt, seq: t.nextSeq()}
+		return nil
+	})
+}
+
+func (r *BankTransferRepository) find(ctx context.Context, match func(*banktransfer.Transfer) bool) (*banktransfer.Transfer, error) {
+	var out *banktransfer.Transfer
+	err := r.s.view(ctx, func(d *data) error {
+		for _, x := range d.transfers {
+			if match(&x.v) {
+				v := x.v
+				out = &v
+				return nil
+			}
+		}
+		return domain.ErrNotFound
+	})
+	return out, err
+}
+
+func (r *BankTransferRepository) FindByOrderID(ctx context.Context, orderID order.ID) (*banktransfer.Transfer, error) {
+	return r.find(ctx, func(bt *banktransfer.Transfer) bool { return bt.OrderID == string(orderID) })
+}
+
+func (r *BankTransferRepository) FindByDestination(ctx context.Context, branchCode, accountNumber, referenceCode string) (*banktransfer.Transfer, error) {
+	return r.find(ctx, func(bt *banktransfer.Transfer) bool {
+		return bt.BranchCode == branchCode && bt.AccountNumber == accountNumber && bt.ReferenceCode == referenceCode
+	})
+}
+
+func (r *BankTransferRepository) ListAwaitingByPayer(ctx context.Context, payerName string, amount money.Money, limit int) ([]*banktransfer.Transfer, error) {
+	var rows []row[banktransfer.Transfer]
+	err := r.s.view(ctx, func(d *data) error {
+		for _, x := range d.transfers {
+			if x.v.Status == banktransfer.TransferAwaiting && x.v.PayerName != "" && x.v.PayerName == payerName && x.v.Amount == amount {
+				rows = append(rows, x)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := sortedValues(rows, func(bt *banktransfer.Transfer) time.Time { return bt.CreatedAt })
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (r *BankTransferRepository) UpdateStatusIf(ctx context.Context, id string, from, to banktransfer.TransferStatus, updatedAt time.Time) (int64, error) {
+	var n int64
+	err := r.s.update(ctx, func(t *tx) error {
+		x, ok := t.d.transfers[id]
+		if !ok || x.v.Status != from {
+			return nil
+		}
+		own(t, &t.d.transfers)
+		x.v.Status = to
+		x.v.UpdatedAt = updatedAt
+		t.d.transfers[id] = x
+		n = 1
+		return nil
+	})
+	return n, err
+}
+
+func (r *BankTransferRepository) CreateDepositIfAbsent(ctx context.Context, dep *banktransfer.Deposit) (bool, error) {
+	var created bool
+	err := r.s.update(ctx, func(t *tx) error {
+		// uq_bank_deposits_entry
+		for _, x := range t.d.deposits {
+			if x.v.BranchCode == dep.BranchCode && x.v.AccountNumber == dep.AccountNumber &&
+				x.v.ValueDate.Equal(dep.ValueDate) && x.v.InquiryNo == dep.InquiryNo {
+				return nil
+			}
+		}
+		if _, ok := t.d.deposits[dep.ID]; ok {
+			return fmt.Errorf("create bank deposit %s: %w", dep.ID, domain.ErrConflict)
+		}
+		own(t, &t.d.deposits)
+		t.d.deposits[dep.ID] = row[banktransfer.Deposit]{v: *dep, seq: t.nextSeq()}
+		created = true
+		return nil
+	})
+	return created, err
+}
+
+func (r *BankTransferRepository) ListDeposits(ctx context.Context, status banktransfer.DepositStatus, limit int) ([]*banktransfer.Deposit, error) {
+	var rows []row[banktransfer.Deposit]
+	err := r.s.view(ctx, func(d *data) error {
+		for _, x := range d.deposits {
+			if status == "" || x.v.Status == status {
+				rows = append(rows, x)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := sortedValues(rows, func(dep *banktransfer.Deposit) time.Time { return dep.CreatedAt })
+	slices.Reverse(out) // 新しい順
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
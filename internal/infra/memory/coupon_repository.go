@@ -0,0 +1,124 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/coupon"
+	"github.com/kazshi01/payment-system/internal/domain/order"
+)
+
+// CouponRepository implements domain.CouponRepository.
+type CouponRepository struct{ s *Store }
+
+func NewCouponRepository(s *Store) *CouponRepository { return &CouponRepository{s: s} }
+
+func (r *CouponRepository) Create(ctx context.Context, c *coupon.Coupon) error {
+	return r.s.update(ctx, func(t *tx) error {
+		if _, ok := t.d.coupons[c.ID]; ok {
+			return fmt.Errorf("create coupon %s: %w", c.ID, domain.ErrConflict)
+		}
+		if _, ok := findCoupon(t.d, c.Code); ok {
+			return fmt.Errorf("create coupon %s: %w", c.Code, coupon.ErrCodeTaken)
+		}
+		own(t, &t.d.coupons)
+		row := *c
+		row.Redeemed = 0
+		t.d.coupons[c.ID] = row
+		return nil
+	})
+}
+
+func (r *CouponRepository) FindByCode(ctx context.Context, code string) (*coupon.Coupon, error) {
+	var out *coupon.Coupon
+	err := r.s.view(ctx, func(d *data) error {
+		c, ok := findCoupon(d, code)
+		if !ok {
+			return domain.ErrNotFound
+		}
+		out = &c
+		return nil
+	})
+	return out, err
+}
+
+// Tx は直列に実行されるため、確認と記録の間に他の利用は割り込まない
+func (r *CouponRepository) Redeem(ctx context.Context, red *coupon.Redemption) error {
+	return r.s.update(ctx, func(t *tx) error {
+		c, ok := t.d.coupons[red.CouponID]
+		if !ok {
+			return fmt.Errorf("redeem coupon: coupon %s not found", red.CouponID)
+		}
+		if _, ok := t.d.orders[order.ID(red.OrderID)]; !ok {
+			return fmt.Errorf("redeem coupon: order %s not found", red.OrderID)
+		}
+		if _, ok := t.d.redeemed[order.ID(red.OrderID)]; ok {
+			return fmt.Errorf("redeem coupon for order %s: %w", red.OrderID, domain.ErrConflict)
+		}
+
+		if c.MaxRedemptions > 0 && c.Redeemed >= c.MaxRedemptions {
+			return fmt.Errorf("redeem coupon %s: %w", c.Code, coupon.ErrUsageLimit)
+		}
+		if c.MaxPerUser > 0 {
+			var n int64
+			for _, x := range t.d.redeemed {
+				if x.CouponID == c.ID && x.UserID == red.UserID {
+					n++
+				}
+			}
+			if n >= c.MaxPerUser {
+				return fmt.Errorf("redeem coupon %s for user: %w", c.Code, coupon.ErrUsageLimit)
+			}
+		}
+
+		own(t, &t.d.coupons)
+		own(t, &t.d.redeemed)
+		c.Redeemed++
+		t.d.coupons[c.ID] = c
+		row := *red
+		row.Code = c.Code
+		t.d.redeemed[order.ID(red.OrderID)] = row
+		return nil
+	})
+}
+
+func (r *CouponRepository) Release(ctx context.Context, orderID order.ID) error {
+	return r.s.update(ctx, func(t *tx) error {
+		red, ok := t.d.redeemed[orderID]
+		if !ok {
+			return nil
+		}
+		own(t, &t.d.coupons)
+		own(t, &t.d.redeemed)
+		delete(t.d.redeemed, orderID)
+		if c, ok := t.d.coupons[red.CouponID]; ok && c.Redeemed > 0 {
+			c.Redeemed--
+			t.d.coupons[c.ID] = c
+		}
+		return nil
+	})
+}
+
+func (r *CouponRepository) FindRedemptionByOrderID(ctx context.Context, orderID order.ID) (*coupon.Redemption, error) {
+	var out *coupon.Redemption
+	err := r.s.view(ctx, func(d *data) error {
+		red, ok := d.redeemed[orderID]
+		if !ok {
+			return domain.ErrNotFound
+		}
+		out = &red
+		return nil
+	})
+	return out, err
+}
+
+// coupons.code の一意索引の代わり
+func findCoupon(d *data, code string) (coupon.Coupon, bool) {
+	for _, c := range d.coupons {
+		if c.Code == code {
+			return c, true
+		}
+	}
+	return coupon.Coupon{}, false
+}
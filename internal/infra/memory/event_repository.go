@@ -0,0 +1,75 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/event"
+	"github.com/kazshi01/payment-system/internal/domain/order"
+)
+
+// EventRepository implements domain.EventRepository.
+type EventRepository struct{ s *Store }
+
+func NewEventRepository(s *Store) *EventRepository { return &EventRepository{s: s} }
+
+func (r *EventRepository) Append(ctx context.Context, e *event.Event) error {
+	return r.s.update(ctx, func(t *tx) error {
+		if _, ok := t.d.events[e.ID]; ok {
+			return fmt.Errorf("append event %s: %w", e.ID, domain.ErrConflict)
+		}
+		own(t, &t.d.events)
+		t.d.events[e.ID] = row[event.Event]{v: *e, seq: t.nextSeq()}
+		return nil
+	})
+}
+
+func (r *EventRepository) ListByOrderID(ctx context.Context, orderID order.ID) ([]*event.Event, error) {
+	var rows []row[event.Event]
+	err := r.s.view(ctx, func(d *data) error {
+		for _, x := range d.events {
+			if x.v.OrderID == string(orderID) {
+				rows = append(rows, x)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sortedValues(rows, func(e *event.Event) time.Time { return e.CreatedAt }), nil
+}
+
+// WebhookInbox implements domain.WebhookInbox.
+type WebhookInbox struct{ s *Store }
+
+func NewWebhookInbox(s *Store) *WebhookInbox { return &WebhookInbox{s: s} }
+
+func (r *WebhookInbox) Receive(ctx context.Context, ev *domain.ProviderEvent, at time.Time) (bool, error) {
+	var processed bool
+	err := r.s.update(ctx, func(t *tx) error {
+		k := inboxKey{provider: ev.Provider, eventID: ev.ID}
+		if x, ok := t.d.inbox[k]; ok {
+			processed = x.processed
+			return nil
+		}
+		own(t, &t.d.inbox)
+		t.d.inbox[k] = inboxRow{}
+		return nil
+	})
+	return processed, err
+}
+
+func (r *WebhookInbox) MarkProcessed(ctx context.Context, provider, eventID string, at time.Time) error {
+	return r.s.update(ctx, func(t *tx) error {
+		k := inboxKey{provider: provider, eventID: eventID}
+		if _, ok := t.d.inbox[k]; !ok {
+			return nil
+		}
+		own(t, &t.d.inbox)
+		t.d.inbox[k] = inboxRow{processed: true}
+		return nil
+	})
+}
@@ -0,0 +1,94 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+)
+
+// IdempotencyStore implements domain.IdempotencyStore.
+type IdempotencyStore struct{ s *Store }
+
+func NewIdempotencyStore(s *Store) *IdempotencyStore { return &IdempotencyStore{s: s} }
+
+func (r *IdempotencyStore) Reserve(ctx context.Context, rec *domain.IdempotencyRecord) (*domain.IdempotencyRecord, error) {
+	var existing *domain.IdempotencyRecord
+	err := r.s.update(ctx, func(t *tx) error {
+		k := idemKey{userID: rec.UserID, key: rec.Key}
+		// 期限切れのキーは上書きして確保する
+		if x, ok := t.d.idem[k]; ok && x.ExpiresAt.After(rec.CreatedAt) {
+			existing = &x
+			return nil
+		}
+		own(t, &t.d.idem)
+		t.d.idem[k] = domain.IdempotencyRecord{
+			UserID:      rec.UserID,
+			Key:         rec.Key,
+			Fingerprint: rec.Fingerprint,
+			CreatedAt:   rec.CreatedAt,
+			ExpiresAt:   rec.ExpiresAt,
+		}
+		return nil
+	})
+	return existing, err
+}
+
+func (r *IdempotencyStore) Complete(ctx context.Context, rec *domain.IdempotencyRecord) error {
+	return r.s.update(ctx, func(t *tx) error {
+		k := idemKey{userID: rec.UserID, key: rec.Key}
+		x, ok := t.d.idem[k]
+		if !ok || x.Completed() {
+			return domain.ErrConflict
+		}
+		own(t, &t.d.idem)
+		x.StatusCode = rec.StatusCode
+		x.Header = rec.Header
+		x.Body = rec.Body
+		t.d.idem[k] = x
+		return nil
+	})
+}
+
+func (r *IdempotencyStore) Release(ctx context.Context, userID, key string) error {
+	return r.s.update(ctx, func(t *tx) error {
+		k := idemKey{userID: userID, key: key}
+		if x, ok := t.d.idem[k]; !ok || x.Completed() {
+			return nil
+		}
+		own(t, &t.d.idem)
+		delete(t.d.idem, k)
+		return nil
+	})
+}
+
+func (r *IdempotencyStore) DeleteExpired(ctx context.Context, now time.Time, limit int) (int64, error) {
+	var n int64
+	err := r.s.update(ctx, func(t *tx) error {
+		var expired []domain.IdempotencyRecord
+		for _, x := range t.d.idem {
+			if !x.ExpiresAt.After(now) {
+				expired = append(expired, x)
+			}
+		}
+		if len(expired) == 0 {
+			return nil
+		}
+		slices.SortFunc(expired, func(a, b domain.IdempotencyRecord) int {
+			return cmp.Compare(a.ExpiresAt.UnixNano(), b.ExpiresAt.UnixNano())
+		})
+		if len(expired) > limit {
+			expired = expired[:limit]
+		}
+
+		own(t, &t.d.idem)
+		for _, x := range expired {
+			delete(t.d.idem, idemKey{userID: x.UserID, key: x.Key})
+		}
+		n = int64(len(expired))
+		return nil
+	})
+	return n, err
+}
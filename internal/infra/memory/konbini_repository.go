@@ -0,0 +1,88 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/order"
+	"github.com/kazshi01/payment-system/internal/domain/payment"
+)
+
+// KonbiniRepository implements domain.KonbiniRepository.
+type KonbiniRepository struct{ s *Store }
+
+func NewKonbiniRepository(s *Store) *KonbiniRepository {
+	return &KonbiniRepository{s: s}
+}
+
+func (r *KonbiniRepository) Create(ctx context.Context, k *payment.Konbini) error {
+	return r.s.update(ctx, func(t *tx) error {
+		if _, ok := t.d.konbinis[k.ID]; ok {
+			return fmt.Errorf("create konbini payment %s: %w", k.ID, domain.ErrConflict)
+		}
+		// uq_konbini_payments_order_awaiting / uq_konbini_payments_provider_payment
+		for _, x := range t.d.konbinis {
+			awaiting := k.Status == payment.KonbiniAwaiting && x.v.OrderID == k.OrderID && x.v.Status == payment.KonbiniAwaiting
+			if awaiting || (x.v.Provider == k.Provider && x.v.ProviderPaymentID == k.ProviderPaymentID) {
+				return fmt.Errorf("create konbini payment for order %s: %w", k.OrderID, domain.ErrConflict)
+			}
+		}
+		own(t, &t.d.konbinis)
+		t.d.konbinis[k.ID] = row[payment.Konbini]{v: *k, seq: t.nextSeq()}
+		return nil
+	})
+}
+
+func (r *KonbiniRepository) FindAwaitingByOrderID(ctx context.Context, orderID order.ID) (*payment.Konbini, error) {
+	var out *payment.Konbini
+	err := r.s.view(ctx, func(d *data) error {
+		for _, x := range d.konbinis {
+			if x.v.OrderID == string(orderID) && x.v.Status == payment.KonbiniAwaiting {
+				k := x.v
+				out = &k
+				return nil
+			}
+		}
+		return domain.ErrNotFound
+	})
+	return out, err
+}
+
+func (r *KonbiniRepository) UpdateStatusIf(ctx context.Context, id payment.KonbiniID, from, to payment.KonbiniStatus, updatedAt time.Time) (int64, error) {
+	var n int64
+	err := r.s.update(ctx, func(t *tx) error {
+		x, ok := t.d.konbinis[id]
+		if !ok || x.v.Status != from {
+			return nil
+		}
+		own(t, &t.d.konbinis)
+		x.v.Status = to
+		x.v.UpdatedAt = updatedAt
+		t.d.konbinis[id] = x
+		n = 1
+		return nil
+	})
+	return n, err
+}
+
+func (r *KonbiniRepository) ListExpired(ctx context.Context, now time.Time, limit int) ([]*payment.Konbini, error) {
+	var rows []row[payment.Konbini]
+	err := r.s.view(ctx, func(d *data) error {
+		for _, x := range d.konbinis {
+			if x.v.Status == payment.KonbiniAwaiting && !x.v.ExpiresAt.After(now) {
+				rows = append(rows, x)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := sortedValues(rows, func(k *payment.Konbini) time.Time { return k.ExpiresAt })
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
@@ -0,0 +1,146 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/ledger"
+	"github.com/kazshi01/payment-system/internal/domain/money"
+	"github.com/kazshi01/payment-system/internal/domain/order"
+)
+
+// LedgerRepository implements domain.LedgerRepository.
+type LedgerRepository struct{ s *Store }
+
+func NewLedgerRepository(s *Store) *LedgerRepository { return &LedgerRepository{s: s} }
+
+func (r *LedgerRepository) Record(ctx context.Context, e *ledger.Entry) error {
+	if err := e.Validate(); err != nil {
+		return fmt.Errorf("%w: %w", domain.ErrInvalidArgument, err)
+	}
+	return r.s.update(ctx, func(t *tx) error {
+		if _, ok := t.d.orders[order.ID(e.OrderID)]; !ok {
+			return fmt.Errorf("create journal entry: order %s not found", e.OrderID)
+		}
+		if _, ok := t.d.journal[e.ID]; ok {
+			return fmt.Errorf("create journal entry: duplicate id %s", e.ID)
+		}
+		for _, x := range t.d.journal {
+			// UNIQUE (kind, reference)
+			if x.v.Kind == e.Kind && x.v.Reference == e.Reference {
+				return fmt.Errorf("%w: %s entry for %s is already recorded", domain.ErrConflict, e.Kind, e.Reference)
+			}
+		}
+		own(t, &t.d.journal)
+		v := *e
+		v.Postings = slices.Clone(e.Postings)
+		t.d.journal[e.ID] = row[ledger.Entry]{v: v, seq: t.nextSeq()}
+		return nil
+	})
+}
+
+func (r *LedgerRepository) Balance(ctx context.Context, account ledger.Account, c money.Currency, from, to time.Time) (*ledger.Balance, error) {
+	b := &ledger.Balance{Account: account, Currency: c}
+	err := r.s.view(ctx, func(d *data) error {
+		for _, x := range d.journal {
+			at := x.v.CreatedAt
+			if (!from.IsZero() && at.Before(from)) || (!to.IsZero() && !at.Before(to)) {
+				continue
+			}
+			for _, p := range x.v.Postings {
+				if p.Account != account || p.Amount.Currency != c {
+					continue
+				}
+				if p.Amount.Amount > 0 {
+					b.Debit += p.Amount.Amount
+				} else {
+					b.Credit -= p.Amount.Amount
+				}
+			}
+		}
+		return nil
+	})
+	return b, err
+}
+
+// Record で検証するので通常は空。Postgres 実装と同じ検査をしておく
+func (r *LedgerRepository) ListUnbalancedEntries(ctx context.Context, limit int) ([]ledger.Violation, error) {
+	var out []ledger.Violation
+	err := r.s.view(ctx, func(d *data) error {
+		for _, x := range d.journal {
+			if len(x.v.Postings) == 0 {
+				out = append(out, ledger.UnbalancedEntry(x.v.ID, x.v.OrderID, money.Money{}, 0))
+				continue
+			}
+			sums := map[money.Currency]int64{}
+			counts := map[money.Currency]int64{}
+			for _, p := range x.v.Postings {
+				sums[p.Amount.Currency] += p.Amount.Amount
+				counts[p.Amount.Currency]++
+			}
+			for c, sum := range sums {
+				if sum != 0 || counts[c] < 2 {
+					out = append(out, ledger.UnbalancedEntry(x.v.ID, x.v.OrderID, money.Money{Amount: sum, Currency: c}, counts[c]))
+				}
+			}
+		}
+		return nil
+	})
+	slices.SortFunc(out, func(a, b ledger.Violation) int { return strings.Compare(a.EntryID, b.EntryID) })
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, err
+}
+
+func (r *LedgerRepository) ListUnpostedOrders(ctx context.Context, limit int) ([]ledger.Violation, error) {
+	var out []ledger.Violation
+	err := r.s.view(ctx, func(d *data) error {
+		type sums struct{ paid, charged, refunded, refundPosted int64 }
+		by := map[string]*sums{}
+		get := func(id string) *sums {
+			if by[id] == nil {
+				by[id] = &sums{}
+			}
+			return by[id]
+		}
+		for _, x := range d.payments {
+			get(x.v.OrderID).paid += x.v.Amount.Amount
+		}
+		for _, x := range d.refunds {
+			get(x.v.OrderID).refunded += x.v.Amount.Amount
+		}
+		for _, x := range d.journal {
+			for _, p := range x.v.Postings {
+				switch {
+				case x.v.Kind == ledger.KindCharge && (p.Account == ledger.AccountProviderReceivable || p.Account == ledger.AccountBank):
+					get(x.v.OrderID).charged += p.Amount.Amount
+				case x.v.Kind == ledger.KindRefund && p.Account == ledger.AccountSalesRefunds:
+					get(x.v.OrderID).refundPosted += p.Amount.Amount
+				}
+			}
+		}
+
+		for id, o := range d.orders {
+			switch o.o.Status {
+			case order.StatusPaid, order.StatusPartiallyRefunded, order.StatusRefunded:
+			default:
+				continue
+			}
+			s := get(string(id))
+			if s.charged == 0 || s.paid != s.charged || s.refunded != s.refundPosted {
+				out = append(out, ledger.UnpostedOrder(string(id), string(o.o.Status), s.paid, s.charged, s.refunded, s.refundPosted))
+			}
+		}
+		return nil
+	})
+	slices.SortFunc(out, func(a, b ledger.Violation) int { return strings.Compare(a.OrderID, b.OrderID) })
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, err
+}
@@ -0,0 +1,75 @@
+package memory
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+)
+
+// Locker implements domain.Locker within a single process.
+// 期限切れのロックは次の TryLock / Extend で失効として扱う（回収処理は不要）
+type Locker struct {
+	mu    sync.Mutex
+	held  map[string]lockEntry
+	fence int64
+}
+
+type lockEntry struct {
+	token     string
+	expiresAt time.Time
+}
+
+func NewLocker() *Locker {
+	return &Locker{held: map[string]lockEntry{}}
+}
+
+func (l *Locker) TryLock(ctx context.Context, key string, ttl time.Duration) (bool, domain.Lock, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return false, domain.Lock{}, err
+	}
+	token := hex.EncodeToString(b)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if e, ok := l.held[key]; ok && now.Before(e.expiresAt) {
+		return false, domain.Lock{}, nil
+	}
+	l.fence++
+	l.held[key] = lockEntry{token: token, expiresAt: now.Add(ttl)}
+	return true, domain.Lock{Key: key, Token: token, Fence: l.fence}, nil
+}
+
+func (l *Locker) Extend(ctx context.Context, lock domain.Lock, ttl time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	e, ok := l.held[lock.Key]
+	if !ok || e.token != lock.Token || !now.Before(e.expiresAt) {
+		return false, nil
+	}
+	e.expiresAt = now.Add(ttl)
+	l.held[lock.Key] = e
+	return true, nil
+}
+
+func (l *Locker) Unlock(ctx context.Context, lock domain.Lock) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if e, ok := l.held[lock.Key]; ok && e.token == lock.Token {
+		delete(l.held, lock.Key)
+	}
+	return nil
+}
+
+func (l *Locker) Ping(ctx context.Context) error { return nil }
+
+func (l *Locker) Close() error { return nil }
@@ -0,0 +1,13 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/infra/lockertest"
+	"github.com/kazshi01/payment-system/internal/infra/memory"
+)
+
+func TestLocker_conformance(t *testing.T) {
+	lockertest.Run(t, func(t *testing.T) domain.Locker { return memory.NewLocker() })
+}
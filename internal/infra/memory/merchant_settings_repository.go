@@ -0,0 +1,36 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/merchant"
+)
+
+// MerchantSettingsRepository implements domain.MerchantSettingsRepository.
+type MerchantSettingsRepository struct{ s *Store }
+
+func NewMerchantSettingsRepository(s *Store) *MerchantSettingsRepository {
+	return &MerchantSettingsRepository{s: s}
+}
+
+func (r *MerchantSettingsRepository) Get(ctx context.Context, userID string) (*merchant.Settings, error) {
+	var out *merchant.Settings
+	err := r.s.view(ctx, func(d *data) error {
+		ms, ok := d.merchants[userID]
+		if !ok {
+			return domain.ErrNotFound
+		}
+		out = &ms
+		return nil
+	})
+	return out, err
+}
+
+func (r *MerchantSettingsRepository) Upsert(ctx context.Context, ms *merchant.Settings) error {
+	return r.s.update(ctx, func(t *tx) error {
+		own(t, &t.d.merchants)
+		t.d.merchants[ms.UserID] = *ms
+		return nil
+	})
+}
@@ -0,0 +1,203 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/order"
+	"github.com/kazshi01/payment-system/internal/domain/tax"
+)
+
+// OrderRepository implements domain.OrderRepository.
+type OrderRepository struct{ s *Store }
+
+func NewOrderRepository(s *Store) *OrderRepository { return &OrderRepository{s: s} }
+
+func (r *OrderRepository) Create(ctx context.Context, o *order.Order) error {
+	return r.s.update(ctx, func(t *tx) error {
+		if _, ok := t.d.orders[o.ID]; ok {
+			return fmt.Errorf("create order %s: %w", o.ID, domain.ErrConflict)
+		}
+		own(t, &t.d.orders)
+		row := orderRow{o: *o}
+		row.o.Items = nil // 明細・税内訳は別リポジトリに保存する
+		row.o.Tax = nil
+		t.d.orders[o.ID] = row
+		return nil
+	})
+}
+
+func (r *OrderRepository) FindByID(ctx context.Context, id order.ID) (*order.Order, error) {
+	var out *order.Order
+	err := r.s.view(ctx, func(d *data) error {
+		row, ok := d.orders[id]
+		if !ok {
+			return domain.ErrNotFound
+		}
+		o := row.o
+		out = &o
+		return nil
+	})
+	return out, err
+}
+
+func (r *OrderRepository) FindByIDForUser(ctx context.Context, id order.ID, userID string) (*order.Order, error) {
+	o, err := r.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if o.UserID != userID {
+		return nil, domain.ErrNotFound
+	}
+	return o, nil
+}
+
+func (r *OrderRepository) Update(ctx context.Context, o *order.Order) error {
+	return r.s.update(ctx, func(t *tx) error {
+		row, ok := t.d.orders[o.ID]
+		if !ok {
+			return nil // UPDATE 0 件と同じ
+		}
+		own(t, &t.d.orders)
+		row.o.Amount = o.Amount
+		row.o.Status = o.Status
+		row.o.UpdatedAt = o.UpdatedAt
+		t.d.orders[o.ID] = row
+		return nil
+	})
+}
+
+func (r *OrderRepository) UpdateStatusIf(ctx context.Context, id order.ID, from, to order.Status, fence int64, updatedAt time.Time) (int64, error) {
+	var n int64
+	err := r.s.update(ctx, func(t *tx) error {
+		row, ok := t.d.orders[id]
+		if !ok || row.o.Status != from {
+			return nil
+		}
+		if fence != 0 && row.fence > fence {
+			return nil
+		}
+		own(t, &t.d.orders)
+		row.o.Status = to
+		row.o.UpdatedAt = updatedAt
+		row.fence = max(row.fence, fence)
+		t.d.orders[id] = row
+		n = 1
+		return nil
+	})
+	return n, err
+}
+
+func (r *OrderRepository) List(ctx context.Context, f domain.OrderListFilter) ([]*order.Order, error) {
+	out := []*order.Order{}
+	err := r.s.view(ctx, func(d *data) error {
+		for _, row := range d.orders {
+			o := row.o
+			if f.UserID != "" && o.UserID != f.UserID {
+				continue
+			}
+			if f.Status != "" && o.Status != f.Status {
+				continue
+			}
+			if !f.CreatedFrom.IsZero() && o.CreatedAt.Before(f.CreatedFrom) {
+				continue
+			}
+			if !f.CreatedTo.IsZero() && !o.CreatedAt.Before(f.CreatedTo) {
+				continue
+			}
+			// (created_at, id) < (after.created_at, after.id)
+			if f.After != nil && compareOrderKey(&o, f.After.CreatedAt, f.After.ID) >= 0 {
+				continue
+			}
+			out = append(out, &o)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// created_at DESC, id DESC
+	slices.SortFunc(out, func(a, b *order.Order) int {
+		return -compareOrderKey(a, b.CreatedAt, b.ID)
+	})
+	if len(out) > f.Limit {
+		out = out[:f.Limit]
+	}
+	return out, nil
+}
+
+func compareOrderKey(o *order.Order, createdAt time.Time, id order.ID) int {
+	if c := o.CreatedAt.Compare(createdAt); c != 0 {
+		return c
+	}
+	return cmp.Compare(o.ID, id)
+}
+
+// OrderItemRepository implements domain.OrderItemRepository.
+type OrderItemRepository struct{ s *Store }
+
+func NewOrderItemRepository(s *Store) *OrderItemRepository { return &OrderItemRepository{s: s} }
+
+func (r *OrderItemRepository) CreateBatch(ctx context.Context, orderID order.ID, items []order.Item) error {
+	return r.s.update(ctx, func(t *tx) error {
+		// order_items の外部キーと主キー
+		if _, ok := t.d.orders[orderID]; !ok {
+			return fmt.Errorf("create order items: order %s not found", orderID)
+		}
+		if len(t.d.items[orderID]) > 0 {
+			return fmt.Errorf("create order items %s: %w", orderID, domain.ErrConflict)
+		}
+		own(t, &t.d.items)
+		t.d.items[orderID] = slices.Clone(items)
+		return nil
+	})
+}
+
+func (r *OrderItemRepository) ListByOrderID(ctx context.Context, orderID order.ID) ([]order.Item, error) {
+	var out []order.Item
+	err := r.s.view(ctx, func(d *data) error {
+		out = slices.Clone(d.items[orderID])
+		return nil
+	})
+	if out == nil {
+		out = []order.Item{}
+	}
+	return out, err
+}
+
+// OrderTaxRepository implements domain.OrderTaxRepository.
+type OrderTaxRepository struct{ s *Store }
+
+func NewOrderTaxRepository(s *Store) *OrderTaxRepository { return &OrderTaxRepository{s: s} }
+
+func (r *OrderTaxRepository) CreateBatch(ctx context.Context, orderID order.ID, buckets []tax.Bucket) error {
+	return r.s.update(ctx, func(t *tx) error {
+		if _, ok := t.d.orders[orderID]; !ok {
+			return fmt.Errorf("create order tax lines: order %s not found", orderID)
+		}
+		if len(t.d.taxLines[orderID]) > 0 {
+			return fmt.Errorf("create order tax lines %s: %w", orderID, domain.ErrConflict)
+		}
+		own(t, &t.d.taxLines)
+		t.d.taxLines[orderID] = slices.Clone(buckets)
+		return nil
+	})
+}
+
+func (r *OrderTaxRepository) ListByOrderID(ctx context.Context, orderID order.ID) ([]tax.Bucket, error) {
+	var out []tax.Bucket
+	err := r.s.view(ctx, func(d *data) error {
+		out = slices.Clone(d.taxLines[orderID])
+		return nil
+	})
+	if out == nil {
+		out = []tax.Bucket{}
+	}
+	slices.SortFunc(out, func(a, b tax.Bucket) int { return cmp.Compare(b.Rate, a.Rate) })
+	return out, err
+}
@@ -0,0 +1,237 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/order"
+	"github.com/kazshi01/payment-system/internal/domain/payment"
+	"github.com/kazshi01/payment-system/internal/domain/refund"
+)
+
+// 行を (時刻, 挿入順) の昇順に並べて値を取り出す
+func sortedValues[T any](rows []row[T], at func(*T) time.Time) []*T {
+	slices.SortFunc(rows, func(a, b row[T]) int {
+		if c := at(&a.v).Compare(at(&b.v)); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.seq, b.seq)
+	})
+	out := make([]*T, 0, len(rows))
+	for i := range rows {
+		out = append(out, &rows[i].v)
+	}
+	return out
+}
+
+// PaymentRepository implements domain.PaymentRepository.
+type PaymentRepository struct{ s *Store }
+
+func NewPaymentRepository(s *Store) *PaymentRepository { return &PaymentRepository{s: s} }
+
+func (r *PaymentRepository) Create(ctx context.Context, p *payment.Payment) error {
+	return r.s.update(ctx, func(t *tx) error {
+		if _, ok := t.d.payments[p.ID]; ok {
+			return fmt.Errorf("create payment %s: %w", p.ID, domain.ErrConflict)
+		}
+		// uq_payments_provider_tx
+		for _, x := range t.d.payments {
+			if x.v.Provider == p.Provider && x.v.TxID == p.TxID {
+				return fmt.Errorf("create payment: duplicate provider tx %s: %w", p.TxID, domain.ErrConflict)
+			}
+		}
+		own(t, &t.d.payments)
+		t.d.payments[p.ID] = row[payment.Payment]{v: *p, seq: t.nextSeq()}
+		return nil
+	})
+}
+
+func (r *PaymentRepository) ListByOrderID(ctx context.Context, orderID order.ID) ([]*payment.Payment, error) {
+	var rows []row[payment.Payment]
+	err := r.s.view(ctx, func(d *data) error {
+		for _, x := range d.payments {
+			if x.v.OrderID == string(orderID) {
+				rows = append(rows, x)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sortedValues(rows, func(p *payment.Payment) time.Time { return p.CreatedAt }), nil
+}
+
+func (r *PaymentRepository) FindByProviderTxID(ctx context.Context, provider, txID string) (*payment.Payment, error) {
+	var out *payment.Payment
+	err := r.s.view(ctx, func(d *data) error {
+		for _, x := range d.payments {
+			if x.v.Provider == provider && x.v.TxID == txID {
+				p := x.v
+				out = &p
+				return nil
+			}
+		}
+		return domain.ErrNotFound
+	})
+	return out, err
+}
+
+func (r *PaymentRepository) ListByProvider(ctx context.Context, provider string, from, to time.Time) ([]*payment.Payment, error) {
+	var rows []row[payment.Payment]
+	err := r.s.view(ctx, func(d *data) error {
+		for _, x := range d.payments {
+			if x.v.Provider == provider && !x.v.CreatedAt.Before(from) && x.v.CreatedAt.Before(to) {
+				rows = append(rows, x)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sortedValues(rows, func(p *payment.Payment) time.Time { return p.CreatedAt }), nil
+}
+
+// AuthorizationRepository implements domain.AuthorizationRepository.
+type AuthorizationRepository struct{ s *Store }
+
+func NewAuthorizationRepository(s *Store) *AuthorizationRepository {
+	return &AuthorizationRepository{s: s}
+}
+
+func (r *AuthorizationRepository) Create(ctx context.Context, a *payment.Authorization) error {
+	return r.s.update(ctx, func(t *tx) error {
+		if _, ok := t.d.auths[a.ID]; ok {
+			return fmt.Errorf("create authorization %s: %w", a.ID, domain.ErrConflict)
+		}
+		// uq_authorizations_order_active
+		for _, x := range t.d.auths {
+			if a.Status == payment.AuthorizationAuthorized && x.v.OrderID == a.OrderID && x.v.Status == payment.AuthorizationAuthorized {
+				return fmt.Errorf("create authorization: order %s already authorized: %w", a.OrderID, domain.ErrConflict)
+			}
+		}
+		own(t, &t.d.auths)
+		t.d.auths[a.ID] = row[payment.Authorization]{v: *a, seq: t.nextSeq()}
+		return nil
+	})
+}
+
+func (r *AuthorizationRepository) FindActiveByOrderID(ctx context.Context, orderID order.ID) (*payment.Authorization, error) {
+	var out *payment.Authorization
+	err := r.s.view(ctx, func(d *data) error {
+		for _, x := range d.auths {
+			if x.v.OrderID == string(orderID) && x.v.Status == payment.AuthorizationAuthorized {
+				a := x.v
+				out = &a
+				return nil
+			}
+		}
+		return domain.ErrNotFound
+	})
+	return out, err
+}
+
+func (r *AuthorizationRepository) UpdateStatusIf(ctx context.Context, id payment.AuthorizationID, from, to payment.AuthorizationStatus, updatedAt time.Time) (int64, error) {
+	var n int64
+	err := r.s.update(ctx, func(t *tx) error {
+		x, ok := t.d.auths[id]
+		if !ok || x.v.Status != from {
+			return nil
+		}
+		own(t, &t.d.auths)
+		x.v.Status = to
+		x.v.UpdatedAt = updatedAt
+		t.d.auths[id] = x
+		n = 1
+		return nil
+	})
+	return n, err
+}
+
+func (r *AuthorizationRepository) ListExpired(ctx context.Context, now time.Time, limit int) ([]*payment.Authorization, error) {
+	var rows []row[payment.Authorization]
+	err := r.s.view(ctx, func(d *data) error {
+		for _, x := range d.auths {
+			if x.v.Status == payment.AuthorizationAuthorized && !x.v.ExpiresAt.After(now) {
+				rows = append(rows, x)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := sortedValues(rows, func(a *payment.Authorization) time.Time { return a.ExpiresAt })
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+// RefundRepository implements domain.RefundRepository.
+type RefundRepository struct{ s *Store }
+
+func NewRefundRepository(s *Store) *RefundRepository { return &RefundRepository{s: s} }
+
+func (r *RefundRepository) CreateWithinCaptured(ctx context.Context, rf *refund.Refund) (int64, error) {
+	var n int64
+	err := r.s.update(ctx, func(t *tx) error {
+		if _, ok := t.d.orders[order.ID(rf.OrderID)]; !ok {
+			return nil
+		}
+		if _, ok := t.d.refunds[rf.ID]; ok {
+			return fmt.Errorf("create refund %s: %w", rf.ID, domain.ErrConflict)
+		}
+
+		// 注文と通貨が違う返金は作らない（CreateRefundWithinCaptured と同じ）
+		if o, ok := t.d.orders[order.ID(rf.OrderID)]; !ok || o.o.Amount.Currency != rf.Amount.Currency {
+			return nil
+		}
+
+		var refunded, captured int64
+		for _, x := range t.d.refunds {
+			// uq_refunds_idempotency_key
+			if x.v.IdempotencyKey == rf.IdempotencyKey {
+				return fmt.Errorf("create refund: duplicate idempotency key: %w", domain.ErrConflict)
+			}
+			if x.v.OrderID == rf.OrderID {
+				refunded += x.v.Amount.Amount
+			}
+		}
+		for _, x := range t.d.payments {
+			if x.v.OrderID == rf.OrderID {
+				captured += x.v.Amount.Amount
+			}
+		}
+		if refunded+rf.Amount.Amount > captured {
+			return nil
+		}
+
+		own(t, &t.d.refunds)
+		t.d.refunds[rf.ID] = row[refund.Refund]{v: *rf, seq: t.nextSeq()}
+		n = 1
+		return nil
+	})
+	return n, err
+}
+
+func (r *RefundRepository) ListByOrderID(ctx context.Context, orderID order.ID) ([]*refund.Refund, error) {
+	var rows []row[refund.Refund]
+	err := r.s.view(ctx, func(d *data) error {
+		for _, x := range d.refunds {
+			if x.v.OrderID == string(orderID) {
+				rows = append(rows, x)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sortedValues(rows, func(rf *refund.Refund) time.Time { return rf.CreatedAt }), nil
+}
@@ -0,0 +1,46 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain/order"
+	"github.com/kazshi01/payment-system/internal/domain/receipt"
+)
+
+// ReceiptRepository implements domain.ReceiptRepository.
+type ReceiptRepository struct{ s *Store }
+
+func NewReceiptRepository(s *Store) *ReceiptRepository { return &ReceiptRepository{s: s} }
+
+// Tx は直列に実行されるため、同じ注文の初回発行が重なることはない
+func (r *ReceiptRepository) Issue(ctx context.Context, orderID order.ID, issuerID string, at time.Time) (*receipt.Receipt, error) {
+	var out *receipt.Receipt
+	err := r.s.update(ctx, func(t *tx) error {
+		own(t, &t.d.receipts)
+		if rc, ok := t.d.receipts[orderID]; ok {
+			rc.Downloads++
+			t.d.receipts[orderID] = rc
+			out = &rc
+			return nil
+		}
+
+		if _, ok := t.d.orders[orderID]; !ok {
+			return fmt.Errorf("issue receipt: order %s not found", orderID)
+		}
+		own(t, &t.d.counters)
+		t.d.counters[issuerID]++
+		rc := receipt.Receipt{
+			OrderID:   orderID,
+			IssuerID:  issuerID,
+			Number:    t.d.counters[issuerID],
+			IssuedAt:  at,
+			Downloads: 1,
+		}
+		t.d.receipts[orderID] = rc
+		out = &rc
+		return nil
+	})
+	return out, err
+}
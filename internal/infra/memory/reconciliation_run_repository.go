@@ -0,0 +1,66 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/settlement"
+)
+
+// ReconciliationRunRepository implements domain.ReconciliationRunRepository.
+type ReconciliationRunRepository struct{ s *Store }
+
+func NewReconciliationRunRepository(s *Store) *ReconciliationRunRepository {
+	return &ReconciliationRunRepository{s: s}
+}
+
+func (r *ReconciliationRunRepository) Create(ctx context.Context, run *settlement.Run) error {
+	return r.s.update(ctx, func(t *tx) error {
+		if _, ok := t.d.recons[run.ID]; ok {
+			return fmt.Errorf("create reconciliation run %s: %w", run.ID, domain.ErrConflict)
+		}
+		own(t, &t.d.recons)
+		v := *run
+		v.Items = slices.Clone(run.Items)
+		t.d.recons[run.ID] = row[settlement.Run]{v: v, seq: t.nextSeq()}
+		return nil
+	})
+}
+
+func (r *ReconciliationRunRepository) FindByID(ctx context.Context, id string) (*settlement.Run, error) {
+	var out *settlement.Run
+	err := r.s.view(ctx, func(d *data) error {
+		x, ok := d.recons[id]
+		if !ok {
+			return domain.ErrNotFound
+		}
+		v := x.v
+		v.Items = slices.Clone(x.v.Items)
+		out = &v
+		return nil
+	})
+	return out, err
+}
+
+func (r *ReconciliationRunRepository) List(ctx context.Context, limit int) ([]*settlement.Run, error) {
+	var rows []row[settlement.Run]
+	err := r.s.view(ctx, func(d *data) error {
+		for _, x := range d.recons {
+			x.v.Items = nil // 一覧では返さない
+			rows = append(rows, x)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := sortedValues(rows, func(r *settlement.Run) time.Time { return r.CreatedAt })
+	slices.Reverse(out) // 新しい順
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
@@ -0,0 +1,177 @@
+// Package memory は DB なしで API を動かすためのインメモリ実装（開発・テスト用）
+//
+// 全リポジトリが 1 つの Store を共有し、Store.Do が Tx になる。
+// Tx は書き込むテーブルを最初の書き込み時に複製し（copy-on-write）、
+// 成功時にまとめて差し替え、失敗時は複製を捨てるだけでロールバックする。
+package memory
+
+import (
+	"context"
+	"maps"
+	"sync"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/banktransfer"
+	"github.com/kazshi01/payment-system/internal/domain/coupon"
+	"github.com/kazshi01/payment-system/internal/domain/event"
+	"github.com/kazshi01/payment-system/internal/domain/ledger"
+	"github.com/kazshi01/payment-system/internal/domain/merchant"
+	"github.com/kazshi01/payment-system/internal/domain/order"
+	"github.com/kazshi01/payment-system/internal/domain/payment"
+	"github.com/kazshi01/payment-system/internal/domain/receipt"
+	"github.com/kazshi01/payment-system/internal/domain/refund"
+	"github.com/kazshi01/payment-system/internal/domain/settlement"
+	"github.com/kazshi01/payment-system/internal/domain/tax"
+	"github.com/kazshi01/payment-system/internal/domain/webhook"
+)
+
+// 挿入順（同時刻の並び替え用）を持つ行
+type row[T any] struct {
+	v   T
+	seq int64
+}
+
+type orderRow struct {
+	o     order.Order
+	fence int64 // orders.lock_fence
+}
+
+type inboxKey struct{ provider, eventID string }
+
+type inboxRow struct {
+	processed bool
+}
+
+type idemKey struct{ userID, key string }
+
+type endpointRow struct {
+	e       webhook.Endpoint
+	deleted bool // 論理削除
+	seq     int64
+}
+
+// data は 1 世代分のテーブル。コミット済みの data は書き換えない
+type data struct {
+	seq int64
+
+	orders     map[order.ID]orderRow
+	items      map[order.ID][]order.Item
+	taxLines   map[order.ID][]tax.Bucket
+	merchants  map[string]merchant.Settings
+	coupons    map[string]coupon.Coupon // ID → クーポン
+	redeemed   map[order.ID]coupon.Redemption
+	receipts   map[order.ID]receipt.Receipt
+	counters   map[string]int64 // 発行者 → 最後の領収書番号
+	payments   map[payment.ID]row[payment.Payment]
+	auths      map[payment.AuthorizationID]row[payment.Authorization]
+	konbinis   map[payment.KonbiniID]row[payment.Konbini]
+	refunds    map[refund.ID]row[refund.Refund]
+	transfers  map[string]row[banktransfer.Transfer]
+	deposits   map[string]row[banktransfer.Deposit]
+	events     map[event.ID]row[event.Event]
+	journal    map[string]row[ledger.Entry] // 仕訳 ID → 仕訳（明細を含む）
+	recons     map[string]row[settlement.Run]
+	inbox      map[inboxKey]inboxRow
+	idem       map[idemKey]domain.IdempotencyRecord
+	endpoints  map[webhook.EndpointID]endpointRow
+	deliveries map[webhook.DeliveryID]row[webhook.Delivery]
+	attempts   map[webhook.DeliveryID][]webhook.Attempt
+}
+
+// Store implements domain.Tx. Transactions are serialized.
+type Store struct {
+	mu   sync.Mutex // Tx の間ずっと保持する
+	data *data
+}
+
+func NewStore() *Store {
+	return &Store{data: &data{
+		orders:     map[order.ID]orderRow{},
+		items:      map[order.ID][]order.Item{},
+		taxLines:   map[order.ID][]tax.Bucket{},
+		merchants:  map[string]merchant.Settings{},
+		coupons:    map[string]coupon.Coupon{},
+		redeemed:   map[order.ID]coupon.Redemption{},
+		receipts:   map[order.ID]receipt.Receipt{},
+		counters:   map[string]int64{},
+		payments:   map[payment.ID]row[payment.Payment]{},
+		auths:      map[payment.AuthorizationID]row[payment.Authorization]{},
+		konbinis:   map[payment.KonbiniID]row[payment.Konbini]{},
+		refunds:    map[refund.ID]row[refund.Refund]{},
+		transfers:  map[string]row[banktransfer.Transfer]{},
+		deposits:   map[string]row[banktransfer.Deposit]{},
+		events:     map[event.ID]row[event.Event]{},
+		journal:    map[string]row[ledger.Entry]{},
+		recons:     map[string]row[settlement.Run]{},
+		inbox:      map[inboxKey]inboxRow{},
+		idem:       map[idemKey]domain.IdempotencyRecord{},
+		endpoints:  map[webhook.EndpointID]endpointRow{},
+		deliveries: map[webhook.DeliveryID]row[webhook.Delivery]{},
+		attempts:   map[webhook.DeliveryID][]webhook.Attempt{},
+	}}
+}
+
+// tx は実行中のトランザクションから見えるテーブル
+type tx struct {
+	store *Store
+	d     *data
+	owned map[any]bool // 複製済みテーブル（&d.orders 等）
+}
+
+type txCtxKey struct{}
+
+func txFrom(ctx context.Context, s *Store) *tx {
+	if t, ok := ctx.Value(txCtxKey{}).(*tx); ok && t.store == s {
+		return t
+	}
+	return nil
+}
+
+// own はテーブルを書き込む前に呼ぶ。Tx 内で初回のみ複製する
+func own[M ~map[K]V, K comparable, V any](t *tx, m *M) {
+	if t.owned[m] {
+		return
+	}
+	*m = maps.Clone(*m)
+	t.owned[m] = true
+}
+
+func (t *tx) nextSeq() int64 {
+	t.d.seq++
+	return t.d.seq
+}
+
+// Do runs fn in a transaction. A nested Do joins the outer transaction.
+func (s *Store) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if txFrom(ctx, s) != nil {
+		return fn(ctx)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	base := *s.data
+	t := &tx{store: s, d: &base, owned: map[any]bool{}}
+	if err := fn(context.WithValue(ctx, txCtxKey{}, t)); err != nil {
+		return err // 複製を捨てるだけ
+	}
+	s.data = t.d
+	return nil
+}
+
+// view は読み取り用。Tx 外ならコミット済みのデータを読む
+func (s *Store) view(ctx context.Context, fn func(d *data) error) error {
+	if t := txFrom(ctx, s); t != nil {
+		return fn(t.d)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return fn(s.data)
+}
+
+// update は書き込み用。Tx 外なら 1 操作だけの Tx として実行する
+func (s *Store) update(ctx context.Context, fn func(t *tx) error) error {
+	return s.Do(ctx, func(ctx context.Context) error {
+		return fn(txFrom(ctx, s))
+	})
+}
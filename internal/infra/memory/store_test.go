@@ -0,0 +1,312 @@
+package memory_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/coupon"
+	"github.com/kazshi01/payment-system/internal/domain/event"
+	"github.com/kazshi01/payment-system/internal/domain/ledger"
+	"github.com/kazshi01/payment-system/internal/domain/money"
+	"github.com/kazshi01/payment-system/internal/domain/order"
+	"github.com/kazshi01/payment-system/internal/domain/payment"
+	"github.com/kazshi01/payment-system/internal/infra/memory"
+)
+
+func newOrder(id string, at time.Time) *order.Order {
+	return &order.Order{ID: order.ID(id), UserID: "user-1", Amount: money.Money{Amount: 1200, Currency: money.JPY}, Status: order.StatusPending, CreatedAt: at, UpdatedAt: at}
+}
+
+func TestStore_rollback(t *testing.T) {
+	ctx := context.Background()
+	s := memory.NewStore()
+	orders := memory.NewOrderRepository(s)
+	events := memory.NewEventRepository(s)
+	now := time.Now()
+
+	if err := orders.Create(ctx, newOrder("o1", now)); err != nil {
+		t.Fatal(err)
+	}
+
+	boom := errors.New("boom")
+	err := s.Do(ctx, func(ctx context.Context) error {
+		if _, err := orders.UpdateStatusIf(ctx, "o1", order.StatusPending, order.StatusPaid, 0, now); err != nil {
+			return err
+		}
+		if err := orders.Create(ctx, newOrder("o2", now)); err != nil {
+			return err
+		}
+		if err := events.Append(ctx, &event.Event{ID: "e1", OrderID: "o1", Type: event.TypeOrderPaid, CreatedAt: now}); err != nil {
+			return err
+		}
+
+		// Tx 内では書き込みが見える
+		o, err := orders.FindByID(ctx, "o1")
+		if err != nil {
+			return err
+		}
+		if o.Status != order.StatusPaid {
+			t.Errorf("status in tx = %s; want PAID", o.Status)
+		}
+		return boom
+	})
+	if !errors.Is(err, boom) {
+		t.Fatalf("err = %v; want boom", err)
+	}
+
+	o, err := orders.FindByID(ctx, "o1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.Status != order.StatusPending {
+		t.Fatalf("status = %s; want PENDING after rollback", o.Status)
+	}
+	if _, err := orders.FindByID(ctx, "o2"); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("o2 err = %v; want ErrNotFound after rollback", err)
+	}
+	if es, _ := events.ListByOrderID(ctx, "o1"); len(es) != 0 {
+		t.Fatalf("events = %d; want 0 after rollback", len(es))
+	}
+}
+
+func TestStore_commitAndNested(t *testing.T) {
+	ctx := context.Background()
+	s := memory.NewStore()
+	orders := memory.NewOrderRepository(s)
+	now := time.Now()
+
+	err := s.Do(ctx, func(ctx context.Context) error {
+		if err := orders.Create(ctx, newOrder("o1", now)); err != nil {
+			return err
+		}
+		// 入れ子の Do は外側の Tx に参加する
+		return s.Do(ctx, func(ctx context.Context) error {
+			return orders.Create(ctx, newOrder("o2", now.Add(time.Second)))
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := orders.List(ctx, domain.OrderListFilter{UserID: "user-1", Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].ID != "o2" || got[1].ID != "o1" {
+		t.Fatalf("orders = %v; want [o2 o1]", got)
+	}
+}
+
+func TestOrderRepository_UpdateStatusIf_fence(t *testing.T) {
+	ctx := context.Background()
+	orders := memory.NewOrderRepository(memory.NewStore())
+	now := time.Now()
+
+	if err := orders.Create(ctx, newOrder("o1", now)); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := orders.UpdateStatusIf(ctx, "o1", order.StatusPending, order.StatusAuthorized, 5, now); n != 1 {
+		t.Fatalf("rows = %d; want 1", n)
+	}
+	// 古いフェンスの保持者は書けない
+	if n, _ := orders.UpdateStatusIf(ctx, "o1", order.StatusAuthorized, order.StatusPaid, 4, now); n != 0 {
+		t.Fatalf("rows = %d; want 0 for stale fence", n)
+	}
+	if n, _ := orders.UpdateStatusIf(ctx, "o1", order.StatusAuthorized, order.StatusPaid, 6, now); n != 1 {
+		t.Fatalf("rows = %d; want 1", n)
+	}
+}
+
+func TestCouponRepository_Redeem_limits(t *testing.T) {
+	ctx := context.Background()
+	s := memory.NewStore()
+	orders := memory.NewOrderRepository(s)
+	coupons := memory.NewCouponRepository(s)
+	now := time.Now()
+
+	c := &coupon.Coupon{ID: "c1", Code: "LIMIT3", Kind: coupon.KindPercentage, PercentOff: 10, MaxRedemptions: 3, MaxPerUser: 2, ValidFrom: now}
+	if err := coupons.Create(ctx, c); err != nil {
+		t.Fatal(err)
+	}
+	if err := coupons.Create(ctx, &coupon.Coupon{ID: "c2", Code: "LIMIT3"}); !errors.Is(err, coupon.ErrCodeTaken) {
+		t.Fatalf("duplicate code err = %v; want ErrCodeTaken", err)
+	}
+
+	// 同時に 10 件利用しても全体の上限 3 件を超えない
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	okByUser := map[string]int{}
+	for i := range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			id := fmt.Sprintf("o%d", i)
+			user := fmt.Sprintf("user-%d", i%2)
+			err := s.Do(ctx, func(ctx context.Context) error {
+				o := newOrder(id, now)
+				o.UserID = user
+				if err := orders.Create(ctx, o); err != nil {
+					return err
+				}
+				return coupons.Redeem(ctx, &coupon.Redemption{ID: "r" + id, CouponID: "c1", OrderID: id, UserID: user, Discount: money.Money{Amount: 120, Currency: money.JPY}, CreatedAt: now})
+			})
+			if err != nil && !errors.Is(err, coupon.ErrUsageLimit) {
+				t.Errorf("redeem %s: %v", id, err)
+			}
+			if err == nil {
+				mu.Lock()
+				okByUser[user]++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := okByUser["user-0"] + okByUser["user-1"]; n != 3 {
+		t.Fatalf("redeemed %d times (%v); want 3", n, okByUser)
+	}
+	for u, n := range okByUser {
+		if n > 2 {
+			t.Fatalf("%s redeemed %d times; want <= 2", u, n)
+		}
+	}
+	got, err := coupons.FindByCode(ctx, "LIMIT3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Redeemed != 3 {
+		t.Fatalf("redeemed = %d; want 3", got.Redeemed)
+	}
+
+	// 取り消した注文の利用を戻すと枠が空く（2 回目は何もしない）
+	var red *coupon.Redemption
+	for i := 0; red == nil && i < 10; i++ {
+		red, _ = coupons.FindRedemptionByOrderID(ctx, order.ID(fmt.Sprintf("o%d", i)))
+	}
+	if red == nil {
+		t.Fatal("no redemption recorded")
+	}
+	for range 2 {
+		if err := coupons.Release(ctx, order.ID(red.OrderID)); err != nil {
+			t.Fatalf("Release err = %v", err)
+		}
+	}
+	if got, _ := coupons.FindByCode(ctx, "LIMIT3"); got.Redeemed != 2 {
+		t.Fatalf("redeemed after release = %d; want 2", got.Redeemed)
+	}
+	if _, err := coupons.FindRedemptionByOrderID(ctx, order.ID(red.OrderID)); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("redemption after release err = %v; want ErrNotFound", err)
+	}
+}
+
+// 描画に失敗して Tx が戻った番号は次の発行で使われる（欠番を作らない）
+func TestReceiptRepository_Issue_numbering(t *testing.T) {
+	ctx := context.Background()
+	s := memory.NewStore()
+	orders := memory.NewOrderRepository(s)
+	receipts := memory.NewReceiptRepository(s)
+	now := time.Now()
+
+	for _, id := range []string{"o1", "o2"} {
+		if err := orders.Create(ctx, newOrder(id, now)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rc, err := receipts.Issue(ctx, "o1", "user-1", now)
+	if err != nil || rc.Number != 1 || rc.Reissued() {
+		t.Fatalf("Issue(o1) = %+v, %v; want number 1", rc, err)
+	}
+	rc, err = receipts.Issue(ctx, "o1", "user-1", now)
+	if err != nil || rc.Number != 1 || rc.Downloads != 2 || !rc.Reissued() {
+		t.Fatalf("Issue(o1) again = %+v, %v; want number 1, reissued", rc, err)
+	}
+
+	boom := errors.New("boom")
+	err = s.Do(ctx, func(ctx context.Context) error {
+		if _, err := receipts.Issue(ctx, "o2", "user-1", now); err != nil {
+			return err
+		}
+		return boom
+	})
+	if !errors.Is(err, boom) {
+		t.Fatalf("err = %v; want boom", err)
+	}
+
+	rc, err = receipts.Issue(ctx, "o2", "user-1", now)
+	if err != nil || rc.Number != 2 || rc.Reissued() {
+		t.Fatalf("Issue(o2) = %+v, %v; want number 2, not reissued", rc, err)
+	}
+}
+
+func TestLedgerRepository(t *testing.T) {
+	ctx := context.Background()
+	s := memory.NewStore()
+	orders := memory.NewOrderRepository(s)
+	payments := memory.NewPaymentRepository(s)
+	journal := memory.NewLedgerRepository(s)
+	day := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	jpy := func(n int64) money.Money { return money.Money{Amount: n, Currency: money.JPY} }
+
+	o := newOrder("o1", day)
+	o.Status = order.StatusPaid
+	if err := orders.Create(ctx, o); err != nil {
+		t.Fatal(err)
+	}
+	if err := payments.Create(ctx, &payment.Payment{ID: "p1", OrderID: "o1", Provider: "stripe", TxID: "tx1", Amount: jpy(1200), CreatedAt: day}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 決済を計上するまでは違反
+	vs, err := journal.ListUnpostedOrders(ctx, 10)
+	if err != nil || len(vs) != 1 || vs[0].OrderID != "o1" {
+		t.Fatalf("ListUnpostedOrders = %v, %v; want o1", vs, err)
+	}
+
+	record := func(e *ledger.Entry, id string) error {
+		e.ID = id
+		return journal.Record(ctx, e)
+	}
+	if err := record(ledger.Charge("o1", "p1", jpy(1200), day), "e1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := record(ledger.Fee("o1", "p1", jpy(43), day.Add(24*time.Hour)), "e2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := record(ledger.Charge("o1", "p1", jpy(1200), day), "e3"); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("Record(same payment) err = %v; want ErrConflict", err)
+	}
+	unbalanced := &ledger.Entry{Kind: ledger.KindRefund, OrderID: "o1", Reference: "r1", Postings: []ledger.Posting{
+		{Account: ledger.AccountSalesRefunds, Amount: jpy(100)},
+		{Account: ledger.AccountProviderReceivable, Amount: jpy(-99)},
+	}}
+	if err := record(unbalanced, "e4"); !errors.Is(err, ledger.ErrUnbalanced) {
+		t.Fatalf("Record(unbalanced) err = %v; want ErrUnbalanced", err)
+	}
+
+	if vs, err := journal.ListUnpostedOrders(ctx, 10); err != nil || len(vs) != 0 {
+		t.Fatalf("ListUnpostedOrders = %v, %v; want none", vs, err)
+	}
+	if vs, err := journal.ListUnbalancedEntries(ctx, 10); err != nil || len(vs) != 0 {
+		t.Fatalf("ListUnbalancedEntries = %v, %v; want none", vs, err)
+	}
+
+	// 期間は [from, to)
+	b, err := journal.Balance(ctx, ledger.AccountProviderReceivable, money.JPY, time.Time{}, time.Time{})
+	if err != nil || b.Debit != 1200 || b.Credit != 43 {
+		t.Fatalf("Balance(all) = %+v, %v; want debit 1200, credit 43", b, err)
+	}
+	b, _ = journal.Balance(ctx, ledger.AccountProviderReceivable, money.JPY, day, day.Add(24*time.Hour))
+	if b.Debit != 1200 || b.Credit != 0 {
+		t.Fatalf("Balance(day 1) = %+v; want debit 1200 only", b)
+	}
+	b, _ = journal.Balance(ctx, ledger.AccountProviderReceivable, money.USD, time.Time{}, time.Time{})
+	if b.Debit != 0 || b.Credit != 0 {
+		t.Fatalf("Balance(USD) = %+v; want zero", b)
+	}
+}
@@ -0,0 +1,210 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/webhook"
+)
+
+// WebhookEndpointRepository implements domain.WebhookEndpointRepository.
+type WebhookEndpointRepository struct{ s *Store }
+
+func NewWebhookEndpointRepository(s *Store) *WebhookEndpointRepository {
+	return &WebhookEndpointRepository{s: s}
+}
+
+func (r *WebhookEndpointRepository) Create(ctx context.Context, e *webhook.Endpoint) error {
+	return r.s.update(ctx, func(t *tx) error {
+		if _, ok := t.d.endpoints[e.ID]; ok {
+			return fmt.Errorf("create webhook endpoint %s: %w", e.ID, domain.ErrConflict)
+		}
+		own(t, &t.d.endpoints)
+		t.d.endpoints[e.ID] = endpointRow{e: *e, seq: t.nextSeq()}
+		return nil
+	})
+}
+
+func (r *WebhookEndpointRepository) FindByID(ctx context.Context, id webhook.EndpointID) (*webhook.Endpoint, error) {
+	var out *webhook.Endpoint
+	err := r.s.view(ctx, func(d *data) error {
+		x, ok := d.endpoints[id]
+		if !ok || x.deleted {
+			return domain.ErrNotFound
+		}
+		e := x.e
+		out = &e
+		return nil
+	})
+	return out, err
+}
+
+func (r *WebhookEndpointRepository) ListByUserID(ctx context.Context, userID string) ([]*webhook.Endpoint, error) {
+	var rows []row[webhook.Endpoint]
+	err := r.s.view(ctx, func(d *data) error {
+		for _, x := range d.endpoints {
+			if x.e.UserID == userID && !x.deleted {
+				rows = append(rows, row[webhook.Endpoint]{v: x.e, seq: x.seq})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sortedValues(rows, func(e *webhook.Endpoint) time.Time { return e.CreatedAt }), nil
+}
+
+func (r *WebhookEndpointRepository) Delete(ctx context.Context, id webhook.EndpointID, at time.Time) (int64, error) {
+	var n int64
+	err := r.s.update(ctx, func(t *tx) error {
+		x, ok := t.d.endpoints[id]
+		if !ok || x.deleted {
+			return nil
+		}
+		own(t, &t.d.endpoints)
+		x.deleted = true
+		t.d.endpoints[id] = x
+		n = 1
+		return nil
+	})
+	return n, err
+}
+
+// WebhookDeliveryRepository implements domain.WebhookDeliveryRepository.
+type WebhookDeliveryRepository struct{ s *Store }
+
+func NewWebhookDeliveryRepository(s *Store) *WebhookDeliveryRepository {
+	return &WebhookDeliveryRepository{s: s}
+}
+
+func (r *WebhookDeliveryRepository) CreateIfAbsent(ctx context.Context, dl *webhook.Delivery) (bool, error) {
+	var created bool
+	err := r.s.update(ctx, func(t *tx) error {
+		// uq_webhook_deliveries_endpoint_event
+		for _, x := range t.d.deliveries {
+			if x.v.EndpointID == dl.EndpointID && x.v.EventID == dl.EventID {
+				return nil
+			}
+		}
+		own(t, &t.d.deliveries)
+		v := *dl
+		v.UpdatedAt = v.CreatedAt
+		t.d.deliveries[dl.ID] = row[webhook.Delivery]{v: v, seq: t.nextSeq()}
+		created = true
+		return nil
+	})
+	return created, err
+}
+
+func (r *WebhookDeliveryRepository) FindByID(ctx context.Context, id webhook.DeliveryID) (*webhook.Delivery, error) {
+	var out *webhook.Delivery
+	err := r.s.view(ctx, func(d *data) error {
+		x, ok := d.deliveries[id]
+		if !ok {
+			return domain.ErrNotFound
+		}
+		v := x.v
+		out = &v
+		return nil
+	})
+	return out, err
+}
+
+func (r *WebhookDeliveryRepository) ListByEndpointID(ctx context.Context, id webhook.EndpointID, limit int) ([]*webhook.Delivery, error) {
+	var out []*webhook.Delivery
+	err := r.s.view(ctx, func(d *data) error {
+		for _, x := range d.deliveries {
+			if x.v.EndpointID == id {
+				v := x.v
+				out = append(out, &v)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// created_at DESC, id DESC
+	slices.SortFunc(out, func(a, b *webhook.Delivery) int {
+		if c := b.CreatedAt.Compare(a.CreatedAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.ID, a.ID)
+	})
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	if out == nil {
+		out = []*webhook.Delivery{}
+	}
+	return out, nil
+}
+
+func (r *WebhookDeliveryRepository) ClaimDue(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*webhook.Delivery, error) {
+	var out []*webhook.Delivery
+	err := r.s.update(ctx, func(t *tx) error {
+		var rows []row[webhook.Delivery]
+		for _, x := range t.d.deliveries {
+			if x.v.Status == webhook.DeliveryPending && !x.v.NextAttemptAt.After(now) {
+				rows = append(rows, x)
+			}
+		}
+		due := sortedValues(rows, func(dl *webhook.Delivery) time.Time { return dl.NextAttemptAt })
+		if len(due) > limit {
+			due = due[:limit]
+		}
+		if len(due) == 0 {
+			return nil
+		}
+
+		own(t, &t.d.deliveries)
+		for _, dl := range due {
+			x := t.d.deliveries[dl.ID]
+			x.v.NextAttemptAt = leaseUntil
+			t.d.deliveries[dl.ID] = x
+
+			v := x.v
+			out = append(out, &v)
+		}
+		return nil
+	})
+	return out, err
+}
+
+func (r *WebhookDeliveryRepository) RecordAttempt(ctx context.Context, dl *webhook.Delivery, a *webhook.Attempt) error {
+	return r.s.update(ctx, func(t *tx) error {
+		x, ok := t.d.deliveries[dl.ID]
+		if !ok {
+			return fmt.Errorf("record webhook attempt: delivery %s: %w", dl.ID, domain.ErrNotFound)
+		}
+		own(t, &t.d.attempts)
+		t.d.attempts[dl.ID] = append(slices.Clip(t.d.attempts[dl.ID]), *a)
+
+		own(t, &t.d.deliveries)
+		x.v.Status = dl.Status
+		x.v.Attempts = dl.Attempts
+		x.v.NextAttemptAt = dl.NextAttemptAt
+		x.v.LastStatusCode = dl.LastStatusCode
+		x.v.LastError = dl.LastError
+		x.v.UpdatedAt = dl.UpdatedAt
+		t.d.deliveries[dl.ID] = x
+		return nil
+	})
+}
+
+func (r *WebhookDeliveryRepository) ListAttempts(ctx context.Context, id webhook.DeliveryID) ([]*webhook.Attempt, error) {
+	out := []*webhook.Attempt{}
+	err := r.s.view(ctx, func(d *data) error {
+		for _, a := range d.attempts[id] {
+			out = append(out, &a)
+		}
+		return nil
+	})
+	return out, err
+}
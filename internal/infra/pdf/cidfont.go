@@ -0,0 +1,36 @@
+package pdf
+
+import "fmt"
+
+// cidFont は埋め込まない日本語の CID フォント。
+// 字形はビューアが持つ代替フォントで描かれるため、環境によって見た目が変わる
+type cidFont struct {
+	name string
+}
+
+// StandardJapaneseFont はフォントファイルなしで使える日本語フォント（平成角ゴシック W5 相当）
+func StandardJapaneseFont() Font { return cidFont{name: "HeiseiKakuGo-W5"} }
+
+// UniJIS-UCS2-HW-H は UCS-2 のコードをそのまま使い、ASCII を半角の字形に割り当てる
+func (f cidFont) glyph(r rune) (uint16, int) {
+	if r > 0xFFFF {
+		r = '?' // UCS-2 の範囲外
+	}
+	switch {
+	case r < 0x100, r >= 0xFF61 && r <= 0xFF9F: // 半角英数・半角カナ
+		return uint16(r), 500
+	}
+	return uint16(r), 1000
+}
+
+func (f cidFont) write(w *writer, _ map[uint16]rune) (int, error) {
+	desc := w.alloc()
+	w.object(desc, fmt.Sprintf("<< /Type /FontDescriptor /FontName /%s /Flags 4 /FontBBox [-92 -250 1010 922] /ItalicAngle 0 /Ascent 880 /Descent -120 /CapHeight 737 /StemV 114 >>", f.name))
+
+	cid := w.alloc()
+	w.object(cid, fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType0 /BaseFont /%s /CIDSystemInfo << /Registry (Adobe) /Ordering (Japan1) /Supplement 2 >> /FontDescriptor %d 0 R /DW 1000 /W [231 632 500] >>", f.name, desc))
+
+	font := w.alloc()
+	w.object(font, fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /UniJIS-UCS2-HW-H /DescendantFonts [%d 0 R] >>", f.name, cid))
+	return font, nil
+}
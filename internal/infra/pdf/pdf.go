@@ -0,0 +1,205 @@
+// Package pdf は帳票の出力に使う最小限の PDF 1.7 ライタ。
+// A4 縦のページに 1 つのフォントでテキストと罫線だけを描く。
+//
+// 日本語は TrueType フォントを埋め込む（使った文字だけのサブセット）か、
+// 埋め込まない標準フォント（StandardJapaneseFont）で描く。
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// A4（pt）。座標の原点は左下
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+// Font はドキュメントで使うフォント。並行して複数のドキュメントから使える
+type Font interface {
+	// glyph は r の文字コード（Type0 フォントの 2 バイトコード）と送り幅（1/1000 em）
+	glyph(r rune) (code uint16, width int)
+	// write はフォントのオブジェクトを書き出し、Type0 フォントのオブジェクト番号を返す
+	// used は本文で使った文字コード → 元の文字
+	write(w *writer, used map[uint16]rune) (int, error)
+}
+
+type Document struct {
+	font  Font
+	title string
+	pages []*Page
+	used  map[uint16]rune
+}
+
+type Page struct {
+	doc *Document
+	buf bytes.Buffer
+}
+
+func New(font Font) *Document {
+	return &Document{font: font, used: map[uint16]rune{}}
+}
+
+// SetTitle は文書情報のタイトル（ビューアのタイトルバーに出る）
+func (d *Document) SetTitle(s string) { d.title = s }
+
+func (d *Document) AddPage() *Page {
+	p := &Page{doc: d}
+	d.pages = append(d.pages, p)
+	return p
+}
+
+// Pages は追加した順のページ（ページ番号を後から書き込む用）
+func (d *Document) Pages() []*Page { return d.pages }
+
+// TextWidth は size pt で描いたときの s の幅（pt）
+func (d *Document) TextWidth(s string, size float64) float64 {
+	w := 0
+	for _, r := range s {
+		_, gw := d.font.glyph(r)
+		w += gw
+	}
+	return float64(w) * size / 1000
+}
+
+// Text は (x, y) をベースラインの左端として s を描く
+func (p *Page) Text(x, y, size float64, s string) {
+	var hex strings.Builder
+	for _, r := range s {
+		code, _ := p.doc.font.glyph(r)
+		if _, ok := p.doc.used[code]; !ok {
+			p.doc.used[code] = r
+		}
+		fmt.Fprintf(&hex, "%04X", code)
+	}
+	fmt.Fprintf(&p.buf, "BT /F1 %s Tf %s %s Td <%s> Tj ET\n", num(size), num(x), num(y), hex.String())
+}
+
+// TextRight は x を右端として描く
+func (p *Page) TextRight(x, y, size float64, s string) {
+	p.Text(x-p.doc.TextWidth(s, size), y, size, s)
+}
+
+// TextCenter は x を中心として描く
+func (p *Page) TextCenter(x, y, size float64, s string) {
+	p.Text(x-p.doc.TextWidth(s, size)/2, y, size, s)
+}
+
+func (p *Page) Line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(&p.buf, "%s w %s %s m %s %s l S\n", num(width), num(x1), num(y1), num(x2), num(y2))
+}
+
+// Rect は左下 (x, y)、幅 w、高さ h の枠を描く
+func (p *Page) Rect(x, y, w, h, width float64) {
+	fmt.Fprintf(&p.buf, "%s w %s %s %s %s re S\n", num(width), num(x), num(y), num(w), num(h))
+}
+
+// Bytes は PDF ファイルを組み立てる
+func (d *Document) Bytes() ([]byte, error) {
+	if len(d.pages) == 0 {
+		return nil, fmt.Errorf("pdf: no pages")
+	}
+
+	w := &writer{}
+	w.buf.WriteString("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+
+	catalog := w.alloc()
+	pages := w.alloc()
+
+	font, err := d.font.write(w, d.used)
+	if err != nil {
+		return nil, err
+	}
+
+	kids := make([]string, 0, len(d.pages))
+	for _, p := range d.pages {
+		content := w.alloc()
+		if err := w.stream(content, "", p.buf.Bytes(), true); err != nil {
+			return nil, err
+		}
+		page := w.alloc()
+		w.object(page, fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 %d 0 R >> >> /Contents %d 0 R >>",
+			pages, num(PageWidth), num(PageHeight), font, content))
+		kids = append(kids, fmt.Sprintf("%d 0 R", page))
+	}
+	w.object(pages, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(kids)))
+	w.object(catalog, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pages))
+
+	info := w.alloc()
+	infoDict := "<< /Producer (payment-system)"
+	if d.title != "" {
+		infoDict += " /Title " + textString(d.title)
+	}
+	w.object(info, infoDict+" >>")
+
+	// 相互参照表
+	xref := w.buf.Len()
+	fmt.Fprintf(&w.buf, "xref\n0 %d\n0000000000 65535 f \n", len(w.offsets)+1)
+	for _, off := range w.offsets {
+		fmt.Fprintf(&w.buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&w.buf, "trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(w.offsets)+1, catalog, info, xref)
+	return w.buf.Bytes(), nil
+}
+
+// writer は間接オブジェクトを書きながら相互参照表用のオフセットを記録する
+type writer struct {
+	buf     bytes.Buffer
+	offsets []int // オブジェクト番号 - 1 → ファイル先頭からの位置
+}
+
+// alloc はオブジェクト番号を予約する（書き出しは後でもよい）
+func (w *writer) alloc() int {
+	w.offsets = append(w.offsets, 0)
+	return len(w.offsets)
+}
+
+func (w *writer) object(n int, body string) {
+	w.offsets[n-1] = w.buf.Len()
+	fmt.Fprintf(&w.buf, "%d 0 obj\n%s\nendobj\n", n, body)
+}
+
+// stream は dict（<< >> の中身、/Length 以外）を付けてストリームを書く
+func (w *writer) stream(n int, dict string, data []byte, compress bool) error {
+	if compress {
+		var z bytes.Buffer
+		zw := zlib.NewWriter(&z)
+		if _, err := zw.Write(data); err != nil {
+			return err
+		}
+		if err := zw.Close(); err != nil {
+			return err
+		}
+		data = z.Bytes()
+		dict += " /Filter /FlateDecode"
+	}
+
+	w.offsets[n-1] = w.buf.Len()
+	fmt.Fprintf(&w.buf, "%d 0 obj\n<< /Length %d%s >>\nstream\n", n, len(data), dict)
+	w.buf.Write(data)
+	w.buf.WriteString("\nendstream\nendobj\n")
+	return nil
+}
+
+// 座標・サイズは小数 2 桁まで
+func num(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
+
+// textString は文書情報用の UTF-16BE 文字列
+func textString(s string) string {
+	var b strings.Builder
+	b.WriteString("<FEFF")
+	for _, u := range utf16.Encode([]rune(s)) {
+		fmt.Fprintf(&b, "%04X", u)
+	}
+	b.WriteString(">")
+	return b.String()
+}
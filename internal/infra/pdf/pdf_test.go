@@ -0,0 +1,211 @@
+package pdf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+// 4 グリフの最小フォント。0: .notdef, 1: 'A', 2: 'あ'（3 を部品に持つ複合グリフ）, 3: 部品
+func testFont(t *testing.T) []byte {
+	t.Helper()
+	be := binary.BigEndian
+
+	simple := func(mark byte) []byte {
+		g := make([]byte, 12)
+		be.PutUint16(g, 1) // numberOfContours
+		g[10], g[11] = mark, mark
+		return g
+	}
+	composite := make([]byte, 16)
+	be.PutUint16(composite, 0xFFFF) // numberOfContours = -1
+	be.PutUint16(composite[10:], 0) // flags（引数は 1 バイト、部品は 1 つ）
+	be.PutUint16(composite[12:], 3) // glyphIndex
+	glyphs := [][]byte{simple(0xA0), simple(0xA1), composite, simple(0xA3)}
+
+	var glyf []byte
+	loca := make([]byte, 2*(len(glyphs)+1))
+	for i, g := range glyphs {
+		be.PutUint16(loca[2*i:], uint16(len(glyf)/2))
+		glyf = append(glyf, g...)
+	}
+	be.PutUint16(loca[2*len(glyphs):], uint16(len(glyf)/2))
+
+	head := make([]byte, 54)
+	be.PutUint32(head[12:], 0x5F0F3CF5)
+	be.PutUint16(head[18:], 2048) // unitsPerEm
+	be.PutUint16(head[40:], 2048)
+	be.PutUint16(head[42:], 1800)
+	be.PutUint16(head[50:], 0) // short loca
+
+	hhea := make([]byte, 36)
+	be.PutUint16(hhea[4:], 1800)
+	be.PutUint16(hhea[6:], uint16(0x10000-400))
+	be.PutUint16(hhea[34:], 3) // numberOfHMetrics（グリフ 3 は最後の幅を使う）
+
+	hmtx := make([]byte, 4*3+2)
+	for i, adv := range []uint16{1024, 1229, 2048} {
+		be.PutUint16(hmtx[4*i:], adv)
+	}
+
+	maxp := make([]byte, 6)
+	be.PutUint32(maxp, 0x00005000)
+	be.PutUint16(maxp[4:], uint16(len(glyphs)))
+
+	// format 4: 'A' → 1, 'あ' → 2
+	segs := []struct{ start, end, delta uint16 }{
+		{'A', 'A', 0x10000 + 1 - 'A'}, // idDelta は 65536 を法とする
+		{0x3042, 0x3042, 0x10000 + 2 - 0x3042},
+		{0xFFFF, 0xFFFF, 1},
+	}
+	sub := make([]byte, 16+8*len(segs))
+	be.PutUint16(sub, 4)
+	be.PutUint16(sub[2:], uint16(len(sub)))
+	be.PutUint16(sub[6:], uint16(2*len(segs)))
+	for i, s := range segs {
+		be.PutUint16(sub[14+2*i:], s.end)
+		be.PutUint16(sub[16+2*len(segs)+2*i:], s.start)
+		be.PutUint16(sub[16+4*len(segs)+2*i:], s.delta)
+	}
+	cmap := make([]byte, 12)
+	be.PutUint16(cmap[2:], 1)
+	be.PutUint16(cmap[4:], 3)
+	be.PutUint16(cmap[6:], 1)
+	be.PutUint32(cmap[8:], 12)
+	cmap = append(cmap, sub...)
+
+	psName := []byte("Test Sans")
+	name := make([]byte, 18)
+	be.PutUint16(name[2:], 1)
+	be.PutUint16(name[4:], 18)
+	be.PutUint16(name[6:], 1) // Macintosh
+	be.PutUint16(name[12:], 6)
+	be.PutUint16(name[14:], uint16(len(psName)))
+	name = append(name, psName...)
+
+	return buildSFNT(map[string][]byte{
+		"head": head, "hhea": hhea, "maxp": maxp, "hmtx": hmtx,
+		"cmap": cmap, "loca": loca, "glyf": glyf, "name": name,
+	})
+}
+
+func TestParseTrueType(t *testing.T) {
+	f, err := ParseTrueType(testFont(t))
+	if err != nil {
+		t.Fatalf("ParseTrueType err = %v", err)
+	}
+	if f.name != "TestSans" {
+		t.Fatalf("name = %q; want TestSans", f.name)
+	}
+	tests := []struct {
+		r     rune
+		gid   uint16
+		width int
+	}{
+		{'A', 1, 600},
+		{'あ', 2, 1000},
+		{'字', 0, 500}, // cmap にない文字は .notdef
+	}
+	for _, tt := range tests {
+		gid, w := f.glyph(tt.r)
+		if gid != tt.gid || w != tt.width {
+			t.Fatalf("glyph(%q) = (%d, %d); want (%d, %d)", tt.r, gid, w, tt.gid, tt.width)
+		}
+	}
+	if got := f.advance(3); got != 1000 {
+		t.Fatalf("advance(3) = %d; want 1000 (last hmtx entry)", got)
+	}
+
+	otf := append([]byte("OTTO"), make([]byte, 8)...)
+	if _, err := ParseTrueType(otf); !errors.Is(err, ErrUnsupportedFont) {
+		t.Fatalf("ParseTrueType(OTTO) err = %v; want ErrUnsupportedFont", err)
+	}
+}
+
+func TestTrueTypeFont_subset(t *testing.T) {
+	f, err := ParseTrueType(testFont(t))
+	if err != nil {
+		t.Fatalf("ParseTrueType err = %v", err)
+	}
+	b, err := f.subset([]uint16{2})
+	if err != nil {
+		t.Fatalf("subset err = %v", err)
+	}
+	tables, err := readTables(b)
+	if err != nil {
+		t.Fatalf("readTables(subset) err = %v", err)
+	}
+	if _, ok := tables["cmap"]; ok {
+		t.Fatalf("subset has cmap table")
+	}
+
+	// 'A'（1）は空になり、'あ' と部品（3）と .notdef は残る（loca は long 形式）
+	loca := tables["loca"]
+	for gid, want := range []int{12, 0, 16, 12} {
+		if got := int(u32(loca, 4*gid+4) - u32(loca, 4*gid)); got != want {
+			t.Fatalf("glyph %d size = %d; want %d", gid, got, want)
+		}
+	}
+	if sum := checksum(b); sum != 0xB1B0AFBA {
+		t.Fatalf("font checksum = %#x; want 0xB1B0AFBA", sum)
+	}
+}
+
+func TestDocument_Bytes(t *testing.T) {
+	ttf, err := ParseTrueType(testFont(t))
+	if err != nil {
+		t.Fatalf("ParseTrueType err = %v", err)
+	}
+	fonts := map[string]Font{"standard": StandardJapaneseFont(), "truetype": ttf}
+	for name, font := range fonts {
+		t.Run(name, func(t *testing.T) {
+			d := New(font)
+			d.SetTitle("領収書")
+			for range 2 {
+				p := d.AddPage()
+				p.Text(50, 800, 12, "Aあ")
+				p.TextRight(545, 780, 10, "A")
+				p.Line(50, 770, 545, 770, 0.5)
+			}
+			b, err := d.Bytes()
+			if err != nil {
+				t.Fatalf("Bytes err = %v", err)
+			}
+			checkXref(t, b)
+			if !bytes.Contains(b, []byte("/Title <FEFF981853CE66F8>")) {
+				t.Fatalf("missing title in info dict")
+			}
+			if !bytes.Contains(b, []byte("/Count 2")) {
+				t.Fatalf("missing page count")
+			}
+		})
+	}
+
+	if _, err := New(StandardJapaneseFont()).Bytes(); err == nil {
+		t.Fatalf("Bytes with no pages err = nil")
+	}
+}
+
+// 相互参照表の各オフセットが "n 0 obj" を指していること
+func checkXref(t *testing.T, b []byte) {
+	t.Helper()
+	m := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`).FindSubmatch(b)
+	if m == nil {
+		t.Fatalf("missing startxref")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(b[xref:], -1)
+	if len(entries) == 0 {
+		t.Fatalf("empty xref")
+	}
+	for i, e := range entries {
+		off, _ := strconv.Atoi(string(e[1]))
+		if want := fmt.Sprintf("%d 0 obj\n", i+1); !bytes.HasPrefix(b[off:], []byte(want)) {
+			t.Fatalf("xref entry %d points to %q", i+1, b[off:min(off+10, len(b))])
+		}
+	}
+}
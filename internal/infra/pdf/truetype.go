@@ -0,0 +1,504 @@
+package pdf
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf16"
+)
+
+var ErrUnsupportedFont = errors.New("pdf: unsupported font")
+
+// TrueTypeFont は埋め込み用の TrueType フォント（.ttf）。
+// 書き出し時は使った文字のグリフだけを残したサブセットを埋め込む
+type TrueTypeFont struct {
+	name       string // PostScript 名
+	unitsPerEm int
+	bbox       [4]int16
+	ascent     int16
+	descent    int16
+	numGlyphs  int
+	advances   []uint16 // hmtx（グリフ番号順。末尾以降は最後の値）
+	cmap       map[rune]uint16
+	loca       []uint32 // グリフ番号 → glyf 内の位置（numGlyphs+1 個）
+	tables     map[string][]byte
+}
+
+// サブセットに残すテーブル（CIDFontType2 の埋め込みに必要なもの）
+var subsetTables = []string{"cvt ", "fpgm", "glyf", "head", "hhea", "hmtx", "loca", "maxp", "prep"}
+
+// ParseTrueType は TrueType アウトラインのフォントを読む。
+// CFF アウトラインの OpenType（.otf）とフォントコレクション（.ttc）は扱わない
+func ParseTrueType(b []byte) (*TrueTypeFont, error) {
+	tables, err := readTables(b)
+	if err != nil {
+		return nil, err
+	}
+	f := &TrueTypeFont{tables: tables}
+	for _, tag := range []string{"head", "hhea", "maxp", "hmtx", "cmap", "loca", "glyf"} {
+		if _, ok := f.tables[tag]; !ok {
+			return nil, fmt.Errorf("%w: missing %q table", ErrUnsupportedFont, tag)
+		}
+	}
+
+	head := f.tables["head"]
+	if len(head) < 54 {
+		return nil, fmt.Errorf("%w: short head table", ErrUnsupportedFont)
+	}
+	f.unitsPerEm = int(u16(head, 18))
+	if f.unitsPerEm == 0 {
+		return nil, fmt.Errorf("%w: unitsPerEm is 0", ErrUnsupportedFont)
+	}
+	for i := range 4 {
+		f.bbox[i] = int16(u16(head, 36+2*i))
+	}
+	longLoca := u16(head, 50) == 1
+
+	if len(f.tables["maxp"]) < 6 {
+		return nil, fmt.Errorf("%w: short maxp table", ErrUnsupportedFont)
+	}
+	f.numGlyphs = int(u16(f.tables["maxp"], 4))
+
+	hhea := f.tables["hhea"]
+	if len(hhea) < 36 {
+		return nil, fmt.Errorf("%w: short hhea table", ErrUnsupportedFont)
+	}
+	f.ascent, f.descent = int16(u16(hhea, 4)), int16(u16(hhea, 6))
+	nm := int(u16(hhea, 34))
+	hmtx := f.tables["hmtx"]
+	if nm == 0 || len(hmtx) < 4*nm {
+		return nil, fmt.Errorf("%w: short hmtx table", ErrUnsupportedFont)
+	}
+	f.advances = make([]uint16, nm)
+	for i := range nm {
+		f.advances[i] = u16(hmtx, 4*i)
+	}
+
+	if err := f.parseLoca(longLoca); err != nil {
+		return nil, err
+	}
+	if f.cmap, err = parseCmap(f.tables["cmap"]); err != nil {
+		return nil, err
+	}
+	f.name = postScriptName(f.tables["name"])
+	return f, nil
+}
+
+// readTables はテーブルディレクトリを読み、タグ → テーブルの中身を返す
+func readTables(b []byte) (map[string][]byte, error) {
+	if len(b) < 12 {
+		return nil, fmt.Errorf("%w: too short", ErrUnsupportedFont)
+	}
+	switch string(b[:4]) {
+	case "\x00\x01\x00\x00", "true":
+	case "OTTO":
+		return nil, fmt.Errorf("%w: CFF-based OpenType; use a TrueType (.ttf) font", ErrUnsupportedFont)
+	case "ttcf":
+		return nil, fmt.Errorf("%w: font collection (.ttc)", ErrUnsupportedFont)
+	default:
+		return nil, fmt.Errorf("%w: not a TrueType font", ErrUnsupportedFont)
+	}
+
+	tables := map[string][]byte{}
+	n := int(u16(b, 4))
+	if len(b) < 12+16*n {
+		return nil, fmt.Errorf("%w: truncated table directory", ErrUnsupportedFont)
+	}
+	for i := range n {
+		rec := b[12+16*i:]
+		tag := string(rec[:4])
+		off, length := int(u32(rec, 8)), int(u32(rec, 12))
+		if off < 0 || length < 0 || off+length > len(b) {
+			return nil, fmt.Errorf("%w: table %q out of range", ErrUnsupportedFont, tag)
+		}
+		tables[tag] = b[off : off+length]
+	}
+	return tables, nil
+}
+
+func (f *TrueTypeFont) parseLoca(long bool) error {
+	loca, glyf := f.tables["loca"], f.tables["glyf"]
+	f.loca = make([]uint32, f.numGlyphs+1)
+	for i := range f.loca {
+		if long {
+			if len(loca) < 4*(i+1) {
+				return fmt.Errorf("%w: short loca table", ErrUnsupportedFont)
+			}
+			f.loca[i] = u32(loca, 4*i)
+		} else {
+			if len(loca) < 2*(i+1) {
+				return fmt.Errorf("%w: short loca table", ErrUnsupportedFont)
+			}
+			f.loca[i] = uint32(u16(loca, 2*i)) * 2
+		}
+		if int(f.loca[i]) > len(glyf) || (i > 0 && f.loca[i] < f.loca[i-1]) {
+			return fmt.Errorf("%w: broken loca table", ErrUnsupportedFont)
+		}
+	}
+	return nil
+}
+
+// Unicode の cmap（format 4 / 12）を読む
+func parseCmap(cmap []byte) (map[rune]uint16, error) {
+	if len(cmap) < 4 {
+		return nil, fmt.Errorf("%w: short cmap table", ErrUnsupportedFont)
+	}
+	// 全 Unicode（format 12）を優先し、なければ BMP（format 4）
+	best, bestScore := -1, 0
+	for i := range int(u16(cmap, 2)) {
+		rec := 4 + 8*i
+		if rec+8 > len(cmap) {
+			break
+		}
+		platform, encoding, off := u16(cmap, rec), u16(cmap, rec+2), int(u32(cmap, rec+4))
+		if off+2 > len(cmap) {
+			continue
+		}
+		score := 0
+		switch format := u16(cmap, off); {
+		case format == 12 && (platform == 3 && encoding == 10 || platform == 0):
+			score = 2
+		case format == 4 && (platform == 3 && encoding == 1 || platform == 0):
+			score = 1
+		}
+		if score > bestScore {
+			best, bestScore = off, score
+		}
+	}
+	if best < 0 {
+		return nil, fmt.Errorf("%w: no Unicode cmap", ErrUnsupportedFont)
+	}
+
+	sub := cmap[best:]
+	m := map[rune]uint16{}
+	if bestScore == 2 {
+		if len(sub) < 16 {
+			return nil, fmt.Errorf("%w: short cmap subtable", ErrUnsupportedFont)
+		}
+		groups := int(u32(sub, 12))
+		if len(sub) < 16+12*groups {
+			return nil, fmt.Errorf("%w: short cmap subtable", ErrUnsupportedFont)
+		}
+		for i := range groups {
+			g := sub[16+12*i:]
+			start, end, gid := u32(g, 0), u32(g, 4), u32(g, 8)
+			if end < start || end > 0x10FFFF {
+				continue
+			}
+			for c := start; c <= end; c++ {
+				m[rune(c)] = uint16(gid + c - start)
+			}
+		}
+		return m, nil
+	}
+
+	if len(sub) < 14 {
+		return nil, fmt.Errorf("%w: short cmap subtable", ErrUnsupportedFont)
+	}
+	segs := int(u16(sub, 6)) / 2
+	ends, starts := 14, 16+2*segs
+	deltas, ranges := starts+2*segs, starts+4*segs
+	if len(sub) < ranges+2*segs {
+		return nil, fmt.Errorf("%w: short cmap subtable", ErrUnsupportedFont)
+	}
+	for s := range segs {
+		start, end := int(u16(sub, starts+2*s)), int(u16(sub, ends+2*s))
+		delta, ro := u16(sub, deltas+2*s), int(u16(sub, ranges+2*s))
+		for c := start; c <= end && c != 0xFFFF; c++ {
+			var gid uint16
+			if ro == 0 {
+				gid = uint16(c) + delta
+			} else {
+				at := ranges + 2*s + ro + 2*(c-start)
+				if at+2 > len(sub) {
+					break
+				}
+				if gid = u16(sub, at); gid != 0 {
+					gid += delta
+				}
+			}
+			if gid != 0 {
+				m[rune(c)] = gid
+			}
+		}
+	}
+	return m, nil
+}
+
+// name テーブルの PostScript 名（nameID 6）。PDF の名前に使える文字だけ残す
+func postScriptName(name []byte) string {
+	const fallback = "EmbeddedFont"
+	if len(name) < 6 {
+		return fallback
+	}
+	count, strOff := int(u16(name, 2)), int(u16(name, 4))
+	for i := range count {
+		rec := 6 + 12*i
+		if rec+12 > len(name) {
+			break
+		}
+		platform, id := u16(name, rec), u16(name, rec+6)
+		length, off := int(u16(name, rec+8)), strOff+int(u16(name, rec+10))
+		if id != 6 || off+length > len(name) {
+			continue
+		}
+		raw := name[off : off+length]
+		var s string
+		if platform == 3 || platform == 0 {
+			units := make([]uint16, len(raw)/2)
+			for j := range units {
+				units[j] = u16(raw, 2*j)
+			}
+			s = string(utf16.Decode(units))
+		} else {
+			s = string(raw)
+		}
+		s = strings.Map(func(r rune) rune {
+			if r < '!' || r > '~' || strings.ContainsRune("()<>[]{}/%#", r) {
+				return -1
+			}
+			return r
+		}, s)
+		if s != "" {
+			return s
+		}
+	}
+	return fallback
+}
+
+// Identity-H なので文字コードはグリフ番号そのもの
+func (f *TrueTypeFont) glyph(r rune) (uint16, int) {
+	gid := f.cmap[r] // ない文字は .notdef（0）
+	return gid, f.advance(gid)
+}
+
+func (f *TrueTypeFont) advance(gid uint16) int {
+	i := min(int(gid), len(f.advances)-1)
+	return int(f.advances[i]) * 1000 / f.unitsPerEm
+}
+
+func (f *TrueTypeFont) write(w *writer, used map[uint16]rune) (int, error) {
+	gids := make([]uint16, 0, len(used))
+	for g := range used {
+		gids = append(gids, g)
+	}
+	slices.Sort(gids)
+
+	sub, err := f.subset(gids)
+	if err != nil {
+		return 0, err
+	}
+	name := subsetTag(gids) + "+" + f.name
+
+	file := w.alloc()
+	if err := w.stream(file, fmt.Sprintf(" /Length1 %d", len(sub)), sub, true); err != nil {
+		return 0, err
+	}
+
+	scale := func(v int16) int { return int(v) * 1000 / f.unitsPerEm }
+	desc := w.alloc()
+	w.object(desc, fmt.Sprintf("<< /Type /FontDescriptor /FontName /%s /Flags 4 /FontBBox [%d %d %d %d] /ItalicAngle 0 /Ascent %d /Descent %d /CapHeight %d /StemV 80 /FontFile2 %d 0 R >>",
+		name, scale(f.bbox[0]), scale(f.bbox[1]), scale(f.bbox[2]), scale(f.bbox[3]), scale(f.ascent), scale(f.descent), scale(f.ascent), file))
+
+	var widths strings.Builder
+	for _, g := range gids {
+		fmt.Fprintf(&widths, "%d [%d] ", g, f.advance(g))
+	}
+	cid := w.alloc()
+	w.object(cid, fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType2 /BaseFont /%s /CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> /FontDescriptor %d 0 R /DW 1000 /W [%s] /CIDToGIDMap /Identity >>",
+		name, desc, strings.TrimSpace(widths.String())))
+
+	toUnicode := w.alloc()
+	if err := w.stream(toUnicode, "", toUnicodeCMap(gids, used), true); err != nil {
+		return 0, err
+	}
+
+	font := w.alloc()
+	w.object(font, fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /Identity-H /DescendantFonts [%d 0 R] /ToUnicode %d 0 R >>", name, cid, toUnicode))
+	return font, nil
+}
+
+// subset は gids（と複合グリフの部品）以外のグリフを空にしたフォントを作る。
+// グリフ番号は変えないため、Identity-H のコードはそのまま使える
+func (f *TrueTypeFont) subset(gids []uint16) ([]byte, error) {
+	glyf := f.tables["glyf"]
+	keep := map[uint16]bool{}
+	queue := append([]uint16{0}, gids...) // .notdef は必須
+	for len(queue) > 0 {
+		g := queue[0]
+		queue = queue[1:]
+		if keep[g] || int(g) >= f.numGlyphs {
+			continue
+		}
+		keep[g] = true
+		queue = append(queue, components(glyf[f.loca[g]:f.loca[g+1]])...)
+	}
+
+	var newGlyf []byte
+	newLoca := make([]byte, 4*(f.numGlyphs+1))
+	for g := range f.numGlyphs {
+		binary.BigEndian.PutUint32(newLoca[4*g:], uint32(len(newGlyf)))
+		if keep[uint16(g)] {
+			newGlyf = append(newGlyf, glyf[f.loca[g]:f.loca[g+1]]...)
+			for len(newGlyf)%4 != 0 {
+				newGlyf = append(newGlyf, 0)
+			}
+		}
+	}
+	binary.BigEndian.PutUint32(newLoca[4*f.numGlyphs:], uint32(len(newGlyf)))
+
+	head := slices.Clone(f.tables["head"])
+	binary.BigEndian.PutUint32(head[8:], 0)  // checkSumAdjustment は最後に計算する
+	binary.BigEndian.PutUint16(head[50:], 1) // loca は常に long 形式で書く
+
+	tables := map[string][]byte{"glyf": newGlyf, "loca": newLoca, "head": head}
+	for _, tag := range subsetTables {
+		if _, ok := tables[tag]; ok {
+			continue
+		}
+		if t, ok := f.tables[tag]; ok {
+			tables[tag] = t
+		}
+	}
+	out := buildSFNT(tables)
+
+	// checkSumAdjustment = 0xB1B0AFBA - フォント全体のチェックサム
+	for off := 12; off < len(out); off += 16 {
+		if string(out[off:off+4]) == "head" {
+			at := int(u32(out, off+8)) + 8
+			binary.BigEndian.PutUint32(out[at:], 0xB1B0AFBA-checksum(out))
+			break
+		}
+	}
+	return out, nil
+}
+
+// 複合グリフが参照する部品のグリフ番号
+func components(g []byte) []uint16 {
+	if len(g) < 10 || int16(u16(g, 0)) >= 0 {
+		return nil
+	}
+	const (
+		argsAreWords   = 0x0001
+		haveScale      = 0x0008
+		moreComponents = 0x0020
+		haveXYScale    = 0x0040
+		have2x2        = 0x0080
+	)
+	var out []uint16
+	for at := 10; at+4 <= len(g); {
+		flags := u16(g, at)
+		out = append(out, u16(g, at+2))
+		at += 4
+		if flags&argsAreWords != 0 {
+			at += 4
+		} else {
+			at += 2
+		}
+		switch {
+		case flags&haveScale != 0:
+			at += 2
+		case flags&haveXYScale != 0:
+			at += 4
+		case flags&have2x2 != 0:
+			at += 8
+		}
+		if flags&moreComponents == 0 {
+			break
+		}
+	}
+	return out
+}
+
+func buildSFNT(tables map[string][]byte) []byte {
+	tags := make([]string, 0, len(tables))
+	for t := range tables {
+		tags = append(tags, t)
+	}
+	sort.Strings(tags)
+
+	n := len(tags)
+	pow := 1
+	for pow*2 <= n {
+		pow *= 2
+	}
+	entrySelector := 0
+	for 1<<(entrySelector+1) <= pow {
+		entrySelector++
+	}
+
+	out := make([]byte, 12+16*n)
+	binary.BigEndian.PutUint32(out[0:], 0x00010000)
+	binary.BigEndian.PutUint16(out[4:], uint16(n))
+	binary.BigEndian.PutUint16(out[6:], uint16(pow*16))
+	binary.BigEndian.PutUint16(out[8:], uint16(entrySelector))
+	binary.BigEndian.PutUint16(out[10:], uint16(n*16-pow*16))
+
+	for i, tag := range tags {
+		t := tables[tag]
+		rec := out[12+16*i:]
+		copy(rec, tag)
+		binary.BigEndian.PutUint32(rec[4:], checksum(t))
+		binary.BigEndian.PutUint32(rec[8:], uint32(len(out)))
+		binary.BigEndian.PutUint32(rec[12:], uint32(len(t)))
+		out = append(out, t...)
+		for len(out)%4 != 0 {
+			out = append(out, 0)
+		}
+	}
+	return out
+}
+
+func checksum(b []byte) uint32 {
+	var sum uint32
+	for i := 0; i < len(b); i += 4 {
+		var word [4]byte
+		copy(word[:], b[i:])
+		sum += binary.BigEndian.Uint32(word[:])
+	}
+	return sum
+}
+
+// サブセットのフォント名の接頭辞（大文字 6 文字）
+func subsetTag(gids []uint16) string {
+	h := fnv.New32a()
+	for _, g := range gids {
+		_, _ = h.Write([]byte{byte(g >> 8), byte(g)})
+	}
+	v := h.Sum32()
+	tag := make([]byte, 6)
+	for i := range tag {
+		tag[i] = 'A' + byte(v%26)
+		v /= 26
+	}
+	return string(tag)
+}
+
+// テキストのコピー・検索用にグリフ番号 → Unicode の対応を書く
+func toUnicodeCMap(gids []uint16, used map[uint16]rune) []byte {
+	var b strings.Builder
+	b.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n")
+	b.WriteString("/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n")
+	b.WriteString("/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n")
+	b.WriteString("1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+	for chunk := range slices.Chunk(gids, 100) {
+		fmt.Fprintf(&b, "%d beginbfchar\n", len(chunk))
+		for _, g := range chunk {
+			fmt.Fprintf(&b, "<%04X> <", g)
+			for _, u := range utf16.Encode([]rune{used[g]}) {
+				fmt.Fprintf(&b, "%04X", u)
+			}
+			b.WriteString(">\n")
+		}
+		b.WriteString("endbfchar\n")
+	}
+	b.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n")
+	return []byte(b.String())
+}
+
+func u16(b []byte, off int) uint16 { return binary.BigEndian.Uint16(b[off:]) }
+func u32(b []byte, off int) uint32 { return binary.BigEndian.Uint32(b[off:]) }
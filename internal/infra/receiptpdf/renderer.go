@@ -0,0 +1,258 @@
+// Package receiptpdf は領収書（適格請求書）を A4 の PDF に描画する
+package receiptpdf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain/money"
+	"github.com/kazshi01/payment-system/internal/domain/order"
+	"github.com/kazshi01/payment-system/internal/domain/receipt"
+	"github.com/kazshi01/payment-system/internal/domain/tax"
+	"github.com/kazshi01/payment-system/internal/infra/pdf"
+)
+
+// 日付は日本時間で印字する（tzdata のない環境でも使えるよう固定オフセット）
+var jst = time.FixedZone("JST", 9*60*60)
+
+// 余白と表の列（pt）
+const (
+	left   = 50.0
+	right  = pdf.PageWidth - 50
+	bottom = 60.0
+
+	colUnit   = 390.0 // 単価（右端）
+	colQty    = 445.0 // 数量（右端）
+	nameWidth = 250.0 // 品名の最大幅
+
+	rowHeight = 18.0
+)
+
+// Renderer implements domain.ReceiptRenderer.
+type Renderer struct {
+	Font pdf.Font
+}
+
+func New(font pdf.Font) *Renderer { return &Renderer{Font: font} }
+
+func (r *Renderer) Render(d *receipt.Document) ([]byte, error) {
+	o := d.Order
+	doc := pdf.New(r.Font)
+	doc.SetTitle("領収書 " + d.Receipt.NumberString())
+
+	p := doc.AddPage()
+	y := r.header(p, d)
+
+	// ---- 金額 ----
+	y -= 40
+	p.Text(left, y, 12, "金額")
+	p.TextCenter(pdf.PageWidth/2, y, 24, formatMoney(o.Amount)+" -")
+	p.Line(left+120, y-6, right-120, y-6, 1)
+	y -= 28
+	p.Text(left, y, 10, "但し、ご注文 "+string(o.ID)+" の代金として")
+	y -= 16
+	p.Text(left, y, 10, "上記正に領収いたしました。（お支払日 "+formatDate(d.PaidAt)+"）")
+
+	// ---- 明細 ----
+	y -= 36
+	if len(o.Items) > 0 {
+		y = itemHeader(p, y)
+		for _, it := range o.Items {
+			if y < bottom+rowHeight {
+				p = doc.AddPage()
+				y = itemHeader(p, continuation(p, d))
+			}
+			y = itemRow(doc, p, y, it)
+		}
+		y -= 6
+	}
+
+	// ---- 合計・税率ごとの内訳・発行者（改ページしないよう必要な高さを確保する） ----
+	if y < bottom+summaryHeight(d) {
+		p = doc.AddPage()
+		y = continuation(p, d)
+	}
+	y = summary(p, y, d)
+	issuer(p, y-30, d)
+
+	pages := doc.Pages()
+	for i, pg := range pages {
+		pg.TextCenter(pdf.PageWidth/2, 30, 8, fmt.Sprintf("%d / %d", i+1, len(pages)))
+	}
+	return doc.Bytes()
+}
+
+// 1 ページ目の見出し。次に描く位置（y）を返す
+func (r *Renderer) header(p *pdf.Page, d *receipt.Document) float64 {
+	y := pdf.PageHeight - 70
+	p.TextCenter(pdf.PageWidth/2, y, 24, "領 収 書")
+	if d.Receipt.Reissued() {
+		p.Rect(left, y-6, 60, 26, 1.5)
+		p.TextCenter(left+30, y+2, 14, "再発行")
+	}
+	y -= 30
+	p.TextRight(right, y, 10, "No. "+d.Receipt.NumberString())
+	y -= 14
+	p.TextRight(right, y, 10, "発行日 "+formatDate(d.Receipt.IssuedAt))
+	return y
+}
+
+// 2 ページ目以降の見出し
+func continuation(p *pdf.Page, d *receipt.Document) float64 {
+	y := pdf.PageHeight - 60
+	p.Text(left, y, 12, "領収書（続き）")
+	p.TextRight(right, y, 10, "No. "+d.Receipt.NumberString())
+	return y - 30
+}
+
+func itemHeader(p *pdf.Page, y float64) float64 {
+	p.Text(left, y, 9, "品名")
+	p.TextRight(colUnit, y, 9, "単価（税抜）")
+	p.TextRight(colQty, y, 9, "数量")
+	p.TextRight(right, y, 9, "金額（税抜）")
+	p.Line(left, y-5, right, y-5, 0.8)
+	return y - rowHeight
+}
+
+func itemRow(doc *pdf.Document, p *pdf.Page, y float64, it order.Item) float64 {
+	name := it.Name
+	if it.TaxRate == tax.RateReduced {
+		name = "※ " + name
+	}
+	p.Text(left, y, 9, truncate(doc, name, 9, nameWidth))
+	p.TextRight(colUnit, y, 9, formatMoney(it.UnitPrice))
+	p.TextRight(colQty, y, 9, strconv.FormatInt(it.Quantity, 10))
+	if sub, err := it.Subtotal(); err == nil {
+		p.TextRight(right, y, 9, formatMoney(sub))
+	}
+	p.Line(left, y-5, right, y-5, 0.3)
+	return y - rowHeight
+}
+
+func summaryHeight(d *receipt.Document) float64 {
+	h := rowHeight * float64(2+2*len(d.Order.Tax))
+	if d.Order.Coupon != nil {
+		h += rowHeight
+	}
+	return h + 120 // 注記と発行者の欄
+}
+
+// 小計・値引き・税率ごとの対象額と税額・合計。次に描く位置を返す
+// 注文の税額は値引き後の金額に対するもの（値引きは作成時に税率ごとに按分済み）
+func summary(p *pdf.Page, y float64, d *receipt.Document) float64 {
+	o := d.Order
+	const label = 300.0
+	row := func(name, amount string) {
+		p.Text(label, y, 10, name)
+		p.TextRight(right, y, 10, amount)
+		y -= rowHeight
+	}
+
+	c := o.Coupon
+	switch {
+	case c != nil:
+		// 値引きは税込なので、小計も値引き前の税込額で示す
+		subtotal, _ := o.Amount.Add(c.Discount)
+		row("小計（税込）", formatMoney(subtotal))
+		row("値引き（"+c.Code+"）", "-"+formatMoney(c.Discount))
+	case len(o.Tax) > 0:
+		subtotal := money.Zero(o.Amount.Currency)
+		for _, b := range o.Tax {
+			subtotal, _ = subtotal.Add(b.Taxable)
+		}
+		row("小計（税抜）", formatMoney(subtotal))
+	}
+	for _, b := range o.Tax {
+		name := fmt.Sprintf("%d%%対象（税抜）", b.Rate)
+		if c != nil {
+			name = fmt.Sprintf("%d%%対象（値引き後・税抜）", b.Rate)
+		}
+		row(name, formatMoney(b.Taxable))
+		row(fmt.Sprintf("　消費税（%d%%）", b.Rate), formatMoney(b.Tax))
+	}
+	p.Line(label, y+rowHeight-5, right, y+rowHeight-5, 0.8)
+	y -= 4
+	p.Text(label, y, 12, "合計（税込）")
+	p.TextRight(right, y, 12, formatMoney(o.Amount))
+	y -= rowHeight
+
+	if hasReduced(o.Items) {
+		p.Text(left, y, 8, "※ は軽減税率（8%）対象です。")
+		y -= 12
+	}
+	return y
+}
+
+// 発行者（適格請求書発行事業者）の欄
+func issuer(p *pdf.Page, y float64, d *receipt.Document) {
+	const x = 300.0
+	p.Text(x, y, 11, d.Issuer.Name)
+	y -= 16
+	if d.Issuer.Address != "" {
+		p.Text(x, y, 9, d.Issuer.Address)
+		y -= 14
+	}
+	if d.Issuer.RegistrationNumber != "" {
+		p.Text(x, y, 9, "登録番号 "+d.Issuer.RegistrationNumber)
+	}
+}
+
+func hasReduced(items []order.Item) bool {
+	for _, it := range items {
+		if it.TaxRate == tax.RateReduced {
+			return true
+		}
+	}
+	return false
+}
+
+// 幅に収まらない品名は末尾を「…」にする
+func truncate(doc *pdf.Document, s string, size, width float64) string {
+	if doc.TextWidth(s, size) <= width {
+		return s
+	}
+	rs := []rune(s)
+	for len(rs) > 0 && doc.TextWidth(string(rs)+"…", size) > width {
+		rs = rs[:len(rs)-1]
+	}
+	return string(rs) + "…"
+}
+
+func formatDate(t time.Time) string {
+	return t.In(jst).Format("2006年1月2日")
+}
+
+// formatMoney は "¥1,234"（JPY）/ "1,234.50 USD" 形式
+func formatMoney(m money.Money) string {
+	sign, a := "", m.Amount
+	if a < 0 {
+		sign, a = "-", -a
+	}
+	exp := m.Currency.Exponent()
+	unit := int64(1)
+	for range exp {
+		unit *= 10
+	}
+	s := groupDigits(a / unit)
+	if exp > 0 {
+		s += fmt.Sprintf(".%0*d", exp, a%unit)
+	}
+	if m.Currency == money.JPY {
+		return sign + "¥" + s
+	}
+	return sign + s + " " + string(m.Currency)
+}
+
+func groupDigits(n int64) string {
+	s := strconv.FormatInt(n, 10)
+	var b strings.Builder
+	for i, c := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
@@ -0,0 +1,123 @@
+package receiptpdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain/coupon"
+	"github.com/kazshi01/payment-system/internal/domain/merchant"
+	"github.com/kazshi01/payment-system/internal/domain/money"
+	"github.com/kazshi01/payment-system/internal/domain/order"
+	"github.com/kazshi01/payment-system/internal/domain/receipt"
+	"github.com/kazshi01/payment-system/internal/domain/tax"
+	"github.com/kazshi01/payment-system/internal/infra/pdf"
+)
+
+func TestFormatMoney(t *testing.T) {
+	tests := []struct {
+		m    money.Money
+		want string
+	}{
+		{money.Money{Amount: 0, Currency: money.JPY}, "¥0"},
+		{money.Money{Amount: 999, Currency: money.JPY}, "¥999"},
+		{money.Money{Amount: 1234567, Currency: money.JPY}, "¥1,234,567"},
+		{money.Money{Amount: -500, Currency: money.JPY}, "-¥500"},
+		{money.Money{Amount: 123456, Currency: money.USD}, "1,234.56 USD"},
+		{money.Money{Amount: 5, Currency: money.EUR}, "0.05 EUR"},
+	}
+	for _, tt := range tests {
+		if got := formatMoney(tt.m); got != tt.want {
+			t.Fatalf("formatMoney(%v) = %q; want %q", tt.m, got, tt.want)
+		}
+	}
+}
+
+// 標準フォントの文字コードは UCS-2 なので、展開したページの内容に 16 進で現れる
+func hexText(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		fmt.Fprintf(&b, "%04X", r)
+	}
+	return b.String()
+}
+
+// 圧縮されたストリームをすべて展開してつなげる
+func inflate(t *testing.T, b []byte) string {
+	t.Helper()
+	var out strings.Builder
+	re := regexp.MustCompile(`(?s)/FlateDecode >>\nstream\n(.*?)\nendstream`)
+	for _, m := range re.FindAllSubmatch(b, -1) {
+		zr, err := zlib.NewReader(bytes.NewReader(m[1]))
+		if err != nil {
+			t.Fatalf("zlib: %v", err)
+		}
+		data, err := io.ReadAll(zr)
+		if err != nil {
+			t.Fatalf("zlib: %v", err)
+		}
+		out.Write(data)
+	}
+	return out.String()
+}
+
+func TestRenderer_Render(t *testing.T) {
+	yen := func(n int64) money.Money { return money.Money{Amount: n, Currency: money.JPY} }
+	items := make([]order.Item, 40) // 1 ページに収まらない
+	for i := range items {
+		items[i] = order.Item{SKU: fmt.Sprintf("sku-%d", i), Name: "コーヒー豆", UnitPrice: yen(100), Quantity: 1, TaxRate: tax.RateStandard}
+	}
+	items[0].TaxRate = tax.RateReduced
+
+	doc := &receipt.Document{
+		Receipt: receipt.Receipt{OrderID: "order-1", IssuerID: "user-1", Number: 42, IssuedAt: time.Date(2026, 10, 1, 20, 0, 0, 0, time.UTC), Downloads: 1},
+		Issuer:  merchant.Issuer{Name: "株式会社サンプル", Address: "東京都千代田区1-1", RegistrationNumber: "T1234567890123"},
+		Order: &order.Order{
+			ID:     "order-1",
+			Amount: yen(4000),
+			Status: order.StatusPaid,
+			Items:  items,
+			Tax: []tax.Bucket{
+				// 値引き 398 円を按分した後の内訳（税込 3,902 / 98）
+				{Rate: tax.RateStandard, Taxable: yen(3548), Tax: yen(354)},
+				{Rate: tax.RateReduced, Taxable: yen(91), Tax: yen(7)},
+			},
+			Coupon: &coupon.Redemption{Code: "WELCOME", Discount: yen(398)},
+		},
+		PaidAt: time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC),
+	}
+
+	r := New(pdf.StandardJapaneseFont())
+	b, err := r.Render(doc)
+	if err != nil {
+		t.Fatalf("Render err = %v", err)
+	}
+	if !bytes.HasPrefix(b, []byte("%PDF-1.7")) || !bytes.Contains(b, []byte("/Count 2")) {
+		t.Fatalf("want a 2-page PDF")
+	}
+	text := inflate(t, b)
+	for _, s := range []string{"R-00000042", "登録番号 T1234567890123", "2026年10月2日", "※ コーヒー豆", "¥4,000", "WELCOME", "小計（税込）", "¥4,398", "10%対象（値引き後・税抜）", "¥3,548", "¥354"} {
+		if !strings.Contains(text, hexText(s)) {
+			t.Errorf("missing %q", s)
+		}
+	}
+	if strings.Contains(text, hexText("値引き前")) {
+		t.Errorf("tax is still described as pre-discount")
+	}
+	if strings.Contains(text, hexText("再発行")) {
+		t.Errorf("first download is marked as reissued")
+	}
+
+	doc.Receipt.Downloads = 2
+	if b, err = r.Render(doc); err != nil {
+		t.Fatalf("Render err = %v", err)
+	}
+	if !strings.Contains(inflate(t, b), hexText("再発行")) {
+		t.Errorf("reissued receipt is missing 再発行")
+	}
+}
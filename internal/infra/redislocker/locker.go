@@ -7,8 +7,14 @@ import (
 	"time"
 
 	"github.com/redis/go-redis/v9"
+
+	"github.com/kazshi01/payment-system/internal/domain"
 )
 
+// フェンシングトークンの採番キー（全ロック共通の単調増加カウンタ）
+// 消えると番号が巻き戻り DB 側で書き込みが拒否されるため、永続化（AOF/RDB）を前提とする
+const fenceKey = "lock:fence"
+
 type Locker struct{ cli *redis.Client }
 
 func New(addr, password string, db int) *Locker {
@@ -25,19 +31,45 @@ func randToken() (string, error) {
 	return hex.EncodeToString(b), nil
 }
 
-func (l *Locker) TryLock(ctx context.Context, key string, ttlSeconds int) (bool, string, error) {
+// 取得できた場合のみフェンスを採番するLua（取得できなければ 0）
+var luaLock = redis.NewScript(`
+if redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then
+  return redis.call("INCR", KEYS[2])
+else
+  return 0
+end
+`)
+
+func (l *Locker) TryLock(ctx context.Context, key string, ttl time.Duration) (bool, domain.Lock, error) {
 	token, err := randToken()
 	if err != nil {
-		return false, "", err
+		return false, domain.Lock{}, err
 	}
-	ok, err := l.cli.SetNX(ctx, key, token, time.Duration(ttlSeconds)*time.Second).Result()
+	fence, err := luaLock.Run(ctx, l.cli, []string{key, fenceKey}, token, ttl.Milliseconds()).Int64()
 	if err != nil {
-		return false, "", err
+		return false, domain.Lock{}, err
 	}
-	if !ok {
-		return false, "", nil
+	if fence == 0 {
+		return false, domain.Lock{}, nil
 	} // 既にロックあり
-	return true, token, nil
+	return true, domain.Lock{Key: key, Token: token, Fence: fence}, nil
+}
+
+// value一致時のみ PEXPIRE するLua
+var luaExtend = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+  return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+else
+  return 0
+end
+`)
+
+func (l *Locker) Extend(ctx context.Context, lock domain.Lock, ttl time.Duration) (bool, error) {
+	n, err := luaExtend.Run(ctx, l.cli, []string{lock.Key}, lock.Token, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
 }
 
 // value一致時のみDELするLua
@@ -49,8 +81,8 @@ else
 end
 `)
 
-func (l *Locker) Unlock(ctx context.Context, key, token string) error {
-	_, err := luaUnlock.Run(ctx, l.cli, []string{key}, token).Result()
+func (l *Locker) Unlock(ctx context.Context, lock domain.Lock) error {
+	_, err := luaUnlock.Run(ctx, l.cli, []string{lock.Key}, lock.Token).Result()
 	return err
 }
 
@@ -0,0 +1,32 @@
+package redislocker_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/infra/lockertest"
+	"github.com/kazshi01/payment-system/internal/infra/redislocker"
+)
+
+// TEST_REDIS_ADDR（例: localhost:6379）がある場合のみ実行する
+func TestLocker_conformance(t *testing.T) {
+	addr := os.Getenv("TEST_REDIS_ADDR")
+	if addr == "" {
+		t.Skip("TEST_REDIS_ADDR not set")
+	}
+
+	lockertest.Run(t, func(t *testing.T) domain.Locker {
+		l := redislocker.New(addr, os.Getenv("TEST_REDIS_PASSWORD"), 0)
+		t.Cleanup(func() { _ = l.Close() })
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		if err := l.Ping(ctx); err != nil {
+			t.Fatalf("redis ping: %v", err)
+		}
+		return l
+	})
+}
@@ -0,0 +1,66 @@
+package redisstream
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+)
+
+// DefaultStream は注文イベントを流す Redis Stream のキー
+const DefaultStream = "payment:order-events"
+
+// Publisher は domain.EventPublisher の Redis Streams 実装（XADD）
+type Publisher struct {
+	cli    *redis.Client
+	stream string
+	maxLen int64 // 0 なら無制限
+}
+
+func New(addr, password string, db int, stream string, maxLen int64) *Publisher {
+	if stream == "" {
+		stream = DefaultStream
+	}
+	return &Publisher{
+		cli: redis.NewClient(&redis.Options{
+			Addr: addr, Password: password, DB: db,
+		}),
+		stream: stream,
+		maxLen: maxLen,
+	}
+}
+
+// Publish はメッセージを 1 エントリとして追加する。
+// 下流は id フィールドで重複排除する（relay は at-least-once）
+func (p *Publisher) Publish(ctx context.Context, m *domain.OutboxMessage) error {
+	args := &redis.XAddArgs{
+		Stream: p.stream,
+		Values: map[string]any{
+			"id":         m.ID,
+			"order_id":   m.AggregateID,
+			"type":       m.Type,
+			"payload":    string(m.Payload),
+			"created_at": m.CreatedAt.UTC().Format(time.RFC3339Nano),
+		},
+	}
+	if p.maxLen > 0 {
+		args.MaxLen = p.maxLen
+		args.Approx = true
+	}
+
+	if err := p.cli.XAdd(ctx, args).Err(); err != nil {
+		return fmt.Errorf("xadd %s: %w", p.stream, err)
+	}
+	return nil
+}
+
+func (p *Publisher) Ping(ctx context.Context) error {
+	return p.cli.Ping(ctx).Err()
+}
+
+func (p *Publisher) Close() error {
+	return p.cli.Close()
+}
@@ -0,0 +1,84 @@
+package webhooksender
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain/webhook"
+)
+
+// Sender は domain.WebhookSender の net/http 実装
+type Sender struct {
+	cli *http.Client
+}
+
+// New は timeout（0 なら 10s）で打ち切る Sender を返す。リダイレクトは追わない。
+// 接続先は名前解決後のアドレスで検査し、内部アドレスには接続しない（DNS rebinding 対策）
+func New(timeout time.Duration) *Sender {
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	dialer := &net.Dialer{
+		Timeout:   5 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   refusePrivate,
+	}
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	tr.Proxy = nil // プロキシ経由だと接続先を検査できない
+	tr.DialContext = dialer.DialContext
+
+	return &Sender{cli: &http.Client{
+		Transport: tr,
+		Timeout:   timeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}}
+}
+
+// 接続直前に呼ばれる。address は名前解決済みの ip:port
+func refusePrivate(network, address string, _ syscall.RawConn) error {
+	ap, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("webhook: unexpected dial address %q: %w", address, err)
+	}
+	if !webhook.IsPublicAddr(ap.Addr()) {
+		return fmt.Errorf("webhook: refusing to connect to non-public address %s", ap.Addr())
+	}
+	return nil
+}
+
+func (s *Sender) Send(ctx context.Context, rawURL string, header map[string]string, body []byte) (int, error) {
+	// https 必須にする前に登録された http の通知先にも送らない
+	if u, err := url.Parse(rawURL); err != nil || u.Scheme != "https" {
+		return 0, fmt.Errorf("webhook: url must be https: %q", rawURL)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rawURL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "payment-system-webhook/1.0")
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := s.cli.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// コネクション再利用のため読み捨てる（上限付き）
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	return resp.StatusCode, nil
+}
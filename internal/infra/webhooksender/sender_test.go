@@ -0,0 +1,33 @@
+package webhooksender_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/infra/webhooksender"
+)
+
+// 名前解決後のアドレスがループバックなら接続しない（DNS rebinding で登録時の検査を抜けても送らない）
+func TestSender_refusesPrivateAddress(t *testing.T) {
+	hit := false
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { hit = true }))
+	t.Cleanup(ts.Close)
+
+	s := webhooksender.New(time.Second)
+	for _, u := range []string{ts.URL, "https://localhost:" + ts.URL[strings.LastIndex(ts.URL, ":")+1:]} {
+		if _, err := s.Send(context.Background(), u+"/hook", nil, []byte(`{}`)); err == nil || !strings.Contains(err.Error(), "non-public address") {
+			t.Errorf("Send(%s) err = %v; want refused", u, err)
+		}
+	}
+	if hit {
+		t.Fatal("request reached the private server")
+	}
+
+	if _, err := s.Send(context.Background(), "http://example.com/hook", nil, []byte(`{}`)); err == nil {
+		t.Fatal("Send(http) err = nil; want error")
+	}
+}
@@ -0,0 +1,176 @@
+package httpi
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain/banktransfer"
+	"github.com/kazshi01/payment-system/internal/domain/order"
+)
+
+type bankTransferJSON struct {
+	ID            string `json:"id"`
+	OrderID       string `json:"order_id"`
+	Mode          string `json:"mode"`
+	BankCode      string `json:"bank_code"`
+	BankName      string `json:"bank_name"`
+	BranchCode    string `json:"branch_code"`
+	BranchName    string `json:"branch_name"`
+	AccountType   string `json:"account_type"`
+	AccountNumber string `json:"account_number"`
+	AccountName   string `json:"account_name"`
+	ReferenceCode string `json:"reference_code,omitempty"`
+	moneyJSON
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func toBankTransferJSON(t *banktransfer.Transfer) bankTransferJSON {
+	return bankTransferJSON{
+		ID:            t.ID,
+		OrderID:       t.OrderID,
+		Mode:          string(t.Mode),
+		BankCode:      t.BankCode,
+		BankName:      t.BankName,
+		BranchCode:    t.BranchCode,
+		BranchName:    t.BranchName,
+		AccountType:   string(t.AccountType),
+		AccountNumber: t.AccountNumber,
+		AccountName:   t.AccountName,
+		ReferenceCode: t.ReferenceCode,
+		moneyJSON:     toMoneyJSON(t.Amount),
+		Status:        string(t.Status),
+		CreatedAt:     t.CreatedAt,
+	}
+}
+
+type bankDepositJSON struct {
+	ID            string `json:"id"`
+	Source        string `json:"source"`
+	Line          int    `json:"line"`
+	BranchCode    string `json:"branch_code"`
+	AccountNumber string `json:"account_number"`
+	InquiryNo     string `json:"inquiry_no"`
+	ValueDate     string `json:"value_date"` // YYYY-MM-DD
+	moneyJSON
+	PayerCode       string    `json:"payer_code,omitempty"`
+	PayerName       string    `json:"payer_name"`
+	RemittingBank   string    `json:"remitting_bank"`
+	RemittingBranch string    `json:"remitting_branch"`
+	Canceled        bool      `json:"canceled"`
+	OrderID         string    `json:"order_id,omitempty"`
+	Status          string    `json:"status"`
+	Reason          string    `json:"reason,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+func toBankDepositJSON(d *banktransfer.Deposit) bankDepositJSON {
+	return bankDepositJSON{
+		ID:              d.ID,
+		Source:          d.Source,
+		Line:            d.Line,
+		BranchCode:      d.BranchCode,
+		AccountNumber:   d.AccountNumber,
+		InquiryNo:       d.InquiryNo,
+		ValueDate:       d.ValueDate.Format(time.DateOnly),
+		moneyJSON:       toMoneyJSON(d.Amount),
+		PayerCode:       d.PayerCode,
+		PayerName:       d.PayerName,
+		RemittingBank:   d.RemittingBank,
+		RemittingBranch: d.RemittingBranch,
+		Canceled:        d.Canceled,
+		OrderID:         d.OrderID,
+		Status:          string(d.Status),
+		Reason:          string(d.Reason),
+		CreatedAt:       d.CreatedAt,
+	}
+}
+
+// POST /orders/{id}/bank-transfer
+func (h *OrderHandler) IssueBankTransfer(w http.ResponseWriter, r *http.Request) {
+	id := order.ID(r.PathValue("id"))
+	if id == "" {
+		http.Error(w, "missing id", http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		PayerName string `json:"payer_name"`
+	}
+	if !decodeOptionalJSON(w, r, &body) {
+		return
+	}
+
+	t, err := h.UC.IssueBankTransfer(r.Context(), id, body.PayerName)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	log.Printf("IssueBankTransfer success: order_id=%s transfer_id=%s", id, t.ID)
+
+	WriteJSON(w, http.StatusOK, toBankTransferJSON(t))
+}
+
+// POST /bank-statements?source=
+// ボディは振込入金通知のファイルそのもの（Content-Type は問わない）
+func (h *OrderHandler) ImportBankStatement(w http.ResponseWriter, r *http.Request) {
+	// 1 明細 200 バイトなので 5 万件程度まで
+	r.Body = http.MaxBytesReader(w, r.Body, 10<<20) // 10MB
+	defer r.Body.Close()
+
+	file, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "read body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	source := r.URL.Query().Get("source")
+	if source == "" {
+		source = "upload-" + time.Now().UTC().Format("20060102T150405Z")
+	}
+
+	res, err := h.UC.ImportBankStatement(r.Context(), source, bytes.NewReader(file))
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	log.Printf("ImportBankStatement success: source=%s paid=%d review=%d skipped=%d", source, res.Paid, res.Review, res.Skipped)
+
+	WriteJSON(w, http.StatusOK, map[string]int{
+		"paid":    res.Paid,
+		"review":  res.Review,
+		"skipped": res.Skipped,
+	})
+}
+
+// GET /bank-deposits?status=&limit=
+func (h *OrderHandler) ListBankDeposits(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var limit int
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	ds, err := h.UC.ListBankDeposits(r.Context(), banktransfer.DepositStatus(q.Get("status")), limit)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+	resp := struct {
+		Items []bankDepositJSON `json:"items"`
+	}{Items: make([]bankDepositJSON, 0, len(ds))}
+	for _, d := range ds {
+		resp.Items = append(resp.Items, toBankDepositJSON(d))
+	}
+	WriteJSON(w, http.StatusOK, resp)
+}
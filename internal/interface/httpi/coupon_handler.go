@@ -0,0 +1,130 @@
+package httpi
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/coupon"
+	"github.com/kazshi01/payment-system/internal/domain/money"
+	"github.com/kazshi01/payment-system/internal/usecase"
+)
+
+// 金額は currency の最小単位
+type couponJSON struct {
+	ID             string     `json:"id"`
+	Code           string     `json:"code"`
+	Kind           string     `json:"kind"`
+	PercentOff     int64      `json:"percent_off,omitempty"`
+	AmountOff      int64      `json:"amount_off,omitempty"`
+	MinAmount      int64      `json:"min_amount,omitempty"`
+	Currency       string     `json:"currency,omitempty"`
+	MaxRedemptions int64      `json:"max_redemptions"`
+	MaxPerUser     int64      `json:"max_per_user"`
+	Redeemed       int64      `json:"redeemed"`
+	ValidFrom      time.Time  `json:"valid_from"`
+	ValidUntil     *time.Time `json:"valid_until,omitempty"` // 無期限なら省略
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+func toCouponJSON(c *coupon.Coupon) couponJSON {
+	j := couponJSON{
+		ID:             c.ID,
+		Code:           c.Code,
+		Kind:           string(c.Kind),
+		PercentOff:     c.PercentOff,
+		AmountOff:      c.AmountOff.Amount,
+		MinAmount:      c.MinAmount.Amount,
+		Currency:       string(c.Currency()),
+		MaxRedemptions: c.MaxRedemptions,
+		MaxPerUser:     c.MaxPerUser,
+		Redeemed:       c.Redeemed,
+		ValidFrom:      c.ValidFrom,
+		CreatedAt:      c.CreatedAt,
+	}
+	if !c.ValidUntil.IsZero() {
+		t := c.ValidUntil
+		j.ValidUntil = &t
+	}
+	return j
+}
+
+type CouponHandler struct {
+	UC *usecase.CouponUsecase
+}
+
+// POST /coupons
+func (h *CouponHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Code           string     `json:"code"`
+		Kind           string     `json:"kind"`
+		PercentOff     int64      `json:"percent_off"`
+		AmountOff      int64      `json:"amount_off"`
+		MinAmount      int64      `json:"min_amount"`
+		Currency       string     `json:"currency"` // amount_off・min_amount の通貨
+		MaxRedemptions int64      `json:"max_redemptions"`
+		MaxPerUser     int64      `json:"max_per_user"`
+		ValidFrom      *time.Time `json:"valid_from"`
+		ValidUntil     *time.Time `json:"valid_until"`
+	}
+	if !decodeOptionalJSON(w, r, &body) {
+		return
+	}
+
+	var cur money.Currency
+	if body.Currency != "" {
+		var err error
+		if cur, err = money.ParseCurrency(body.Currency); err != nil {
+			WriteError(w, fmt.Errorf("%w: %w", domain.ErrInvalidArgument, err))
+			return
+		}
+	}
+	in := &coupon.Coupon{
+		Code:           body.Code,
+		Kind:           coupon.Kind(body.Kind),
+		PercentOff:     body.PercentOff,
+		MaxRedemptions: body.MaxRedemptions,
+		MaxPerUser:     body.MaxPerUser,
+	}
+	if body.AmountOff != 0 {
+		in.AmountOff = money.Money{Amount: body.AmountOff, Currency: cur}
+	}
+	if body.MinAmount != 0 {
+		in.MinAmount = money.Money{Amount: body.MinAmount, Currency: cur}
+	}
+	if body.ValidFrom != nil {
+		in.ValidFrom = *body.ValidFrom
+	}
+	if body.ValidUntil != nil {
+		in.ValidUntil = *body.ValidUntil
+	}
+
+	c, err := h.UC.CreateCoupon(r.Context(), in)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	log.Printf("CreateCoupon success: coupon_id=%s code=%s", c.ID, c.Code)
+
+	w.Header().Set("Location", "/coupons/"+c.Code)
+	WriteJSON(w, http.StatusCreated, toCouponJSON(c))
+}
+
+// GET /coupons/{code}
+func (h *CouponHandler) Get(w http.ResponseWriter, r *http.Request) {
+	code := r.PathValue("code")
+	if code == "" {
+		http.Error(w, "missing code", http.StatusBadRequest)
+		return
+	}
+
+	c, err := h.UC.GetCoupon(r.Context(), code)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+	WriteJSON(w, http.StatusOK, toCouponJSON(c))
+}
@@ -0,0 +1,200 @@
+package httpi
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/auth"
+	"github.com/kazshi01/payment-system/internal/domain"
+)
+
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	defaultIdempotencyKeyTTL = 24 * time.Hour
+	maxIdempotencyKeyLength  = 255
+	idempotencyStoreTimeout  = 3 * time.Second
+)
+
+type IdempotencyConfig struct {
+	Store domain.IdempotencyStore
+	TTL   time.Duration    // キーの有効期間（0 なら 24h）
+	Now   func() time.Time // nil なら time.Now
+}
+
+// Idempotency は Idempotency-Key ヘッダ付きのリクエストについて、
+// ユーザ単位で最初のレスポンス（ステータス・ヘッダ・ボディ）を保存し、リトライ時は再生する。
+// auth.Middleware の内側で使う（ユーザID が必要）
+func Idempotency(cfg IdempotencyConfig) func(http.Handler) http.Handler {
+	if cfg.TTL <= 0 {
+		cfg.TTL = defaultIdempotencyKeyTTL
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				WriteError(w, fmt.Errorf("%w: %s must be at most %d characters", domain.ErrInvalidArgument, IdempotencyKeyHeader, maxIdempotencyKeyLength))
+				return
+			}
+
+			userID, ok := auth.UserIDFrom(r.Context())
+			if !ok || userID == "" {
+				WriteError(w, domain.ErrUnauthorized)
+				return
+			}
+
+			// 指紋を取るためにボディを読み切り、ハンドラ用に戻す
+			r.Body = http.MaxBytesReader(w, r.Body, 1<<20) // 1MB
+			body, err := io.ReadAll(r.Body)
+			_ = r.Body.Close()
+			if err != nil {
+				http.Error(w, "read body: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			now := cfg.Now()
+			rec := &domain.IdempotencyRecord{
+				UserID:      userID,
+				Key:         key,
+				Fingerprint: requestFingerprint(r, body),
+				CreatedAt:   now,
+				ExpiresAt:   now.Add(cfg.TTL),
+			}
+
+			ctx, cancel := context.WithTimeout(r.Context(), idempotencyStoreTimeout)
+			existing, err := cfg.Store.Reserve(ctx, rec)
+			cancel()
+			if err != nil {
+				WriteError(w, err)
+				return
+			}
+			if existing != nil {
+				replayIdempotent(w, existing, rec.Fingerprint)
+				return
+			}
+
+			rw := &recordingWriter{ResponseWriter: w, status: http.StatusOK}
+			completed := false
+			defer func() {
+				// panic 等で保存できなかった場合は、リトライできるようキーを解放する
+				if !completed {
+					releaseIdempotencyKey(cfg.Store, userID, key)
+				}
+			}()
+
+			next.ServeHTTP(rw, r)
+
+			// 5xx は一時的な失敗とみなして保存しない（同じキーでリトライできる）
+			if rw.status >= http.StatusInternalServerError {
+				return
+			}
+
+			// 処理は完了しているので、保存に失敗してもキーは解放しない
+			// （解放すると二重作成になり得る。期限切れまで 409 を返す）
+			completed = true
+
+			rec.StatusCode = rw.status
+			rec.Header = storedHeader(w.Header())
+			rec.Body = rw.buf.Bytes()
+
+			ctx, cancel = context.WithTimeout(context.WithoutCancel(r.Context()), idempotencyStoreTimeout)
+			defer cancel()
+			if err := cfg.Store.Complete(ctx, rec); err != nil {
+				log.Printf("warn: complete idempotency key: user_id=%s key=%s: %v", userID, key, err)
+			}
+		})
+	}
+}
+
+func replayIdempotent(w http.ResponseWriter, rec *domain.IdempotencyRecord, fingerprint string) {
+	if rec.Fingerprint != fingerprint {
+		WriteError(w, domain.ErrIdempotencyKeyReused)
+		return
+	}
+	// 最初のリクエストがまだ処理中
+	if !rec.Completed() {
+		WriteError(w, fmt.Errorf("%w: a request with the same %s is in progress", domain.ErrConflict, IdempotencyKeyHeader))
+		return
+	}
+
+	for k, vs := range rec.Header {
+		for _, v := range vs {
+			w.Header().Add(k, v)
+		}
+	}
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(rec.StatusCode)
+	_, _ = w.Write(rec.Body)
+}
+
+func releaseIdempotencyKey(store domain.IdempotencyStore, userID, key string) {
+	ctx, cancel := context.WithTimeout(context.Background(), idempotencyStoreTimeout)
+	defer cancel()
+	if err := store.Release(ctx, userID, key); err != nil {
+		log.Printf("warn: release idempotency key: user_id=%s key=%s: %v", userID, key, err)
+	}
+}
+
+// 同じキーで別の操作・別の内容を送ってきたことを検出するためのハッシュ
+func requestFingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method))
+	h.Write([]byte{0})
+	h.Write([]byte(r.URL.Path))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// 再生するヘッダ（接続ごとに変わるものは除く）
+func storedHeader(h http.Header) map[string][]string {
+	out := make(map[string][]string, len(h))
+	for k, vs := range h {
+		switch k {
+		case "Date", "Content-Length", "Connection":
+			continue
+		}
+		out[k] = append([]string(nil), vs...)
+	}
+	return out
+}
+
+// レスポンスをクライアントに書きつつ、保存用に控える
+type recordingWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	buf         bytes.Buffer
+}
+
+func (rw *recordingWriter) WriteHeader(code int) {
+	if !rw.wroteHeader {
+		rw.status = code
+		rw.wroteHeader = true
+	}
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+func (rw *recordingWriter) Write(b []byte) (int, error) {
+	if !rw.wroteHeader {
+		rw.WriteHeader(http.StatusOK)
+	}
+	rw.buf.Write(b)
+	return rw.ResponseWriter.Write(b)
+}
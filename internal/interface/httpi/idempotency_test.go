@@ -0,0 +1,141 @@
+package httpi_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/auth"
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/interface/httpi"
+)
+
+type memIdempotencyStore struct {
+	mu sync.Mutex
+	m  map[string]*domain.IdempotencyRecord
+}
+
+func newMemIdempotencyStore() *memIdempotencyStore {
+	return &memIdempotencyStore{m: map[string]*domain.IdempotencyRecord{}}
+}
+
+func (s *memIdempotencyStore) Reserve(ctx context.Context, rec *domain.IdempotencyRecord) (*domain.IdempotencyRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k := rec.UserID + "/" + rec.Key
+	if cur, ok := s.m[k]; ok && cur.ExpiresAt.After(rec.CreatedAt) {
+		cp := *cur
+		return &cp, nil
+	}
+	cp := *rec
+	s.m[k] = &cp
+	return nil, nil
+}
+
+func (s *memIdempotencyStore) Complete(ctx context.Context, rec *domain.IdempotencyRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cp := *rec
+	s.m[rec.UserID+"/"+rec.Key] = &cp
+	return nil
+}
+
+func (s *memIdempotencyStore) Release(ctx context.Context, userID, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cur, ok := s.m[userID+"/"+key]; ok && !cur.Completed() {
+		delete(s.m, userID+"/"+key)
+	}
+	return nil
+}
+
+func (s *memIdempotencyStore) DeleteExpired(ctx context.Context, now time.Time, limit int) (int64, error) {
+	return 0, nil
+}
+
+type idemFixture struct {
+	h     http.Handler
+	calls int
+	now   time.Time
+}
+
+func newIdemFixture(status int) *idemFixture {
+	f := &idemFixture{now: time.Date(2025, 9, 27, 7, 0, 0, 0, time.UTC)}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.calls++
+		w.Header().Set("Location", "/orders/o-1")
+		httpi.WriteJSON(w, status, map[string]any{"call": f.calls})
+	})
+	f.h = httpi.Idempotency(httpi.IdempotencyConfig{
+		Store: newMemIdempotencyStore(),
+		TTL:   time.Hour,
+		Now:   func() time.Time { return f.now },
+	})(next)
+	return f
+}
+
+func (f *idemFixture) do(userID, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(httpi.IdempotencyKeyHeader, key)
+	}
+	ctx := context.WithValue(req.Context(), auth.ClaimsKey, map[string]any{"sub": userID})
+	rec := httptest.NewRecorder()
+	f.h.ServeHTTP(rec, req.WithContext(ctx))
+	return rec
+}
+
+func TestIdempotency_replay(t *testing.T) {
+	f := newIdemFixture(http.StatusCreated)
+
+	first := f.do("user-1", "k1", `{"amount_jpy":1200}`)
+	second := f.do("user-1", "k1", `{"amount_jpy":1200}`)
+
+	if f.calls != 1 {
+		t.Fatalf("handler calls = %d; want 1", f.calls)
+	}
+	if second.Code != http.StatusCreated || second.Body.String() != first.Body.String() {
+		t.Fatalf("replay = %d %q; want %d %q", second.Code, second.Body.String(), first.Code, first.Body.String())
+	}
+	if second.Header().Get("Location") != "/orders/o-1" || second.Header().Get(httpi.IdempotentReplayedHeader) != "true" {
+		t.Fatalf("replay headers = %v", second.Header())
+	}
+
+	// キーはユーザ単位
+	if rec := f.do("user-2", "k1", `{"amount_jpy":1200}`); rec.Header().Get(httpi.IdempotentReplayedHeader) != "" || f.calls != 2 {
+		t.Fatalf("other user's request was replayed: calls=%d", f.calls)
+	}
+}
+
+func TestIdempotency_mismatch(t *testing.T) {
+	f := newIdemFixture(http.StatusCreated)
+
+	f.do("user-1", "k1", `{"amount_jpy":1200}`)
+	if rec := f.do("user-1", "k1", `{"amount_jpy":999}`); rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d; want 422", rec.Code)
+	}
+}
+
+func TestIdempotency_expiry(t *testing.T) {
+	f := newIdemFixture(http.StatusCreated)
+
+	f.do("user-1", "k1", `{"amount_jpy":1200}`)
+	f.now = f.now.Add(2 * time.Hour)
+	if rec := f.do("user-1", "k1", `{"amount_jpy":999}`); rec.Code != http.StatusCreated || f.calls != 2 {
+		t.Fatalf("status = %d calls = %d; want new request after expiry", rec.Code, f.calls)
+	}
+}
+
+func TestIdempotency_serverErrorNotStored(t *testing.T) {
+	f := newIdemFixture(http.StatusBadGateway)
+
+	f.do("user-1", "k1", `{"amount_jpy":1200}`)
+	f.do("user-1", "k1", `{"amount_jpy":1200}`)
+	if f.calls != 2 {
+		t.Fatalf("handler calls = %d; want 2 (5xx must be retryable)", f.calls)
+	}
+}
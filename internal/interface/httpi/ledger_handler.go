@@ -0,0 +1,68 @@
+package httpi
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/usecase"
+)
+
+// 金額は currency の最小単位。from / to は指定されたときのみ返す
+type balanceJSON struct {
+	Account  string     `json:"account"`
+	Currency string     `json:"currency"`
+	Debit    int64      `json:"debit"`
+	Credit   int64      `json:"credit"`
+	Balance  int64      `json:"balance"` // 資産・費用は借方 - 貸方、収益は貸方 - 借方
+	From     *time.Time `json:"from,omitempty"`
+	To       *time.Time `json:"to,omitempty"`
+}
+
+type LedgerHandler struct {
+	UC *usecase.LedgerUsecase
+}
+
+// GET /ledger/accounts/{account}/balance?currency=&from=&to=
+func (h *LedgerHandler) Balance(w http.ResponseWriter, r *http.Request) {
+	qv := r.URL.Query()
+	q := usecase.BalanceQuery{
+		Account:  r.PathValue("account"),
+		Currency: qv.Get("currency"),
+	}
+	if v := qv.Get("from"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			http.Error(w, "invalid from (RFC3339)", http.StatusBadRequest)
+			return
+		}
+		q.From = t
+	}
+	if v := qv.Get("to"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			http.Error(w, "invalid to (RFC3339)", http.StatusBadRequest)
+			return
+		}
+		q.To = t
+	}
+
+	b, err := h.UC.GetBalance(r.Context(), q)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+	j := balanceJSON{
+		Account:  string(b.Account),
+		Currency: string(b.Currency),
+		Debit:    b.Debit,
+		Credit:   b.Credit,
+		Balance:  b.Net().Amount,
+	}
+	if !q.From.IsZero() {
+		j.From = &q.From
+	}
+	if !q.To.IsZero() {
+		j.To = &q.To
+	}
+	WriteJSON(w, http.StatusOK, j)
+}
@@ -0,0 +1,74 @@
+package httpi
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain/merchant"
+	"github.com/kazshi01/payment-system/internal/usecase"
+)
+
+type merchantSettingsJSON struct {
+	TaxRounding        string     `json:"tax_rounding"`
+	IssuerName         string     `json:"issuer_name"`
+	IssuerAddress      string     `json:"issuer_address"`
+	RegistrationNumber string     `json:"registration_number"`
+	UpdatedAt          *time.Time `json:"updated_at,omitempty"` // 未設定（既定値）なら省略
+}
+
+func toMerchantSettingsJSON(s *merchant.Settings) merchantSettingsJSON {
+	j := merchantSettingsJSON{
+		TaxRounding:        string(s.TaxRounding),
+		IssuerName:         s.Issuer.Name,
+		IssuerAddress:      s.Issuer.Address,
+		RegistrationNumber: s.Issuer.RegistrationNumber,
+	}
+	if !s.UpdatedAt.IsZero() {
+		t := s.UpdatedAt
+		j.UpdatedAt = &t
+	}
+	return j
+}
+
+type MerchantSettingsHandler struct {
+	UC *usecase.MerchantSettingsUsecase
+}
+
+// GET /merchant/settings
+func (h *MerchantSettingsHandler) Get(w http.ResponseWriter, r *http.Request) {
+	s, err := h.UC.GetSettings(r.Context())
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+	WriteJSON(w, http.StatusOK, toMerchantSettingsJSON(s))
+}
+
+// PUT /merchant/settings（省略した項目は変更しない）
+func (h *MerchantSettingsHandler) Put(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		TaxRounding        *string `json:"tax_rounding"`
+		IssuerName         *string `json:"issuer_name"`
+		IssuerAddress      *string `json:"issuer_address"`
+		RegistrationNumber *string `json:"registration_number"`
+	}
+	if !decodeOptionalJSON(w, r, &body) {
+		return
+	}
+
+	s, err := h.UC.UpdateSettings(r.Context(), usecase.UpdateSettingsInput{
+		TaxRounding:        body.TaxRounding,
+		IssuerName:         body.IssuerName,
+		IssuerAddress:      body.IssuerAddress,
+		RegistrationNumber: body.RegistrationNumber,
+	})
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	log.Printf("UpdateMerchantSettings success: user_id=%s tax_rounding=%s registration_number=%s", s.UserID, s.TaxRounding, s.Issuer.RegistrationNumber)
+
+	WriteJSON(w, http.StatusOK, toMerchantSettingsJSON(s))
+}
@@ -0,0 +1,57 @@
+package httpi
+
+import (
+	"fmt"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/money"
+)
+
+// moneyJSON はレスポンスの金額。amount は通貨の最小単位（JPY は円、USD はセント）
+// 円の場合は旧クライアント向けに amount_jpy も返す
+type moneyJSON struct {
+	Amount    int64  `json:"amount"`
+	Currency  string `json:"currency"`
+	AmountJPY *int64 `json:"amount_jpy,omitempty"`
+}
+
+func toMoneyJSON(m money.Money) moneyJSON {
+	j := moneyJSON{Amount: m.Amount, Currency: string(m.Currency)}
+	if m.Currency == money.JPY {
+		a := m.Amount
+		j.AmountJPY = &a
+	}
+	return j
+}
+
+// amountBody はリクエストの金額。{"amount": 1234, "currency": "USD"} か旧形式の {"amount_jpy": 1200}
+type amountBody struct {
+	Amount    *int64 `json:"amount"`
+	Currency  string `json:"currency"`
+	AmountJPY *int64 `json:"amount_jpy"`
+}
+
+// money は金額を解釈する。currency を省略した場合は def（空なら注文の通貨に任せる）
+// 金額自体を省略した場合は通貨だけ持った 0 を返す
+func (b amountBody) money(def money.Currency) (money.Money, error) {
+	c := def
+	if b.Currency != "" {
+		var err error
+		if c, err = money.ParseCurrency(b.Currency); err != nil {
+			return money.Money{}, fmt.Errorf("%w: %w", domain.ErrInvalidArgument, err)
+		}
+	}
+
+	switch {
+	case b.Amount != nil && b.AmountJPY != nil:
+		return money.Money{}, fmt.Errorf("%w: amount and amount_jpy are mutually exclusive", domain.ErrInvalidArgument)
+	case b.AmountJPY != nil:
+		if c != "" && c != money.JPY {
+			return money.Money{}, fmt.Errorf("%w: amount_jpy cannot be used with currency %s", domain.ErrInvalidArgument, c)
+		}
+		return money.Money{Amount: *b.AmountJPY, Currency: money.JPY}, nil
+	case b.Amount != nil:
+		return money.Money{Amount: *b.Amount, Currency: c}, nil
+	}
+	return money.Money{Currency: c}, nil
+}
@@ -0,0 +1,108 @@
+package httpi
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain/order"
+	"github.com/kazshi01/payment-system/internal/domain/payment"
+)
+
+type authorizationJSON struct {
+	ID             string `json:"id"`
+	OrderID        string `json:"order_id"`
+	Provider       string `json:"provider"`
+	ProviderAuthID string `json:"provider_auth_id"`
+	moneyJSON
+	Status    string    `json:"status"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func toAuthorizationJSON(a *payment.Authorization) authorizationJSON {
+	return authorizationJSON{
+		ID:             string(a.ID),
+		OrderID:        a.OrderID,
+		Provider:       a.Provider,
+		ProviderAuthID: a.ProviderAuthID,
+		moneyJSON:      toMoneyJSON(a.Amount),
+		Status:         string(a.Status),
+		ExpiresAt:      a.ExpiresAt,
+		CreatedAt:      a.CreatedAt,
+	}
+}
+
+// POST /orders/{id}/authorize
+func (h *OrderHandler) Authorize(w http.ResponseWriter, r *http.Request) {
+	id := order.ID(r.PathValue("id"))
+	if id == "" {
+		http.Error(w, "missing id", http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		PaymentMethod string `json:"payment_method"`
+	}
+	if !decodeOptionalJSON(w, r, &body) {
+		return
+	}
+
+	a, err := h.UC.AuthorizeOrder(r.Context(), id, body.PaymentMethod)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	log.Printf("AuthorizeOrder success: order_id=%s authorization_id=%s", id, a.ID)
+
+	WriteJSON(w, http.StatusCreated, toAuthorizationJSON(a))
+}
+
+// POST /orders/{id}/capture
+func (h *OrderHandler) Capture(w http.ResponseWriter, r *http.Request) {
+	id := order.ID(r.PathValue("id"))
+	if id == "" {
+		http.Error(w, "missing id", http.StatusBadRequest)
+		return
+	}
+
+	// 金額省略時は与信額を全額確定。通貨省略時は注文の通貨
+	var body amountBody
+	if !decodeOptionalJSON(w, r, &body) {
+		return
+	}
+
+	amount, err := body.money("")
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+	p, err := h.UC.CaptureOrder(r.Context(), id, amount)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	log.Printf("CaptureOrder success: order_id=%s payment_id=%s amount=%s", id, p.ID, p.Amount)
+
+	WriteJSON(w, http.StatusCreated, toPaymentJSON(p))
+}
+
+// POST /orders/{id}/void
+func (h *OrderHandler) Void(w http.ResponseWriter, r *http.Request) {
+	id := order.ID(r.PathValue("id"))
+	if id == "" {
+		http.Error(w, "missing id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.UC.VoidOrder(r.Context(), id); err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	log.Printf("VoidOrder success: order_id=%s", id)
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -3,22 +3,146 @@ package httpi
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"strconv"
 	"time"
 
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/money"
 	"github.com/kazshi01/payment-system/internal/domain/order"
+	"github.com/kazshi01/payment-system/internal/domain/payment"
+	"github.com/kazshi01/payment-system/internal/domain/tax"
 	"github.com/kazshi01/payment-system/internal/usecase"
 )
 
 type orderJSON struct {
-	ID        string    `json:"id"`
-	UserID    string    `json:"user_id"`
-	AmountJPY int64     `json:"amount_jpy"`
+	ID     string `json:"id"`
+	UserID string `json:"user_id"`
+	moneyJSON
 	Status    string    `json:"status"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	// 明細ありの注文のみ。amount は subtotal + 各税額
+	Items    []orderItemJSON `json:"items,omitempty"`
+	Subtotal *int64          `json:"subtotal,omitempty"`
+	Tax      []orderTaxJSON  `json:"tax,omitempty"`
+
+	// クーポンを使った注文のみ。amount は値引き後
+	Discount *discountJSON `json:"discount,omitempty"`
+}
+
+type discountJSON struct {
+	CouponCode string `json:"coupon_code"`
+	Amount     int64  `json:"amount"`
+}
+
+// 明細の金額は注文と同じ通貨の最小単位
+type orderItemJSON struct {
+	SKU       string `json:"sku"`
+	Name      string `json:"name"`
+	UnitPrice int64  `json:"unit_price"`
+	Quantity  int64  `json:"quantity"`
+	Subtotal  int64  `json:"subtotal"`
+	TaxRate   int    `json:"tax_rate"`
+}
+
+// 税率ごとの消費税内訳
+type orderTaxJSON struct {
+	Rate    int   `json:"rate"`
+	Taxable int64 `json:"taxable"`
+	Tax     int64 `json:"tax"`
+}
+
+type orderPageJSON struct {
+	Items      []orderJSON `json:"items"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
+
+func toOrderJSON(o *order.Order) orderJSON {
+	j := orderJSON{
+		ID:        string(o.ID),
+		UserID:    o.UserID,
+		moneyJSON: toMoneyJSON(o.Amount),
+		Status:    string(o.Status),
+		CreatedAt: o.CreatedAt,
+		UpdatedAt: o.UpdatedAt,
+		Items:     toOrderItemsJSON(o.Items),
+	}
+	if len(o.Tax) > 0 {
+		var subtotal int64
+		for _, b := range o.Tax {
+			subtotal += b.Taxable.Amount
+			j.Tax = append(j.Tax, orderTaxJSON{Rate: int(b.Rate), Taxable: b.Taxable.Amount, Tax: b.Tax.Amount})
+		}
+		j.Subtotal = &subtotal
+	}
+	if o.Coupon != nil {
+		j.Discount = &discountJSON{CouponCode: o.Coupon.Code, Amount: o.Coupon.Discount.Amount}
+	}
+	return j
+}
+
+func toOrderItemsJSON(items []order.Item) []orderItemJSON {
+	if len(items) == 0 {
+		return nil
+	}
+	out := make([]orderItemJSON, 0, len(items))
+	for _, it := range items {
+		sub, _ := it.Subtotal() // 保存済みの明細は検証済み
+		out = append(out, orderItemJSON{
+			SKU:       it.SKU,
+			Name:      it.Name,
+			UnitPrice: it.UnitPrice.Amount,
+			Quantity:  it.Quantity,
+			Subtotal:  sub.Amount,
+			TaxRate:   int(it.TaxRate),
+		})
+	}
+	return out
+}
+
+type paymentJSON struct {
+	ID           string `json:"id"`
+	OrderID      string `json:"order_id"`
+	Method       string `json:"method"`
+	Provider     string `json:"provider"`
+	ProviderTxID string `json:"provider_tx_id"`
+	moneyJSON
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func toPaymentJSON(p *payment.Payment) paymentJSON {
+	return paymentJSON{
+		ID:           string(p.ID),
+		OrderID:      p.OrderID,
+		Method:       string(p.Method),
+		Provider:     p.Provider,
+		ProviderTxID: p.TxID,
+		moneyJSON:    toMoneyJSON(p.Amount),
+		CreatedAt:    p.CreatedAt,
+	}
+}
+
+type refundJSON struct {
+	ID        string `json:"id"`
+	OrderID   string `json:"order_id"`
+	PaymentID string `json:"payment_id"`
+	moneyJSON
+	Reason           string    `json:"reason"`
+	ProviderRefundID string    `json:"provider_refund_id"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+type eventJSON struct {
+	ID        string         `json:"id"`
+	OrderID   string         `json:"order_id"`
+	Type      string         `json:"type"`
+	Payload   map[string]any `json:"payload"`
+	CreatedAt time.Time      `json:"created_at"`
 }
 
 type OrderHandler struct {
@@ -31,8 +155,18 @@ func (h *OrderHandler) Create(w http.ResponseWriter, r *http.Request) {
 	r.Body = http.MaxBytesReader(w, r.Body, 1<<20) // 1MB
 	defer r.Body.Close()
 
+	// items を渡すと請求額は明細から税込で計算する（amount は省略可、指定するなら税込合計と一致させる）
+	// 単価は税抜。tax_rate は 10（標準）か 8（軽減）で、省略時は 10
 	var body struct {
-		AmountJPY int64 `json:"amount_jpy"`
+		amountBody
+		Items []struct {
+			SKU       string `json:"sku"`
+			Name      string `json:"name"`
+			UnitPrice int64  `json:"unit_price"`
+			Quantity  int64  `json:"quantity"`
+			TaxRate   *int   `json:"tax_rate"`
+		} `json:"items"`
+		CouponCode string `json:"coupon_code"` // 税込の請求額から値引きする
 	}
 
 	dec := json.NewDecoder(r.Body)
@@ -50,22 +184,35 @@ func (h *OrderHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	o, err := h.UC.CreateOrder(r.Context(), body.AmountJPY)
+	// 新形式（amount / items）では通貨を必須にする
+	amount, err := body.money("")
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+	in := usecase.CreateOrderInput{Amount: amount, CouponCode: body.CouponCode}
+	for _, it := range body.Items {
+		rate := tax.RateStandard
+		if it.TaxRate != nil {
+			rate = tax.Rate(*it.TaxRate)
+		}
+		in.Items = append(in.Items, order.Item{
+			SKU:       it.SKU,
+			Name:      it.Name,
+			UnitPrice: money.Money{Amount: it.UnitPrice, Currency: amount.Currency},
+			Quantity:  it.Quantity,
+			TaxRate:   rate,
+		})
+	}
 
+	o, err := h.UC.CreateOrder(r.Context(), in)
 	if err != nil {
 		WriteError(w, err)
 		return
 	}
 
 	// JSON形式で返却するための処理
-	resp := orderJSON{
-		ID:        string(o.ID),
-		UserID:    o.UserID,
-		AmountJPY: o.AmountJPY,
-		Status:    string(o.Status),
-		CreatedAt: o.CreatedAt,
-		UpdatedAt: o.UpdatedAt,
-	}
+	resp := toOrderJSON(o)
 
 	b, err := json.Marshal(resp)
 	if err != nil {
@@ -78,6 +225,74 @@ func (h *OrderHandler) Create(w http.ResponseWriter, r *http.Request) {
 	WriteJSON(w, http.StatusCreated, resp)
 }
 
+// GET /orders/{id}
+func (h *OrderHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id := order.ID(r.PathValue("id"))
+	if id == "" {
+		http.Error(w, "missing id", http.StatusBadRequest)
+		return
+	}
+
+	o, err := h.UC.GetOrder(r.Context(), id)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, toOrderJSON(o))
+}
+
+// GET /orders?status=&created_from=&created_to=&cursor=&limit=
+func (h *OrderHandler) List(w http.ResponseWriter, r *http.Request) {
+	qv := r.URL.Query()
+
+	q := usecase.ListOrdersQuery{
+		Status: order.Status(qv.Get("status")),
+		Cursor: qv.Get("cursor"),
+	}
+
+	if v := qv.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		q.Limit = n
+	}
+	if v := qv.Get("created_from"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			http.Error(w, "invalid created_from (RFC3339)", http.StatusBadRequest)
+			return
+		}
+		q.CreatedFrom = t
+	}
+	if v := qv.Get("created_to"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			http.Error(w, "invalid created_to (RFC3339)", http.StatusBadRequest)
+			return
+		}
+		q.CreatedTo = t
+	}
+
+	page, err := h.UC.ListOrders(r.Context(), q)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	resp := orderPageJSON{
+		Items:      make([]orderJSON, 0, len(page.Items)),
+		NextCursor: page.NextCursor,
+	}
+	for _, o := range page.Items {
+		resp.Items = append(resp.Items, toOrderJSON(o))
+	}
+
+	WriteJSON(w, http.StatusOK, resp)
+}
+
 // POST /orders/{id}/pay
 func (h *OrderHandler) Pay(w http.ResponseWriter, r *http.Request) {
 	id := order.ID(r.PathValue("id"))
@@ -86,7 +301,26 @@ func (h *OrderHandler) Pay(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.UC.PayOrder(r.Context(), id); err != nil {
+	// method 省略時はカード。カードは payment_method（PG 上の支払い手段）が必須
+	var body struct {
+		Method        string `json:"method"`
+		PaymentMethod string `json:"payment_method"`
+	}
+	if !decodeOptionalJSON(w, r, &body) {
+		return
+	}
+
+	switch payment.Method(body.Method) {
+	case "", payment.MethodCard:
+	case payment.MethodKonbini:
+		h.payKonbini(w, r, id)
+		return
+	default:
+		WriteError(w, fmt.Errorf("%w: unsupported payment method %q", domain.ErrInvalidArgument, body.Method))
+		return
+	}
+
+	if err := h.UC.PayOrder(r.Context(), id, body.PaymentMethod); err != nil {
 		WriteError(w, err)
 		return
 	}
@@ -95,3 +329,112 @@ func (h *OrderHandler) Pay(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// POST /orders/{id}/cancel
+func (h *OrderHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	id := order.ID(r.PathValue("id"))
+	if id == "" {
+		http.Error(w, "missing id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.UC.CancelOrder(r.Context(), id); err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	log.Printf("CancelOrder success: order_id=%s", id)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// POST /orders/{id}/refunds
+func (h *OrderHandler) Refund(w http.ResponseWriter, r *http.Request) {
+	id := order.ID(r.PathValue("id"))
+	if id == "" {
+		http.Error(w, "missing id", http.StatusBadRequest)
+		return
+	}
+
+	// 金額省略時は残額を全額返金。通貨省略時は注文の通貨
+	var body struct {
+		amountBody
+		Reason string `json:"reason"`
+	}
+	if !decodeOptionalJSON(w, r, &body) {
+		return
+	}
+
+	amount, err := body.money("")
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+	rf, err := h.UC.RefundOrder(r.Context(), id, amount, body.Reason)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	log.Printf("RefundOrder success: order_id=%s refund_id=%s amount=%s", id, rf.ID, rf.Amount)
+
+	WriteJSON(w, http.StatusCreated, refundJSON{
+		ID:               string(rf.ID),
+		OrderID:          rf.OrderID,
+		PaymentID:        rf.PaymentID,
+		moneyJSON:        toMoneyJSON(rf.Amount),
+		Reason:           rf.Reason,
+		ProviderRefundID: rf.ProviderRefundID,
+		CreatedAt:        rf.CreatedAt,
+	})
+}
+
+// GET /orders/{id}/payments
+func (h *OrderHandler) ListPayments(w http.ResponseWriter, r *http.Request) {
+	id := order.ID(r.PathValue("id"))
+	if id == "" {
+		http.Error(w, "missing id", http.StatusBadRequest)
+		return
+	}
+
+	ps, err := h.UC.ListPayments(r.Context(), id)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	resp := make([]paymentJSON, 0, len(ps))
+	for _, p := range ps {
+		resp = append(resp, toPaymentJSON(p))
+	}
+
+	WriteJSON(w, http.StatusOK, resp)
+}
+
+// GET /orders/{id}/events
+func (h *OrderHandler) ListEvents(w http.ResponseWriter, r *http.Request) {
+	id := order.ID(r.PathValue("id"))
+	if id == "" {
+		http.Error(w, "missing id", http.StatusBadRequest)
+		return
+	}
+
+	es, err := h.UC.ListEvents(r.Context(), id)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	resp := make([]eventJSON, 0, len(es))
+	for _, e := range es {
+		resp = append(resp, eventJSON{
+			ID:        string(e.ID),
+			OrderID:   e.OrderID,
+			Type:      string(e.Type),
+			Payload:   e.Payload,
+			CreatedAt: e.CreatedAt,
+		})
+	}
+
+	WriteJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,52 @@
+package httpi
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain/order"
+	"github.com/kazshi01/payment-system/internal/domain/payment"
+)
+
+type konbiniJSON struct {
+	ID                 string `json:"id"`
+	OrderID            string `json:"order_id"`
+	Provider           string `json:"provider"`
+	ProviderPaymentID  string `json:"provider_payment_id"`
+	PaymentCode        string `json:"payment_code"`
+	ConfirmationNumber string `json:"confirmation_number,omitempty"`
+	moneyJSON
+	Status    string    `json:"status"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func toKonbiniJSON(k *payment.Konbini) konbiniJSON {
+	return konbiniJSON{
+		ID:                 string(k.ID),
+		OrderID:            k.OrderID,
+		Provider:           k.Provider,
+		ProviderPaymentID:  k.ProviderPaymentID,
+		PaymentCode:        k.PaymentCode,
+		ConfirmationNumber: k.ConfirmationNumber,
+		moneyJSON:          toMoneyJSON(k.Amount),
+		Status:             string(k.Status),
+		ExpiresAt:          k.ExpiresAt,
+		CreatedAt:          k.CreatedAt,
+	}
+}
+
+// POST /orders/{id}/pay {"method":"KONBINI"}
+// 支払いは店頭で行われるため 202 で払込番号を返す
+func (h *OrderHandler) payKonbini(w http.ResponseWriter, r *http.Request, id order.ID) {
+	k, err := h.UC.PayOrderKonbini(r.Context(), id)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	log.Printf("PayOrderKonbini success: order_id=%s konbini_id=%s", id, k.ID)
+
+	WriteJSON(w, http.StatusAccepted, toKonbiniJSON(k))
+}
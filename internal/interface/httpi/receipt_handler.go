@@ -0,0 +1,41 @@
+package httpi
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/kazshi01/payment-system/internal/domain/order"
+	"github.com/kazshi01/payment-system/internal/usecase"
+)
+
+type ReceiptHandler struct {
+	UC *usecase.ReceiptUsecase
+}
+
+// GET /orders/{id}/receipt.pdf
+func (h *ReceiptHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id := order.ID(r.PathValue("id"))
+	if id == "" {
+		http.Error(w, "missing id", http.StatusBadRequest)
+		return
+	}
+
+	pdf, rc, err := h.UC.IssueReceipt(r.Context(), id)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	log.Printf("IssueReceipt success: order_id=%s number=%d downloads=%d", id, rc.Number, rc.Downloads)
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.pdf"`, rc.NumberString()))
+	w.Header().Set("Content-Length", strconv.Itoa(len(pdf)))
+	w.Header().Set("Cache-Control", "no-store") // ダウンロードのたびに再発行として数える
+	w.Header().Set("X-Receipt-Number", rc.NumberString())
+	w.Header().Set("X-Receipt-Reissued", strconv.FormatBool(rc.Reissued()))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(pdf)
+}
@@ -0,0 +1,121 @@
+package httpi
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain/settlement"
+	"github.com/kazshi01/payment-system/internal/usecase"
+)
+
+type reconciliationSummaryJSON struct {
+	Matched         int `json:"matched"`
+	AmountMismatch  int `json:"amount_mismatch"`
+	MissingInOurs   int `json:"missing_in_ours"`
+	MissingInTheirs int `json:"missing_in_theirs"`
+}
+
+// ours / theirs はない側を省略する
+type reconciliationItemJSON struct {
+	Status       string     `json:"status"`
+	ProviderTxID string     `json:"provider_tx_id"`
+	Line         int        `json:"line,omitempty"`
+	PaymentID    string     `json:"payment_id,omitempty"`
+	OrderID      string     `json:"order_id,omitempty"`
+	Ours         *moneyJSON `json:"ours,omitempty"`
+	Theirs       *moneyJSON `json:"theirs,omitempty"`
+}
+
+type reconciliationRunJSON struct {
+	ID        string                    `json:"id"`
+	Provider  string                    `json:"provider"`
+	Source    string                    `json:"source"`
+	From      time.Time                 `json:"from"`
+	To        time.Time                 `json:"to"`
+	Summary   reconciliationSummaryJSON `json:"summary"`
+	Items     []reconciliationItemJSON  `json:"items,omitempty"` // 一覧では省略
+	CreatedAt time.Time                 `json:"created_at"`
+}
+
+func toReconciliationRunJSON(run *settlement.Run) reconciliationRunJSON {
+	j := reconciliationRunJSON{
+		ID:       run.ID,
+		Provider: run.Provider,
+		Source:   run.Source,
+		From:     run.From,
+		To:       run.To,
+		Summary: reconciliationSummaryJSON{
+			Matched:         run.Summary.Matched,
+			AmountMismatch:  run.Summary.AmountMismatch,
+			MissingInOurs:   run.Summary.MissingInOurs,
+			MissingInTheirs: run.Summary.MissingInTheirs,
+		},
+		CreatedAt: run.CreatedAt,
+	}
+	for _, it := range run.Items {
+		item := reconciliationItemJSON{
+			Status:       string(it.Status),
+			ProviderTxID: it.TxID,
+			Line:         it.Line,
+			PaymentID:    it.PaymentID,
+			OrderID:      it.OrderID,
+		}
+		if it.Ours.Currency != "" {
+			m := toMoneyJSON(it.Ours)
+			item.Ours = &m
+		}
+		if it.Theirs.Currency != "" {
+			m := toMoneyJSON(it.Theirs)
+			item.Theirs = &m
+		}
+		j.Items = append(j.Items, item)
+	}
+	return j
+}
+
+type ReconciliationHandler struct {
+	UC *usecase.ReconciliationUsecase
+}
+
+// GET /reconciliation-runs?limit=
+func (h *ReconciliationHandler) List(w http.ResponseWriter, r *http.Request) {
+	var limit int
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	runs, err := h.UC.ListRuns(r.Context(), limit)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+	resp := struct {
+		Items []reconciliationRunJSON `json:"items"`
+	}{Items: make([]reconciliationRunJSON, 0, len(runs))}
+	for _, run := range runs {
+		resp.Items = append(resp.Items, toReconciliationRunJSON(run))
+	}
+	WriteJSON(w, http.StatusOK, resp)
+}
+
+// GET /reconciliation-runs/{id}
+func (h *ReconciliationHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if id == "" {
+		http.Error(w, "missing id", http.StatusBadRequest)
+		return
+	}
+
+	run, err := h.UC.GetRun(r.Context(), id)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+	WriteJSON(w, http.StatusOK, toReconciliationRunJSON(run))
+}
@@ -3,6 +3,7 @@ package httpi
 import (
 	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
 
 	"github.com/kazshi01/payment-system/internal/domain"
@@ -19,10 +20,41 @@ func WriteError(w http.ResponseWriter, err error) {
 	switch {
 	case errors.Is(err, domain.ErrInvalidArgument):
 		code = http.StatusBadRequest
+	case errors.Is(err, domain.ErrUnauthorized):
+		code = http.StatusUnauthorized
+	case errors.Is(err, domain.ErrForbidden):
+		code = http.StatusForbidden
 	case errors.Is(err, domain.ErrNotFound):
 		code = http.StatusNotFound
 	case errors.Is(err, domain.ErrConflict):
 		code = http.StatusConflict
+	case errors.Is(err, domain.ErrIdempotencyKeyReused):
+		code = http.StatusUnprocessableEntity
+	case errors.Is(err, domain.ErrPaymentPending):
+		code = http.StatusAccepted
+	case errors.Is(err, domain.ErrPaymentDeclined):
+		code = http.StatusPaymentRequired
+	case errors.Is(err, domain.ErrPaymentGatewayUnavailable):
+		code = http.StatusBadGateway
 	}
 	http.Error(w, err.Error(), code)
 }
+
+// 空ボディを許す JSON デコード。失敗時はレスポンスを書いて false を返す
+func decodeOptionalJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	// 事故防止のボディ上限
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20) // 1MB
+	defer r.Body.Close()
+
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields() // 未知のフィールドを禁止
+	if err := dec.Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return false
+	}
+	if dec.More() {
+		http.Error(w, "unexpected extra JSON", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,210 @@
+package httpi
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain/webhook"
+	"github.com/kazshi01/payment-system/internal/usecase"
+)
+
+type webhookEndpointJSON struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Secret    string    `json:"secret,omitempty"` // 作成時のみ返す
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func toWebhookEndpointJSON(e *webhook.Endpoint) webhookEndpointJSON {
+	events := make([]string, 0, len(e.Events))
+	for _, t := range e.Events {
+		events = append(events, string(t))
+	}
+	return webhookEndpointJSON{
+		ID:        string(e.ID),
+		URL:       e.URL,
+		Events:    events,
+		CreatedAt: e.CreatedAt,
+	}
+}
+
+type webhookDeliveryJSON struct {
+	ID             string               `json:"id"`
+	EndpointID     string               `json:"endpoint_id"`
+	EventID        string               `json:"event_id"`
+	EventType      string               `json:"event_type"`
+	Status         string               `json:"status"`
+	Attempts       int                  `json:"attempts"`
+	NextAttemptAt  *time.Time           `json:"next_attempt_at,omitempty"` // PENDING のみ
+	LastStatusCode int                  `json:"last_status_code,omitempty"`
+	LastError      string               `json:"last_error,omitempty"`
+	CreatedAt      time.Time            `json:"created_at"`
+	UpdatedAt      time.Time            `json:"updated_at"`
+	AttemptLog     []webhookAttemptJSON `json:"attempt_log,omitempty"`
+}
+
+type webhookAttemptJSON struct {
+	ID          string    `json:"id"`
+	StatusCode  int       `json:"status_code,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	DurationMs  int64     `json:"duration_ms"`
+	AttemptedAt time.Time `json:"attempted_at"`
+}
+
+func toWebhookDeliveryJSON(d *webhook.Delivery) webhookDeliveryJSON {
+	j := webhookDeliveryJSON{
+		ID:             string(d.ID),
+		EndpointID:     string(d.EndpointID),
+		EventID:        d.EventID,
+		EventType:      string(d.EventType),
+		Status:         string(d.Status),
+		Attempts:       d.Attempts,
+		LastStatusCode: d.LastStatusCode,
+		LastError:      d.LastError,
+		CreatedAt:      d.CreatedAt,
+		UpdatedAt:      d.UpdatedAt,
+	}
+	if d.Status == webhook.DeliveryPending {
+		next := d.NextAttemptAt
+		j.NextAttemptAt = &next
+	}
+	return j
+}
+
+func toWebhookAttemptJSON(a *webhook.Attempt) webhookAttemptJSON {
+	return webhookAttemptJSON{
+		ID:          a.ID,
+		StatusCode:  a.StatusCode,
+		Error:       a.Error,
+		DurationMs:  a.Duration.Milliseconds(),
+		AttemptedAt: a.AttemptedAt,
+	}
+}
+
+type WebhookEndpointHandler struct {
+	UC *usecase.MerchantWebhookUsecase
+}
+
+// POST /webhook-endpoints
+func (h *WebhookEndpointHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		URL    string   `json:"url"`
+		Events []string `json:"events"`
+	}
+	if !decodeOptionalJSON(w, r, &body) {
+		return
+	}
+
+	e, err := h.UC.CreateEndpoint(r.Context(), body.URL, body.Events)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	log.Printf("CreateWebhookEndpoint success: endpoint_id=%s url=%s", e.ID, e.URL)
+
+	resp := toWebhookEndpointJSON(e)
+	resp.Secret = e.Secret
+
+	w.Header().Set("Location", "/webhook-endpoints/"+string(e.ID))
+	WriteJSON(w, http.StatusCreated, resp)
+}
+
+// GET /webhook-endpoints
+func (h *WebhookEndpointHandler) List(w http.ResponseWriter, r *http.Request) {
+	es, err := h.UC.ListEndpoints(r.Context())
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	resp := make([]webhookEndpointJSON, 0, len(es))
+	for _, e := range es {
+		resp = append(resp, toWebhookEndpointJSON(e))
+	}
+	WriteJSON(w, http.StatusOK, resp)
+}
+
+// DELETE /webhook-endpoints/{id}
+func (h *WebhookEndpointHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id := webhook.EndpointID(r.PathValue("id"))
+	if id == "" {
+		http.Error(w, "missing id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.UC.DeleteEndpoint(r.Context(), id); err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	log.Printf("DeleteWebhookEndpoint success: endpoint_id=%s", id)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET /webhook-endpoints/{id}/deliveries
+func (h *WebhookEndpointHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	id := webhook.EndpointID(r.PathValue("id"))
+	if id == "" {
+		http.Error(w, "missing id", http.StatusBadRequest)
+		return
+	}
+
+	ds, err := h.UC.ListDeliveries(r.Context(), id)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	resp := make([]webhookDeliveryJSON, 0, len(ds))
+	for _, d := range ds {
+		resp = append(resp, toWebhookDeliveryJSON(d))
+	}
+	WriteJSON(w, http.StatusOK, resp)
+}
+
+// GET /webhook-deliveries/{id}
+func (h *WebhookEndpointHandler) GetDelivery(w http.ResponseWriter, r *http.Request) {
+	id := webhook.DeliveryID(r.PathValue("id"))
+	if id == "" {
+		http.Error(w, "missing id", http.StatusBadRequest)
+		return
+	}
+
+	d, attempts, err := h.UC.GetDelivery(r.Context(), id)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	resp := toWebhookDeliveryJSON(d)
+	resp.AttemptLog = make([]webhookAttemptJSON, 0, len(attempts))
+	for _, a := range attempts {
+		resp.AttemptLog = append(resp.AttemptLog, toWebhookAttemptJSON(a))
+	}
+	WriteJSON(w, http.StatusOK, resp)
+}
+
+// POST /webhook-deliveries/{id}/redeliver
+func (h *WebhookEndpointHandler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	id := webhook.DeliveryID(r.PathValue("id"))
+	if id == "" {
+		http.Error(w, "missing id", http.StatusBadRequest)
+		return
+	}
+
+	d, a, err := h.UC.Redeliver(r.Context(), id)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	log.Printf("RedeliverWebhook: delivery_id=%s status_code=%d error=%q", id, a.StatusCode, a.Error)
+
+	resp := toWebhookDeliveryJSON(d)
+	resp.AttemptLog = []webhookAttemptJSON{toWebhookAttemptJSON(a)}
+	WriteJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,59 @@
+package httpi
+
+import (
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/usecase"
+)
+
+// WebhookVerifier は PG ごとの署名検証とイベント正規化（例: pg.StripeWebhook）
+type WebhookVerifier interface {
+	Verify(header http.Header, body []byte) (*domain.ProviderEvent, error)
+}
+
+type WebhookHandler struct {
+	UC        *usecase.WebhookUsecase
+	Verifiers map[string]WebhookVerifier // key: パスの {provider}
+}
+
+// POST /webhooks/{provider}
+// 署名で認証するため OIDC ミドルウェアは通さない
+func (h *WebhookHandler) Receive(w http.ResponseWriter, r *http.Request) {
+	v, ok := h.Verifiers[r.PathValue("provider")]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	// 署名はボディのバイト列そのものに対して検証する
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20) // 1MB
+	defer r.Body.Close()
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "read body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ev, err := v.Verify(r.Header, body)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	duplicate, err := h.UC.HandleProviderEvent(r.Context(), ev)
+	if err != nil {
+		// 2xx 以外を返せばプロバイダが再送する
+		log.Printf("warn: handle provider event: provider=%s event_id=%s type=%s: %v", ev.Provider, ev.ID, ev.RawType, err)
+		WriteError(w, err)
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, map[string]any{
+		"received":  true,
+		"duplicate": duplicate,
+	})
+}
@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/auth"
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/coupon"
+)
+
+// CouponUsecase はクーポンの登録・参照（管理者のみ）。利用は CreateOrder で行う
+type CouponUsecase struct {
+	Repo  domain.CouponRepository
+	Clock Clock
+	IDGen IDGen
+}
+
+// CreateCoupon は c の ID・作成日時・利用回数を埋めて登録する。ValidFrom が空なら今から有効
+func (uc *CouponUsecase) CreateCoupon(ctx context.Context, c *coupon.Coupon) (*coupon.Coupon, error) {
+	if !auth.IsAdmin(ctx) {
+		if _, ok := auth.UserIDFrom(ctx); !ok {
+			return nil, domain.ErrUnauthorized
+		}
+		return nil, domain.ErrForbidden
+	}
+
+	now := uc.Clock.Now()
+	out := *c
+	out.ID = uc.IDGen.New()
+	out.Code = coupon.NormalizeCode(c.Code)
+	out.Redeemed = 0
+	out.CreatedAt = now
+	if out.ValidFrom.IsZero() {
+		out.ValidFrom = now
+	}
+	if err := out.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrInvalidArgument, err)
+	}
+
+	// ---- DB 反映は 3s ----
+	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	if err := uc.Repo.Create(dbCtx, &out); err != nil {
+		if errors.Is(err, coupon.ErrCodeTaken) {
+			return nil, fmt.Errorf("%w: %w", domain.ErrConflict, err)
+		}
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetCoupon は利用回数を含めて返す
+func (uc *CouponUsecase) GetCoupon(ctx context.Context, code string) (*coupon.Coupon, error) {
+	if !auth.IsAdmin(ctx) {
+		if _, ok := auth.UserIDFrom(ctx); !ok {
+			return nil, domain.ErrUnauthorized
+		}
+		return nil, domain.ErrForbidden
+	}
+
+	// ---- DB 読み取りは 3s ----
+	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	return uc.Repo.FindByCode(dbCtx, coupon.NormalizeCode(code))
+}
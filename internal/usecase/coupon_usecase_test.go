@@ -0,0 +1,283 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/coupon"
+	"github.com/kazshi01/payment-system/internal/domain/money"
+	"github.com/kazshi01/payment-system/internal/domain/order"
+	"github.com/kazshi01/payment-system/internal/domain/tax"
+	"github.com/kazshi01/payment-system/internal/usecase"
+)
+
+type memCouponRepo struct {
+	byCode   map[string]*coupon.Coupon
+	redeemed map[order.ID]coupon.Redemption
+}
+
+func newMemCouponRepo(cs ...coupon.Coupon) *memCouponRepo {
+	r := &memCouponRepo{byCode: map[string]*coupon.Coupon{}, redeemed: map[order.ID]coupon.Redemption{}}
+	for _, c := range cs {
+		r.byCode[c.Code] = &c
+	}
+	return r
+}
+
+func (r *memCouponRepo) Create(ctx context.Context, c *coupon.Coupon) error {
+	if _, ok := r.byCode[c.Code]; ok {
+		return coupon.ErrCodeTaken
+	}
+	cp := *c
+	r.byCode[c.Code] = &cp
+	return nil
+}
+
+func (r *memCouponRepo) FindByCode(ctx context.Context, code string) (*coupon.Coupon, error) {
+	c, ok := r.byCode[code]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	cp := *c
+	return &cp, nil
+}
+
+func (r *memCouponRepo) Redeem(ctx context.Context, red *coupon.Redemption) error {
+	c := r.byCode[red.Code]
+	if c.MaxRedemptions > 0 && c.Redeemed >= c.MaxRedemptions {
+		return coupon.ErrUsageLimit
+	}
+	var n int64
+	for _, x := range r.redeemed {
+		if x.CouponID == c.ID && x.UserID == red.UserID {
+			n++
+		}
+	}
+	if c.MaxPerUser > 0 && n >= c.MaxPerUser {
+		return coupon.ErrUsageLimit
+	}
+	c.Redeemed++
+	r.redeemed[order.ID(red.OrderID)] = *red
+	return nil
+}
+
+func (r *memCouponRepo) Release(ctx context.Context, id order.ID) error {
+	red, ok := r.redeemed[id]
+	if !ok {
+		return nil
+	}
+	delete(r.redeemed, id)
+	if c := r.byCode[red.Code]; c != nil && c.Redeemed > 0 {
+		c.Redeemed--
+	}
+	return nil
+}
+
+func (r *memCouponRepo) FindRedemptionByOrderID(ctx context.Context, id order.ID) (*coupon.Redemption, error) {
+	red, ok := r.redeemed[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return &red, nil
+}
+
+// 請求額を記録する PG
+type amountPG struct {
+	okPG
+	got *[]int64
+}
+
+func (p amountPG) Charge(ctx context.Context, intent domain.PaymentIntent) (string, error) {
+	*p.got = append(*p.got, intent.Amount)
+	return p.okPG.Charge(ctx, intent)
+}
+
+func TestOrderUsecase_CreateOrder_coupon(t *testing.T) {
+	now := time.Now()
+	coupons := newMemCouponRepo(
+		coupon.Coupon{ID: "c1", Code: "WELCOME10", Kind: coupon.KindPercentage, PercentOff: 10, MinAmount: jpy(1000), MaxPerUser: 1, ValidFrom: now.Add(-time.Hour)},
+		coupon.Coupon{ID: "c2", Code: "SUMMER", Kind: coupon.KindFixed, AmountOff: jpy(500), ValidFrom: now.Add(-48 * time.Hour), ValidUntil: now.Add(-24 * time.Hour)},
+	)
+	n := 0
+	var charged []int64
+	uc := &usecase.OrderUsecase{
+		Repo:     newMemRepo(),
+		Payments: newMemPaymentRepo(),
+		Events:   newMemEventRepo(),
+		Coupons:  coupons,
+		Tx:       nopTx{},
+		PG:       amountPG{okPG: okPG{txid: "tx1"}, got: &charged},
+		Clock:    fixedClock{t: now},
+		IDGen:    seqIDGen{n: &n},
+		Locker:   okLocker{},
+	}
+	ctx := ctxWithUser("user-1")
+
+	for _, in := range []usecase.CreateOrderInput{
+		{Amount: jpy(800), CouponCode: "WELCOME10"},                                       // 最低注文金額未満
+		{Amount: money.Money{Amount: 2000, Currency: money.USD}, CouponCode: "WELCOME10"}, // 通貨違い
+		{Amount: jpy(1200), CouponCode: "SUMMER"},                                         // 期限切れ
+		{Amount: jpy(1200), CouponCode: "NO-SUCH"},                                        // 存在しない
+	} {
+		if _, err := uc.CreateOrder(ctx, in); !errors.Is(err, domain.ErrInvalidArgument) {
+			t.Fatalf("CreateOrder(%+v) err = %v; want ErrInvalidArgument", in, err)
+		}
+	}
+
+	// コードの大文字小文字は区別しない
+	o, err := uc.CreateOrder(ctx, usecase.CreateOrderInput{Amount: jpy(1234), CouponCode: " welcome10 "})
+	if err != nil {
+		t.Fatalf("CreateOrder err = %v", err)
+	}
+	// 1234 の 10% = 123.4 → 123 円引き
+	if o.Amount != jpy(1111) || o.Coupon == nil || o.Coupon.Discount != jpy(123) {
+		t.Fatalf("order = %+v; want 1111 JPY after 123 JPY discount", o)
+	}
+
+	if err := uc.PayOrder(ctx, o.ID, "pm_card_visa"); err != nil {
+		t.Fatalf("PayOrder err = %v", err)
+	}
+	if len(charged) != 1 || charged[0] != 1111 {
+		t.Fatalf("charged = %v; want [1111]", charged)
+	}
+
+	got, err := uc.GetOrder(ctx, o.ID)
+	if err != nil {
+		t.Fatalf("GetOrder err = %v", err)
+	}
+	if got.Coupon == nil || got.Coupon.Code != "WELCOME10" {
+		t.Fatalf("coupon = %+v", got.Coupon)
+	}
+
+	// 1 ユーザ 1 回まで（他のユーザは使える）
+	if _, err := uc.CreateOrder(ctx, usecase.CreateOrderInput{Amount: jpy(1200), CouponCode: "WELCOME10"}); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("second use err = %v; want ErrConflict", err)
+	}
+	if _, err := uc.CreateOrder(ctxWithUser("user-2"), usecase.CreateOrderInput{Amount: jpy(1200), CouponCode: "WELCOME10"}); err != nil {
+		t.Fatalf("other user err = %v", err)
+	}
+}
+
+// 取り消し・期限切れになった注文のクーポンは、利用回数と 1 ユーザの枠を戻す
+func TestOrderUsecase_couponReleasedOnCancel(t *testing.T) {
+	t0 := time.Date(2025, 10, 1, 9, 0, 0, 0, time.UTC)
+	coupons := newMemCouponRepo(coupon.Coupon{ID: "c1", Code: "ONCE", Kind: coupon.KindFixed, AmountOff: jpy(100), MaxRedemptions: 1, MaxPerUser: 1, ValidFrom: t0.Add(-time.Hour)})
+	n := 0
+	uc := &usecase.OrderUsecase{
+		Repo:            newMemRepo(),
+		Payments:        newMemPaymentRepo(),
+		Events:          newMemEventRepo(),
+		Coupons:         coupons,
+		Tx:              nopTx{},
+		PG:              okPG{txid: "tx1"},
+		PendingOrderTTL: time.Hour,
+		Clock:           fixedClock{t: t0},
+		IDGen:           seqIDGen{n: &n},
+		Locker:          okLocker{},
+	}
+	ctx := ctxWithUser("user-1")
+	in := usecase.CreateOrderInput{Amount: jpy(1000), CouponCode: "ONCE"}
+
+	o, err := uc.CreateOrder(ctx, in)
+	if err != nil {
+		t.Fatalf("CreateOrder err = %v", err)
+	}
+	if err := uc.CancelOrder(ctx, o.ID); err != nil {
+		t.Fatalf("CancelOrder err = %v", err)
+	}
+	if _, err := coupons.FindRedemptionByOrderID(ctx, o.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("redemption after cancel err = %v; want ErrNotFound", err)
+	}
+
+	// 取り消した分の枠で使い直せる。期限切れでも戻る
+	o, err = uc.CreateOrder(ctx, in)
+	if err != nil {
+		t.Fatalf("CreateOrder after cancel err = %v", err)
+	}
+	uc.Clock = fixedClock{t: t0.Add(time.Hour + time.Minute)}
+	if got, err := uc.ExpireStaleOrders(context.Background(), 10); err != nil || got != 1 {
+		t.Fatalf("ExpireStaleOrders = %d, %v; want 1", got, err)
+	}
+	if c, _ := coupons.FindByCode(ctx, "ONCE"); c.Redeemed != 0 {
+		t.Fatalf("redeemed = %d after expiry; want 0", c.Redeemed)
+	}
+	if _, err := uc.CreateOrder(ctx, in); err != nil {
+		t.Fatalf("CreateOrder after expiry err = %v", err)
+	}
+}
+
+func TestCouponUsecase_CreateCoupon(t *testing.T) {
+	n := 0
+	uc := &usecase.CouponUsecase{
+		Repo:  newMemCouponRepo(),
+		Clock: fixedClock{t: time.Now()},
+		IDGen: seqIDGen{n: &n},
+	}
+	in := &coupon.Coupon{Code: "spring-500", Kind: coupon.KindFixed, AmountOff: jpy(500), MaxRedemptions: 100}
+
+	if _, err := uc.CreateCoupon(ctxWithUser("user-1"), in); !errors.Is(err, domain.ErrForbidden) {
+		t.Fatalf("err = %v; want ErrForbidden", err)
+	}
+
+	admin := ctxWithAdmin("admin-1")
+	c, err := uc.CreateCoupon(admin, in)
+	if err != nil {
+		t.Fatalf("CreateCoupon err = %v", err)
+	}
+	if c.Code != "SPRING-500" || c.ValidFrom.IsZero() {
+		t.Fatalf("coupon = %+v", c)
+	}
+
+	if _, err := uc.CreateCoupon(admin, in); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("duplicate err = %v; want ErrConflict", err)
+	}
+	if _, err := uc.CreateCoupon(admin, &coupon.Coupon{Code: "BAD", Kind: coupon.KindPercentage, PercentOff: 120}); !errors.Is(err, domain.ErrInvalidArgument) {
+		t.Fatalf("invalid err = %v; want ErrInvalidArgument", err)
+	}
+}
+
+// 明細ありの注文では、値引きを税率ごとに按分して値引き後の税額を記録する
+func TestOrderUsecase_CreateOrder_couponTax(t *testing.T) {
+	now := time.Now()
+	n := 0
+	taxes := newMemTaxRepo()
+	uc := &usecase.OrderUsecase{
+		Repo:     newMemRepo(),
+		Payments: newMemPaymentRepo(),
+		Events:   newMemEventRepo(),
+		Items:    newMemItemRepo(),
+		Taxes:    taxes,
+		Coupons:  newMemCouponRepo(coupon.Coupon{ID: "c1", Code: "OFF1000", Kind: coupon.KindFixed, AmountOff: jpy(1000), ValidFrom: now.Add(-time.Hour)}),
+		Tx:       nopTx{},
+		PG:       okPG{txid: "tx1"},
+		Clock:    fixedClock{t: now},
+		IDGen:    seqIDGen{n: &n},
+		Locker:   okLocker{},
+	}
+	items := []order.Item{
+		{SKU: "mug", Name: "マグカップ", UnitPrice: jpy(2000), Quantity: 1, TaxRate: tax.RateStandard}, // 税込 2,200
+		{SKU: "tea", Name: "お茶", UnitPrice: jpy(1000), Quantity: 1, TaxRate: tax.RateReduced},     // 税込 1,080
+	}
+
+	o, err := uc.CreateOrder(ctxWithUser("user-1"), usecase.CreateOrderInput{Items: items, CouponCode: "OFF1000"})
+	if err != nil {
+		t.Fatalf("CreateOrder err = %v", err)
+	}
+	if o.Amount != jpy(2280) {
+		t.Fatalf("amount = %v; want 2280 JPY", o.Amount)
+	}
+
+	// 1000 × 2200/3280 = 670.7 → 671、1000 × 1080/3280 = 329.3 → 329
+	want := []tax.Bucket{
+		{Rate: tax.RateStandard, Taxable: jpy(1390), Tax: jpy(139)}, // 税込 1,529
+		{Rate: tax.RateReduced, Taxable: jpy(696), Tax: jpy(55)},    // 税込 751
+	}
+	for _, got := range [][]tax.Bucket{o.Tax, taxes.m[o.ID]} {
+		if len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+			t.Fatalf("tax = %+v; want %+v", got, want)
+		}
+	}
+}
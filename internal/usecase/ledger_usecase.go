@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/auth"
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/ledger"
+	"github.com/kazshi01/payment-system/internal/domain/money"
+)
+
+// LedgerUsecase は元帳の参照と不変条件の検査。計上は OrderUsecase が決済・返金と同じ Tx で行う
+type LedgerUsecase struct {
+	Repo domain.LedgerRepository
+}
+
+// BalanceQuery は残高照会の条件。From / To がゼロ値なら期間の制限なし
+type BalanceQuery struct {
+	Account  string
+	Currency string
+	From     time.Time // 以上
+	To       time.Time // 未満
+}
+
+// GetBalance は勘定の期間内の借方・貸方の合計を返す（管理者のみ）
+func (uc *LedgerUsecase) GetBalance(ctx context.Context, q BalanceQuery) (*ledger.Balance, error) {
+	if !auth.IsAdmin(ctx) {
+		if _, ok := auth.UserIDFrom(ctx); !ok {
+			return nil, domain.ErrUnauthorized
+		}
+		return nil, domain.ErrForbidden
+	}
+
+	account, err := ledger.ParseAccount(q.Account)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrInvalidArgument, err)
+	}
+	c, err := money.ParseCurrency(q.Currency)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrInvalidArgument, err)
+	}
+	if !q.From.IsZero() && !q.To.IsZero() && !q.From.Before(q.To) {
+		return nil, fmt.Errorf("%w: from must be before to", domain.ErrInvalidArgument)
+	}
+
+	// ---- DB 読み取りは 3s ----
+	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	return uc.Repo.Balance(dbCtx, account, c, q.From, q.To)
+}
+
+// Check は元帳の不変条件（全仕訳の貸借一致、支払い済みの注文が決済・返金額どおりに計上済み）を検査し、
+// 違反を種類ごとに最大 limit 件返す。全件を走査するので運用コマンドから呼び、タイムアウトは ctx に任せる
+func (uc *LedgerUsecase) Check(ctx context.Context, limit int) ([]ledger.Violation, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("%w: limit must be positive", domain.ErrInvalidArgument)
+	}
+	unbalanced, err := uc.Repo.ListUnbalancedEntries(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+	unposted, err := uc.Repo.ListUnpostedOrders(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+	return append(unbalanced, unposted...), nil
+}
@@ -0,0 +1,152 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/ledger"
+	"github.com/kazshi01/payment-system/internal/domain/money"
+	"github.com/kazshi01/payment-system/internal/usecase"
+)
+
+type memLedgerRepo struct {
+	entries []ledger.Entry
+	err     error // Record が返すエラー
+}
+
+func (r *memLedgerRepo) Record(ctx context.Context, e *ledger.Entry) error {
+	if r.err != nil {
+		return r.err
+	}
+	if err := e.Validate(); err != nil {
+		return err
+	}
+	for _, x := range r.entries {
+		if x.Kind == e.Kind && x.Reference == e.Reference {
+			return domain.ErrConflict
+		}
+	}
+	r.entries = append(r.entries, *e)
+	return nil
+}
+
+func (r *memLedgerRepo) Balance(ctx context.Context, account ledger.Account, c money.Currency, from, to time.Time) (*ledger.Balance, error) {
+	b := &ledger.Balance{Account: account, Currency: c}
+	for _, e := range r.entries {
+		for _, p := range e.Postings {
+			if p.Account != account || p.Amount.Currency != c {
+				continue
+			}
+			if p.Amount.Amount > 0 {
+				b.Debit += p.Amount.Amount
+			} else {
+				b.Credit -= p.Amount.Amount
+			}
+		}
+	}
+	return b, nil
+}
+
+func (r *memLedgerRepo) ListUnbalancedEntries(ctx context.Context, limit int) ([]ledger.Violation, error) {
+	return nil, nil
+}
+
+func (r *memLedgerRepo) ListUnpostedOrders(ctx context.Context, limit int) ([]ledger.Violation, error) {
+	return nil, nil
+}
+
+func (r *memLedgerRepo) kinds() []ledger.Kind {
+	var ks []ledger.Kind
+	for _, e := range r.entries {
+		ks = append(ks, e.Kind)
+	}
+	return ks
+}
+
+func TestOrderUsecase_postsToLedger(t *testing.T) {
+	n := 0
+	payments := newMemPaymentRepo()
+	journal := &memLedgerRepo{}
+	uc := &usecase.OrderUsecase{
+		Repo:     newMemRepo(),
+		Payments: payments,
+		Events:   newMemEventRepo(),
+		Refunds:  newMemRefundRepo(payments),
+		Auths:    newMemAuthRepo(),
+		Ledger:   journal,
+		FeeRate:  360,
+		Tx:       nopTx{},
+		PG:       okPG{txid: "tx1"},
+		Clock:    fixedClock{t: time.Now()},
+		IDGen:    seqIDGen{n: &n},
+		Locker:   okLocker{},
+	}
+	ctx := ctxWithUser("user-1")
+
+	o, _ := uc.CreateOrder(ctx, usecase.CreateOrderInput{Amount: jpy(1000)})
+	if err := uc.PayOrder(ctx, o.ID, "pm_card_visa"); err != nil {
+		t.Fatalf("PayOrder err = %v", err)
+	}
+	if _, err := uc.RefundOrder(ctxWithAdmin("admin-1"), o.ID, jpy(300), ""); err != nil {
+		t.Fatalf("RefundOrder err = %v", err)
+	}
+
+	want := []ledger.Kind{ledger.KindCharge, ledger.KindFee, ledger.KindRefund}
+	if got := journal.kinds(); len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Fatalf("entries = %v; want %v", got, want)
+	}
+	ps, _ := payments.ListByOrderID(ctx, o.ID)
+	if journal.entries[0].Reference != string(ps[0].ID) || journal.entries[0].OrderID != string(o.ID) {
+		t.Fatalf("charge entry = %+v; want reference to payment %s", journal.entries[0], ps[0].ID)
+	}
+
+	// 売掛金 = 1000 - 手数料 36 - 返金 300
+	b, _ := journal.Balance(ctx, ledger.AccountProviderReceivable, money.JPY, time.Time{}, time.Time{})
+	if got := b.Net(); got != jpy(664) {
+		t.Fatalf("receivable = %s; want 664 JPY", got)
+	}
+
+	// 手数料 0 なら手数料の仕訳は作らない
+	uc.FeeRate = 0
+	o2, _ := uc.CreateOrder(ctx, usecase.CreateOrderInput{Amount: jpy(500)})
+	if err := uc.PayOrder(ctx, o2.ID, "pm_card_visa"); err != nil {
+		t.Fatalf("PayOrder err = %v", err)
+	}
+	if got := journal.kinds(); len(got) != 4 || got[3] != ledger.KindCharge {
+		t.Fatalf("entries = %v; want one more charge", got)
+	}
+
+	// 計上に失敗したら決済の記録ごと失敗させる（Tx が戻る）
+	journal.err = errors.New("db down")
+	o3, _ := uc.CreateOrder(ctx, usecase.CreateOrderInput{Amount: jpy(500)})
+	if err := uc.PayOrder(ctx, o3.ID, "pm_card_visa"); err == nil {
+		t.Fatalf("PayOrder err = nil; want the ledger error")
+	}
+}
+
+func TestLedgerUsecase_GetBalance(t *testing.T) {
+	uc := &usecase.LedgerUsecase{Repo: &memLedgerRepo{}}
+	q := usecase.BalanceQuery{Account: "sales", Currency: "jpy"}
+
+	if _, err := uc.GetBalance(ctxWithUser("user-1"), q); !errors.Is(err, domain.ErrForbidden) {
+		t.Fatalf("GetBalance(user) err = %v; want ErrForbidden", err)
+	}
+	admin := ctxWithAdmin("admin-1")
+	if b, err := uc.GetBalance(admin, q); err != nil || b.Currency != money.JPY {
+		t.Fatalf("GetBalance = %+v, %v; want JPY balance", b, err)
+	}
+
+	now := time.Now()
+	for _, bad := range []usecase.BalanceQuery{
+		{Account: "cash", Currency: "JPY"},
+		{Account: "sales", Currency: "XXX"},
+		{Account: "sales", Currency: "JPY", From: now, To: now},
+	} {
+		if _, err := uc.GetBalance(admin, bad); !errors.Is(err, domain.ErrInvalidArgument) {
+			t.Fatalf("GetBalance(%+v) err = %v; want ErrInvalidArgument", bad, err)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/auth"
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/merchant"
+	"github.com/kazshi01/payment-system/internal/domain/tax"
+)
+
+// MerchantSettingsUsecase はログイン中の加盟店が自分の設定を参照・変更する
+type MerchantSettingsUsecase struct {
+	Repo  domain.MerchantSettingsRepository
+	Clock Clock
+}
+
+// UpdateSettingsInput は設定の変更内容（nil の項目は変更しない）
+type UpdateSettingsInput struct {
+	TaxRounding        *string
+	IssuerName         *string
+	IssuerAddress      *string
+	RegistrationNumber *string // 空文字で登録番号を消す
+}
+
+// GetSettings は未設定なら既定値を返す
+func (uc *MerchantSettingsUsecase) GetSettings(ctx context.Context) (*merchant.Settings, error) {
+	userID, ok := auth.UserIDFrom(ctx)
+	if !ok || userID == "" {
+		return nil, domain.ErrUnauthorized
+	}
+
+	// ---- DB 読み取りは 3s ----
+	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	return uc.get(dbCtx, userID)
+}
+
+// UpdateSettings は指定された項目だけを変更する。
+// 端数処理は作成済みの注文に、発行者の情報は発行済みの領収書番号に影響しない
+func (uc *MerchantSettingsUsecase) UpdateSettings(ctx context.Context, in UpdateSettingsInput) (*merchant.Settings, error) {
+	userID, ok := auth.UserIDFrom(ctx)
+	if !ok || userID == "" {
+		return nil, domain.ErrUnauthorized
+	}
+
+	// ---- DB 反映は 3s ----
+	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	ms, err := uc.get(dbCtx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if in.TaxRounding != nil {
+		if ms.TaxRounding, err = tax.ParseRounding(*in.TaxRounding); err != nil {
+			return nil, fmt.Errorf("%w: %w", domain.ErrInvalidArgument, err)
+		}
+	}
+	if in.IssuerName != nil {
+		ms.Issuer.Name = strings.TrimSpace(*in.IssuerName)
+	}
+	if in.IssuerAddress != nil {
+		ms.Issuer.Address = strings.TrimSpace(*in.IssuerAddress)
+	}
+	if in.RegistrationNumber != nil {
+		ms.Issuer.RegistrationNumber = strings.ToUpper(strings.TrimSpace(*in.RegistrationNumber))
+	}
+	if err := ms.Issuer.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrInvalidArgument, err)
+	}
+
+	ms.UpdatedAt = uc.Clock.Now()
+	if err := uc.Repo.Upsert(dbCtx, ms); err != nil {
+		return nil, err
+	}
+	return ms, nil
+}
+
+func (uc *MerchantSettingsUsecase) get(ctx context.Context, userID string) (*merchant.Settings, error) {
+	ms, err := uc.Repo.Get(ctx, userID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return merchant.Default(userID), nil
+	}
+	return ms, err
+}
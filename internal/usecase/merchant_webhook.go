@@ -0,0 +1,464 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/netip"
+	"net/url"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/auth"
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/event"
+	"github.com/kazshi01/payment-system/internal/domain/money"
+	"github.com/kazshi01/payment-system/internal/domain/order"
+	"github.com/kazshi01/payment-system/internal/domain/webhook"
+)
+
+const (
+	defaultWebhookMaxAge      = 24 * time.Hour
+	defaultWebhookBaseBackoff = 30 * time.Second
+	defaultWebhookMaxBackoff  = 4 * time.Hour
+	webhookClaimLease         = time.Minute
+	maxWebhookDeliveryList    = 100
+)
+
+// 注文イベント → 加盟店に通知するイベント
+var webhookEventTypes = map[event.Type]webhook.EventType{
+	event.TypeOrderPaid:     webhook.EventOrderPaid,
+	event.TypeOrderCanceled: webhook.EventOrderCanceled,
+	event.TypeOrderVoided:   webhook.EventOrderCanceled,
+	event.TypeOrderRefunded: webhook.EventRefundSucceeded,
+}
+
+// MerchantWebhookUsecase は加盟店（注文の所有ユーザ）への Webhook 通知を扱う。
+// outbox の EventPublisher として配信レコードを作り、DeliverDue が送信する
+type MerchantWebhookUsecase struct {
+	Endpoints  domain.WebhookEndpointRepository
+	Deliveries domain.WebhookDeliveryRepository
+	Orders     domain.OrderRepository
+	Sender     domain.WebhookSender
+	Resolver   domain.HostResolver // 登録時に通知先を引く（nil なら net.DefaultResolver）
+	Tx         domain.Tx
+	Clock      Clock
+	IDGen      IDGen
+
+	MaxAge      time.Duration // 最初のイベントからこの期間を過ぎたら諦める（0 なら 24h）
+	BaseBackoff time.Duration // 1回目の失敗後の待ち時間（0 なら 30s）。以後倍々
+	MaxBackoff  time.Duration // 待ち時間の上限（0 なら 4h）
+}
+
+// --- Endpoints ---
+
+func (uc *MerchantWebhookUsecase) CreateEndpoint(ctx context.Context, rawURL string, events []string) (*webhook.Endpoint, error) {
+	userID, ok := auth.UserIDFrom(ctx)
+	if !ok || userID == "" {
+		return nil, domain.ErrUnauthorized
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "https" || u.Hostname() == "" {
+		return nil, fmt.Errorf("%w: url must be an absolute https URL", domain.ErrInvalidArgument)
+	}
+	if err := uc.checkPublicHost(ctx, u.Hostname()); err != nil {
+		return nil, err
+	}
+
+	if len(events) == 0 {
+		return nil, fmt.Errorf("%w: events must not be empty", domain.ErrInvalidArgument)
+	}
+	types := make([]webhook.EventType, 0, len(events))
+	seen := map[webhook.EventType]bool{}
+	for _, s := range events {
+		t := webhook.EventType(s)
+		if !t.Valid() {
+			return nil, fmt.Errorf("%w: unknown event %q", domain.ErrInvalidArgument, s)
+		}
+		if !seen[t] {
+			seen[t] = true
+			types = append(types, t)
+		}
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	e := &webhook.Endpoint{
+		ID:        webhook.EndpointID(uc.IDGen.New()),
+		UserID:    userID,
+		URL:       u.String(),
+		Events:    types,
+		Secret:    secret,
+		CreatedAt: uc.Clock.Now(),
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	if err := uc.Endpoints.Create(dbCtx, e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// 通知先が内部アドレスを指していないか確かめる（SSRF 対策）。
+// 登録後に DNS を書き換えられても、送信時に接続先のアドレスをもう一度検査する
+func (uc *MerchantWebhookUsecase) checkPublicHost(ctx context.Context, host string) error {
+	if ip, err := netip.ParseAddr(host); err == nil {
+		if !webhook.IsPublicAddr(ip) {
+			return fmt.Errorf("%w: url must not point to a private address", domain.ErrInvalidArgument)
+		}
+		return nil
+	}
+
+	var r domain.HostResolver = net.DefaultResolver
+	if uc.Resolver != nil {
+		r = uc.Resolver
+	}
+
+	// ---- 名前解決は 3s ----
+	lookupCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	ips, err := r.LookupNetIP(lookupCtx, "ip", host)
+	if err != nil || len(ips) == 0 {
+		return fmt.Errorf("%w: cannot resolve url host %q", domain.ErrInvalidArgument, host)
+	}
+	for _, ip := range ips {
+		if !webhook.IsPublicAddr(ip) {
+			return fmt.Errorf("%w: url must not point to a private address", domain.ErrInvalidArgument)
+		}
+	}
+	return nil
+}
+
+// 自分の通知先一覧
+func (uc *MerchantWebhookUsecase) ListEndpoints(ctx context.Context) ([]*webhook.Endpoint, error) {
+	userID, ok := auth.UserIDFrom(ctx)
+	if !ok || userID == "" {
+		return nil, domain.ErrUnauthorized
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	return uc.Endpoints.ListByUserID(dbCtx, userID)
+}
+
+// 通知先を削除する（所有者または管理者）。未配信分は以後送らない
+func (uc *MerchantWebhookUsecase) DeleteEndpoint(ctx context.Context, id webhook.EndpointID) error {
+	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	if _, err := uc.findEndpoint(dbCtx, id); err != nil {
+		return err
+	}
+
+	rows, err := uc.Endpoints.Delete(dbCtx, id, uc.Clock.Now())
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// --- Delivery log ---
+
+// 通知先の直近の配信（新しい順、最大100件）
+func (uc *MerchantWebhookUsecase) ListDeliveries(ctx context.Context, id webhook.EndpointID) ([]*webhook.Delivery, error) {
+	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	if _, err := uc.findEndpoint(dbCtx, id); err != nil {
+		return nil, err
+	}
+	return uc.Deliveries.ListByEndpointID(dbCtx, id, maxWebhookDeliveryList)
+}
+
+// 配信と試行履歴（所有者または管理者）
+func (uc *MerchantWebhookUsecase) GetDelivery(ctx context.Context, id webhook.DeliveryID) (*webhook.Delivery, []*webhook.Attempt, error) {
+	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	d, err := uc.Deliveries.FindByID(dbCtx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !auth.IsAdmin(ctx) {
+		if _, err := uc.findEndpoint(dbCtx, d.EndpointID); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	attempts, err := uc.Deliveries.ListAttempts(dbCtx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return d, attempts, nil
+}
+
+// Redeliver は配信を今すぐ 1 回再送する（管理者のみ）。
+// 状態に関わらず送信し、成功すれば SUCCEEDED になる。失敗しても自動リトライの予定は変えない
+func (uc *MerchantWebhookUsecase) Redeliver(ctx context.Context, id webhook.DeliveryID) (*webhook.Delivery, *webhook.Attempt, error) {
+	if !auth.IsAdmin(ctx) {
+		if _, ok := auth.UserIDFrom(ctx); !ok {
+			return nil, nil, domain.ErrUnauthorized
+		}
+		return nil, nil, domain.ErrForbidden
+	}
+
+	dbReadCtx, cancelRead := context.WithTimeout(ctx, 3*time.Second)
+	defer cancelRead()
+
+	d, err := uc.Deliveries.FindByID(dbReadCtx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	a, err := uc.deliver(ctx, d, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	return d, a, nil
+}
+
+// --- Fan-out (domain.EventPublisher) ---
+
+// Publish は outbox のイベントを、注文の所有ユーザが購読している通知先ごとの配信にする。
+// relay の再送で同じイベントが来ても (endpoint, event) で一意なので重複しない
+func (uc *MerchantWebhookUsecase) Publish(ctx context.Context, m *domain.OutboxMessage) error {
+	typ, ok := webhookEventTypes[event.Type(m.Type)]
+	if !ok {
+		return nil
+	}
+
+	var env outboxEnvelope
+	if err := json.Unmarshal(m.Payload, &env); err != nil {
+		env = outboxEnvelope{} // 読めなければ details は空で送る
+	}
+
+	// 注文の状態はイベントを記録した時点のもの（配信までに進んだ状態を混ぜない）
+	snap := env.Order
+	if snap == nil {
+		// 注文の状態を持たない古いメッセージは注文を読み直す
+		o, err := uc.Orders.FindByID(ctx, order.ID(m.AggregateID))
+		if err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				return nil
+			}
+			return err
+		}
+		snap = snapshotOf(o)
+	}
+
+	endpoints, err := uc.Endpoints.ListByUserID(ctx, snap.UserID)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"id":         m.ID,
+		"type":       typ,
+		"created_at": m.CreatedAt,
+		"data": map[string]any{
+			"order": putAmount(map[string]any{
+				"id":     snap.ID,
+				"status": snap.Status,
+			}, "amount", money.Money{Amount: snap.Amount, Currency: money.Currency(snap.Currency)}),
+			"details": env.Payload,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("marshal webhook payload: %w", err)
+	}
+
+	now := uc.Clock.Now()
+	return uc.Tx.Do(ctx, func(ctx context.Context) error {
+		for _, e := range endpoints {
+			if !e.Subscribes(typ) {
+				continue
+			}
+			if _, err := uc.Deliveries.CreateIfAbsent(ctx, &webhook.Delivery{
+				ID:            webhook.DeliveryID(uc.IDGen.New()),
+				EndpointID:    e.ID,
+				EventID:       m.ID,
+				EventType:     typ,
+				Payload:       body,
+				Status:        webhook.DeliveryPending,
+				NextAttemptAt: now,
+				CreatedAt:     now,
+				UpdatedAt:     now,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// --- Worker ---
+
+// DeliverDue は送信期限が来た配信を最大 limit 件送信し、成功件数を返す。
+// 取り出した配信はリース期間中ほかの worker から見えないので並列に動かしてよい
+func (uc *MerchantWebhookUsecase) DeliverDue(ctx context.Context, limit int) (int, error) {
+	now := uc.Clock.Now()
+
+	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	ds, err := uc.Deliveries.ClaimDue(dbCtx, now, now.Add(webhookClaimLease), limit)
+	cancel()
+	if err != nil {
+		return 0, err
+	}
+
+	succeeded := 0
+	for _, d := range ds {
+		a, err := uc.deliver(ctx, d, false)
+		if err != nil {
+			log.Printf("warn: deliver webhook: delivery_id=%s: %v", d.ID, err)
+			continue
+		}
+		if a.Succeeded() {
+			succeeded++
+		}
+	}
+	return succeeded, nil
+}
+
+// 1回送信して試行を記録する。manual なら失敗しても状態・次回予定を変えない
+func (uc *MerchantWebhookUsecase) deliver(ctx context.Context, d *webhook.Delivery, manual bool) (*webhook.Attempt, error) {
+	dbReadCtx, cancelRead := context.WithTimeout(ctx, 3*time.Second)
+	defer cancelRead()
+
+	a := &webhook.Attempt{
+		ID:         uc.IDGen.New(),
+		DeliveryID: d.ID,
+	}
+
+	e, err := uc.Endpoints.FindByID(dbReadCtx, d.EndpointID)
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		// 削除済みの通知先には送らない
+		a.AttemptedAt = uc.Clock.Now()
+		a.Error = "endpoint deleted"
+	case err != nil:
+		return nil, err
+	default:
+		uc.send(ctx, e, d, a)
+	}
+
+	d.Attempts++
+	d.LastStatusCode = a.StatusCode
+	d.LastError = a.Error
+	d.UpdatedAt = a.AttemptedAt
+
+	switch {
+	case a.Succeeded():
+		d.Status = webhook.DeliverySucceeded
+	case e == nil:
+		d.Status = webhook.DeliveryFailed
+	case manual:
+		// 手動再送の失敗は履歴に残すだけ
+	default:
+		next := a.AttemptedAt.Add(uc.backoff(d.Attempts))
+		if next.After(d.CreatedAt.Add(uc.maxAge())) {
+			d.Status = webhook.DeliveryFailed
+		} else {
+			d.NextAttemptAt = next
+		}
+	}
+
+	// ---- DB 反映は 3s ----
+	dbCtx, cancelDB := context.WithTimeout(ctx, 3*time.Second)
+	defer cancelDB()
+
+	if err := uc.Tx.Do(dbCtx, func(dbCtx context.Context) error {
+		return uc.Deliveries.RecordAttempt(dbCtx, d, a)
+	}); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (uc *MerchantWebhookUsecase) send(ctx context.Context, e *webhook.Endpoint, d *webhook.Delivery, a *webhook.Attempt) {
+	// ---- 送信は 10s ----
+	sendCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	start := uc.Clock.Now()
+	status, err := uc.Sender.Send(sendCtx, e.URL, map[string]string{
+		webhook.IDHeader:        d.EventID,
+		webhook.SignatureHeader: webhook.Sign(e.Secret, start, d.Payload),
+	}, d.Payload)
+
+	a.AttemptedAt = start
+	a.Duration = uc.Clock.Now().Sub(start)
+	a.StatusCode = status
+	if err != nil {
+		a.Error = err.Error()
+	} else if !a.Succeeded() {
+		a.Error = fmt.Sprintf("unexpected status %d", status)
+	}
+}
+
+// 30s, 1m, 2m, ... を上限で頭打ちにする
+func (uc *MerchantWebhookUsecase) backoff(attempts int) time.Duration {
+	base := uc.BaseBackoff
+	if base <= 0 {
+		base = defaultWebhookBaseBackoff
+	}
+	maxBackoff := uc.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = defaultWebhookMaxBackoff
+	}
+
+	d := base
+	for i := 1; i < attempts; i++ {
+		d *= 2
+		if d >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return d
+}
+
+func (uc *MerchantWebhookUsecase) maxAge() time.Duration {
+	if uc.MaxAge <= 0 {
+		return defaultWebhookMaxAge
+	}
+	return uc.MaxAge
+}
+
+// 所有者以外には存在を見せない（管理者は全件）
+func (uc *MerchantWebhookUsecase) findEndpoint(ctx context.Context, id webhook.EndpointID) (*webhook.Endpoint, error) {
+	userID, ok := auth.UserIDFrom(ctx)
+	if !ok || userID == "" {
+		return nil, domain.ErrUnauthorized
+	}
+
+	e, err := uc.Endpoints.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if e.UserID != userID && !auth.IsAdmin(ctx) {
+		return nil, domain.ErrNotFound
+	}
+	return e, nil
+}
+
+func newWebhookSecret() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate webhook secret: %w", err)
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
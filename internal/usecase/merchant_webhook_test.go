@@ -0,0 +1,404 @@
+package usecase_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/netip"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/money"
+	"github.com/kazshi01/payment-system/internal/domain/order"
+	"github.com/kazshi01/payment-system/internal/domain/webhook"
+	"github.com/kazshi01/payment-system/internal/usecase"
+)
+
+type memEndpointRepo struct {
+	m map[webhook.EndpointID]*webhook.Endpoint
+}
+
+func (r *memEndpointRepo) Create(ctx context.Context, e *webhook.Endpoint) error {
+	cp := *e
+	r.m[e.ID] = &cp
+	return nil
+}
+
+func (r *memEndpointRepo) FindByID(ctx context.Context, id webhook.EndpointID) (*webhook.Endpoint, error) {
+	e, ok := r.m[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	cp := *e
+	return &cp, nil
+}
+
+func (r *memEndpointRepo) ListByUserID(ctx context.Context, userID string) ([]*webhook.Endpoint, error) {
+	var out []*webhook.Endpoint
+	for _, e := range r.m {
+		if e.UserID == userID {
+			cp := *e
+			out = append(out, &cp)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+func (r *memEndpointRepo) Delete(ctx context.Context, id webhook.EndpointID, at time.Time) (int64, error) {
+	if _, ok := r.m[id]; !ok {
+		return 0, nil
+	}
+	delete(r.m, id)
+	return 1, nil
+}
+
+type memDeliveryRepo struct {
+	m        map[webhook.DeliveryID]*webhook.Delivery
+	attempts []*webhook.Attempt
+}
+
+func (r *memDeliveryRepo) CreateIfAbsent(ctx context.Context, d *webhook.Delivery) (bool, error) {
+	for _, x := range r.m {
+		if x.EndpointID == d.EndpointID && x.EventID == d.EventID {
+			return false, nil
+		}
+	}
+	cp := *d
+	r.m[d.ID] = &cp
+	return true, nil
+}
+
+func (r *memDeliveryRepo) FindByID(ctx context.Context, id webhook.DeliveryID) (*webhook.Delivery, error) {
+	d, ok := r.m[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	cp := *d
+	return &cp, nil
+}
+
+func (r *memDeliveryRepo) ListByEndpointID(ctx context.Context, id webhook.EndpointID, limit int) ([]*webhook.Delivery, error) {
+	var out []*webhook.Delivery
+	for _, d := range r.m {
+		if d.EndpointID == id && len(out) < limit {
+			cp := *d
+			out = append(out, &cp)
+		}
+	}
+	return out, nil
+}
+
+func (r *memDeliveryRepo) ClaimDue(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*webhook.Delivery, error) {
+	var out []*webhook.Delivery
+	for _, d := range r.m {
+		if d.Status == webhook.DeliveryPending && !d.NextAttemptAt.After(now) && len(out) < limit {
+			d.NextAttemptAt = leaseUntil
+			cp := *d
+			out = append(out, &cp)
+		}
+	}
+	return out, nil
+}
+
+func (r *memDeliveryRepo) RecordAttempt(ctx context.Context, d *webhook.Delivery, a *webhook.Attempt) error {
+	cp := *d
+	r.m[d.ID] = &cp
+	ca := *a
+	r.attempts = append(r.attempts, &ca)
+	return nil
+}
+
+func (r *memDeliveryRepo) ListAttempts(ctx context.Context, id webhook.DeliveryID) ([]*webhook.Attempt, error) {
+	var out []*webhook.Attempt
+	for _, a := range r.attempts {
+		if a.DeliveryID == id {
+			cp := *a
+			out = append(out, &cp)
+		}
+	}
+	return out, nil
+}
+
+type stubSender struct {
+	status int
+	err    error
+	sent   []map[string]string
+}
+
+func (s *stubSender) Send(ctx context.Context, url string, header map[string]string, body []byte) (int, error) {
+	s.sent = append(s.sent, header)
+	return s.status, s.err
+}
+
+func newMerchantWebhookTest(now *time.Time) (*usecase.MerchantWebhookUsecase, *memRepo, *memDeliveryRepo, *stubSender) {
+	n := 0
+	orders := newMemRepo()
+	deliveries := &memDeliveryRepo{m: map[webhook.DeliveryID]*webhook.Delivery{}}
+	sender := &stubSender{status: 200}
+	return &usecase.MerchantWebhookUsecase{
+		Endpoints:   &memEndpointRepo{m: map[webhook.EndpointID]*webhook.Endpoint{}},
+		Deliveries:  deliveries,
+		Orders:      orders,
+		Sender:      sender,
+		Resolver:    stubResolver{},
+		Tx:          nopTx{},
+		Clock:       ptrClock{t: now},
+		IDGen:       seqIDGen{n: &n},
+		BaseBackoff: time.Minute,
+		MaxBackoff:  time.Hour,
+		MaxAge:      3 * time.Hour,
+	}, orders, deliveries, sender
+}
+
+// example.com は公開アドレス、internal.example.com は内部アドレスに解決する
+type stubResolver struct{}
+
+func (stubResolver) LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error) {
+	switch host {
+	case "example.com":
+		return []netip.Addr{netip.MustParseAddr("93.184.215.14")}, nil
+	case "internal.example.com":
+		return []netip.Addr{netip.MustParseAddr("93.184.215.14"), netip.MustParseAddr("10.0.0.5")}, nil
+	}
+	return nil, errors.New("no such host")
+}
+
+// テスト側で進める時計
+type ptrClock struct{ t *time.Time }
+
+func (c ptrClock) Now() time.Time { return *c.t }
+
+func paidMessage(orderID string, now time.Time) *domain.OutboxMessage {
+	return &domain.OutboxMessage{
+		ID:          "evt-1",
+		AggregateID: orderID,
+		Type:        "ORDER_PAID",
+		Payload:     []byte(`{"id":"evt-1","payload":{"payment_id":"p-1"}}`),
+		CreatedAt:   now,
+	}
+}
+
+func TestMerchantWebhook_CreateEndpoint_validation(t *testing.T) {
+	now := time.Date(2025, 9, 27, 7, 0, 0, 0, time.UTC)
+	uc, _, _, _ := newMerchantWebhookTest(&now)
+	ctx := ctxWithUser("user-1")
+
+	bad := []struct {
+		url    string
+		events []string
+	}{
+		{"ftp://example.com/hook", []string{"order.paid"}},
+		{"/relative", []string{"order.paid"}},
+		{"http://example.com/hook", []string{"order.paid"}},
+		// 内部アドレス（SSRF）
+		{"https://127.0.0.1/hook", []string{"order.paid"}},
+		{"https://10.1.2.3/hook", []string{"order.paid"}},
+		{"https://169.254.169.254/latest/meta-data", []string{"order.paid"}},
+		{"https://[::1]:8443/hook", []string{"order.paid"}},
+		{"https://[::ffff:192.168.0.1]/hook", []string{"order.paid"}},
+		{"https://internal.example.com/hook", []string{"order.paid"}},
+		{"https://unknown.example.com/hook", []string{"order.paid"}},
+		{"https://example.com/hook", nil},
+		{"https://example.com/hook", []string{"order.shipped"}},
+	}
+	for _, c := range bad {
+		if _, err := uc.CreateEndpoint(ctx, c.url, c.events); !errors.Is(err, domain.ErrInvalidArgument) {
+			t.Errorf("CreateEndpoint(%q, %v) err = %v; want ErrInvalidArgument", c.url, c.events, err)
+		}
+	}
+
+	e, err := uc.CreateEndpoint(ctx, "https://example.com/hook", []string{"order.paid", "order.paid"})
+	if err != nil {
+		t.Fatalf("CreateEndpoint err = %v", err)
+	}
+	if len(e.Events) != 1 || len(e.Secret) < 20 || e.UserID != "user-1" {
+		t.Fatalf("endpoint = %+v", e)
+	}
+
+	// 他人の通知先は見えない
+	if err := uc.DeleteEndpoint(ctxWithUser("user-2"), e.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("err = %v; want ErrNotFound", err)
+	}
+}
+
+func TestMerchantWebhook_fanOutAndDeliver(t *testing.T) {
+	now := time.Date(2025, 9, 27, 7, 0, 0, 0, time.UTC)
+	uc, orders, deliveries, sender := newMerchantWebhookTest(&now)
+	ctx := ctxWithUser("user-1")
+
+	_ = orders.Create(context.Background(), &order.Order{ID: "o-1", UserID: "user-1", Amount: jpy(1200), Status: order.StatusPaid})
+	paid, _ := uc.CreateEndpoint(ctx, "https://example.com/paid", []string{"order.paid"})
+	_, _ = uc.CreateEndpoint(ctx, "https://example.com/refund", []string{"refund.succeeded"})
+
+	// relay の再送で同じイベントが 2 回来ても配信は 1 件
+	for i := 0; i < 2; i++ {
+		if err := uc.Publish(context.Background(), paidMessage("o-1", now)); err != nil {
+			t.Fatalf("Publish err = %v", err)
+		}
+	}
+	if len(deliveries.m) != 1 {
+		t.Fatalf("deliveries = %d; want 1", len(deliveries.m))
+	}
+	var d *webhook.Delivery
+	for _, x := range deliveries.m {
+		d = x
+	}
+	if d.EndpointID != paid.ID || d.EventType != webhook.EventOrderPaid {
+		t.Fatalf("delivery = %+v", d)
+	}
+	var body struct {
+		ID   string `json:"id"`
+		Type string `json:"type"`
+		Data struct {
+			Order struct {
+				ID        string `json:"id"`
+				AmountJPY int64  `json:"amount_jpy"`
+			} `json:"order"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(d.Payload, &body); err != nil {
+		t.Fatalf("unmarshal payload: %v", err)
+	}
+	if body.ID != "evt-1" || body.Type != "order.paid" || body.Data.Order.ID != "o-1" || body.Data.Order.AmountJPY != 1200 {
+		t.Fatalf("payload = %s", d.Payload)
+	}
+
+	// 1回目は失敗 → 1分後に再送予定
+	sender.status = 500
+	if n, err := uc.DeliverDue(context.Background(), 10); err != nil || n != 0 {
+		t.Fatalf("DeliverDue = %d, %v", n, err)
+	}
+	got := deliveries.m[d.ID]
+	if got.Status != webhook.DeliveryPending || got.Attempts != 1 || !got.NextAttemptAt.Equal(now.Add(time.Minute)) {
+		t.Fatalf("delivery after failure = %+v", got)
+	}
+
+	// 期限前は送らない
+	if n, _ := uc.DeliverDue(context.Background(), 10); n != 0 || len(sender.sent) != 1 {
+		t.Fatalf("delivered before next attempt: sent=%d", len(sender.sent))
+	}
+
+	now = now.Add(time.Minute)
+	sender.status = 204
+	if n, err := uc.DeliverDue(context.Background(), 10); err != nil || n != 1 {
+		t.Fatalf("DeliverDue = %d, %v; want 1", n, err)
+	}
+	got = deliveries.m[d.ID]
+	if got.Status != webhook.DeliverySucceeded || got.Attempts != 2 {
+		t.Fatalf("delivery after success = %+v", got)
+	}
+
+	// 署名は通知先のシークレットで、送信時刻に対して付く
+	h := sender.sent[len(sender.sent)-1]
+	e, _ := uc.Endpoints.FindByID(context.Background(), paid.ID)
+	if h[webhook.IDHeader] != "evt-1" || h[webhook.SignatureHeader] != webhook.Sign(e.Secret, now, d.Payload) {
+		t.Fatalf("headers = %v", h)
+	}
+	if as, _ := deliveries.ListAttempts(context.Background(), d.ID); len(as) != 2 {
+		t.Fatalf("attempts = %d; want 2", len(as))
+	}
+}
+
+// 配信までに注文が進んでも、通知の状態はイベントを記録した時点のもの
+func TestMerchantWebhook_statusAtEventTime(t *testing.T) {
+	now := time.Date(2025, 9, 27, 7, 0, 0, 0, time.UTC)
+	uc, orders, deliveries, _ := newMerchantWebhookTest(&now)
+	ctx := ctxWithUser("user-1")
+
+	ouc, _ := newRefundTestUsecase()
+	ouc.Repo = orders
+	outbox := newMemOutbox()
+	ouc.Outbox = outbox
+
+	o, _ := ouc.CreateOrder(ctx, usecase.CreateOrderInput{Amount: jpy(1200)})
+	if err := ouc.PayOrder(ctx, o.ID, "pm_card_visa"); err != nil {
+		t.Fatalf("PayOrder err = %v", err)
+	}
+	if _, err := ouc.RefundOrder(ctxWithAdmin("admin-1"), o.ID, money.Money{}, "requested"); err != nil {
+		t.Fatalf("RefundOrder err = %v", err)
+	}
+	if _, err := uc.CreateEndpoint(ctx, "https://example.com/paid", []string{"order.paid"}); err != nil {
+		t.Fatalf("CreateEndpoint err = %v", err)
+	}
+
+	for _, m := range outbox.ms {
+		if m.Type == "ORDER_PAID" {
+			if err := uc.Publish(context.Background(), m); err != nil {
+				t.Fatalf("Publish err = %v", err)
+			}
+		}
+	}
+	if len(deliveries.m) != 1 {
+		t.Fatalf("deliveries = %d; want 1", len(deliveries.m))
+	}
+	for _, d := range deliveries.m {
+		var body struct {
+			Data struct {
+				Order struct {
+					Status string `json:"status"`
+				} `json:"order"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(d.Payload, &body); err != nil {
+			t.Fatalf("unmarshal payload: %v", err)
+		}
+		if body.Data.Order.Status != string(order.StatusPaid) {
+			t.Fatalf("order status = %q; want PAID (order is now REFUNDED)", body.Data.Order.Status)
+		}
+	}
+}
+
+func TestMerchantWebhook_givesUpAfterMaxAge(t *testing.T) {
+	now := time.Date(2025, 9, 27, 7, 0, 0, 0, time.UTC)
+	uc, orders, deliveries, sender := newMerchantWebhookTest(&now)
+	sender.status = 503
+
+	_ = orders.Create(context.Background(), &order.Order{ID: "o-1", UserID: "user-1", Amount: jpy(1200), Status: order.StatusPaid})
+	_, _ = uc.CreateEndpoint(ctxWithUser("user-1"), "https://example.com/paid", []string{"order.paid"})
+	_ = uc.Publish(context.Background(), paidMessage("o-1", now))
+
+	for i := 0; i < 20; i++ {
+		_, _ = uc.DeliverDue(context.Background(), 10)
+		now = now.Add(time.Hour)
+	}
+
+	for _, d := range deliveries.m {
+		if d.Status != webhook.DeliveryFailed {
+			t.Fatalf("status = %s; want FAILED after max age", d.Status)
+		}
+		// 1m, 2m, 4m, ... 1h 上限で 3h 以内に収まる回数だけ試す
+		if d.Attempts < 4 || d.Attempts > 8 {
+			t.Fatalf("attempts = %d", d.Attempts)
+		}
+	}
+}
+
+func TestMerchantWebhook_Redeliver(t *testing.T) {
+	now := time.Date(2025, 9, 27, 7, 0, 0, 0, time.UTC)
+	uc, orders, deliveries, sender := newMerchantWebhookTest(&now)
+
+	_ = orders.Create(context.Background(), &order.Order{ID: "o-1", UserID: "user-1", Amount: jpy(1200), Status: order.StatusPaid})
+	_, _ = uc.CreateEndpoint(ctxWithUser("user-1"), "https://example.com/paid", []string{"order.paid"})
+	_ = uc.Publish(context.Background(), paidMessage("o-1", now))
+
+	var id webhook.DeliveryID
+	for k, d := range deliveries.m {
+		id = k
+		d.Status = webhook.DeliveryFailed
+	}
+
+	if _, _, err := uc.Redeliver(ctxWithUser("user-1"), id); !errors.Is(err, domain.ErrForbidden) {
+		t.Fatalf("err = %v; want ErrForbidden", err)
+	}
+
+	d, a, err := uc.Redeliver(ctxWithAdmin("admin-1"), id)
+	if err != nil {
+		t.Fatalf("Redeliver err = %v", err)
+	}
+	if !a.Succeeded() || d.Status != webhook.DeliverySucceeded || len(sender.sent) != 1 {
+		t.Fatalf("delivery = %+v attempt = %+v", d, a)
+	}
+}
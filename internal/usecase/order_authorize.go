@@ -0,0 +1,374 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/auth"
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/event"
+	"github.com/kazshi01/payment-system/internal/domain/money"
+	"github.com/kazshi01/payment-system/internal/domain/order"
+	"github.com/kazshi01/payment-system/internal/domain/payment"
+)
+
+// カード与信の一般的な保持期間に合わせる
+const defaultAuthorizationTTL = 7 * 24 * time.Hour
+
+// --- Authorize ---
+
+// 与信のみ行い、売上確定（Capture）は出荷時に行う。paymentMethod は PayOrder と同じ
+func (uc *OrderUsecase) AuthorizeOrder(ctx context.Context, id order.ID, paymentMethod string) (*payment.Authorization, error) {
+	isAdmin := auth.IsAdmin(ctx)
+
+	// 一般ユーザは userID 必須。管理者は不要
+	userID, _ := auth.UserIDFrom(ctx)
+	if !isAdmin && userID == "" {
+		return nil, domain.ErrUnauthorized
+	}
+	if paymentMethod == "" {
+		return nil, fmt.Errorf("%w: payment_method is required", domain.ErrInvalidArgument)
+	}
+
+	ctx, unlock, err := uc.lockOrder(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	// ---- 注文取得は 3s ----
+	dbReadCtx, cancelRead := context.WithTimeout(ctx, 3*time.Second)
+	defer cancelRead()
+
+	o, err := uc.findOrder(dbReadCtx, id, isAdmin, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTransition(o.Status, order.StatusAuthorized); err != nil {
+		return nil, err
+	}
+
+	// ---- PG 呼び出しは 5s ----
+	pgCtx, cancelPG := context.WithTimeout(ctx, 5*time.Second)
+	defer cancelPG()
+
+	key := paymentKey("authorize", o.ID, paymentMethod)
+	authID, err := uc.PG.Authorize(pgCtx, domain.PaymentIntent{
+		OrderID:        string(o.ID),
+		Amount:         o.Amount.Amount,
+		Currency:       o.Amount.Currency.Lower(),
+		PaymentMethod:  paymentMethod,
+		IdempotencyKey: key,
+	})
+	if err != nil {
+		// 失敗の記録はベストエフォート（PG のエラーを優先して返す）
+		if recErr := uc.recordEventTx(ctx, o.ID, event.TypeAuthFailed, map[string]any{
+			"error":           err.Error(),
+			"idempotency_key": key,
+		}); recErr != nil {
+			log.Printf("warn: record %s event: order_id=%s: %v", event.TypeAuthFailed, o.ID, recErr)
+		}
+		return nil, err
+	}
+
+	// ---- DB 反映は 3s ----
+	dbCtx, cancelDB := context.WithTimeout(ctx, 3*time.Second)
+	defer cancelDB()
+
+	now := uc.Clock.Now()
+	ttl := uc.AuthorizationTTL
+	if ttl <= 0 {
+		ttl = defaultAuthorizationTTL
+	}
+	a := &payment.Authorization{
+		ID:             payment.AuthorizationID(uc.IDGen.New()),
+		OrderID:        string(o.ID),
+		Provider:       uc.Provider,
+		ProviderAuthID: authID,
+		Amount:         o.Amount,
+		Status:         payment.AuthorizationAuthorized,
+		ExpiresAt:      now.Add(ttl),
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	err = uc.Tx.Do(dbCtx, func(dbCtx context.Context) error {
+		if err := uc.transition(dbCtx, o, order.StatusAuthorized, now); err != nil {
+			return err
+		}
+
+		if err := uc.Auths.Create(dbCtx, a); err != nil {
+			return err
+		}
+
+		return uc.recordEvent(dbCtx, o.ID, event.TypeOrderAuthorized, putAmount(map[string]any{
+			"authorization_id": string(a.ID),
+			"provider":         a.Provider,
+			"provider_auth_id": a.ProviderAuthID,
+			"expires_at":       a.ExpiresAt,
+		}, "amount", a.Amount))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// --- Capture ---
+
+// 与信済み注文の売上を確定する（管理者のみ）。amount が 0 なら与信額全額、
+// 与信額未満なら部分確定となり、残りの与信は解放される。
+// amount の通貨が空なら注文の通貨とみなす
+func (uc *OrderUsecase) CaptureOrder(ctx context.Context, id order.ID, amount money.Money) (*payment.Payment, error) {
+	if amount.Amount < 0 {
+		return nil, fmt.Errorf("%w: amount must be >= 0", domain.ErrInvalidArgument)
+	}
+	if !auth.IsAdmin(ctx) {
+		if _, ok := auth.UserIDFrom(ctx); !ok {
+			return nil, domain.ErrUnauthorized
+		}
+		return nil, domain.ErrForbidden
+	}
+
+	ctx, unlock, err := uc.lockOrder(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	// ---- 注文・与信の取得は 3s ----
+	dbReadCtx, cancelRead := context.WithTimeout(ctx, 3*time.Second)
+	defer cancelRead()
+
+	o, err := uc.Repo.FindByID(dbReadCtx, id)
+	if err != nil {
+		return nil, err
+	}
+	if o.Status != order.StatusAuthorized {
+		return nil, domain.ErrConflict
+	}
+
+	a, err := uc.Auths.FindActiveByOrderID(dbReadCtx, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, fmt.Errorf("%w: no active authorization for order %s", domain.ErrInternal, id)
+		}
+		return nil, err
+	}
+
+	// 期限切れの与信は確定できない（自動取り消しを待つ）
+	if !uc.Clock.Now().Before(a.ExpiresAt) {
+		return nil, fmt.Errorf("%w: authorization expired", domain.ErrConflict)
+	}
+
+	amount, err = amountIn(amount, a.Amount.Currency)
+	if err != nil {
+		return nil, err
+	}
+	if amount.IsZero() {
+		amount = a.Amount
+	}
+	if c, _ := amount.Cmp(a.Amount); c > 0 {
+		return nil, fmt.Errorf("%w: capture amount exceeds authorized amount %s", domain.ErrInvalidArgument, a.Amount)
+	}
+
+	// ---- PG 呼び出しは 5s ----
+	pgCtx, cancelPG := context.WithTimeout(ctx, 5*time.Second)
+	defer cancelPG()
+
+	txID, err := uc.PG.Capture(pgCtx, domain.CaptureRequest{
+		OrderID:        string(o.ID),
+		ProviderAuthID: a.ProviderAuthID,
+		Amount:         amount.Amount,
+		Currency:       amount.Currency.Lower(),
+		IdempotencyKey: "capture:" + string(o.ID),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// ---- DB 反映は 3s ----
+	dbCtx, cancelDB := context.WithTimeout(ctx, 3*time.Second)
+	defer cancelDB()
+
+	now := uc.Clock.Now()
+	p := &payment.Payment{
+		ID:        payment.ID(uc.IDGen.New()),
+		OrderID:   string(o.ID),
+		Method:    payment.MethodCard,
+		Provider:  a.Provider,
+		TxID:      txID,
+		Amount:    amount,
+		CreatedAt: now,
+	}
+
+	err = uc.Tx.Do(dbCtx, func(dbCtx context.Context) error {
+		if err := uc.transition(dbCtx, o, order.StatusPaid, now); err != nil {
+			return err
+		}
+
+		rows, err := uc.Auths.UpdateStatusIf(dbCtx, a.ID, payment.AuthorizationAuthorized, payment.AuthorizationCaptured, now)
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return domain.ErrConflict
+		}
+
+		if err := uc.Payments.Create(dbCtx, p); err != nil {
+			return err
+		}
+		if err := uc.postCharge(dbCtx, p); err != nil {
+			return err
+		}
+
+		if err := uc.recordEvent(dbCtx, o.ID, event.TypeOrderCaptured, map[string]any{
+			"authorization_id": string(a.ID),
+			"amount_jpy":       amount,
+			"provider_tx_id":   txID,
+		}); err != nil {
+			return err
+		}
+		return uc.recordEvent(dbCtx, o.ID, event.TypeOrderPaid, map[string]any{
+			"payment_id": string(p.ID),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// --- Void ---
+
+// 未確定の与信を取り消し、注文を CANCELED にする
+func (uc *OrderUsecase) VoidOrder(ctx context.Context, id order.ID) error {
+	isAdmin := auth.IsAdmin(ctx)
+
+	// 一般ユーザは userID 必須。管理者は不要
+	userID, _ := auth.UserIDFrom(ctx)
+	if !isAdmin && userID == "" {
+		return domain.ErrUnauthorized
+	}
+
+	ctx, unlock, err := uc.lockOrder(ctx, id)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// ---- 注文・与信の取得は 3s ----
+	dbReadCtx, cancelRead := context.WithTimeout(ctx, 3*time.Second)
+	defer cancelRead()
+
+	o, err := uc.findOrder(dbReadCtx, id, isAdmin, userID)
+	if err != nil {
+		return err
+	}
+	if o.Status != order.StatusAuthorized {
+		return domain.ErrConflict
+	}
+
+	a, err := uc.Auths.FindActiveByOrderID(dbReadCtx, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return fmt.Errorf("%w: no active authorization for order %s", domain.ErrInternal, id)
+		}
+		return err
+	}
+
+	return uc.voidAuthorization(ctx, a, "requested")
+}
+
+// 期限切れの与信を最大 limit 件取り消す。取り消した件数を返す
+// バックグラウンドから呼ばれる想定のため認可チェックは行わない
+func (uc *OrderUsecase) VoidExpiredAuthorizations(ctx context.Context, limit int) (int, error) {
+	// ---- 対象の取得は 3s ----
+	dbReadCtx, cancelRead := context.WithTimeout(ctx, 3*time.Second)
+	defer cancelRead()
+
+	expired, err := uc.Auths.ListExpired(dbReadCtx, uc.Clock.Now(), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	voided := 0
+	for _, a := range expired {
+		if err := uc.voidExpired(ctx, a); err != nil {
+			log.Printf("warn: void expired authorization: order_id=%s authorization_id=%s: %v", a.OrderID, a.ID, err)
+			continue
+		}
+		voided++
+	}
+	return voided, nil
+}
+
+func (uc *OrderUsecase) voidExpired(ctx context.Context, a *payment.Authorization) error {
+	id := order.ID(a.OrderID)
+
+	// 手動の capture / void と競合しないよう同じロックを取る
+	ctx, unlock, err := uc.lockOrder(ctx, id)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// ロック待ちの間に確定・取り消しされていないか確認
+	dbReadCtx, cancelRead := context.WithTimeout(ctx, 3*time.Second)
+	defer cancelRead()
+
+	cur, err := uc.Auths.FindActiveByOrderID(dbReadCtx, id)
+	if err != nil {
+		return err
+	}
+	if cur.ID != a.ID {
+		return domain.ErrConflict
+	}
+
+	return uc.voidAuthorization(ctx, cur, "expired")
+}
+
+// PG で与信を取り消し、注文を CANCELED・与信を VOIDED にする（ロック取得済みで呼ぶ）
+func (uc *OrderUsecase) voidAuthorization(ctx context.Context, a *payment.Authorization, reason string) error {
+	id := order.ID(a.OrderID)
+
+	// ---- PG 呼び出しは 5s ----
+	pgCtx, cancelPG := context.WithTimeout(ctx, 5*time.Second)
+	defer cancelPG()
+
+	if err := uc.PG.Void(pgCtx, domain.VoidRequest{
+		OrderID:        a.OrderID,
+		ProviderAuthID: a.ProviderAuthID,
+		IdempotencyKey: "void:" + a.OrderID,
+	}); err != nil {
+		return err
+	}
+
+	// ---- DB 反映は 3s ----
+	dbCtx, cancelDB := context.WithTimeout(ctx, 3*time.Second)
+	defer cancelDB()
+
+	return uc.Tx.Do(dbCtx, func(dbCtx context.Context) error {
+		now := uc.Clock.Now()
+
+		if err := uc.updateStatus(dbCtx, id, order.StatusAuthorized, order.StatusCanceled, now); err != nil {
+			return err
+		}
+
+		rows, err := uc.Auths.UpdateStatusIf(dbCtx, a.ID, payment.AuthorizationAuthorized, payment.AuthorizationVoided, now)
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return domain.ErrConflict
+		}
+
+		return uc.recordEvent(dbCtx, id, event.TypeOrderVoided, map[string]any{
+			"authorization_id": string(a.ID),
+			"reason":           reason,
+		})
+	})
+}
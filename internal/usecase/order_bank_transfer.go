@@ -0,0 +1,394 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/auth"
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/banktransfer"
+	"github.com/kazshi01/payment-system/internal/domain/event"
+	"github.com/kazshi01/payment-system/internal/domain/money"
+	"github.com/kazshi01/payment-system/internal/domain/order"
+	"github.com/kazshi01/payment-system/internal/domain/payment"
+)
+
+// 発行した番号が使用済みだった場合に選び直す回数
+const issueDestinationAttempts = 3
+
+// --- Bank transfer ---
+
+// IssueBankTransfer は未決済の注文に振込先を発行し、注文を AWAITING_PAYMENT にする（一般ユーザは自分の注文のみ）。
+// 発行済みなら同じ振込先を返す。payerName は振込名義の申告（任意。振込依頼人コードの書き忘れに備える）
+func (uc *OrderUsecase) IssueBankTransfer(ctx context.Context, id order.ID, payerName string) (*banktransfer.Transfer, error) {
+	if uc.Bank == nil || uc.Transfers == nil {
+		return nil, fmt.Errorf("%w: bank transfer is not available", domain.ErrInvalidArgument)
+	}
+	isAdmin := auth.IsAdmin(ctx)
+
+	userID, _ := auth.UserIDFrom(ctx)
+	if !isAdmin && userID == "" {
+		return nil, domain.ErrUnauthorized
+	}
+
+	// 支払い・取り消しと同じロックで直列化する
+	ctx, unlock, err := uc.lockOrder(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	// ---- 注文・振込先の取得は 3s ----
+	dbReadCtx, cancelRead := context.WithTimeout(ctx, 3*time.Second)
+	defer cancelRead()
+
+	o, err := uc.findOrder(dbReadCtx, id, isAdmin, userID)
+	if err != nil {
+		return nil, err
+	}
+	t, err := uc.Transfers.FindByOrderID(dbReadCtx, o.ID)
+	if err == nil {
+		return t, nil
+	}
+	if !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+
+	// 与信済み（カード）・支払い済みの注文には発行しない
+	if o.Status != order.StatusPending {
+		return nil, fmt.Errorf("%w: bank transfer is only for pending orders (order is %s)", domain.ErrConflict, o.Status)
+	}
+	if o.Amount.Currency != money.JPY {
+		return nil, fmt.Errorf("%w: bank transfer accepts only JPY", domain.ErrInvalidArgument)
+	}
+
+	// ---- 発行と DB 反映は 3s ----
+	dbCtx, cancelDB := context.WithTimeout(ctx, 3*time.Second)
+	defer cancelDB()
+
+	now := uc.Clock.Now()
+	t = &banktransfer.Transfer{
+		ID:        uc.IDGen.New(),
+		OrderID:   string(o.ID),
+		PayerName: banktransfer.NormalizeName(payerName),
+		Amount:    o.Amount,
+		Status:    banktransfer.TransferAwaiting,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	req := domain.BankTransferRequest{
+		OrderID:  string(o.ID),
+		Amount:   o.Amount.Amount,
+		Currency: o.Amount.Currency.Lower(),
+	}
+	for attempt := 1; ; attempt++ {
+		if t.Destination, err = uc.Bank.IssueDestination(dbCtx, req); err != nil {
+			return nil, err
+		}
+		err = uc.Tx.Do(dbCtx, func(dbCtx context.Context) error {
+			// 入金を待つ間はカードで払えないようにする
+			if err := uc.transition(dbCtx, o, order.StatusAwaitingPayment, now); err != nil {
+				return err
+			}
+			if err := uc.Transfers.Create(dbCtx, t); err != nil {
+				return err
+			}
+			return uc.recordEvent(dbCtx, o.ID, event.TypeBankTransferIssued, putAmount(map[string]any{
+				"transfer_id":    t.ID,
+				"mode":           string(t.Mode),
+				"branch_code":    t.BranchCode,
+				"account_number": t.AccountNumber,
+				"reference_code": t.ReferenceCode,
+			}, "amount", t.Amount))
+		})
+		// ロック中なので注文への二重発行ではなく、番号の衝突。選び直す
+		if errors.Is(err, domain.ErrConflict) && attempt < issueDestinationAttempts {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return t, nil
+	}
+}
+
+// DepositImportResult は振込入金通知の取り込み結果（明細の件数）
+type DepositImportResult struct {
+	Paid    int // 注文を支払い済みにした
+	Review  int // 手動確認に回した
+	Skipped int // 取り込み済み
+}
+
+// ImportBankStatement は全銀協フォーマットの振込入金通知を取り込み、振込先と金額で注文に消し込む（管理者のみ）。
+// 過不足・支払い済みや取り消し済みの注文への入金・振込先の分からない入金は支払い済みにせず REVIEW として記録する。
+// 取り込み済みの明細は飛ばすので、途中で失敗したファイルはそのまま取り込み直せる
+func (uc *OrderUsecase) ImportBankStatement(ctx context.Context, source string, r io.Reader) (*DepositImportResult, error) {
+	if !auth.IsAdmin(ctx) {
+		if _, ok := auth.UserIDFrom(ctx); !ok {
+			return nil, domain.ErrUnauthorized
+		}
+		return nil, domain.ErrForbidden
+	}
+	if uc.Transfers == nil {
+		return nil, fmt.Errorf("%w: bank transfer is not available", domain.ErrInvalidArgument)
+	}
+
+	ds, err := banktransfer.ParseZengin(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrInvalidArgument, err)
+	}
+
+	res := &DepositImportResult{}
+	for _, d := range ds {
+		d.Source = source
+		created, err := uc.applyDeposit(ctx, d)
+		if err != nil {
+			return res, fmt.Errorf("deposit at record %d: %w", d.Line, err)
+		}
+		switch {
+		case !created:
+			res.Skipped++
+		case d.Status == banktransfer.DepositMatched:
+			res.Paid++
+		default:
+			res.Review++
+		}
+	}
+	return res, nil
+}
+
+// applyDeposit は入金明細 1 件を記録し、過不足がなければ注文を支払い済みにする。記録済みの明細なら false
+func (uc *OrderUsecase) applyDeposit(ctx context.Context, d *banktransfer.Deposit) (bool, error) {
+	t, reason, err := uc.matchDeposit(ctx, d)
+	if err != nil {
+		return false, err
+	}
+
+	d.ID = uc.IDGen.New()
+	d.CreatedAt = uc.Clock.Now()
+	if t == nil {
+		d.Status, d.Reason = banktransfer.DepositReview, banktransfer.ReasonUnknownDestination
+
+		// ---- DB 反映は 3s ----
+		dbCtx, cancelDB := context.WithTimeout(ctx, 3*time.Second)
+		defer cancelDB()
+
+		created, err := uc.Transfers.CreateDepositIfAbsent(dbCtx, d)
+		if created {
+			log.Printf("warn: bank deposit needs review: %s line=%d account=%s-%s amount=%s payer=%q",
+				d.Reason, d.Line, d.BranchCode, d.AccountNumber, d.Amount, d.PayerName)
+		}
+		return created, err
+	}
+	d.TransferID, d.OrderID = t.ID, t.OrderID
+
+	// API 経由の pay / cancel と同じロックで直列化する
+	ctx, unlock, err := uc.lockOrder(ctx, order.ID(t.OrderID))
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	// ---- DB 反映は 3s ----
+	dbCtx, cancelDB := context.WithTimeout(ctx, 3*time.Second)
+	defer cancelDB()
+
+	var created bool
+	err = uc.Tx.Do(dbCtx, func(dbCtx context.Context) error {
+		o, err := uc.Repo.FindByID(dbCtx, order.ID(t.OrderID))
+		if err != nil {
+			return err
+		}
+		// 振込先はロックの外で引いたので読み直す
+		t, err := uc.Transfers.FindByOrderID(dbCtx, o.ID)
+		if err != nil {
+			return err
+		}
+		if reason == "" {
+			reason = t.Check(d)
+		}
+		if reason == "" && (o.Status != order.StatusAwaitingPayment || t.Status != banktransfer.TransferAwaiting) {
+			reason = banktransfer.ReasonOrderNotPending
+		}
+
+		d.Status, d.Reason = banktransfer.DepositMatched, reason
+		if reason != "" {
+			d.Status = banktransfer.DepositReview
+		}
+		if created, err = uc.Transfers.CreateDepositIfAbsent(dbCtx, d); err != nil || !created {
+			return err
+		}
+
+		if reason != "" {
+			log.Printf("warn: bank deposit needs review: %s order_id=%s amount=%s billed=%s", reason, o.ID, d.Amount, t.Amount)
+			return uc.recordEvent(dbCtx, o.ID, event.TypeDepositReview, depositPayload(d))
+		}
+		return uc.payByDeposit(dbCtx, o, t, d)
+	})
+	return created, err
+}
+
+// matchDeposit は入金の振込先を探す。バーチャル口座なら口座番号、共通口座なら振込依頼人コードで引き、
+// どちらもなければ申告された名義と金額で候補を探す（候補が 1 つでも自動では消し込まない）
+func (uc *OrderUsecase) matchDeposit(ctx context.Context, d *banktransfer.Deposit) (*banktransfer.Transfer, banktransfer.Reason, error) {
+	// ---- DB 読み取りは 3s ----
+	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	refs := []string{""}
+	if ref := banktransfer.ExtractReference(d); ref != "" {
+		refs = append(refs, ref)
+	}
+	for _, ref := range refs {
+		t, err := uc.Transfers.FindByDestination(dbCtx, d.BranchCode, d.AccountNumber, ref)
+		if err == nil {
+			return t, "", nil
+		}
+		if !errors.Is(err, domain.ErrNotFound) {
+			return nil, "", err
+		}
+	}
+
+	name := banktransfer.NormalizeName(d.PayerName)
+	if name == "" {
+		return nil, "", nil
+	}
+	ts, err := uc.Transfers.ListAwaitingByPayer(dbCtx, name, d.Amount, 2)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(ts) != 1 {
+		return nil, "", nil
+	}
+	return ts[0], banktransfer.ReasonNameOnly, nil
+}
+
+// 入金を決済として記録し、注文を支払い済みにする（Tx 内で呼ぶ）
+func (uc *OrderUsecase) payByDeposit(ctx context.Context, o *order.Order, t *banktransfer.Transfer, d *banktransfer.Deposit) error {
+	now := uc.Clock.Now()
+	if err := uc.transition(ctx, o, order.StatusPaid, now); err != nil {
+		return err
+	}
+	rows, err := uc.Transfers.UpdateStatusIf(ctx, t.ID, banktransfer.TransferAwaiting, banktransfer.TransferPaid, now)
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return domain.ErrConflict
+	}
+
+	p := &payment.Payment{
+		ID:        payment.ID(uc.IDGen.New()),
+		OrderID:   string(o.ID),
+		Method:    payment.MethodBankTransfer,
+		Provider:  banktransfer.Provider,
+		TxID:      d.ID,
+		Amount:    d.Amount,
+		CreatedAt: now,
+	}
+	if err := uc.Payments.Create(ctx, p); err != nil {
+		return err
+	}
+	if err := uc.postCharge(ctx, p); err != nil {
+		return err
+	}
+
+	if err := uc.recordEvent(ctx, o.ID, event.TypeChargeSucceeded, depositPayload(d)); err != nil {
+		return err
+	}
+	return uc.recordEvent(ctx, o.ID, event.TypeOrderPaid, map[string]any{
+		"payment_id": string(p.ID),
+	})
+}
+
+// 注文の入金待ちの振込先。なければ（コンビニ払いの注文など）ErrNotFound
+func (uc *OrderUsecase) awaitingTransfer(ctx context.Context, id order.ID) (*banktransfer.Transfer, error) {
+	if uc.Transfers == nil {
+		return nil, domain.ErrNotFound
+	}
+	t, err := uc.Transfers.FindByOrderID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if t.Status != banktransfer.TransferAwaiting {
+		return nil, domain.ErrNotFound
+	}
+	return t, nil
+}
+
+// 注文を CANCELED・振込先を CANCELED にする（ロック取得済みで呼ぶ）。
+// 後から届いた入金は order_not_pending として手動確認に回る
+func (uc *OrderUsecase) cancelTransfer(ctx context.Context, t *banktransfer.Transfer, payload map[string]any) error {
+	id := order.ID(t.OrderID)
+
+	// ---- DB 反映は 3s ----
+	dbCtx, cancelDB := context.WithTimeout(ctx, 3*time.Second)
+	defer cancelDB()
+
+	return uc.Tx.Do(dbCtx, func(dbCtx context.Context) error {
+		now := uc.Clock.Now()
+
+		if err := uc.updateStatus(dbCtx, id, order.StatusAwaitingPayment, order.StatusCanceled, now); err != nil {
+			return err
+		}
+		rows, err := uc.Transfers.UpdateStatusIf(dbCtx, t.ID, banktransfer.TransferAwaiting, banktransfer.TransferCanceled, now)
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return domain.ErrConflict
+		}
+
+		payload["transfer_id"] = t.ID
+		return uc.recordEvent(dbCtx, id, event.TypeOrderCanceled, payload)
+	})
+}
+
+func depositPayload(d *banktransfer.Deposit) map[string]any {
+	payload := map[string]any{
+		"provider":       banktransfer.Provider,
+		"provider_tx_id": d.ID,
+		"transfer_id":    d.TransferID,
+		"payer_name":     d.PayerName,
+		"value_date":     d.ValueDate.Format(time.DateOnly),
+		"source":         d.Source,
+	}
+	if d.Reason != "" {
+		payload["reason"] = string(d.Reason)
+	}
+	return putAmount(payload, "amount", d.Amount)
+}
+
+// ListBankDeposits は入金明細を新しい順に返す（管理者のみ）。status を REVIEW にすると手動確認待ちの一覧
+func (uc *OrderUsecase) ListBankDeposits(ctx context.Context, status banktransfer.DepositStatus, limit int) ([]*banktransfer.Deposit, error) {
+	if !auth.IsAdmin(ctx) {
+		if _, ok := auth.UserIDFrom(ctx); !ok {
+			return nil, domain.ErrUnauthorized
+		}
+		return nil, domain.ErrForbidden
+	}
+	if uc.Transfers == nil {
+		return nil, fmt.Errorf("%w: bank transfer is not available", domain.ErrInvalidArgument)
+	}
+	switch status {
+	case "", banktransfer.DepositMatched, banktransfer.DepositReview:
+	default:
+		return nil, fmt.Errorf("%w: unknown deposit status %q", domain.ErrInvalidArgument, status)
+	}
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	if limit > maxListLimit {
+		limit = maxListLimit
+	}
+
+	// ---- DB 読み取りは 3s ----
+	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	return uc.Transfers.ListDeposits(dbCtx, status, limit)
+}
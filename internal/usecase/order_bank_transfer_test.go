@@ -0,0 +1,320 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/banktransfer"
+	"github.com/kazshi01/payment-system/internal/domain/event"
+	"github.com/kazshi01/payment-system/internal/domain/ledger"
+	"github.com/kazshi01/payment-system/internal/domain/money"
+	"github.com/kazshi01/payment-system/internal/domain/order"
+	"github.com/kazshi01/payment-system/internal/domain/payment"
+	"github.com/kazshi01/payment-system/internal/usecase"
+)
+
+type memTransferRepo struct {
+	m        map[order.ID]*banktransfer.Transfer
+	deposits []*banktransfer.Deposit
+}
+
+func newMemTransferRepo() *memTransferRepo {
+	return &memTransferRepo{m: map[order.ID]*banktransfer.Transfer{}}
+}
+
+func (r *memTransferRepo) Create(ctx context.Context, t *banktransfer.Transfer) error {
+	if _, ok := r.m[order.ID(t.OrderID)]; ok {
+		return domain.ErrConflict
+	}
+	if _, err := r.FindByDestination(ctx, t.BranchCode, t.AccountNumber, t.ReferenceCode); err == nil {
+		return domain.ErrConflict
+	}
+	cp := *t
+	r.m[order.ID(t.OrderID)] = &cp
+	return nil
+}
+
+func (r *memTransferRepo) FindByOrderID(ctx context.Context, id order.ID) (*banktransfer.Transfer, error) {
+	t, ok := r.m[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	cp := *t
+	return &cp, nil
+}
+
+func (r *memTransferRepo) FindByDestination(ctx context.Context, branch, account, ref string) (*banktransfer.Transfer, error) {
+	for _, t := range r.m {
+		if t.BranchCode == branch && t.AccountNumber == account && t.ReferenceCode == ref {
+			cp := *t
+			return &cp, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (r *memTransferRepo) ListAwaitingByPayer(ctx context.Context, name string, amount money.Money, limit int) ([]*banktransfer.Transfer, error) {
+	var out []*banktransfer.Transfer
+	for _, t := range r.m {
+		if t.Status == banktransfer.TransferAwaiting && t.PayerName == name && t.Amount == amount && len(out) < limit {
+			cp := *t
+			out = append(out, &cp)
+		}
+	}
+	return out, nil
+}
+
+func (r *memTransferRepo) UpdateStatusIf(ctx context.Context, id string, from, to banktransfer.TransferStatus, at time.Time) (int64, error) {
+	for _, t := range r.m {
+		if t.ID == id && t.Status == from {
+			t.Status, t.UpdatedAt = to, at
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func (r *memTransferRepo) CreateDepositIfAbsent(ctx context.Context, d *banktransfer.Deposit) (bool, error) {
+	for _, x := range r.deposits {
+		if x.BranchCode == d.BranchCode && x.AccountNumber == d.AccountNumber && x.ValueDate.Equal(d.ValueDate) && x.InquiryNo == d.InquiryNo {
+			return false, nil
+		}
+	}
+	cp := *d
+	r.deposits = append(r.deposits, &cp)
+	return true, nil
+}
+
+func (r *memTransferRepo) ListDeposits(ctx context.Context, status banktransfer.DepositStatus, limit int) ([]*banktransfer.Deposit, error) {
+	var out []*banktransfer.Deposit
+	for _, d := range slices.Backward(r.deposits) {
+		if (status == "" || d.Status == status) && len(out) < limit {
+			cp := *d
+			out = append(out, &cp)
+		}
+	}
+	return out, nil
+}
+
+// 振込依頼人コードを順に払い出す共通口座
+type stubBank struct{ refs []string }
+
+func (b *stubBank) IssueDestination(ctx context.Context, req domain.BankTransferRequest) (banktransfer.Destination, error) {
+	ref := b.refs[0]
+	b.refs = b.refs[1:]
+	return banktransfer.Destination{
+		Mode: banktransfer.ModeReferenceCode, BankCode: "0001", BranchCode: "001",
+		AccountType: banktransfer.AccountOrdinary, AccountNumber: "1234567", ReferenceCode: ref,
+	}, nil
+}
+
+// zengin は 001-1234567 宛ての振込入金通知を組み立てる（明細は "照会番号,金額,振込依頼人名"）
+func zengin(t *testing.T, entries ...string) string {
+	t.Helper()
+	pad := func(s string) string {
+		var b []byte
+		for _, r := range s {
+			if r >= '｡' && r <= 'ﾟ' {
+				b = append(b, byte(r-'｡'+0xa1))
+			} else {
+				b = append(b, byte(r))
+			}
+		}
+		return string(b) + strings.Repeat(" ", 200-len(b))
+	}
+	recs := []string{pad("1010071001071001071001" + "0001" + strings.Repeat(" ", 15) + "001" + strings.Repeat(" ", 15) + "   1" + "1234567")}
+	var sum int64
+	for _, e := range entries {
+		var inquiry, name string
+		var amount int64
+		f := strings.SplitN(e, ",", 3)
+		inquiry, name = f[0], f[2]
+		if _, err := fmt.Sscan(f[1], &amount); err != nil {
+			t.Fatal(err)
+		}
+		sum += amount
+		recs = append(recs, pad(fmt.Sprintf("2%-6s071001071001%010d%010d%010s%-48s", inquiry, amount, 0, "", name)))
+	}
+	recs = append(recs, pad(fmt.Sprintf("8%06d%012d", len(entries), sum)), pad("9"))
+	return strings.Join(recs, "\r\n")
+}
+
+func newBankTransferTestUsecase(refs ...string) (*usecase.OrderUsecase, *memTransferRepo, *memPaymentRepo, *memEventRepo) {
+	n := 0
+	transfers := newMemTransferRepo()
+	payments := newMemPaymentRepo()
+	events := newMemEventRepo()
+	uc := &usecase.OrderUsecase{
+		Repo:      newMemRepo(),
+		Payments:  payments,
+		Events:    events,
+		Bank:      &stubBank{refs: refs},
+		Transfers: transfers,
+		Tx:        nopTx{},
+		PG:        okPG{txid: "tx1"},
+		Clock:     fixedClock{t: time.Date(2025, 10, 1, 9, 0, 0, 0, time.UTC)},
+		IDGen:     seqIDGen{n: &n},
+		Locker:    okLocker{},
+	}
+	return uc, transfers, payments, events
+}
+
+func TestOrderUsecase_IssueBankTransfer(t *testing.T) {
+	uc, _, _, events := newBankTransferTestUsecase("1234566", "7654321")
+	ctx := ctxWithUser("user-1")
+	o, _ := uc.CreateOrder(ctx, usecase.CreateOrderInput{Amount: jpy(12000)})
+
+	tr, err := uc.IssueBankTransfer(ctx, o.ID, "ﾔﾏﾀﾞ ﾀﾛｳ")
+	if err != nil {
+		t.Fatalf("IssueBankTransfer err = %v", err)
+	}
+	if tr.ReferenceCode != "1234566" || tr.PayerName != "ヤマダタロウ" || tr.Amount != jpy(12000) {
+		t.Fatalf("transfer = %+v", tr)
+	}
+
+	// 発行済みなら同じ振込先
+	again, err := uc.IssueBankTransfer(ctx, o.ID, "")
+	if err != nil || again.ID != tr.ID {
+		t.Fatalf("second issue = %+v, %v; want %s", again, err, tr.ID)
+	}
+	if got := eventTypes(events.m[o.ID]); !slices.Equal(got, []event.Type{event.TypeOrderCreated, event.TypeBankTransferIssued}) {
+		t.Fatalf("events = %v", got)
+	}
+
+	if _, err := uc.IssueBankTransfer(ctxWithUser("user-2"), o.ID, ""); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("other user err = %v; want ErrNotFound", err)
+	}
+
+	// 入金待ちの注文はカードで払えない（振込でも払えてしまう）
+	if got, _ := uc.Repo.FindByID(ctx, o.ID); got.Status != order.StatusAwaitingPayment {
+		t.Fatalf("status = %s; want AWAITING_PAYMENT", got.Status)
+	}
+	if err := uc.PayOrder(ctx, o.ID, "pm_card_visa"); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("PayOrder err = %v; want ErrConflict", err)
+	}
+}
+
+func TestOrderUsecase_CancelOrder_BankTransfer(t *testing.T) {
+	uc, transfers, payments, _ := newBankTransferTestUsecase("1234566")
+	ctx := ctxWithUser("user-1")
+	o, _ := uc.CreateOrder(ctx, usecase.CreateOrderInput{Amount: jpy(12000)})
+	if _, err := uc.IssueBankTransfer(ctx, o.ID, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := uc.CancelOrder(ctx, o.ID); err != nil {
+		t.Fatalf("CancelOrder err = %v", err)
+	}
+	if got, _ := uc.Repo.FindByID(ctx, o.ID); got.Status != order.StatusCanceled {
+		t.Fatalf("status = %s; want CANCELED", got.Status)
+	}
+	if s := transfers.m[o.ID].Status; s != banktransfer.TransferCanceled {
+		t.Fatalf("transfer status = %s; want CANCELED", s)
+	}
+
+	// 取り消し後の入金は消し込まない
+	res, err := uc.ImportBankStatement(ctxWithAdmin("admin-1"), "a.txt", strings.NewReader(zengin(t, "000001,12000,1234566 ﾔﾏﾀﾞ ﾀﾛｳ")))
+	if err != nil || *res != (usecase.DepositImportResult{Review: 1}) {
+		t.Fatalf("import = %+v, %v; want 1 review", res, err)
+	}
+	if d := transfers.deposits[0]; d.Reason != banktransfer.ReasonOrderNotPending || len(payments.m[o.ID]) != 0 {
+		t.Fatalf("deposit = %+v, payments = %v", d, payments.m[o.ID])
+	}
+}
+
+func TestOrderUsecase_ImportBankStatement(t *testing.T) {
+	uc, transfers, payments, events := newBankTransferTestUsecase("1234566", "2345676", "3456787")
+	journal := &memLedgerRepo{}
+	uc.Ledger = journal
+	ctx := ctxWithUser("user-1")
+	exact, _ := uc.CreateOrder(ctx, usecase.CreateOrderInput{Amount: jpy(12000)})
+	short, _ := uc.CreateOrder(ctx, usecase.CreateOrderInput{Amount: jpy(5000)})
+	byName, _ := uc.CreateOrder(ctx, usecase.CreateOrderInput{Amount: jpy(3000)})
+	for _, o := range []*order.Order{exact, short, byName} {
+		if _, err := uc.IssueBankTransfer(ctx, o.ID, "ｻﾄｳ ﾊﾅｺ"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	file := zengin(t,
+		"000001,12000,1234566 ﾔﾏﾀﾞ ﾀﾛｳ",
+		"000002,4560,ﾔﾏﾀﾞ ﾀﾛｳ 2345676", // 振込手数料を差し引かれた
+		"000003,3000,ｻﾄｳ ﾊﾅｺ",          // 振込依頼人コードの書き忘れ
+		"000004,800,ﾀﾅｶ ｲﾁﾛｳ",
+	)
+	if _, err := uc.ImportBankStatement(ctx, "a.txt", strings.NewReader(file)); !errors.Is(err, domain.ErrForbidden) {
+		t.Fatalf("user err = %v; want ErrForbidden", err)
+	}
+
+	admin := ctxWithAdmin("admin-1")
+	res, err := uc.ImportBankStatement(admin, "a.txt", strings.NewReader(file))
+	if err != nil {
+		t.Fatalf("ImportBankStatement err = %v", err)
+	}
+	if *res != (usecase.DepositImportResult{Paid: 1, Review: 3}) {
+		t.Fatalf("result = %+v", *res)
+	}
+
+	got, _ := uc.Repo.FindByID(admin, exact.ID)
+	if got.Status != order.StatusPaid {
+		t.Fatalf("exact order status = %s; want PAID", got.Status)
+	}
+	ps := payments.m[exact.ID]
+	if len(ps) != 1 || ps[0].Method != payment.MethodBankTransfer || ps[0].Amount != jpy(12000) {
+		t.Fatalf("payments = %+v", ps)
+	}
+	if got := eventTypes(events.m[exact.ID]); !slices.Contains(got, event.TypeOrderPaid) {
+		t.Fatalf("events = %v; want ORDER_PAID", got)
+	}
+
+	// 振込の入金は PG の売掛金ではなく普通預金に入り、手数料もかからない
+	bank, _ := journal.Balance(admin, ledger.AccountBank, money.JPY, time.Time{}, time.Time{})
+	recv, _ := journal.Balance(admin, ledger.AccountProviderReceivable, money.JPY, time.Time{}, time.Time{})
+	if bank.Net() != jpy(12000) || !recv.Net().IsZero() || len(journal.entries) != 1 {
+		t.Fatalf("bank = %s, receivable = %s, entries = %d; want 12000 JPY in bank only", bank.Net(), recv.Net(), len(journal.entries))
+	}
+
+	// 振込の入金は PG で返金できない（入金 ID をカード決済として返金しない）
+	if _, err := uc.RefundOrder(admin, exact.ID, jpy(1000), ""); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("RefundOrder err = %v; want ErrConflict", err)
+	}
+
+	// 過不足・名義だけの一致は注文を動かさない
+	for _, o := range []*order.Order{short, byName} {
+		got, _ := uc.Repo.FindByID(admin, o.ID)
+		if got.Status != order.StatusAwaitingPayment {
+			t.Fatalf("order %s status = %s; want AWAITING_PAYMENT", o.ID, got.Status)
+		}
+		if es := eventTypes(events.m[o.ID]); !slices.Contains(es, event.TypeDepositReview) {
+			t.Fatalf("order %s events = %v; want DEPOSIT_NEEDS_REVIEW", o.ID, es)
+		}
+	}
+	review, _ := uc.ListBankDeposits(admin, banktransfer.DepositReview, 0)
+	var reasons []banktransfer.Reason
+	for _, d := range review {
+		reasons = append(reasons, d.Reason)
+	}
+	want := []banktransfer.Reason{banktransfer.ReasonUnknownDestination, banktransfer.ReasonNameOnly, banktransfer.ReasonUnderpaid}
+	if !slices.Equal(reasons, want) {
+		t.Fatalf("review reasons = %v; want %v", reasons, want)
+	}
+
+	// 取り込み直しても二重に消し込まない
+	res, err = uc.ImportBankStatement(admin, "a.txt", strings.NewReader(file))
+	if err != nil || *res != (usecase.DepositImportResult{Skipped: 4}) {
+		t.Fatalf("reimport = %+v, %v; want 4 skipped", res, err)
+	}
+	if len(transfers.deposits) != 4 || len(payments.m[exact.ID]) != 1 {
+		t.Fatalf("deposits = %d, payments = %d after reimport", len(transfers.deposits), len(payments.m[exact.ID]))
+	}
+
+	if _, err := uc.ImportBankStatement(admin, "b.txt", strings.NewReader("garbage")); !errors.Is(err, domain.ErrInvalidArgument) {
+		t.Fatalf("invalid file err = %v; want ErrInvalidArgument", err)
+	}
+}
@@ -0,0 +1,108 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/event"
+	"github.com/kazshi01/payment-system/internal/domain/order"
+)
+
+// 3-D Secure の支払いを待つ余裕を残し、コンビニ払いの既定の支払期限と揃える
+const defaultPendingOrderTTL = 72 * time.Hour
+
+// 複数インスタンスで動かしても掃除するのは1台だけ
+const pendingSweeperLockKey = "lock:sweeper:pending-orders"
+
+// 1回の取得で扱う件数の既定値
+const defaultSweepBatchSize = 100
+
+// ExpireStaleOrders は作成から PendingOrderTTL を過ぎても PENDING のままの注文を CANCELED にする。
+// 振込先・払込番号を発行した注文は AWAITING_PAYMENT なので対象にならない（入金を待つ）。
+// バックグラウンドジョブから呼ぶ想定のため認可チェックは行わない。
+// 他のインスタンスが掃除中（リーダーロックを取れない）なら何もせず 0 を返す。
+// 対象は batchSize 件ずつ取得し、なくなるまで処理する。取り消した件数を返す
+func (uc *OrderUsecase) ExpireStaleOrders(ctx context.Context, batchSize int) (int, error) {
+	if batchSize <= 0 {
+		batchSize = defaultSweepBatchSize
+	}
+
+	ctx, _, unlock, err := uc.holdLock(ctx, pendingSweeperLockKey)
+	if errors.Is(err, domain.ErrConflict) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	ttl := uc.PendingOrderTTL
+	if ttl <= 0 {
+		ttl = defaultPendingOrderTTL
+	}
+	f := domain.OrderListFilter{
+		Status:    order.StatusPending,
+		CreatedTo: uc.Clock.Now().Add(-ttl),
+		Limit:     batchSize,
+	}
+
+	expired := 0
+	for {
+		// ---- 対象の取得は 3s ----
+		dbReadCtx, cancelRead := context.WithTimeout(ctx, 3*time.Second)
+		batch, err := uc.Repo.List(dbReadCtx, f)
+		cancelRead()
+		if err != nil {
+			return expired, err
+		}
+
+		for _, o := range batch {
+			if err := uc.expireStale(ctx, o, ttl); err != nil {
+				// 支払い中などで取り消せなかった注文は次回に回す
+				log.Printf("warn: expire stale order: order_id=%s: %v", o.ID, err)
+				continue
+			}
+			expired++
+		}
+		if len(batch) < batchSize {
+			return expired, nil
+		}
+
+		// リーダーロックを失ったら残りは次に取れたインスタンスに任せる
+		if err := ctx.Err(); err != nil {
+			return expired, err
+		}
+		// 取り消せなかった注文を取り直さないよう、カーソルで先へ進む
+		last := batch[len(batch)-1]
+		f.After = &domain.OrderCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+}
+
+func (uc *OrderUsecase) expireStale(ctx context.Context, o *order.Order, ttl time.Duration) error {
+	// API 経由の pay / cancel と同じロックで直列化する
+	ctx, unlock, err := uc.lockOrder(ctx, o.ID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// ---- DB 反映は 3s ----
+	dbCtx, cancelDB := context.WithTimeout(ctx, 3*time.Second)
+	defer cancelDB()
+
+	return uc.Tx.Do(dbCtx, func(dbCtx context.Context) error {
+		// 一覧の取得後に支払われていれば条件付き更新が ErrConflict になる
+		if err := uc.updateStatus(dbCtx, o.ID, order.StatusPending, order.StatusCanceled, uc.Clock.Now()); err != nil {
+			return err
+		}
+
+		return uc.recordEvent(dbCtx, o.ID, event.TypeOrderCanceled, map[string]any{
+			"reason":        "expired",
+			"pending_since": o.CreatedAt,
+			"ttl":           ttl.String(),
+		})
+	})
+}
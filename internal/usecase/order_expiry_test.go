@@ -0,0 +1,103 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/event"
+	"github.com/kazshi01/payment-system/internal/domain/order"
+	"github.com/kazshi01/payment-system/internal/usecase"
+)
+
+// Locker ダミー（busy のキーだけ他で保持中）
+type keyBusyLocker struct {
+	okLocker
+	busy map[string]bool
+}
+
+func (l keyBusyLocker) TryLock(ctx context.Context, key string, ttl time.Duration) (bool, domain.Lock, error) {
+	if l.busy[key] {
+		return false, domain.Lock{}, nil
+	}
+	return l.okLocker.TryLock(ctx, key, ttl)
+}
+
+func TestOrderUsecase_ExpireStaleOrders(t *testing.T) {
+	n := 0
+	events := newMemEventRepo()
+	t0 := time.Date(2025, 10, 1, 9, 0, 0, 0, time.UTC)
+	uc := &usecase.OrderUsecase{
+		Repo:            newMemRepo(),
+		Payments:        newMemPaymentRepo(),
+		Events:          events,
+		Bank:            &stubBank{refs: []string{"1234566"}},
+		Transfers:       newMemTransferRepo(),
+		Tx:              nopTx{},
+		PG:              okPG{txid: "tx1"},
+		PendingOrderTTL: 72 * time.Hour,
+		Clock:           fixedClock{t: t0},
+		IDGen:           seqIDGen{n: &n},
+		Locker:          okLocker{},
+	}
+	ctx := ctxWithUser("user-1")
+
+	var stale []*order.Order
+	for range 4 {
+		o, _ := uc.CreateOrder(ctx, usecase.CreateOrderInput{Amount: jpy(1000)})
+		stale = append(stale, o)
+	}
+	paid, _ := uc.CreateOrder(ctx, usecase.CreateOrderInput{Amount: jpy(1000)})
+	if err := uc.PayOrder(ctx, paid.ID, "pm_card_visa"); err != nil {
+		t.Fatal(err)
+	}
+	// 振込先を発行した注文は入金を待つ
+	transfer, _ := uc.CreateOrder(ctx, usecase.CreateOrderInput{Amount: jpy(1000)})
+	if _, err := uc.IssueBankTransfer(ctx, transfer.ID, ""); err != nil {
+		t.Fatal(err)
+	}
+	uc.Clock = fixedClock{t: t0.Add(time.Hour)}
+	fresh, _ := uc.CreateOrder(ctx, usecase.CreateOrderInput{Amount: jpy(1000)})
+
+	uc.Clock = fixedClock{t: t0.Add(72*time.Hour + time.Minute)}
+
+	// 他のインスタンスが掃除中なら何もしない
+	uc.Locker = keyBusyLocker{busy: map[string]bool{"lock:sweeper:pending-orders": true}}
+	if got, err := uc.ExpireStaleOrders(context.Background(), 2); err != nil || got != 0 {
+		t.Fatalf("without leader lock = %d, %v; want 0", got, err)
+	}
+
+	// 支払い中の注文は飛ばし、残りを 2 件ずつ処理する
+	paying := stale[1]
+	uc.Locker = keyBusyLocker{busy: map[string]bool{"lock:pay:" + string(paying.ID): true}}
+	got, err := uc.ExpireStaleOrders(context.Background(), 2)
+	if err != nil || got != 3 {
+		t.Fatalf("ExpireStaleOrders = %d, %v; want 3", got, err)
+	}
+
+	want := map[order.ID]order.Status{
+		stale[0].ID: order.StatusCanceled,
+		paying.ID:   order.StatusPending,
+		stale[2].ID: order.StatusCanceled,
+		stale[3].ID: order.StatusCanceled,
+		paid.ID:     order.StatusPaid,
+		transfer.ID: order.StatusAwaitingPayment,
+		fresh.ID:    order.StatusPending,
+	}
+	for id, st := range want {
+		if o, _ := uc.Repo.FindByID(ctx, id); o.Status != st {
+			t.Errorf("order %s status = %s; want %s", id, o.Status, st)
+		}
+	}
+	es := events.m[stale[0].ID]
+	if last := es[len(es)-1]; last.Type != event.TypeOrderCanceled || last.Payload["reason"] != "expired" {
+		t.Fatalf("last event = %s %v; want ORDER_CANCELED expired", last.Type, last.Payload)
+	}
+
+	// 次回は残った注文だけ
+	uc.Locker = okLocker{}
+	if got, err := uc.ExpireStaleOrders(context.Background(), 2); err != nil || got != 1 {
+		t.Fatalf("second sweep = %d, %v; want 1", got, err)
+	}
+}
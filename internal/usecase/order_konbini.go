@@ -0,0 +1,290 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/auth"
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/event"
+	"github.com/kazshi01/payment-system/internal/domain/money"
+	"github.com/kazshi01/payment-system/internal/domain/order"
+	"github.com/kazshi01/payment-system/internal/domain/payment"
+)
+
+// 主要 PG のコンビニ払いの既定の支払期限に合わせる
+const defaultKonbiniTTL = 3 * 24 * time.Hour
+
+// --- Konbini ---
+
+// PayOrderKonbini はコンビニ払いの払込番号を発行し、注文を AWAITING_PAYMENT にする（一般ユーザは自分の注文のみ）。
+// 支払いは店頭で行われ、完了は Webhook で反映する。発行済みなら同じ払込番号を返す
+func (uc *OrderUsecase) PayOrderKonbini(ctx context.Context, id order.ID) (*payment.Konbini, error) {
+	if uc.Konbini == nil || uc.Konbinis == nil {
+		return nil, fmt.Errorf("%w: konbini payment is not available", domain.ErrInvalidArgument)
+	}
+	isAdmin := auth.IsAdmin(ctx)
+
+	userID, _ := auth.UserIDFrom(ctx)
+	if !isAdmin && userID == "" {
+		return nil, domain.ErrUnauthorized
+	}
+
+	// カードの pay / authorize と同じロックで直列化する
+	ctx, unlock, err := uc.lockOrder(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	// ---- 注文・払込番号の取得は 3s ----
+	dbReadCtx, cancelRead := context.WithTimeout(ctx, 3*time.Second)
+	defer cancelRead()
+
+	o, err := uc.findOrder(dbReadCtx, id, isAdmin, userID)
+	if err != nil {
+		return nil, err
+	}
+	if o.Status == order.StatusAwaitingPayment {
+		k, err := uc.Konbinis.FindAwaitingByOrderID(dbReadCtx, o.ID)
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, fmt.Errorf("%w: order is awaiting bank transfer payment", domain.ErrConflict)
+		}
+		return k, err
+	}
+	if err := checkTransition(o.Status, order.StatusAwaitingPayment); err != nil {
+		return nil, err
+	}
+	if o.Amount.Currency != money.JPY {
+		return nil, fmt.Errorf("%w: konbini payment accepts only JPY", domain.ErrInvalidArgument)
+	}
+
+	now := uc.Clock.Now()
+
+	// ---- PG 呼び出しは 5s ----
+	pgCtx, cancelPG := context.WithTimeout(ctx, 5*time.Second)
+	defer cancelPG()
+
+	v, err := uc.Konbini.IssueKonbini(pgCtx, domain.KonbiniRequest{
+		OrderID:          string(o.ID),
+		Amount:           o.Amount.Amount,
+		Currency:         o.Amount.Currency.Lower(),
+		ExpiresAfterDays: uc.konbiniDays(),
+		IdempotencyKey:   "konbini:" + string(o.ID),
+	})
+	if err != nil {
+		// 失敗の記録はベストエフォート（PG のエラーを優先して返す）
+		if recErr := uc.recordEventTx(ctx, o.ID, event.TypeChargeFailed, map[string]any{
+			"method": string(payment.MethodKonbini),
+			"error":  err.Error(),
+		}); recErr != nil {
+			log.Printf("warn: record %s event: order_id=%s: %v", event.TypeChargeFailed, o.ID, recErr)
+		}
+		return nil, err
+	}
+
+	// ---- DB 反映は 3s ----
+	dbCtx, cancelDB := context.WithTimeout(ctx, 3*time.Second)
+	defer cancelDB()
+
+	k := &payment.Konbini{
+		ID:                 payment.KonbiniID(uc.IDGen.New()),
+		OrderID:            string(o.ID),
+		Provider:           uc.Provider,
+		ProviderPaymentID:  v.ProviderPaymentID,
+		PaymentCode:        v.PaymentCode,
+		ConfirmationNumber: v.ConfirmationNumber,
+		Amount:             o.Amount,
+		Status:             payment.KonbiniAwaiting,
+		ExpiresAt:          v.ExpiresAt,
+		CreatedAt:          now,
+		UpdatedAt:          now,
+	}
+
+	// DB 反映に失敗しても、再試行は同じ Idempotency-Key・同じパラメータなので同じ番号が返る
+	err = uc.Tx.Do(dbCtx, func(dbCtx context.Context) error {
+		if err := uc.transition(dbCtx, o, order.StatusAwaitingPayment, now); err != nil {
+			return err
+		}
+
+		if err := uc.Konbinis.Create(dbCtx, k); err != nil {
+			return err
+		}
+
+		return uc.recordEvent(dbCtx, o.ID, event.TypeKonbiniIssued, putAmount(map[string]any{
+			"konbini_id":          string(k.ID),
+			"provider":            k.Provider,
+			"provider_payment_id": k.ProviderPaymentID,
+			"payment_code":        k.PaymentCode,
+			"expires_at":          k.ExpiresAt,
+		}, "amount", k.Amount))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// 支払期限の日数。PG は日単位でしか受け付けないので切り上げる（1〜60 日）
+func (uc *OrderUsecase) konbiniDays() int {
+	ttl := uc.KonbiniTTL
+	if ttl <= 0 {
+		ttl = defaultKonbiniTTL
+	}
+	days := int((ttl + 24*time.Hour - 1) / (24 * time.Hour))
+	return min(max(days, 1), 60)
+}
+
+// --- Expiry ---
+
+// ExpireKonbiniPayments は支払期限を過ぎた払込番号を無効化し、注文を CANCELED にする。
+// バックグラウンドジョブから呼ぶ想定のため認可チェックは行わない。無効化できた件数を返す
+func (uc *OrderUsecase) ExpireKonbiniPayments(ctx context.Context, limit int) (int, error) {
+	if uc.Konbini == nil || uc.Konbinis == nil {
+		return 0, nil
+	}
+
+	// ---- 対象の取得は 3s ----
+	dbReadCtx, cancelRead := context.WithTimeout(ctx, 3*time.Second)
+	defer cancelRead()
+
+	expired, err := uc.Konbinis.ListExpired(dbReadCtx, uc.Clock.Now(), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	canceled := 0
+	for _, k := range expired {
+		if err := uc.expireKonbini(ctx, k); err != nil {
+			log.Printf("warn: expire konbini payment: order_id=%s konbini_id=%s: %v", k.OrderID, k.ID, err)
+			continue
+		}
+		canceled++
+	}
+	return canceled, nil
+}
+
+func (uc *OrderUsecase) expireKonbini(ctx context.Context, k *payment.Konbini) error {
+	id := order.ID(k.OrderID)
+
+	// 支払い完了の Webhook と競合しないよう同じロックを取る
+	ctx, unlock, err := uc.lockOrder(ctx, id)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// ロック待ちの間に支払われていないか確認
+	dbReadCtx, cancelRead := context.WithTimeout(ctx, 3*time.Second)
+	defer cancelRead()
+
+	cur, err := uc.Konbinis.FindAwaitingByOrderID(dbReadCtx, id)
+	if err != nil {
+		return err
+	}
+	if cur.ID != k.ID {
+		return domain.ErrConflict
+	}
+
+	return uc.cancelKonbini(ctx, cur, map[string]any{
+		"reason":     "expired",
+		"expires_at": cur.ExpiresAt,
+	})
+}
+
+// 注文が AWAITING_PAYMENT なら振込先・払込番号ごと取り消す（ロック取得済みで呼ぶ）。
+// それ以外の状態なら handled=false を返し、通常の取り消しに任せる
+func (uc *OrderUsecase) cancelAwaitingPayment(ctx context.Context, id order.ID, isAdmin bool, userID string) (handled bool, err error) {
+	// ---- 注文・払込番号の取得は 3s ----
+	dbReadCtx, cancelRead := context.WithTimeout(ctx, 3*time.Second)
+	defer cancelRead()
+
+	o, err := uc.findOrder(dbReadCtx, id, isAdmin, userID)
+	if err != nil {
+		return true, err
+	}
+	if o.Status != order.StatusAwaitingPayment {
+		return false, nil
+	}
+	payload := map[string]any{
+		"canceled_by": userID,
+		"by_admin":    isAdmin,
+	}
+
+	t, err := uc.awaitingTransfer(dbReadCtx, o.ID)
+	if err == nil {
+		return true, uc.cancelTransfer(ctx, t, payload)
+	}
+	if !errors.Is(err, domain.ErrNotFound) {
+		return true, err
+	}
+
+	k, err := uc.awaitingKonbini(dbReadCtx, o.ID)
+	if err != nil {
+		return true, err
+	}
+	return true, uc.cancelKonbini(ctx, k, payload)
+}
+
+// PG で払込番号を無効化し、注文を CANCELED・払込番号を EXPIRED にする（ロック取得済みで呼ぶ）。
+// 店頭で支払い済みだった場合は PG が ErrConflict を返すので、注文は Webhook で PAID に収束させる
+func (uc *OrderUsecase) cancelKonbini(ctx context.Context, k *payment.Konbini, payload map[string]any) error {
+	id := order.ID(k.OrderID)
+
+	// ---- PG 呼び出しは 5s ----
+	pgCtx, cancelPG := context.WithTimeout(ctx, 5*time.Second)
+	defer cancelPG()
+
+	if err := uc.Konbini.CancelKonbini(pgCtx, domain.KonbiniCancelRequest{
+		OrderID:           k.OrderID,
+		ProviderPaymentID: k.ProviderPaymentID,
+		IdempotencyKey:    "konbini-cancel:" + k.OrderID,
+	}); err != nil {
+		return err
+	}
+
+	// ---- DB 反映は 3s ----
+	dbCtx, cancelDB := context.WithTimeout(ctx, 3*time.Second)
+	defer cancelDB()
+
+	return uc.Tx.Do(dbCtx, func(dbCtx context.Context) error {
+		now := uc.Clock.Now()
+
+		if err := uc.updateStatus(dbCtx, id, order.StatusAwaitingPayment, order.StatusCanceled, now); err != nil {
+			return err
+		}
+		if err := uc.expireKonbiniRow(dbCtx, k, now); err != nil {
+			return err
+		}
+
+		payload["konbini_id"] = string(k.ID)
+		return uc.recordEvent(dbCtx, id, event.TypeOrderCanceled, payload)
+	})
+}
+
+// 払込番号を AWAITING から EXPIRED にする（Tx の中で呼ぶ）
+func (uc *OrderUsecase) expireKonbiniRow(ctx context.Context, k *payment.Konbini, at time.Time) error {
+	rows, err := uc.Konbinis.UpdateStatusIf(ctx, k.ID, payment.KonbiniAwaiting, payment.KonbiniExpired, at)
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return domain.ErrConflict
+	}
+	return nil
+}
+
+// 注文の支払い待ちの払込番号（Tx の中で呼ぶ）。AWAITING_PAYMENT なのに無ければ内部エラー
+func (uc *OrderUsecase) awaitingKonbini(ctx context.Context, id order.ID) (*payment.Konbini, error) {
+	if uc.Konbinis == nil {
+		return nil, fmt.Errorf("%w: konbini repository is not configured", domain.ErrInternal)
+	}
+	k, err := uc.Konbinis.FindAwaitingByOrderID(ctx, id)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, fmt.Errorf("%w: no awaiting konbini payment for order %s", domain.ErrInternal, id)
+	}
+	return k, err
+}
@@ -0,0 +1,264 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/event"
+	"github.com/kazshi01/payment-system/internal/domain/order"
+	"github.com/kazshi01/payment-system/internal/domain/payment"
+	"github.com/kazshi01/payment-system/internal/usecase"
+)
+
+type memKonbiniRepo struct {
+	m map[payment.KonbiniID]*payment.Konbini
+}
+
+func newMemKonbiniRepo() *memKonbiniRepo {
+	return &memKonbiniRepo{m: map[payment.KonbiniID]*payment.Konbini{}}
+}
+
+func (r *memKonbiniRepo) Create(ctx context.Context, k *payment.Konbini) error {
+	if _, err := r.FindAwaitingByOrderID(ctx, order.ID(k.OrderID)); err == nil {
+		return domain.ErrConflict
+	}
+	cp := *k
+	r.m[k.ID] = &cp
+	return nil
+}
+
+func (r *memKonbiniRepo) FindAwaitingByOrderID(ctx context.Context, id order.ID) (*payment.Konbini, error) {
+	for _, k := range r.m {
+		if k.OrderID == string(id) && k.Status == payment.KonbiniAwaiting {
+			cp := *k
+			return &cp, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (r *memKonbiniRepo) UpdateStatusIf(ctx context.Context, id payment.KonbiniID, from, to payment.KonbiniStatus, at time.Time) (int64, error) {
+	k, ok := r.m[id]
+	if !ok || k.Status != from {
+		return 0, nil
+	}
+	k.Status, k.UpdatedAt = to, at
+	return 1, nil
+}
+
+func (r *memKonbiniRepo) ListExpired(ctx context.Context, now time.Time, limit int) ([]*payment.Konbini, error) {
+	var out []*payment.Konbini
+	for _, k := range r.m {
+		if k.Status == payment.KonbiniAwaiting && !k.ExpiresAt.After(now) && len(out) < limit {
+			cp := *k
+			out = append(out, &cp)
+		}
+	}
+	return out, nil
+}
+
+// 注文ID から決済IDを作り、無効化した決済IDを覚えておく。
+// Stripe と同じく、同じ Idempotency-Key は同じパラメータなら同じ番号を返し、違えば ErrConflict
+type stubKonbini struct {
+	now      time.Time
+	canceled []string
+	issued   map[string]domain.KonbiniRequest
+	codes    int
+}
+
+func (s *stubKonbini) IssueKonbini(ctx context.Context, req domain.KonbiniRequest) (domain.KonbiniVoucher, error) {
+	if s.issued == nil {
+		s.issued = map[string]domain.KonbiniRequest{}
+	}
+	if prev, ok := s.issued[req.IdempotencyKey]; ok && prev != req {
+		return domain.KonbiniVoucher{}, domain.ErrConflict
+	} else if !ok {
+		s.issued[req.IdempotencyKey] = req
+		s.codes++
+	}
+	return domain.KonbiniVoucher{
+		ProviderPaymentID:  "pi_" + req.OrderID,
+		PaymentCode:        fmt.Sprintf("%012d", 123456789000+s.codes),
+		ConfirmationNumber: "654321",
+		ExpiresAt:          s.now.AddDate(0, 0, req.ExpiresAfterDays),
+	}, nil
+}
+
+func (s *stubKonbini) CancelKonbini(ctx context.Context, req domain.KonbiniCancelRequest) error {
+	s.canceled = append(s.canceled, req.ProviderPaymentID)
+	return nil
+}
+
+var konbiniNow = time.Date(2025, 10, 1, 9, 0, 0, 0, time.UTC)
+
+func newKonbiniTestUsecase() (*usecase.OrderUsecase, *memKonbiniRepo, *stubKonbini, *memPaymentRepo, *memEventRepo) {
+	n := 0
+	konbinis := newMemKonbiniRepo()
+	gw := &stubKonbini{now: konbiniNow}
+	payments := newMemPaymentRepo()
+	events := newMemEventRepo()
+	uc := &usecase.OrderUsecase{
+		Repo:       newMemRepo(),
+		Payments:   payments,
+		Events:     events,
+		Konbini:    gw,
+		Konbinis:   konbinis,
+		KonbiniTTL: 72 * time.Hour,
+		Tx:         nopTx{},
+		PG:         okPG{txid: "tx1"},
+		Provider:   "stripe",
+		Clock:      fixedClock{t: konbiniNow},
+		IDGen:      seqIDGen{n: &n},
+		Locker:     okLocker{},
+	}
+	return uc, konbinis, gw, payments, events
+}
+
+func TestOrderUsecase_PayOrderKonbini(t *testing.T) {
+	uc, konbinis, _, payments, events := newKonbiniTestUsecase()
+	ctx := ctxWithUser("user-1")
+	o, _ := uc.CreateOrder(ctx, usecase.CreateOrderInput{Amount: jpy(3980)})
+
+	k, err := uc.PayOrderKonbini(ctx, o.ID)
+	if err != nil {
+		t.Fatalf("PayOrderKonbini err = %v", err)
+	}
+	if k.PaymentCode != "123456789001" || k.Status != payment.KonbiniAwaiting || !k.ExpiresAt.Equal(konbiniNow.Add(72*time.Hour)) || k.Amount != jpy(3980) {
+		t.Fatalf("konbini = %+v", k)
+	}
+	got, _ := uc.Repo.FindByID(ctx, o.ID)
+	if got.Status != order.StatusAwaitingPayment {
+		t.Fatalf("status = %s; want AWAITING_PAYMENT", got.Status)
+	}
+
+	// 発行済みなら同じ払込番号。カードでは払えない
+	again, err := uc.PayOrderKonbini(ctx, o.ID)
+	if err != nil || again.ID != k.ID {
+		t.Fatalf("second issue = %+v, %v; want %s", again, err, k.ID)
+	}
+	if err := uc.PayOrder(ctx, o.ID, "pm_card_visa"); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("PayOrder err = %v; want ErrConflict", err)
+	}
+
+	// 店頭で支払われた
+	err = uc.ApplyProviderEvent(context.Background(), &domain.ProviderEvent{
+		Provider: "stripe", ID: "evt_1", Type: domain.ProviderPaymentSucceeded,
+		OrderID: string(o.ID), ProviderTxID: k.ProviderPaymentID, Amount: 3980, Currency: "jpy",
+	})
+	if err != nil {
+		t.Fatalf("ApplyProviderEvent err = %v", err)
+	}
+	got, _ = uc.Repo.FindByID(ctx, o.ID)
+	if got.Status != order.StatusPaid {
+		t.Fatalf("status = %s; want PAID", got.Status)
+	}
+	if ps := payments.m[o.ID]; len(ps) != 1 || ps[0].Method != payment.MethodKonbini || ps[0].TxID != k.ProviderPaymentID {
+		t.Fatalf("payments = %+v", ps)
+	}
+	if s := konbinis.m[k.ID].Status; s != payment.KonbiniPaid {
+		t.Fatalf("konbini status = %s; want PAID", s)
+	}
+	want := []event.Type{event.TypeOrderCreated, event.TypeKonbiniIssued, event.TypeChargeSucceeded, event.TypeOrderPaid}
+	if got := eventTypes(events.m[o.ID]); !slices.Equal(got, want) {
+		t.Fatalf("events = %v; want %v", got, want)
+	}
+}
+
+// 1 回目だけ DB 反映に失敗する（ロールバックされ fn は実行されない）
+type failOnceTx struct{ failed *bool }
+
+func (t failOnceTx) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if !*t.failed {
+		*t.failed = true
+		return errors.New("db down")
+	}
+	return fn(ctx)
+}
+
+func TestOrderUsecase_PayOrderKonbini_RetryAfterPersistFailure(t *testing.T) {
+	uc, _, gw, _, _ := newKonbiniTestUsecase()
+	ctx := ctxWithUser("user-1")
+	o, _ := uc.CreateOrder(ctx, usecase.CreateOrderInput{Amount: jpy(3980)})
+
+	failed := false
+	uc.Tx = failOnceTx{failed: &failed}
+	if _, err := uc.PayOrderKonbini(ctx, o.ID); err == nil {
+		t.Fatal("PayOrderKonbini err = nil; want db error")
+	}
+
+	// 時刻が進んでも PG には同じパラメータが届き、同じ番号で発行が完了する
+	uc.Clock = fixedClock{t: konbiniNow.Add(10 * time.Minute)}
+	k, err := uc.PayOrderKonbini(ctx, o.ID)
+	if err != nil {
+		t.Fatalf("retry err = %v", err)
+	}
+	if k.PaymentCode != "123456789001" || gw.codes != 1 {
+		t.Fatalf("retry konbini = %+v, issued = %d; want the first payment code", k, gw.codes)
+	}
+	if got, _ := uc.Repo.FindByID(ctx, o.ID); got.Status != order.StatusAwaitingPayment {
+		t.Fatalf("status = %s; want AWAITING_PAYMENT", got.Status)
+	}
+}
+
+func TestOrderUsecase_ExpireKonbiniPayments(t *testing.T) {
+	uc, konbinis, gw, _, events := newKonbiniTestUsecase()
+	ctx := ctxWithUser("user-1")
+	unpaid, _ := uc.CreateOrder(ctx, usecase.CreateOrderInput{Amount: jpy(1000)})
+	if _, err := uc.PayOrderKonbini(ctx, unpaid.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// 期限前は何もしない
+	if n, err := uc.ExpireKonbiniPayments(context.Background(), 10); err != nil || n != 0 {
+		t.Fatalf("before deadline = %d, %v; want 0", n, err)
+	}
+
+	uc.Clock = fixedClock{t: konbiniNow.Add(72 * time.Hour)}
+	gw.now = konbiniNow.Add(72 * time.Hour)
+	later, _ := uc.CreateOrder(ctx, usecase.CreateOrderInput{Amount: jpy(2000)})
+	k, err := uc.PayOrderKonbini(ctx, later.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := uc.ExpireKonbiniPayments(context.Background(), 10)
+	if err != nil || n != 1 {
+		t.Fatalf("ExpireKonbiniPayments = %d, %v; want 1", n, err)
+	}
+	got, _ := uc.Repo.FindByID(ctx, unpaid.ID)
+	if got.Status != order.StatusCanceled {
+		t.Fatalf("status = %s; want CANCELED", got.Status)
+	}
+	if !slices.Equal(gw.canceled, []string{"pi_" + string(unpaid.ID)}) {
+		t.Fatalf("canceled at PG = %v", gw.canceled)
+	}
+	es := events.m[unpaid.ID]
+	if last := es[len(es)-1]; last.Type != event.TypeOrderCanceled || last.Payload["reason"] != "expired" {
+		t.Fatalf("last event = %s %v; want ORDER_CANCELED expired", last.Type, last.Payload)
+	}
+
+	// 期限切れ後に届いた支払いは注文を戻さない
+	err = uc.ApplyProviderEvent(context.Background(), &domain.ProviderEvent{
+		Provider: "stripe", ID: "evt_late", Type: domain.ProviderPaymentSucceeded,
+		OrderID: string(unpaid.ID), ProviderTxID: "pi_" + string(unpaid.ID), Amount: 1000, Currency: "jpy",
+	})
+	if err != nil {
+		t.Fatalf("late ApplyProviderEvent err = %v", err)
+	}
+	if got, _ := uc.Repo.FindByID(ctx, unpaid.ID); got.Status != order.StatusCanceled {
+		t.Fatalf("status after late payment = %s; want CANCELED", got.Status)
+	}
+
+	// 利用者の取り消しも PG 側の払込番号を無効化する
+	if err := uc.CancelOrder(ctx, later.ID); err != nil {
+		t.Fatalf("CancelOrder err = %v", err)
+	}
+	if s := konbinis.m[k.ID].Status; s != payment.KonbiniExpired || len(gw.canceled) != 2 {
+		t.Fatalf("konbini status = %s, canceled = %v", s, gw.canceled)
+	}
+}
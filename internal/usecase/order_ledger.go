@@ -0,0 +1,41 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/kazshi01/payment-system/internal/domain/ledger"
+	"github.com/kazshi01/payment-system/internal/domain/payment"
+	"github.com/kazshi01/payment-system/internal/domain/refund"
+)
+
+// postCharge は確定した決済を売上に計上し、手数料がかかれば手数料も計上する。
+// 銀行振込は PG を通らないので普通預金に計上し、手数料も計上しない。決済の記録と同じ Tx 内で呼ぶ（Ledger が nil なら何もしない）
+func (uc *OrderUsecase) postCharge(ctx context.Context, p *payment.Payment) error {
+	if uc.Ledger == nil {
+		return nil
+	}
+	if p.Method == payment.MethodBankTransfer {
+		return uc.post(ctx, ledger.BankCharge(p.OrderID, string(p.ID), p.Amount, p.CreatedAt))
+	}
+	if err := uc.post(ctx, ledger.Charge(p.OrderID, string(p.ID), p.Amount, p.CreatedAt)); err != nil {
+		return err
+	}
+	if fee := uc.FeeRate.Apply(p.Amount); fee.IsPositive() {
+		return uc.post(ctx, ledger.Fee(p.OrderID, string(p.ID), fee, p.CreatedAt))
+	}
+	return nil
+}
+
+// postRefund は返金を計上する。返金の記録と同じ Tx 内で呼ぶ。
+// PG が返金時に手数料を返すかは契約次第なので、手数料は戻さない
+func (uc *OrderUsecase) postRefund(ctx context.Context, rf *refund.Refund) error {
+	if uc.Ledger == nil {
+		return nil
+	}
+	return uc.post(ctx, ledger.Refund(rf.OrderID, string(rf.ID), rf.Amount, rf.CreatedAt))
+}
+
+func (uc *OrderUsecase) post(ctx context.Context, e *ledger.Entry) error {
+	e.ID = uc.IDGen.New()
+	return uc.Ledger.Record(ctx, e)
+}
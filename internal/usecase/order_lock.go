@@ -0,0 +1,108 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/order"
+)
+
+// 注文ロックの既定リース。PG 呼び出しが長引いても保持中はウォッチドッグが延長する
+const defaultLockTTL = 15 * time.Second
+
+type fenceCtxKey struct{}
+
+// ロック取得時のフェンシングトークンを ctx に載せる（updateStatus が orders 行へ書く）
+func withFence(ctx context.Context, fence int64) context.Context {
+	return context.WithValue(ctx, fenceCtxKey{}, fence)
+}
+
+// ロック外（フェンスなし）は 0
+func fenceFrom(ctx context.Context) int64 {
+	f, _ := ctx.Value(fenceCtxKey{}).(int64)
+	return f
+}
+
+func (uc *OrderUsecase) lockTTL() time.Duration {
+	if uc.LockTTL > 0 {
+		return uc.LockTTL
+	}
+	return defaultLockTTL
+}
+
+// 注文単位のロックを取る（pay / cancel / refund / authorize / webhook で共有）。取れなければ ErrConflict
+// 返す ctx はフェンスを持ち、リースを失った時点でキャンセルされる（後続の PG 呼び出し・DB 反映を止める）
+func (uc *OrderUsecase) lockOrder(ctx context.Context, id order.ID) (lockedCtx context.Context, unlock func(), err error) {
+	lockedCtx, lock, unlock, err := uc.holdLock(ctx, "lock:pay:"+string(id))
+	if err != nil {
+		return nil, nil, err
+	}
+	return withFence(lockedCtx, lock.Fence), unlock, nil
+}
+
+// key のロックを取り、解放までウォッチドッグで保持し続ける。取れなければ ErrConflict
+// 返す ctx はリースを失った時点でキャンセルされる
+func (uc *OrderUsecase) holdLock(ctx context.Context, key string) (lockedCtx context.Context, lock domain.Lock, unlock func(), err error) {
+	ttl := uc.lockTTL()
+
+	ok, lock, err := uc.Locker.TryLock(ctx, key, ttl)
+	if err != nil {
+		return nil, domain.Lock{}, nil, err
+	}
+	if !ok {
+		return nil, domain.Lock{}, nil, domain.ErrConflict
+	}
+
+	lockedCtx, lost := context.WithCancel(ctx)
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		uc.keepLock(lockedCtx, lost, lock, ttl, stop)
+	}()
+
+	return lockedCtx, lock, func() {
+		close(stop)
+		<-done
+		lost()
+
+		uctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+		defer cancel()
+		_ = uc.Locker.Unlock(uctx, lock)
+	}, nil
+}
+
+// ウォッチドッグ: TTL の 1/3 ごとにリースを延長する
+// 他者に取られていた・延長に失敗した時点で失ったものとみなして lost を呼ぶ。
+// 失敗後の再試行を待つと、延長できたか分からないままリースが切れて他者が取れてしまう
+func (uc *OrderUsecase) keepLock(ctx context.Context, lost context.CancelFunc, lock domain.Lock, ttl time.Duration, stop <-chan struct{}) {
+	t := time.NewTicker(ttl / 3)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+
+		ectx, cancel := context.WithTimeout(ctx, ttl/3)
+		ok, err := uc.Locker.Extend(ectx, lock, ttl)
+		cancel()
+
+		switch {
+		case err != nil:
+			log.Printf("warn: extend lock failed, giving up the lease: key=%s fence=%d: %v", lock.Key, lock.Fence, err)
+			lost()
+			return
+		case !ok:
+			log.Printf("warn: lock lost: key=%s fence=%d", lock.Key, lock.Fence)
+			lost()
+			return
+		}
+	}
+}
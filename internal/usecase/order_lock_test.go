@@ -0,0 +1,149 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/order"
+	"github.com/kazshi01/payment-system/internal/usecase"
+)
+
+// Locker ダミー（延長回数を数える。extendOK=false ならリースを失った扱い、
+// 最初の failFirst 回の延長はエラー）
+type leaseLocker struct {
+	okLocker
+	fence     int64
+	extendOK  bool
+	failFirst int
+
+	mu      sync.Mutex
+	extends int
+}
+
+func (l *leaseLocker) TryLock(ctx context.Context, key string, ttl time.Duration) (bool, domain.Lock, error) {
+	return true, domain.Lock{Key: key, Token: "tok", Fence: l.fence}, nil
+}
+
+func (l *leaseLocker) Extend(ctx context.Context, lock domain.Lock, ttl time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.extends++
+	if l.extends <= l.failFirst {
+		return false, errors.New("redis timeout")
+	}
+	return l.extendOK, nil
+}
+
+func (l *leaseLocker) extendCount() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.extends
+}
+
+// PG ダミー（Charge に delay かかる。途中で ctx が切れたらそのエラーを返す）
+type slowPG struct {
+	okPG
+	delay time.Duration
+}
+
+func (p slowPG) Charge(ctx context.Context, intent domain.PaymentIntent) (string, error) {
+	select {
+	case <-time.After(p.delay):
+		return p.txid, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func newLockTestUsecase(repo *memRepo, pg domain.PaymentGateway, locker domain.Locker) *usecase.OrderUsecase {
+	n := 0
+	return &usecase.OrderUsecase{
+		Repo:     repo,
+		Payments: newMemPaymentRepo(),
+		Events:   newMemEventRepo(),
+		Tx:       nopTx{},
+		PG:       pg,
+		Clock:    fixedClock{t: time.Now()},
+		IDGen:    seqIDGen{n: &n},
+		Locker:   locker,
+		LockTTL:  30 * time.Millisecond,
+	}
+}
+
+func TestOrderUsecase_PayOrder_leaseExtended(t *testing.T) {
+	repo := newMemRepo()
+	locker := &leaseLocker{fence: 1, extendOK: true}
+	uc := newLockTestUsecase(repo, slowPG{okPG: okPG{txid: "tx1"}, delay: 100 * time.Millisecond}, locker)
+	ctx := ctxWithUser("user-1")
+
+	o, _ := uc.CreateOrder(ctx, usecase.CreateOrderInput{Amount: jpy(1200)})
+	if err := uc.PayOrder(ctx, o.ID, "pm_card_visa"); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	if n := locker.extendCount(); n < 2 {
+		t.Fatalf("extends = %d; want >= 2", n)
+	}
+	if got := repo.m[o.ID].Status; got != order.StatusPaid {
+		t.Fatalf("status = %s; want PAID", got)
+	}
+	if got := repo.fences[o.ID]; got != 1 {
+		t.Fatalf("lock_fence = %d; want 1", got)
+	}
+}
+
+func TestOrderUsecase_PayOrder_leaseLost(t *testing.T) {
+	repo := newMemRepo()
+	locker := &leaseLocker{fence: 1, extendOK: false}
+	uc := newLockTestUsecase(repo, slowPG{okPG: okPG{txid: "tx1"}, delay: time.Second}, locker)
+	ctx := ctxWithUser("user-1")
+
+	o, _ := uc.CreateOrder(ctx, usecase.CreateOrderInput{Amount: jpy(1200)})
+	if err := uc.PayOrder(ctx, o.ID, "pm_card_visa"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v; want context.Canceled", err)
+	}
+	if got := repo.m[o.ID].Status; got != order.StatusPending {
+		t.Fatalf("status = %s; want PENDING", got)
+	}
+}
+
+// 延長が 1 回でも失敗したら、後で延長できても PG 呼び出しを止める
+func TestOrderUsecase_PayOrder_extendFailedOnce(t *testing.T) {
+	repo := newMemRepo()
+	locker := &leaseLocker{fence: 1, extendOK: true, failFirst: 1}
+	uc := newLockTestUsecase(repo, slowPG{okPG: okPG{txid: "tx1"}, delay: time.Second}, locker)
+	ctx := ctxWithUser("user-1")
+
+	o, _ := uc.CreateOrder(ctx, usecase.CreateOrderInput{Amount: jpy(1200)})
+	if err := uc.PayOrder(ctx, o.ID, "pm_card_visa"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v; want context.Canceled", err)
+	}
+	if n := locker.extendCount(); n != 1 {
+		t.Fatalf("extends = %d; want 1", n)
+	}
+	if got := repo.m[o.ID].Status; got != order.StatusPending {
+		t.Fatalf("status = %s; want PENDING", got)
+	}
+}
+
+func TestOrderUsecase_PayOrder_staleFence(t *testing.T) {
+	repo := newMemRepo()
+	uc := newLockTestUsecase(repo, okPG{txid: "tx1"}, &leaseLocker{fence: 1, extendOK: true})
+	ctx := ctxWithUser("user-1")
+
+	o, _ := uc.CreateOrder(ctx, usecase.CreateOrderInput{Amount: jpy(1200)})
+
+	// より新しいロック保持者が書き込み済み
+	repo.fences[o.ID] = 2
+
+	if err := uc.PayOrder(ctx, o.ID, "pm_card_visa"); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("err = %v; want ErrConflict", err)
+	}
+	if got := repo.m[o.ID].Status; got != order.StatusPending {
+		t.Fatalf("status = %s; want PENDING", got)
+	}
+}
@@ -0,0 +1,156 @@
+package usecase
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/auth"
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/order"
+)
+
+const (
+	defaultListLimit = 20
+	maxListLimit     = 100
+)
+
+// ListOrdersQuery は GET /orders の検索条件
+type ListOrdersQuery struct {
+	Status      order.Status
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	Cursor      string // 前ページの NextCursor（不透明な文字列）
+	Limit       int    // 0 なら既定値
+}
+
+type OrderPage struct {
+	Items      []*order.Order
+	NextCursor string // 続きがなければ空
+}
+
+// --- Get ---
+
+// 一般ユーザは自分の注文のみ、管理者は全注文を取得できる
+func (uc *OrderUsecase) GetOrder(ctx context.Context, id order.ID) (*order.Order, error) {
+	isAdmin := auth.IsAdmin(ctx)
+
+	userID, _ := auth.UserIDFrom(ctx)
+	if !isAdmin && userID == "" {
+		return nil, domain.ErrUnauthorized
+	}
+
+	// ---- DB 読み取りは 3s ----
+	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	o, err := uc.findOrder(dbCtx, id, isAdmin, userID)
+	if err != nil {
+		return nil, err
+	}
+	if uc.Items != nil {
+		if o.Items, err = uc.Items.ListByOrderID(dbCtx, id); err != nil {
+			return nil, err
+		}
+	}
+	if uc.Taxes != nil {
+		if o.Tax, err = uc.Taxes.ListByOrderID(dbCtx, id); err != nil {
+			return nil, err
+		}
+	}
+	if uc.Coupons != nil {
+		o.Coupon, err = uc.Coupons.FindRedemptionByOrderID(dbCtx, id)
+		if errors.Is(err, domain.ErrNotFound) {
+			o.Coupon, err = nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
+
+// --- List ---
+
+// 新しい順のキーセットページング。一般ユーザは自分の注文に限定される
+func (uc *OrderUsecase) ListOrders(ctx context.Context, q ListOrdersQuery) (*OrderPage, error) {
+	isAdmin := auth.IsAdmin(ctx)
+
+	userID, _ := auth.UserIDFrom(ctx)
+	if !isAdmin && userID == "" {
+		return nil, domain.ErrUnauthorized
+	}
+
+	limit := q.Limit
+	if limit == 0 {
+		limit = defaultListLimit
+	}
+	if limit < 0 || limit > maxListLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", domain.ErrInvalidArgument, maxListLimit)
+	}
+	if q.Status != "" && !q.Status.Valid() {
+		return nil, fmt.Errorf("%w: unknown status %q", domain.ErrInvalidArgument, q.Status)
+	}
+	if !q.CreatedFrom.IsZero() && !q.CreatedTo.IsZero() && !q.CreatedFrom.Before(q.CreatedTo) {
+		return nil, fmt.Errorf("%w: created_from must be before created_to", domain.ErrInvalidArgument)
+	}
+
+	f := domain.OrderListFilter{
+		Status:      q.Status,
+		CreatedFrom: q.CreatedFrom,
+		CreatedTo:   q.CreatedTo,
+		Limit:       limit + 1, // 1件多く取って次ページの有無を判定
+	}
+	if !isAdmin {
+		f.UserID = userID
+	}
+	if q.Cursor != "" {
+		c, err := decodeCursor(q.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		f.After = c
+	}
+
+	// ---- DB 読み取りは 3s ----
+	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	items, err := uc.Repo.List(dbCtx, f)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &OrderPage{Items: items}
+	if len(items) > limit {
+		page.Items = items[:limit]
+		last := page.Items[limit-1]
+		page.NextCursor = encodeCursor(domain.OrderCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+	return page, nil
+}
+
+// カーソルは "created_at|id" を base64url にしたもの
+func encodeCursor(c domain.OrderCursor) string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + string(c.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(s string) (*domain.OrderCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid cursor", domain.ErrInvalidArgument)
+	}
+	ts, id, ok := strings.Cut(string(b), "|")
+	if !ok || id == "" {
+		return nil, fmt.Errorf("%w: invalid cursor", domain.ErrInvalidArgument)
+	}
+	t, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid cursor", domain.ErrInvalidArgument)
+	}
+	return &domain.OrderCursor{CreatedAt: t, ID: order.ID(id)}, nil
+}
@@ -0,0 +1,173 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/auth"
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/event"
+	"github.com/kazshi01/payment-system/internal/domain/money"
+	"github.com/kazshi01/payment-system/internal/domain/order"
+	"github.com/kazshi01/payment-system/internal/domain/payment"
+	"github.com/kazshi01/payment-system/internal/domain/refund"
+)
+
+// --- Refund ---
+
+// 支払い済み注文を返金する（管理者のみ）。amount が 0 なら残額を全額返金する。
+// 部分返金は何度でも可能だが、累計が確定済み決済額を超えることはない。
+// amount の通貨が空なら注文の通貨とみなす。カード以外の決済は PG で返金できないので ErrConflict
+func (uc *OrderUsecase) RefundOrder(ctx context.Context, id order.ID, amount money.Money, reason string) (*refund.Refund, error) {
+	if amount.Amount < 0 {
+		return nil, fmt.Errorf("%w: amount must be >= 0", domain.ErrInvalidArgument)
+	}
+	if !auth.IsAdmin(ctx) {
+		if _, ok := auth.UserIDFrom(ctx); !ok {
+			return nil, domain.ErrUnauthorized
+		}
+		return nil, domain.ErrForbidden
+	}
+
+	// 支払い・取り消しと同じロックで直列化する
+	ctx, unlock, err := uc.lockOrder(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	// ---- 注文・決済・返金履歴の取得は 3s ----
+	dbReadCtx, cancelRead := context.WithTimeout(ctx, 3*time.Second)
+	defer cancelRead()
+
+	o, err := uc.Repo.FindByID(dbReadCtx, id)
+	if err != nil {
+		return nil, err
+	}
+	// 全額返金へ遷移できない状態（未決済・返金済み等）は返金できない
+	if err := checkTransition(o.Status, order.StatusRefunded); err != nil {
+		return nil, err
+	}
+
+	ps, err := uc.Payments.ListByOrderID(dbReadCtx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(ps) == 0 {
+		return nil, fmt.Errorf("%w: no payment for paid order %s", domain.ErrInternal, id)
+	}
+	p := ps[len(ps)-1]
+	// PG で返金できるのはカード決済だけ。振込・コンビニ払いは口座への送金など手動で返す
+	if p.Method != payment.MethodCard {
+		return nil, fmt.Errorf("%w: %s payment cannot be refunded through the payment gateway", domain.ErrConflict, p.Method)
+	}
+
+	// 部分売上確定があるため、返金上限は注文金額ではなく確定額の合計
+	captured := money.Zero(o.Amount.Currency)
+	for _, x := range ps {
+		if captured, err = captured.Add(x.Amount); err != nil {
+			return nil, fmt.Errorf("%w: %w", domain.ErrInternal, err)
+		}
+	}
+
+	past, err := uc.Refunds.ListByOrderID(dbReadCtx, id)
+	if err != nil {
+		return nil, err
+	}
+	refunded := money.Zero(o.Amount.Currency)
+	for _, r := range past {
+		if refunded, err = refunded.Add(r.Amount); err != nil {
+			return nil, fmt.Errorf("%w: %w", domain.ErrInternal, err)
+		}
+	}
+
+	remaining, err := captured.Sub(refunded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrInternal, err)
+	}
+	amount, err = amountIn(amount, o.Amount.Currency)
+	if err != nil {
+		return nil, err
+	}
+	if amount.IsZero() {
+		amount = remaining
+	}
+	if c, _ := amount.Cmp(remaining); !amount.IsPositive() || c > 0 {
+		return nil, fmt.Errorf("%w: refund amount exceeds refundable amount %s", domain.ErrInvalidArgument, remaining)
+	}
+
+	next := order.StatusPartiallyRefunded
+	if amount == remaining {
+		next = order.StatusRefunded
+	}
+
+	// 連番と金額で冪等キーを作る。同じ返金のリトライは同じキーになり、
+	// PG で成功して DB 反映に失敗した後に金額を変えて出し直すと別の返金になる
+	req := domain.RefundRequest{
+		OrderID:        string(o.ID),
+		ProviderTxID:   p.TxID,
+		Amount:         amount.Amount,
+		Currency:       amount.Currency.Lower(),
+		Reason:         reason,
+		IdempotencyKey: fmt.Sprintf("refund:%s:%d:%d", o.ID, len(past)+1, amount.Amount),
+	}
+
+	// ---- PG 呼び出しは 5s ----
+	pgCtx, cancelPG := context.WithTimeout(ctx, 5*time.Second)
+	defer cancelPG()
+
+	providerRefundID, err := uc.PG.Refund(pgCtx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// ---- DB 反映は 3s ----
+	dbCtx, cancelDB := context.WithTimeout(ctx, 3*time.Second)
+	defer cancelDB()
+
+	now := uc.Clock.Now()
+	rf := &refund.Refund{
+		ID:               refund.ID(uc.IDGen.New()),
+		OrderID:          string(o.ID),
+		PaymentID:        string(p.ID),
+		Amount:           amount,
+		Reason:           reason,
+		ProviderRefundID: providerRefundID,
+		IdempotencyKey:   req.IdempotencyKey,
+		CreatedAt:        now,
+	}
+
+	err = uc.Tx.Do(dbCtx, func(dbCtx context.Context) error {
+		// 先に orders 行を更新して行ロックを取り、返金の同時実行を直列化する
+		if err := uc.transition(dbCtx, o, next, now); err != nil {
+			return err
+		}
+
+		rows, err := uc.Refunds.CreateWithinCaptured(dbCtx, rf)
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return domain.ErrConflict
+		}
+		if err := uc.postRefund(dbCtx, rf); err != nil {
+			return err
+		}
+
+		total, err := refunded.Add(rf.Amount)
+		if err != nil {
+			return err
+		}
+		payload := putAmount(map[string]any{
+			"refund_id":          string(rf.ID),
+			"reason":             rf.Reason,
+			"provider_refund_id": rf.ProviderRefundID,
+		}, "amount", rf.Amount)
+		return uc.recordEvent(dbCtx, o.ID, event.TypeOrderRefunded, putAmount(payload, "refunded_total", total))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rf, nil
+}
@@ -2,25 +2,68 @@ package usecase
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
 	"time"
 
 	"github.com/kazshi01/payment-system/internal/auth"
 	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/coupon"
+	"github.com/kazshi01/payment-system/internal/domain/event"
+	"github.com/kazshi01/payment-system/internal/domain/ledger"
+	"github.com/kazshi01/payment-system/internal/domain/money"
 	"github.com/kazshi01/payment-system/internal/domain/order"
-)
-
-const (
-	CurrencyJPY = "jpy"
-	lockTTL     = 15
+	"github.com/kazshi01/payment-system/internal/domain/payment"
+	"github.com/kazshi01/payment-system/internal/domain/tax"
 )
 
 type Clock interface{ Now() time.Time }
 type IDGen interface{ New() string }
 
 type OrderUsecase struct {
-	Repo domain.OrderRepository
-	Tx   domain.Tx
-	PG   domain.PaymentGateway
+	Repo     domain.OrderRepository
+	Items    domain.OrderItemRepository
+	Taxes    domain.OrderTaxRepository
+	Coupons  domain.CouponRepository
+	Payments domain.PaymentRepository
+	Events   domain.EventRepository
+	Refunds  domain.RefundRepository
+	Auths    domain.AuthorizationRepository
+	Outbox   domain.OutboxRepository // nil なら下流へは配信しない
+	Ledger   domain.LedgerRepository // nil なら元帳に計上しない
+	Tx       domain.Tx
+	PG       domain.PaymentGateway
+	Provider string // payments.provider に記録する PG 名
+
+	// 銀行振込の振込先の発行と入金の記録。どちらかが nil なら銀行振込は使えない
+	Bank      domain.BankTransferGateway
+	Transfers domain.BankTransferRepository
+
+	// コンビニ払いの払込番号の発行と記録。どちらかが nil ならコンビニ払いは使えない
+	Konbini  domain.KonbiniGateway
+	Konbinis domain.KonbiniRepository
+
+	// コンビニ払いの支払期限（0 なら defaultKonbiniTTL）。PG には日単位に切り上げて渡す
+	KonbiniTTL time.Duration
+
+	// 加盟店設定（消費税の端数処理）。nil なら既定の切り捨て
+	Merchants domain.MerchantSettingsRepository
+
+	// 決済額にかかる PG 手数料の率（元帳に計上する。0 なら計上しない）
+	FeeRate ledger.FeeRate
+
+	// オーソリの有効期限（0 なら defaultAuthorizationTTL）
+	AuthorizationTTL time.Duration
+
+	// PENDING のまま放置された注文を取り消すまでの期間（0 なら defaultPendingOrderTTL）
+	PendingOrderTTL time.Duration
+
+	// 注文ロックのリース（0 なら defaultLockTTL）。保持中は自動で延長する
+	LockTTL time.Duration
 
 	Clock  Clock
 	IDGen  IDGen
@@ -29,44 +72,198 @@ type OrderUsecase struct {
 
 // --- Create ---
 
-func (uc *OrderUsecase) CreateOrder(ctx context.Context, amountJPY int64) (*order.Order, error) {
-	if amountJPY <= 0 {
-		return nil, domain.ErrInvalidArgument
+// CreateOrderInput は注文作成の入力。Items を渡すと請求額はサーバ側で明細から計算する
+type CreateOrderInput struct {
+	// 明細なしの注文の金額。明細ありで指定した場合は明細の税込合計と一致しなければならない
+	Amount money.Money
+	Items  []order.Item
+
+	// 値引きに使うプロモーションコード（任意）。値引きは税込の請求額から行う
+	CouponCode string
+}
+
+// 請求額と消費税の内訳を決める（明細があれば明細から税込で計算する）
+func (in CreateOrderInput) amount(rounding tax.Rounding) (money.Money, []tax.Bucket, error) {
+	if len(in.Items) == 0 {
+		return in.Amount, nil, nil
 	}
 
-	if uc.IDGen == nil {
+	t, err := order.ComputeTotals(in.Items, rounding)
+	if err != nil {
+		return money.Money{}, nil, fmt.Errorf("%w: %w", domain.ErrInvalidArgument, err)
+	}
+	if (in.Amount.Amount != 0 && in.Amount != t.Total) || (in.Amount.Currency != "" && in.Amount.Currency != t.Total.Currency) {
+		return money.Money{}, nil, fmt.Errorf("%w: amount %s does not match items total %s", domain.ErrInvalidArgument, in.Amount, t.Total)
+	}
+	return t.Total, t.Tax, nil
+}
+
+func (uc *OrderUsecase) CreateOrder(ctx context.Context, in CreateOrderInput) (*order.Order, error) {
+	if uc.IDGen == nil || (len(in.Items) > 0 && (uc.Items == nil || uc.Taxes == nil)) || (in.CouponCode != "" && uc.Coupons == nil) {
 		return nil, domain.ErrInternal
 	}
 
-	// ログインユーザーIDを取得
+	// ログインユーザーIDを取得（端数処理は注文した加盟店の設定に従う）
 	userID, ok := auth.UserIDFrom(ctx)
 	if !ok || userID == "" {
 		return nil, domain.ErrUnauthorized
 	}
 
+	rounding := tax.DefaultRounding
+	if len(in.Items) > 0 {
+		var err error
+		if rounding, err = uc.taxRounding(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	amount, taxes, err := in.amount(rounding)
+	if err != nil {
+		return nil, err
+	}
+
+	// PaymentIntent には値引き後の金額を渡す
+	var red *coupon.Redemption
+	if in.CouponCode != "" {
+		if red, err = uc.discount(ctx, in.CouponCode, amount); err != nil {
+			return nil, err
+		}
+		if amount, err = amount.Sub(red.Discount); err != nil {
+			return nil, fmt.Errorf("%w: %w", domain.ErrInternal, err)
+		}
+		// 消費税は値引き後の金額に対して記録する（値引きは税率ごとに按分）
+		if taxes, err = tax.Discount(taxes, red.Discount, rounding); err != nil {
+			return nil, fmt.Errorf("%w: %w", domain.ErrInternal, err)
+		}
+	}
+	// 通貨ごとの上下限（PG が受け付ける範囲）
+	if err := amount.ValidateCharge(); err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrInvalidArgument, err)
+	}
+
 	o := &order.Order{
 		ID:        order.ID(uc.IDGen.New()),
 		UserID:    userID,
-		AmountJPY: amountJPY,
+		Amount:    amount,
 		Status:    order.StatusPending,
 		CreatedAt: uc.Clock.Now(),
 		UpdatedAt: uc.Clock.Now(),
+		Items:     slices.Clone(in.Items),
+		Tax:       taxes,
+	}
+	if red != nil {
+		red.ID = uc.IDGen.New()
+		red.OrderID = string(o.ID)
+		red.UserID = userID
+		red.CreatedAt = o.CreatedAt
+		o.Coupon = red
 	}
 
 	// ---- DB 反映は 3s ----
 	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
-	if err := uc.Repo.Create(dbCtx, o); err != nil {
+	err = uc.Tx.Do(dbCtx, func(dbCtx context.Context) error {
+		if err := uc.Repo.Create(dbCtx, o); err != nil {
+			return err
+		}
+		payload := putAmount(map[string]any{
+			"user_id": o.UserID,
+		}, "amount", o.Amount)
+
+		if len(o.Items) > 0 {
+			if err := uc.Items.CreateBatch(dbCtx, o.ID, o.Items); err != nil {
+				return err
+			}
+			// 請求額の根拠を監査用に残す
+			lines := make([]map[string]any, 0, len(o.Items))
+			for _, it := range o.Items {
+				lines = append(lines, map[string]any{
+					"sku":        it.SKU,
+					"unit_price": it.UnitPrice.Amount,
+					"quantity":   it.Quantity,
+				})
+			}
+			payload["items"] = lines
+
+			if err := uc.Taxes.CreateBatch(dbCtx, o.ID, o.Tax); err != nil {
+				return err
+			}
+			taxLines := make([]map[string]any, 0, len(o.Tax))
+			for _, b := range o.Tax {
+				taxLines = append(taxLines, map[string]any{
+					"rate":    int(b.Rate),
+					"taxable": b.Taxable.Amount,
+					"tax":     b.Tax.Amount,
+				})
+			}
+			payload["tax"] = taxLines
+		}
+		if o.Coupon != nil {
+			// 利用上限は記録時に確認する（同時利用でも超えない）
+			if err := uc.Coupons.Redeem(dbCtx, o.Coupon); err != nil {
+				if errors.Is(err, coupon.ErrUsageLimit) {
+					return fmt.Errorf("%w: %w", domain.ErrConflict, err)
+				}
+				return err
+			}
+			payload["coupon_code"] = o.Coupon.Code
+			putAmount(payload, "discount", o.Coupon.Discount)
+		}
+		return uc.recordEvent(dbCtx, o.ID, event.TypeOrderCreated, payload)
+	})
+	if err != nil {
 		return nil, err
 	}
 	return o, nil
 }
 
+// クーポンを検証し、値引き前の請求額 amount に対する値引き額を計算する
+func (uc *OrderUsecase) discount(ctx context.Context, code string, amount money.Money) (*coupon.Redemption, error) {
+	// ---- DB 読み取りは 3s ----
+	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	c, err := uc.Coupons.FindByCode(dbCtx, coupon.NormalizeCode(code))
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, fmt.Errorf("%w: unknown coupon %q", domain.ErrInvalidArgument, code)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	off, err := c.Discount(amount, uc.Clock.Now())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrInvalidArgument, err)
+	}
+	return &coupon.Redemption{CouponID: c.ID, Code: c.Code, Discount: off}, nil
+}
+
+// 加盟店の端数処理設定（未設定なら既定値）
+func (uc *OrderUsecase) taxRounding(ctx context.Context, userID string) (tax.Rounding, error) {
+	if uc.Merchants == nil {
+		return tax.DefaultRounding, nil
+	}
+
+	// ---- DB 読み取りは 3s ----
+	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	ms, err := uc.Merchants.Get(dbCtx, userID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return tax.DefaultRounding, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return ms.TaxRounding, nil
+}
+
 // --- Pay ---
 
-// 外部決済(PG)はTxの外で行い、DB反映はTxでまとめる
-func (uc *OrderUsecase) PayOrder(ctx context.Context, id order.ID) error {
+// 外部決済(PG)はTxの外で行い、DB反映はTxでまとめる。
+// paymentMethod は顧客の支払い手段（フロントでトークン化した PG 上の ID）
+func (uc *OrderUsecase) PayOrder(ctx context.Context, id order.ID, paymentMethod string) error {
 	isAdmin := auth.IsAdmin(ctx)
 
 	// 一般ユーザは userID 必須。管理者は不要
@@ -76,55 +273,72 @@ func (uc *OrderUsecase) PayOrder(ctx context.Context, id order.ID) error {
 			return domain.ErrUnauthorized
 		}
 	}
+	if paymentMethod == "" {
+		return fmt.Errorf("%w: payment_method is required", domain.ErrInvalidArgument)
+	}
 
 	// 入口ガード（同時実行を1本化）
-	lockKey := "lock:pay:" + string(id)
-
-	ok, token, err := uc.Locker.TryLock(ctx, lockKey, lockTTL)
+	ctx, unlock, err := uc.lockOrder(ctx, id)
 	if err != nil {
 		return err
 	}
-	if !ok {
-		return domain.ErrConflict
-	}
-
-	defer func() {
-		uctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
-		defer cancel()
-		_ = uc.Locker.Unlock(uctx, lockKey, token)
-	}()
+	defer unlock()
 
 	// ---- 注文取得は 3s ----
 	dbReadCtx, cancelRead := context.WithTimeout(ctx, 3*time.Second)
 	defer cancelRead()
 
-	var (
-		o *order.Order
-	)
-
-	if isAdmin {
-		o, err = uc.Repo.FindByID(dbReadCtx, id)
-	} else {
-		o, err = uc.Repo.FindByIDForUser(dbReadCtx, id, userID)
-	}
+	o, err := uc.findOrder(dbReadCtx, id, isAdmin, userID)
 	if err != nil {
 		return err
 	}
-	if o.Status != order.StatusPending {
-		return domain.ErrConflict
+	// 与信済みの注文は capture で確定する（二重請求になる）
+	if o.Status == order.StatusAuthorized {
+		return fmt.Errorf("%w: authorized order must be captured", domain.ErrConflict)
+	}
+	// 払込番号・振込先の発行後にカードで払うと、店頭・振込でも払えてしまう
+	if o.Status == order.StatusAwaitingPayment {
+		return fmt.Errorf("%w: order is awaiting konbini or bank transfer payment", domain.ErrConflict)
+	}
+	if err := checkTransition(o.Status, order.StatusPaid); err != nil {
+		return err
+	}
+
+	intent := domain.PaymentIntent{
+		OrderID:        string(o.ID),
+		Amount:         o.Amount.Amount,
+		Currency:       o.Amount.Currency.Lower(),
+		PaymentMethod:  paymentMethod,
+		IdempotencyKey: paymentKey("pay", o.ID, paymentMethod), // 返金は "refund:" prefix で別キーにする
+	}
+
+	// 請求前に試行を記録（記録できなければ請求しない）
+	if err := uc.recordEventTx(ctx, o.ID, event.TypeChargeAttempted, map[string]any{
+		"amount":          intent.Amount,
+		"currency":        intent.Currency,
+		"idempotency_key": intent.IdempotencyKey,
+	}); err != nil {
+		return err
 	}
 
 	// ---- PG 呼び出しは 5s ----
 	pgCtx, cancelPG := context.WithTimeout(ctx, 5*time.Second)
 	defer cancelPG()
 
-	txID, err := uc.PG.Charge(pgCtx, domain.PaymentIntent{
-		OrderID:        string(o.ID),
-		Amount:         o.AmountJPY,
-		Currency:       CurrencyJPY,
-		IdempotencyKey: "pay:" + string(o.ID), // 他操作(cancel/refund)は将来別prefixで対応
-	})
+	txID, err := uc.PG.Charge(pgCtx, intent)
 	if err != nil {
+		// 3-D Secure 等で結果待ちの場合は PENDING のまま Webhook を待つ
+		typ := event.TypeChargeFailed
+		if errors.Is(err, domain.ErrPaymentPending) {
+			typ = event.TypeChargePending
+		}
+
+		// 失敗の記録はベストエフォート（PG のエラーを優先して返す）
+		if recErr := uc.recordEventTx(ctx, o.ID, typ, map[string]any{
+			"error": err.Error(),
+		}); recErr != nil {
+			log.Printf("warn: record %s event: order_id=%s: %v", typ, o.ID, recErr)
+		}
 		return err
 	}
 
@@ -135,22 +349,247 @@ func (uc *OrderUsecase) PayOrder(ctx context.Context, id order.ID) error {
 	return uc.Tx.Do(dbCtx, func(dbCtx context.Context) error {
 		updatedAt := uc.Clock.Now()
 
-		var rows int64
+		// 所有者チェックは findOrder で済んでいる
+		if err := uc.transition(dbCtx, o, order.StatusPaid, updatedAt); err != nil {
+			return err
+		}
 
-		if isAdmin {
-			rows, err = uc.Repo.UpdateStatusIfPending(dbCtx, o.ID, order.StatusPaid, updatedAt)
-		} else {
-			rows, err = uc.Repo.UpdateStatusIfPendingForUser(dbCtx, o.ID, userID, order.StatusPaid, updatedAt)
+		// PG のトランザクションIDを PAID 化と同じ Tx で記録する
+		p := &payment.Payment{
+			ID:        payment.ID(uc.IDGen.New()),
+			OrderID:   string(o.ID),
+			Method:    payment.MethodCard,
+			Provider:  uc.Provider,
+			TxID:      txID,
+			Amount:    o.Amount,
+			CreatedAt: updatedAt,
 		}
+		if err := uc.Payments.Create(dbCtx, p); err != nil {
+			return err
+		}
+		if err := uc.postCharge(dbCtx, p); err != nil {
+			return err
+		}
+
+		if err := uc.recordEvent(dbCtx, o.ID, event.TypeChargeSucceeded, map[string]any{
+			"provider":       p.Provider,
+			"provider_tx_id": p.TxID,
+		}); err != nil {
+			return err
+		}
+		return uc.recordEvent(dbCtx, o.ID, event.TypeOrderPaid, map[string]any{
+			"payment_id": string(p.ID),
+		})
+	})
+}
+
+// 請求・与信の冪等キー。注文と支払い手段ごとに分ける。
+// 同じカードでのリトライは PG の結果を再利用し、拒否された後に別のカードで払い直すと新しい請求になる
+func paymentKey(prefix string, id order.ID, paymentMethod string) string {
+	sum := sha256.Sum256([]byte(paymentMethod))
+	return prefix + ":" + string(id) + ":" + hex.EncodeToString(sum[:8])
+}
+
+// --- Cancel ---
+
+// 未決済の注文を取り消す。支払いと同じロックで直列化する
+func (uc *OrderUsecase) CancelOrder(ctx context.Context, id order.ID) error {
+	isAdmin := auth.IsAdmin(ctx)
+
+	// 一般ユーザは userID 必須。管理者は不要
+	userID, _ := auth.UserIDFrom(ctx)
+	if !isAdmin && userID == "" {
+		return domain.ErrUnauthorized
+	}
+
+	ctx, unlock, err := uc.lockOrder(ctx, id)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// 支払い待ちの払込番号・振込先も取り消す（PG 呼び出しは Tx の外）
+	if handled, err := uc.cancelAwaitingPayment(ctx, id, isAdmin, userID); handled {
+		return err
+	}
+
+	// ---- DB 反映は 3s ----
+	dbCtx, cancelDB := context.WithTimeout(ctx, 3*time.Second)
+	defer cancelDB()
+
+	return uc.Tx.Do(dbCtx, func(dbCtx context.Context) error {
+		o, err := uc.findOrder(dbCtx, id, isAdmin, userID)
 		if err != nil {
 			return err
 		}
-		if rows == 0 {
-			return domain.ErrConflict
+		// 与信済みの注文は PG 側の与信も取り消す必要があるため void を使う
+		if o.Status == order.StatusAuthorized {
+			return fmt.Errorf("%w: authorized order must be voided", domain.ErrConflict)
 		}
 
-		_ = txID // 将来 payments / events で利用
+		if err := uc.transition(dbCtx, o, order.StatusCanceled, uc.Clock.Now()); err != nil {
+			return err
+		}
+
+		return uc.recordEvent(dbCtx, o.ID, event.TypeOrderCanceled, map[string]any{
+			"canceled_by": userID,
+			"by_admin":    isAdmin,
+		})
+	})
+}
+
+// --- Payments ---
+
+// 注文に紐づく決済記録を返す（一般ユーザは自分の注文のみ）
+func (uc *OrderUsecase) ListPayments(ctx context.Context, id order.ID) ([]*payment.Payment, error) {
+	isAdmin := auth.IsAdmin(ctx)
+
+	userID, _ := auth.UserIDFrom(ctx)
+	if !isAdmin && userID == "" {
+		return nil, domain.ErrUnauthorized
+	}
+
+	// ---- DB 読み取りは 3s ----
+	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	// 所有者チェックを兼ねて注文の存在を確認
+	if _, err := uc.findOrder(dbCtx, id, isAdmin, userID); err != nil {
+		return nil, err
+	}
+
+	return uc.Payments.ListByOrderID(dbCtx, id)
+}
+
+// --- Events ---
+
+// 注文の監査ログ（タイムライン）を古い順に返す
+func (uc *OrderUsecase) ListEvents(ctx context.Context, id order.ID) ([]*event.Event, error) {
+	isAdmin := auth.IsAdmin(ctx)
+
+	userID, _ := auth.UserIDFrom(ctx)
+	if !isAdmin && userID == "" {
+		return nil, domain.ErrUnauthorized
+	}
+
+	// ---- DB 読み取りは 3s ----
+	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	if _, err := uc.findOrder(dbCtx, id, isAdmin, userID); err != nil {
+		return nil, err
+	}
+
+	return uc.Events.ListByOrderID(dbCtx, id)
+}
 
+// --- helpers ---
+
+// 管理者は全注文、一般ユーザは自分の注文のみ取得できる
+func (uc *OrderUsecase) findOrder(ctx context.Context, id order.ID, isAdmin bool, userID string) (*order.Order, error) {
+	if isAdmin {
+		return uc.Repo.FindByID(ctx, id)
+	}
+	return uc.Repo.FindByIDForUser(ctx, id, userID)
+}
+
+// 遷移表にない遷移は ErrConflict として返す（*order.TransitionError も errors.As で取り出せる）
+func checkTransition(from, to order.Status) error {
+	if err := order.CheckTransition(from, to); err != nil {
+		return fmt.Errorf("%w: %w", domain.ErrConflict, err)
+	}
+	return nil
+}
+
+// 遷移表で検証してから、現在のステータスを条件に orders 行を更新する（Tx の中で呼ぶ）
+// 別の処理に先を越された・リースを失い新しい保持者が書き込み済みの場合は ErrConflict
+// CANCELED にする場合はクーポンの利用も取り消す
+func (uc *OrderUsecase) updateStatus(ctx context.Context, id order.ID, from, to order.Status, at time.Time) error {
+	if err := checkTransition(from, to); err != nil {
+		return err
+	}
+	rows, err := uc.Repo.UpdateStatusIf(ctx, id, from, to, fenceFrom(ctx), at)
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return domain.ErrConflict
+	}
+	if to == order.StatusCanceled && uc.Coupons != nil {
+		// 取り消した注文のクーポン利用は同じ Tx で戻す（上限の枠を返す）
+		if err := uc.Coupons.Release(ctx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 読み込んだ注文を to に遷移させて保存する（Tx の中で呼ぶ）
+func (uc *OrderUsecase) transition(ctx context.Context, o *order.Order, to order.Status, at time.Time) error {
+	from := o.Status
+	if err := uc.updateStatus(ctx, o.ID, from, to, at); err != nil {
+		return err
+	}
+	return o.Transition(to, at)
+}
+
+// 状態変更と同じ Tx の中で呼ぶ（ctx に Tx が乗っている前提）
+// 下流システム向けに同じ内容を、この Tx で読んだ注文の状態と合わせて outbox にも書く
+func (uc *OrderUsecase) recordEvent(ctx context.Context, id order.ID, typ event.Type, payload map[string]any) error {
+	e := &event.Event{
+		ID:        event.ID(uc.IDGen.New()),
+		OrderID:   string(id),
+		Type:      typ,
+		Payload:   payload,
+		CreatedAt: uc.Clock.Now(),
+	}
+	if err := uc.Events.Append(ctx, e); err != nil {
+		return err
+	}
+	if uc.Outbox == nil {
 		return nil
+	}
+
+	o, err := uc.Repo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	m, err := outboxMessageFromEvent(e, o)
+	if err != nil {
+		return err
+	}
+	return uc.Outbox.Enqueue(ctx, m)
+}
+
+// 状態変更を伴わないイベントを単独の Tx で記録する
+func (uc *OrderUsecase) recordEventTx(ctx context.Context, id order.ID, typ event.Type, payload map[string]any) error {
+	// ---- DB 反映は 3s ----
+	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	return uc.Tx.Do(dbCtx, func(dbCtx context.Context) error {
+		return uc.recordEvent(dbCtx, id, typ, payload)
 	})
 }
+
+// 金額をイベントの payload に載せる（key と "currency"）
+// 円の場合は既存の購読者向けに従来の key+"_jpy" も残す
+func putAmount(payload map[string]any, key string, m money.Money) map[string]any {
+	payload[key] = m.Amount
+	payload["currency"] = string(m.Currency)
+	if m.Currency == money.JPY {
+		payload[key+"_jpy"] = m.Amount
+	}
+	return payload
+}
+
+// 指定された金額を注文の通貨に揃える。通貨が空なら注文の通貨とみなし、違えば拒否する
+func amountIn(m money.Money, c money.Currency) (money.Money, error) {
+	if m.Currency == "" {
+		m.Currency = c
+	}
+	if m.Currency != c {
+		return money.Money{}, fmt.Errorf("%w: %w", domain.ErrInvalidArgument, money.ErrCurrencyMismatch)
+	}
+	return m, nil
+}
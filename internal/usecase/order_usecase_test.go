@@ -3,12 +3,22 @@ package usecase_test
 import (
 	"context"
 	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
 	"testing"
 	"time"
 
 	"github.com/kazshi01/payment-system/internal/auth"
 	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/event"
+	"github.com/kazshi01/payment-system/internal/domain/merchant"
+	"github.com/kazshi01/payment-system/internal/domain/money"
 	"github.com/kazshi01/payment-system/internal/domain/order"
+	"github.com/kazshi01/payment-system/internal/domain/payment"
+	"github.com/kazshi01/payment-system/internal/domain/refund"
+	"github.com/kazshi01/payment-system/internal/domain/tax"
 	"github.com/kazshi01/payment-system/internal/usecase"
 )
 
@@ -35,9 +45,60 @@ func (p okPG) Charge(ctx context.Context, intent domain.PaymentIntent) (string,
 	return p.txid, p.err
 }
 
-type memRepo struct{ m map[order.ID]*order.Order }
+func (p okPG) Refund(ctx context.Context, req domain.RefundRequest) (string, error) {
+	return "re-" + req.IdempotencyKey, p.err
+}
+
+func (p okPG) Authorize(ctx context.Context, intent domain.PaymentIntent) (string, error) {
+	return "auth-" + p.txid, p.err
+}
+
+func (p okPG) Capture(ctx context.Context, req domain.CaptureRequest) (string, error) {
+	return p.txid, p.err
+}
+
+func (p okPG) Void(ctx context.Context, req domain.VoidRequest) error { return p.err }
+
+// idemPG は PG の冪等キー挙動を真似る。同じキーは前回の結果を返し、
+// 同じキーで支払い手段が違えば ErrConflict（Stripe の idempotency_error 相当）
+type idemPG struct {
+	okPG
+	seen map[string]domain.PaymentIntent
+}
+
+func (p *idemPG) Charge(ctx context.Context, intent domain.PaymentIntent) (string, error) {
+	if prev, ok := p.seen[intent.IdempotencyKey]; ok && prev.PaymentMethod != intent.PaymentMethod {
+		return "", domain.ErrConflict
+	}
+	p.seen[intent.IdempotencyKey] = intent
+	if intent.PaymentMethod == "pm_card_chargeDeclined" {
+		return "", domain.ErrPaymentDeclined
+	}
+	return "tx-" + intent.PaymentMethod, nil
+}
+
+// 同じ冪等キーで金額が違う返金は ErrConflict
+type idemRefundPG struct {
+	okPG
+	seen map[string]int64
+}
+
+func (p *idemRefundPG) Refund(ctx context.Context, req domain.RefundRequest) (string, error) {
+	if amt, ok := p.seen[req.IdempotencyKey]; ok && amt != req.Amount {
+		return "", domain.ErrConflict
+	}
+	p.seen[req.IdempotencyKey] = req.Amount
+	return p.okPG.Refund(ctx, req)
+}
+
+type memRepo struct {
+	m      map[order.ID]*order.Order
+	fences map[order.ID]int64 // orders.lock_fence
+}
 
-func newMemRepo() *memRepo { return &memRepo{m: map[order.ID]*order.Order{}} }
+func newMemRepo() *memRepo {
+	return &memRepo{m: map[order.ID]*order.Order{}, fences: map[order.ID]int64{}}
+}
 
 func (r *memRepo) Create(ctx context.Context, o *order.Order) error {
 	if _, dup := r.m[o.ID]; dup {
@@ -67,52 +128,340 @@ func (r *memRepo) Update(ctx context.Context, o *order.Order) error {
 	return nil
 }
 
-func (r *memRepo) UpdateStatusIfPending(ctx context.Context, id order.ID, st order.Status, at time.Time) (int64, error) {
+func (r *memRepo) FindByIDForUser(ctx context.Context, id order.ID, userID string) (*order.Order, error) {
+	o, err := r.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if o.UserID != userID {
+		return nil, domain.ErrNotFound
+	}
+	return o, nil
+}
+
+func (r *memRepo) UpdateStatusIf(ctx context.Context, id order.ID, from, to order.Status, fence int64, at time.Time) (int64, error) {
 	o, ok := r.m[id]
 	if !ok {
 		return 0, domain.ErrNotFound
 	}
-	if o.Status != order.StatusPending {
+	if o.Status != from {
 		return 0, nil
 	}
-	o.Status = st
+	if fence != 0 && r.fences[id] > fence {
+		return 0, nil
+	}
+	o.Status = to
 	o.UpdatedAt = at
+	r.fences[id] = max(r.fences[id], fence)
 	return 1, nil
 }
 
-func (r *memRepo) FindByIDForUser(ctx context.Context, id order.ID, userID string) (*order.Order, error) {
-	o, err := r.FindByID(ctx, id)
-	if err != nil {
-		return nil, err
+func (r *memRepo) List(ctx context.Context, f domain.OrderListFilter) ([]*order.Order, error) {
+	var out []*order.Order
+	for _, o := range r.m {
+		if f.UserID != "" && o.UserID != f.UserID {
+			continue
+		}
+		if f.Status != "" && o.Status != f.Status {
+			continue
+		}
+		if !f.CreatedFrom.IsZero() && o.CreatedAt.Before(f.CreatedFrom) {
+			continue
+		}
+		if !f.CreatedTo.IsZero() && !o.CreatedAt.Before(f.CreatedTo) {
+			continue
+		}
+		if f.After != nil && !orderBefore(o, f.After.CreatedAt, f.After.ID) {
+			continue
+		}
+		cp := *o
+		out = append(out, &cp)
 	}
-	if o.UserID != userID {
-		return nil, domain.ErrNotFound
+	sort.Slice(out, func(i, j int) bool { return orderBefore(out[j], out[i].CreatedAt, out[i].ID) })
+	if len(out) > f.Limit {
+		out = out[:f.Limit]
 	}
-	return o, nil
+	return out, nil
 }
-func (r *memRepo) UpdateStatusIfPendingForUser(ctx context.Context, id order.ID, userID string, st order.Status, at time.Time) (int64, error) {
-	o, err := r.FindByIDForUser(ctx, id, userID)
-	if err != nil {
-		return 0, err
+
+// (created_at, id) の降順で o が (t, id) より後ろに来るか
+func orderBefore(o *order.Order, t time.Time, id order.ID) bool {
+	if !o.CreatedAt.Equal(t) {
+		return o.CreatedAt.Before(t)
+	}
+	return o.ID < id
+}
+
+type memPaymentRepo struct {
+	m map[order.ID][]*payment.Payment
+}
+
+func newMemPaymentRepo() *memPaymentRepo {
+	return &memPaymentRepo{m: map[order.ID][]*payment.Payment{}}
+}
+
+func (r *memPaymentRepo) Create(ctx context.Context, p *payment.Payment) error {
+	cp := *p
+	id := order.ID(p.OrderID)
+	r.m[id] = append(r.m[id], &cp)
+	return nil
+}
+
+func (r *memPaymentRepo) ListByOrderID(ctx context.Context, id order.ID) ([]*payment.Payment, error) {
+	out := make([]*payment.Payment, 0, len(r.m[id]))
+	for _, p := range r.m[id] {
+		cp := *p
+		out = append(out, &cp)
+	}
+	return out, nil
+}
+
+func (r *memPaymentRepo) FindByProviderTxID(ctx context.Context, provider, txID string) (*payment.Payment, error) {
+	for _, ps := range r.m {
+		for _, p := range ps {
+			if p.Provider == provider && p.TxID == txID {
+				cp := *p
+				return &cp, nil
+			}
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (r *memPaymentRepo) ListByProvider(ctx context.Context, provider string, from, to time.Time) ([]*payment.Payment, error) {
+	var out []*payment.Payment
+	for _, ps := range r.m {
+		for _, p := range ps {
+			if p.Provider == provider && !p.CreatedAt.Before(from) && p.CreatedAt.Before(to) {
+				cp := *p
+				out = append(out, &cp)
+			}
+		}
+	}
+	slices.SortFunc(out, func(a, b *payment.Payment) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	return out, nil
+}
+
+type memRefundRepo struct {
+	payments *memPaymentRepo
+	m        map[order.ID][]*refund.Refund
+}
+
+func newMemRefundRepo(payments *memPaymentRepo) *memRefundRepo {
+	return &memRefundRepo{payments: payments, m: map[order.ID][]*refund.Refund{}}
+}
+
+func (r *memRefundRepo) CreateWithinCaptured(ctx context.Context, rf *refund.Refund) (int64, error) {
+	id := order.ID(rf.OrderID)
+	var captured, total int64
+	for _, p := range r.payments.m[id] {
+		captured += p.Amount.Amount
 	}
-	if o.Status != order.StatusPending {
+	for _, x := range r.m[id] {
+		total += x.Amount.Amount
+	}
+	if total+rf.Amount.Amount > captured {
 		return 0, nil
 	}
-	o.Status = st
-	o.UpdatedAt = at
-	r.m[o.ID] = o
+	cp := *rf
+	r.m[id] = append(r.m[id], &cp)
+	return 1, nil
+}
+
+func (r *memRefundRepo) ListByOrderID(ctx context.Context, id order.ID) ([]*refund.Refund, error) {
+	out := make([]*refund.Refund, 0, len(r.m[id]))
+	for _, x := range r.m[id] {
+		cp := *x
+		out = append(out, &cp)
+	}
+	return out, nil
+}
+
+type memAuthRepo struct {
+	m map[payment.AuthorizationID]*payment.Authorization
+}
+
+func newMemAuthRepo() *memAuthRepo {
+	return &memAuthRepo{m: map[payment.AuthorizationID]*payment.Authorization{}}
+}
+
+func (r *memAuthRepo) Create(ctx context.Context, a *payment.Authorization) error {
+	cp := *a
+	r.m[a.ID] = &cp
+	return nil
+}
+
+func (r *memAuthRepo) FindActiveByOrderID(ctx context.Context, id order.ID) (*payment.Authorization, error) {
+	for _, a := range r.m {
+		if a.OrderID == string(id) && a.Status == payment.AuthorizationAuthorized {
+			cp := *a
+			return &cp, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (r *memAuthRepo) UpdateStatusIf(ctx context.Context, id payment.AuthorizationID, from, to payment.AuthorizationStatus, at time.Time) (int64, error) {
+	a, ok := r.m[id]
+	if !ok || a.Status != from {
+		return 0, nil
+	}
+	a.Status = to
+	a.UpdatedAt = at
 	return 1, nil
 }
 
+func (r *memAuthRepo) ListExpired(ctx context.Context, now time.Time, limit int) ([]*payment.Authorization, error) {
+	var out []*payment.Authorization
+	for _, a := range r.m {
+		if a.Status == payment.AuthorizationAuthorized && !a.ExpiresAt.After(now) && len(out) < limit {
+			cp := *a
+			out = append(out, &cp)
+		}
+	}
+	return out, nil
+}
+
+type memWebhookInbox struct {
+	processed map[string]bool // key: provider + "/" + event_id
+}
+
+func newMemWebhookInbox() *memWebhookInbox {
+	return &memWebhookInbox{processed: map[string]bool{}}
+}
+
+func (r *memWebhookInbox) Receive(ctx context.Context, ev *domain.ProviderEvent, at time.Time) (bool, error) {
+	key := ev.Provider + "/" + ev.ID
+	done, ok := r.processed[key]
+	if !ok {
+		r.processed[key] = false
+	}
+	return done, nil
+}
+
+func (r *memWebhookInbox) MarkProcessed(ctx context.Context, provider, eventID string, at time.Time) error {
+	r.processed[provider+"/"+eventID] = true
+	return nil
+}
+
+// MarkProcessed を fail 回だけ失敗させる
+type failMarkInbox struct {
+	*memWebhookInbox
+	fail int
+}
+
+func (r *failMarkInbox) MarkProcessed(ctx context.Context, provider, eventID string, at time.Time) error {
+	if r.fail > 0 {
+		r.fail--
+		return errors.New("db down")
+	}
+	return r.memWebhookInbox.MarkProcessed(ctx, provider, eventID, at)
+}
+
+type memItemRepo struct{ m map[order.ID][]order.Item }
+
+func newMemItemRepo() *memItemRepo { return &memItemRepo{m: map[order.ID][]order.Item{}} }
+
+func (r *memItemRepo) CreateBatch(ctx context.Context, id order.ID, items []order.Item) error {
+	r.m[id] = slices.Clone(items)
+	return nil
+}
+
+func (r *memItemRepo) ListByOrderID(ctx context.Context, id order.ID) ([]order.Item, error) {
+	return slices.Clone(r.m[id]), nil
+}
+
+type memTaxRepo struct{ m map[order.ID][]tax.Bucket }
+
+func newMemTaxRepo() *memTaxRepo { return &memTaxRepo{m: map[order.ID][]tax.Bucket{}} }
+
+func (r *memTaxRepo) CreateBatch(ctx context.Context, id order.ID, buckets []tax.Bucket) error {
+	r.m[id] = slices.Clone(buckets)
+	return nil
+}
+
+func (r *memTaxRepo) ListByOrderID(ctx context.Context, id order.ID) ([]tax.Bucket, error) {
+	return slices.Clone(r.m[id]), nil
+}
+
+type memMerchantRepo struct{ m map[string]merchant.Settings }
+
+func (r *memMerchantRepo) Get(ctx context.Context, userID string) (*merchant.Settings, error) {
+	s, ok := r.m[userID]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return &s, nil
+}
+
+func (r *memMerchantRepo) Upsert(ctx context.Context, s *merchant.Settings) error {
+	r.m[s.UserID] = *s
+	return nil
+}
+
+type memEventRepo struct{ m map[order.ID][]*event.Event }
+
+func newMemEventRepo() *memEventRepo { return &memEventRepo{m: map[order.ID][]*event.Event{}} }
+
+func (r *memEventRepo) Append(ctx context.Context, e *event.Event) error {
+	cp := *e
+	id := order.ID(e.OrderID)
+	r.m[id] = append(r.m[id], &cp)
+	return nil
+}
+
+func (r *memEventRepo) ListByOrderID(ctx context.Context, id order.ID) ([]*event.Event, error) {
+	out := make([]*event.Event, 0, len(r.m[id]))
+	for _, e := range r.m[id] {
+		cp := *e
+		out = append(out, &cp)
+	}
+	return out, nil
+}
+
+func eventTypes(es []*event.Event) []event.Type {
+	ts := make([]event.Type, 0, len(es))
+	for _, e := range es {
+		ts = append(ts, e.Type)
+	}
+	return ts
+}
+
 // Locker ダミー（常にロック成功）
 type okLocker struct{}
 
-func (okLocker) TryLock(ctx context.Context, key string, ttlSeconds int) (bool, string, error) {
-	return true, "tok", nil
+func (okLocker) TryLock(ctx context.Context, key string, ttl time.Duration) (bool, domain.Lock, error) {
+	return true, domain.Lock{Key: key, Token: "tok", Fence: 1}, nil
+}
+func (okLocker) Extend(ctx context.Context, lock domain.Lock, ttl time.Duration) (bool, error) {
+	return true, nil
+}
+func (okLocker) Unlock(ctx context.Context, lock domain.Lock) error { return nil }
+func (okLocker) Ping(ctx context.Context) error                     { return nil }
+func (okLocker) Close() error                                       { return nil }
+
+// Locker ダミー（常にロック失敗＝他で処理中）
+type busyLocker struct{ okLocker }
+
+func (busyLocker) TryLock(ctx context.Context, key string, ttl time.Duration) (bool, domain.Lock, error) {
+	return false, domain.Lock{}, nil
+}
+
+// 呼び出し毎に連番IDを返す
+type seqIDGen struct{ n *int }
+
+func (g seqIDGen) New() string {
+	*g.n++
+	return "id-" + strconv.Itoa(*g.n)
+}
+
+// 呼び出し毎に1分進む時計
+type tickClock struct{ t *time.Time }
+
+func (c tickClock) Now() time.Time {
+	*c.t = c.t.Add(time.Minute)
+	return *c.t
 }
-func (okLocker) Unlock(ctx context.Context, key, token string) error { return nil }
-func (okLocker) Ping(ctx context.Context) error                      { return nil }
-func (okLocker) Close() error                                        { return nil }
 
 // ---------- ユーティリティ ----------
 
@@ -124,22 +473,36 @@ func ctxWithUser(userID string) context.Context {
 	return context.WithValue(context.Background(), auth.ClaimsKey, claims)
 }
 
+func jpy(n int64) money.Money { return money.Money{Amount: n, Currency: money.JPY} }
+
+func ctxWithAdmin(userID string) context.Context {
+	claims := map[string]any{
+		"sub": userID,
+		"realm_access": map[string]any{
+			"roles": []any{"payment_admin"},
+		},
+	}
+	return context.WithValue(context.Background(), auth.ClaimsKey, claims)
+}
+
 // ---------- テスト ----------
 
 func TestOrderUsecase_CreateOrder_ok(t *testing.T) {
 	repo := newMemRepo()
 	uc := &usecase.OrderUsecase{
-		Repo:   repo,
-		Tx:     nopTx{},
-		PG:     okPG{txid: "tx1"},
-		Clock:  fixedClock{t: time.Date(2025, 9, 27, 10, 0, 0, 0, time.Local)},
-		IDGen:  fixedIDGen{v: "order-1"},
-		Locker: okLocker{},
+		Repo:     repo,
+		Payments: newMemPaymentRepo(),
+		Events:   newMemEventRepo(),
+		Tx:       nopTx{},
+		PG:       okPG{txid: "tx1"},
+		Clock:    fixedClock{t: time.Date(2025, 9, 27, 10, 0, 0, 0, time.Local)},
+		IDGen:    fixedIDGen{v: "order-1"},
+		Locker:   okLocker{},
 	}
 
 	ctx := ctxWithUser("user-1")
 
-	o, err := uc.CreateOrder(ctx, 1200)
+	o, err := uc.CreateOrder(ctx, usecase.CreateOrderInput{Amount: jpy(1200)})
 	if err != nil {
 		t.Fatalf("CreateOrder err = %v", err)
 	}
@@ -154,7 +517,7 @@ func TestOrderUsecase_CreateOrder_ok(t *testing.T) {
 	if err != nil {
 		t.Fatalf("repo.FindByID err = %v", err)
 	}
-	if stored.AmountJPY != 1200 || stored.UserID != "user-1" {
+	if stored.Amount.Amount != 1200 || stored.UserID != "user-1" {
 		t.Fatalf("stored mismatch: %+v", stored)
 	}
 }
@@ -163,57 +526,182 @@ func TestOrderUsecase_PayOrder_ok(t *testing.T) {
 	repo := newMemRepo()
 	now := time.Date(2025, 9, 27, 10, 0, 0, 0, time.Local)
 	uc := &usecase.OrderUsecase{
-		Repo:   repo,
-		Tx:     nopTx{},
-		PG:     okPG{txid: "tx-abc"},
-		Clock:  fixedClock{t: now},
-		IDGen:  fixedIDGen{v: "order-1"},
-		Locker: okLocker{},
+		Repo:     repo,
+		Payments: newMemPaymentRepo(),
+		Events:   newMemEventRepo(),
+		Tx:       nopTx{},
+		PG:       okPG{txid: "tx-abc"},
+		Clock:    fixedClock{t: now},
+		IDGen:    fixedIDGen{v: "order-1"},
+		Locker:   okLocker{},
 	}
 
 	// 事前に注文を作成
 	ctx := ctxWithUser("user-1")
-	o, _ := uc.CreateOrder(ctx, 1000)
+	o, _ := uc.CreateOrder(ctx, usecase.CreateOrderInput{Amount: jpy(1000)})
 
-	if err := uc.PayOrder(ctx, o.ID); err != nil {
+	if err := uc.PayOrder(ctx, o.ID, "pm_card_visa"); err != nil {
 		t.Fatalf("PayOrder err = %v", err)
 	}
 	got, _ := repo.FindByID(context.Background(), o.ID)
 	if got.Status != order.StatusPaid {
 		t.Fatalf("status = %s; want PAID", got.Status)
 	}
+
+	ps, err := uc.ListPayments(ctx, o.ID)
+	if err != nil {
+		t.Fatalf("ListPayments err = %v", err)
+	}
+	if len(ps) != 1 || ps[0].TxID != "tx-abc" || ps[0].OrderID != string(o.ID) {
+		t.Fatalf("payments mismatch: %+v", ps)
+	}
+
+	es, err := uc.ListEvents(ctx, o.ID)
+	if err != nil {
+		t.Fatalf("ListEvents err = %v", err)
+	}
+	want := []event.Type{
+		event.TypeOrderCreated,
+		event.TypeChargeAttempted,
+		event.TypeChargeSucceeded,
+		event.TypeOrderPaid,
+	}
+	if got := eventTypes(es); !slices.Equal(got, want) {
+		t.Fatalf("events = %v; want %v", got, want)
+	}
+}
+
+func TestOrderUsecase_CreateOrder_items(t *testing.T) {
+	items := newMemItemRepo()
+	uc := &usecase.OrderUsecase{
+		Repo:     newMemRepo(),
+		Items:    items,
+		Taxes:    newMemTaxRepo(),
+		Payments: newMemPaymentRepo(),
+		Events:   newMemEventRepo(),
+		Tx:       nopTx{},
+		PG:       okPG{},
+		Clock:    fixedClock{t: time.Now()},
+		IDGen:    fixedIDGen{v: "x"},
+		Locker:   okLocker{},
+	}
+	ctx := ctxWithUser("user-1")
+	lines := []order.Item{
+		{SKU: "A-1", Name: "コーヒー豆", UnitPrice: jpy(1200), Quantity: 2, TaxRate: tax.RateReduced},
+		{SKU: "B-1", Name: "フィルター", UnitPrice: jpy(300), Quantity: 1, TaxRate: tax.RateStandard},
+	}
+
+	// 税込合計と食い違う金額は拒否（税抜合計 2700 も不可）
+	for _, a := range []int64{100, 2700} {
+		if _, err := uc.CreateOrder(ctx, usecase.CreateOrderInput{Amount: jpy(a), Items: lines}); !errors.Is(err, domain.ErrInvalidArgument) {
+			t.Fatalf("amount %d: err = %v; want ErrInvalidArgument", a, err)
+		}
+	}
+
+	o, err := uc.CreateOrder(ctx, usecase.CreateOrderInput{Items: lines})
+	if err != nil {
+		t.Fatalf("CreateOrder err = %v", err)
+	}
+	// 2400 × 8% = 192, 300 × 10% = 30
+	if o.Amount != jpy(2922) {
+		t.Fatalf("amount = %v; want 2922 JPY", o.Amount)
+	}
+	if len(items.m[o.ID]) != 2 {
+		t.Fatalf("stored items = %+v", items.m[o.ID])
+	}
+
+	got, err := uc.GetOrder(ctx, o.ID)
+	if err != nil {
+		t.Fatalf("GetOrder err = %v", err)
+	}
+	if !slices.Equal(got.Items, lines) {
+		t.Fatalf("items = %+v; want %+v", got.Items, lines)
+	}
+	wantTax := []tax.Bucket{
+		{Rate: tax.RateStandard, Taxable: jpy(300), Tax: jpy(30)},
+		{Rate: tax.RateReduced, Taxable: jpy(2400), Tax: jpy(192)},
+	}
+	if !slices.Equal(got.Tax, wantTax) {
+		t.Fatalf("tax = %+v; want %+v", got.Tax, wantTax)
+	}
+
+	es, _ := uc.ListEvents(ctx, o.ID)
+	if len(es) != 1 || es[0].Payload["items"] == nil || es[0].Payload["tax"] == nil {
+		t.Fatalf("ORDER_CREATED payload = %+v", es)
+	}
+}
+
+func TestOrderUsecase_CreateOrder_merchantTaxRounding(t *testing.T) {
+	merchants := &memMerchantRepo{m: map[string]merchant.Settings{
+		"user-ceil": {UserID: "user-ceil", TaxRounding: tax.RoundingCeil},
+	}}
+	n := 0
+	uc := &usecase.OrderUsecase{
+		Repo:      newMemRepo(),
+		Items:     newMemItemRepo(),
+		Taxes:     newMemTaxRepo(),
+		Events:    newMemEventRepo(),
+		Tx:        nopTx{},
+		Clock:     fixedClock{t: time.Now()},
+		IDGen:     seqIDGen{n: &n},
+		Merchants: merchants,
+	}
+	// 333 × 3 = 999、999 × 8% = 79.92
+	lines := []order.Item{
+		{SKU: "A-1", Name: "おにぎり", UnitPrice: jpy(333), Quantity: 3, TaxRate: tax.RateReduced},
+	}
+
+	for _, tc := range []struct {
+		user string
+		want int64
+	}{
+		{"user-default", 999 + 79}, // 未設定は切り捨て
+		{"user-ceil", 999 + 80},
+	} {
+		o, err := uc.CreateOrder(ctxWithUser(tc.user), usecase.CreateOrderInput{Items: lines})
+		if err != nil {
+			t.Fatalf("%s: CreateOrder err = %v", tc.user, err)
+		}
+		if o.Amount != jpy(tc.want) {
+			t.Fatalf("%s: amount = %v; want %d JPY", tc.user, o.Amount, tc.want)
+		}
+	}
 }
 
 // ---------- エラーテスト ----------
 
 func TestOrderUsecase_CreateOrder_invalidAmount(t *testing.T) {
 	uc := &usecase.OrderUsecase{
-		Repo:   newMemRepo(),
-		Tx:     nopTx{},
-		PG:     okPG{},
-		Clock:  fixedClock{t: time.Now()},
-		IDGen:  fixedIDGen{v: "x"},
-		Locker: okLocker{},
+		Repo:     newMemRepo(),
+		Payments: newMemPaymentRepo(),
+		Events:   newMemEventRepo(),
+		Tx:       nopTx{},
+		PG:       okPG{},
+		Clock:    fixedClock{t: time.Now()},
+		IDGen:    fixedIDGen{v: "x"},
+		Locker:   okLocker{},
 	}
 	ctx := ctxWithUser("user-1")
 
-	if _, err := uc.CreateOrder(ctx, 0); !errors.Is(err, domain.ErrInvalidArgument) {
+	if _, err := uc.CreateOrder(ctx, usecase.CreateOrderInput{Amount: jpy(0)}); !errors.Is(err, domain.ErrInvalidArgument) {
 		t.Fatalf("err = %v; want ErrInvalidArgument", err)
 	}
 }
 
 func TestOrderUsecase_PayOrder_notFound(t *testing.T) {
 	uc := &usecase.OrderUsecase{
-		Repo:   newMemRepo(),
-		Tx:     nopTx{},
-		PG:     okPG{},
-		Clock:  fixedClock{t: time.Now()},
-		IDGen:  fixedIDGen{v: "x"},
-		Locker: okLocker{},
+		Repo:     newMemRepo(),
+		Payments: newMemPaymentRepo(),
+		Events:   newMemEventRepo(),
+		Tx:       nopTx{},
+		PG:       okPG{},
+		Clock:    fixedClock{t: time.Now()},
+		IDGen:    fixedIDGen{v: "x"},
+		Locker:   okLocker{},
 	}
 	ctx := ctxWithUser("user-1")
 
-	err := uc.PayOrder(ctx, "unknown-id")
+	err := uc.PayOrder(ctx, "unknown-id", "pm_card_visa")
 	if !errors.Is(err, domain.ErrNotFound) {
 		t.Fatalf("err = %v; want ErrNotFound", err)
 	}
@@ -221,40 +709,891 @@ func TestOrderUsecase_PayOrder_notFound(t *testing.T) {
 
 func TestOrderUsecase_PayOrder_wrongUser(t *testing.T) {
 	uc := &usecase.OrderUsecase{
-		Repo:   newMemRepo(),
-		Tx:     nopTx{},
-		PG:     okPG{},
-		Clock:  fixedClock{t: time.Now()},
-		IDGen:  fixedIDGen{v: "x"},
-		Locker: okLocker{},
+		Repo:     newMemRepo(),
+		Payments: newMemPaymentRepo(),
+		Events:   newMemEventRepo(),
+		Tx:       nopTx{},
+		PG:       okPG{},
+		Clock:    fixedClock{t: time.Now()},
+		IDGen:    fixedIDGen{v: "x"},
+		Locker:   okLocker{},
 	}
 
-	o, _ := uc.CreateOrder(ctxWithUser("user-1"), 1200)
+	o, _ := uc.CreateOrder(ctxWithUser("user-1"), usecase.CreateOrderInput{Amount: jpy(1200)})
 
-	err := uc.PayOrder(ctxWithUser("user-2"), o.ID)
+	err := uc.PayOrder(ctxWithUser("user-2"), o.ID, "pm_card_visa")
 	if !errors.Is(err, domain.ErrNotFound) {
 		t.Fatalf("err = %v; want ErrNotFound", err)
 	}
 }
 
+func TestOrderUsecase_PayOrder_missingPaymentMethod(t *testing.T) {
+	uc, repo := newRefundTestUsecase()
+	ctx := ctxWithUser("user-1")
+
+	o, _ := uc.CreateOrder(ctx, usecase.CreateOrderInput{Amount: jpy(1200)})
+
+	if err := uc.PayOrder(ctx, o.ID, ""); !errors.Is(err, domain.ErrInvalidArgument) {
+		t.Fatalf("PayOrder err = %v; want ErrInvalidArgument", err)
+	}
+	if _, err := uc.AuthorizeOrder(ctx, o.ID, ""); !errors.Is(err, domain.ErrInvalidArgument) {
+		t.Fatalf("AuthorizeOrder err = %v; want ErrInvalidArgument", err)
+	}
+	got, _ := repo.FindByID(context.Background(), o.ID)
+	if got.Status != order.StatusPending {
+		t.Fatalf("status = %s; want PENDING", got.Status)
+	}
+}
+
 func TestOrderUsecase_PayOrder_alreadyPaid(t *testing.T) {
 	uc := &usecase.OrderUsecase{
-		Repo:   newMemRepo(),
-		Tx:     nopTx{},
-		PG:     okPG{},
-		Clock:  fixedClock{t: time.Now()},
-		IDGen:  fixedIDGen{v: "x"},
-		Locker: okLocker{},
+		Repo:     newMemRepo(),
+		Payments: newMemPaymentRepo(),
+		Events:   newMemEventRepo(),
+		Tx:       nopTx{},
+		PG:       okPG{},
+		Clock:    fixedClock{t: time.Now()},
+		IDGen:    fixedIDGen{v: "x"},
+		Locker:   okLocker{},
 	}
 	ctx := ctxWithUser("user-1")
 
-	o, _ := uc.CreateOrder(ctx, 1200)
+	o, _ := uc.CreateOrder(ctx, usecase.CreateOrderInput{Amount: jpy(1200)})
 
-	if err := uc.PayOrder(ctx, o.ID); err != nil {
+	if err := uc.PayOrder(ctx, o.ID, "pm_card_visa"); err != nil {
 		t.Fatalf("unexpected err: %v", err)
 	}
 
-	if err := uc.PayOrder(ctx, o.ID); !errors.Is(err, domain.ErrConflict) {
+	err := uc.PayOrder(ctx, o.ID, "pm_card_visa")
+	if !errors.Is(err, domain.ErrConflict) {
 		t.Fatalf("err = %v; want ErrConflict", err)
 	}
+	var te *order.TransitionError
+	if !errors.As(err, &te) || te.From != order.StatusPaid || te.To != order.StatusPaid {
+		t.Fatalf("err = %v; want TransitionError PAID -> PAID", err)
+	}
+}
+
+func TestOrderUsecase_ListPayments_wrongUser(t *testing.T) {
+	uc := &usecase.OrderUsecase{
+		Repo:     newMemRepo(),
+		Payments: newMemPaymentRepo(),
+		Events:   newMemEventRepo(),
+		Tx:       nopTx{},
+		PG:       okPG{txid: "tx1"},
+		Clock:    fixedClock{t: time.Now()},
+		IDGen:    fixedIDGen{v: "x"},
+		Locker:   okLocker{},
+	}
+
+	ctx := ctxWithUser("user-1")
+	o, _ := uc.CreateOrder(ctx, usecase.CreateOrderInput{Amount: jpy(1200)})
+	if err := uc.PayOrder(ctx, o.ID, "pm_card_visa"); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	if _, err := uc.ListPayments(ctxWithUser("user-2"), o.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("err = %v; want ErrNotFound", err)
+	}
+}
+
+func TestOrderUsecase_PayOrder_chargeFailedRecorded(t *testing.T) {
+	pgErr := errors.New("card declined")
+	uc := &usecase.OrderUsecase{
+		Repo:     newMemRepo(),
+		Payments: newMemPaymentRepo(),
+		Events:   newMemEventRepo(),
+		Tx:       nopTx{},
+		PG:       okPG{err: pgErr},
+		Clock:    fixedClock{t: time.Now()},
+		IDGen:    fixedIDGen{v: "x"},
+		Locker:   okLocker{},
+	}
+	ctx := ctxWithUser("user-1")
+
+	o, _ := uc.CreateOrder(ctx, usecase.CreateOrderInput{Amount: jpy(1200)})
+
+	if err := uc.PayOrder(ctx, o.ID, "pm_card_visa"); !errors.Is(err, pgErr) {
+		t.Fatalf("err = %v; want %v", err, pgErr)
+	}
+
+	es, err := uc.ListEvents(ctx, o.ID)
+	if err != nil {
+		t.Fatalf("ListEvents err = %v", err)
+	}
+	want := []event.Type{
+		event.TypeOrderCreated,
+		event.TypeChargeAttempted,
+		event.TypeChargeFailed,
+	}
+	if got := eventTypes(es); !slices.Equal(got, want) {
+		t.Fatalf("events = %v; want %v", got, want)
+	}
+}
+
+func TestOrderUsecase_PayOrder_declinedThenOtherCard(t *testing.T) {
+	repo := newMemRepo()
+	uc := &usecase.OrderUsecase{
+		Repo:     repo,
+		Payments: newMemPaymentRepo(),
+		Events:   newMemEventRepo(),
+		Tx:       nopTx{},
+		PG:       &idemPG{seen: map[string]domain.PaymentIntent{}},
+		Clock:    fixedClock{t: time.Now()},
+		IDGen:    fixedIDGen{v: "order-1"},
+		Locker:   okLocker{},
+	}
+	ctx := ctxWithUser("user-1")
+	o, _ := uc.CreateOrder(ctx, usecase.CreateOrderInput{Amount: jpy(1200)})
+
+	if err := uc.PayOrder(ctx, o.ID, "pm_card_chargeDeclined"); !errors.Is(err, domain.ErrPaymentDeclined) {
+		t.Fatalf("first PayOrder err = %v; want ErrPaymentDeclined", err)
+	}
+	// 別のカードでの払い直しは前回の拒否を再生せず、新しい請求として通る
+	if err := uc.PayOrder(ctx, o.ID, "pm_card_visa"); err != nil {
+		t.Fatalf("second PayOrder err = %v", err)
+	}
+	got, _ := repo.FindByID(context.Background(), o.ID)
+	if got.Status != order.StatusPaid {
+		t.Fatalf("status = %s; want PAID", got.Status)
+	}
+
+	es, _ := uc.ListEvents(ctx, o.ID)
+	var keys []any
+	for _, e := range es {
+		if e.Type == event.TypeChargeAttempted {
+			keys = append(keys, e.Payload["idempotency_key"])
+		}
+	}
+	if len(keys) != 2 || keys[0] == nil || keys[0] == keys[1] {
+		t.Fatalf("attempt keys = %v; want two distinct keys", keys)
+	}
+}
+
+func TestOrderUsecase_CancelOrder_ok(t *testing.T) {
+	repo := newMemRepo()
+	uc := &usecase.OrderUsecase{
+		Repo:     repo,
+		Payments: newMemPaymentRepo(),
+		Events:   newMemEventRepo(),
+		Tx:       nopTx{},
+		PG:       okPG{txid: "tx1"},
+		Clock:    fixedClock{t: time.Now()},
+		IDGen:    fixedIDGen{v: "x"},
+		Locker:   okLocker{},
+	}
+	ctx := ctxWithUser("user-1")
+
+	o, _ := uc.CreateOrder(ctx, usecase.CreateOrderInput{Amount: jpy(1200)})
+
+	if err := uc.CancelOrder(ctx, o.ID); err != nil {
+		t.Fatalf("CancelOrder err = %v", err)
+	}
+	got, _ := repo.FindByID(context.Background(), o.ID)
+	if got.Status != order.StatusCanceled {
+		t.Fatalf("status = %s; want CANCELED", got.Status)
+	}
+
+	// 取り消し後は支払えない
+	if err := uc.PayOrder(ctx, o.ID, "pm_card_visa"); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("PayOrder err = %v; want ErrConflict", err)
+	}
+
+	es, _ := uc.ListEvents(ctx, o.ID)
+	want := []event.Type{event.TypeOrderCreated, event.TypeOrderCanceled}
+	if got := eventTypes(es); !slices.Equal(got, want) {
+		t.Fatalf("events = %v; want %v", got, want)
+	}
+}
+
+func TestOrderUsecase_CancelOrder_alreadyPaid(t *testing.T) {
+	uc := &usecase.OrderUsecase{
+		Repo:     newMemRepo(),
+		Payments: newMemPaymentRepo(),
+		Events:   newMemEventRepo(),
+		Tx:       nopTx{},
+		PG:       okPG{txid: "tx1"},
+		Clock:    fixedClock{t: time.Now()},
+		IDGen:    fixedIDGen{v: "x"},
+		Locker:   okLocker{},
+	}
+	ctx := ctxWithUser("user-1")
+
+	o, _ := uc.CreateOrder(ctx, usecase.CreateOrderInput{Amount: jpy(1200)})
+	if err := uc.PayOrder(ctx, o.ID, "pm_card_visa"); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	if err := uc.CancelOrder(ctx, o.ID); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("err = %v; want ErrConflict", err)
+	}
+}
+
+func TestOrderUsecase_CancelOrder_wrongUser(t *testing.T) {
+	uc := &usecase.OrderUsecase{
+		Repo:     newMemRepo(),
+		Payments: newMemPaymentRepo(),
+		Events:   newMemEventRepo(),
+		Tx:       nopTx{},
+		PG:       okPG{},
+		Clock:    fixedClock{t: time.Now()},
+		IDGen:    fixedIDGen{v: "x"},
+		Locker:   okLocker{},
+	}
+
+	o, _ := uc.CreateOrder(ctxWithUser("user-1"), usecase.CreateOrderInput{Amount: jpy(1200)})
+
+	if err := uc.CancelOrder(ctxWithUser("user-2"), o.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("err = %v; want ErrNotFound", err)
+	}
+}
+
+func TestOrderUsecase_CancelOrder_locked(t *testing.T) {
+	repo := newMemRepo()
+	uc := &usecase.OrderUsecase{
+		Repo:     repo,
+		Payments: newMemPaymentRepo(),
+		Events:   newMemEventRepo(),
+		Tx:       nopTx{},
+		PG:       okPG{},
+		Clock:    fixedClock{t: time.Now()},
+		IDGen:    fixedIDGen{v: "x"},
+		Locker:   busyLocker{},
+	}
+	ctx := ctxWithUser("user-1")
+
+	o, _ := uc.CreateOrder(ctx, usecase.CreateOrderInput{Amount: jpy(1200)})
+
+	// 支払い処理中（ロック保持中）は取り消せない
+	if err := uc.CancelOrder(ctx, o.ID); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("err = %v; want ErrConflict", err)
+	}
+	got, _ := repo.FindByID(context.Background(), o.ID)
+	if got.Status != order.StatusPending {
+		t.Fatalf("status = %s; want PENDING", got.Status)
+	}
+}
+
+func newRefundTestUsecase() (*usecase.OrderUsecase, *memRepo) {
+	repo := newMemRepo()
+	payments := newMemPaymentRepo()
+	return &usecase.OrderUsecase{
+		Repo:     repo,
+		Payments: payments,
+		Events:   newMemEventRepo(),
+		Refunds:  newMemRefundRepo(payments),
+		Auths:    newMemAuthRepo(),
+		Tx:       nopTx{},
+		PG:       okPG{txid: "tx1"},
+		Clock:    fixedClock{t: time.Now()},
+		IDGen:    fixedIDGen{v: "x"},
+		Locker:   okLocker{},
+	}, repo
+}
+
+func TestOrderUsecase_RefundOrder_partialThenFull(t *testing.T) {
+	uc, repo := newRefundTestUsecase()
+	ctx := ctxWithUser("user-1")
+	admin := ctxWithAdmin("admin-1")
+
+	o, _ := uc.CreateOrder(ctx, usecase.CreateOrderInput{Amount: jpy(1000)})
+	if err := uc.PayOrder(ctx, o.ID, "pm_card_visa"); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	rf, err := uc.RefundOrder(admin, o.ID, jpy(300), "damaged")
+	if err != nil {
+		t.Fatalf("RefundOrder err = %v", err)
+	}
+	if rf.Amount.Amount != 300 || rf.IdempotencyKey != "refund:x:1:300" {
+		t.Fatalf("refund mismatch: %+v", rf)
+	}
+	got, _ := repo.FindByID(context.Background(), o.ID)
+	if got.Status != order.StatusPartiallyRefunded {
+		t.Fatalf("status = %s; want PARTIALLY_REFUNDED", got.Status)
+	}
+
+	// 残額を超える返金は不可
+	if _, err := uc.RefundOrder(admin, o.ID, jpy(701), ""); !errors.Is(err, domain.ErrInvalidArgument) {
+		t.Fatalf("err = %v; want ErrInvalidArgument", err)
+	}
+
+	// amount=0 は残額を全額返金
+	rf, err = uc.RefundOrder(admin, o.ID, money.Money{}, "")
+	if err != nil {
+		t.Fatalf("RefundOrder err = %v", err)
+	}
+	if rf.Amount.Amount != 700 || rf.IdempotencyKey != "refund:x:2:700" {
+		t.Fatalf("refund mismatch: %+v", rf)
+	}
+	got, _ = repo.FindByID(context.Background(), o.ID)
+	if got.Status != order.StatusRefunded {
+		t.Fatalf("status = %s; want REFUNDED", got.Status)
+	}
+
+	if _, err := uc.RefundOrder(admin, o.ID, jpy(1), ""); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("err = %v; want ErrConflict", err)
+	}
+}
+
+// 通貨を記録する PG
+type currencyPG struct {
+	okPG
+	got *[]string
+}
+
+func (p currencyPG) Charge(ctx context.Context, intent domain.PaymentIntent) (string, error) {
+	*p.got = append(*p.got, intent.Currency)
+	return p.okPG.Charge(ctx, intent)
+}
+
+func (p currencyPG) Refund(ctx context.Context, req domain.RefundRequest) (string, error) {
+	*p.got = append(*p.got, req.Currency)
+	return p.okPG.Refund(ctx, req)
+}
+
+// PG の返金が通って DB 反映に失敗した後、金額を変えて出し直せる
+func TestOrderUsecase_RefundOrder_retryWithOtherAmount(t *testing.T) {
+	uc, repo := newRefundTestUsecase()
+	ctx := ctxWithUser("user-1")
+	admin := ctxWithAdmin("admin-1")
+
+	o, _ := uc.CreateOrder(ctx, usecase.CreateOrderInput{Amount: jpy(1000)})
+	if err := uc.PayOrder(ctx, o.ID, "pm_card_visa"); err != nil {
+		t.Fatalf("PayOrder err = %v", err)
+	}
+
+	uc.PG = &idemRefundPG{okPG: okPG{txid: "tx1"}, seen: map[string]int64{}}
+	failed := false
+	uc.Tx = failOnceTx{failed: &failed}
+	if _, err := uc.RefundOrder(admin, o.ID, jpy(300), "damaged"); err == nil {
+		t.Fatal("RefundOrder err = nil; want db error")
+	}
+
+	rf, err := uc.RefundOrder(admin, o.ID, jpy(200), "damaged")
+	if err != nil {
+		t.Fatalf("RefundOrder (other amount) err = %v", err)
+	}
+	if rf.Amount != jpy(200) {
+		t.Fatalf("refund = %+v; want 200 JPY", rf)
+	}
+	got, _ := repo.FindByID(context.Background(), o.ID)
+	if got.Status != order.StatusPartiallyRefunded {
+		t.Fatalf("status = %s; want PARTIALLY_REFUNDED", got.Status)
+	}
+}
+
+func TestOrderUsecase_multiCurrency(t *testing.T) {
+	uc, _ := newRefundTestUsecase()
+	var got []string
+	uc.PG = currencyPG{okPG: okPG{txid: "tx1"}, got: &got}
+	ctx := ctxWithUser("user-1")
+	admin := ctxWithAdmin("admin-1")
+
+	// 通貨ごとの下限・未対応通貨
+	for _, m := range []money.Money{{Amount: 49, Currency: money.USD}, {Amount: 1000, Currency: "XXX"}} {
+		if _, err := uc.CreateOrder(ctx, usecase.CreateOrderInput{Amount: m}); !errors.Is(err, domain.ErrInvalidArgument) {
+			t.Fatalf("CreateOrder(%v) err = %v; want ErrInvalidArgument", m, err)
+		}
+	}
+
+	o, err := uc.CreateOrder(ctx, usecase.CreateOrderInput{Amount: money.Money{Amount: 1999, Currency: money.USD}})
+	if err != nil {
+		t.Fatalf("CreateOrder err = %v", err)
+	}
+	if err := uc.PayOrder(ctx, o.ID, "pm_card_visa"); err != nil {
+		t.Fatalf("PayOrder err = %v", err)
+	}
+
+	// 注文と違う通貨では返金できない
+	if _, err := uc.RefundOrder(admin, o.ID, jpy(100), ""); !errors.Is(err, domain.ErrInvalidArgument) {
+		t.Fatalf("err = %v; want ErrInvalidArgument", err)
+	}
+
+	// 通貨省略は注文の通貨
+	rf, err := uc.RefundOrder(admin, o.ID, money.Money{Amount: 500}, "")
+	if err != nil {
+		t.Fatalf("RefundOrder err = %v", err)
+	}
+	if rf.Amount != (money.Money{Amount: 500, Currency: money.USD}) {
+		t.Fatalf("refund amount = %v; want 5.00 USD", rf.Amount)
+	}
+	if !slices.Equal(got, []string{"usd", "usd"}) {
+		t.Fatalf("PG currencies = %v; want [usd usd]", got)
+	}
+}
+
+func TestOrderUsecase_RefundOrder_notPaid(t *testing.T) {
+	uc, _ := newRefundTestUsecase()
+
+	o, _ := uc.CreateOrder(ctxWithUser("user-1"), usecase.CreateOrderInput{Amount: jpy(1000)})
+
+	if _, err := uc.RefundOrder(ctxWithAdmin("admin-1"), o.ID, jpy(100), ""); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("err = %v; want ErrConflict", err)
+	}
+}
+
+func TestOrderUsecase_RefundOrder_forbiddenForUser(t *testing.T) {
+	uc, _ := newRefundTestUsecase()
+	ctx := ctxWithUser("user-1")
+
+	o, _ := uc.CreateOrder(ctx, usecase.CreateOrderInput{Amount: jpy(1000)})
+	if err := uc.PayOrder(ctx, o.ID, "pm_card_visa"); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	if _, err := uc.RefundOrder(ctx, o.ID, jpy(100), ""); !errors.Is(err, domain.ErrForbidden) {
+		t.Fatalf("err = %v; want ErrForbidden", err)
+	}
+}
+
+func TestOrderUsecase_GetOrder(t *testing.T) {
+	uc := &usecase.OrderUsecase{
+		Repo:     newMemRepo(),
+		Payments: newMemPaymentRepo(),
+		Events:   newMemEventRepo(),
+		Tx:       nopTx{},
+		PG:       okPG{},
+		Clock:    fixedClock{t: time.Now()},
+		IDGen:    fixedIDGen{v: "x"},
+		Locker:   okLocker{},
+	}
+
+	o, _ := uc.CreateOrder(ctxWithUser("user-1"), usecase.CreateOrderInput{Amount: jpy(1200)})
+
+	got, err := uc.GetOrder(ctxWithUser("user-1"), o.ID)
+	if err != nil {
+		t.Fatalf("GetOrder err = %v", err)
+	}
+	if got.Amount.Amount != 1200 {
+		t.Fatalf("order mismatch: %+v", got)
+	}
+
+	if _, err := uc.GetOrder(ctxWithUser("user-2"), o.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("err = %v; want ErrNotFound", err)
+	}
+	if _, err := uc.GetOrder(ctxWithAdmin("admin-1"), o.ID); err != nil {
+		t.Fatalf("admin GetOrder err = %v", err)
+	}
+}
+
+func TestOrderUsecase_ListOrders_pagination(t *testing.T) {
+	n := 0
+	now := time.Date(2025, 9, 27, 10, 0, 0, 0, time.UTC)
+	uc := &usecase.OrderUsecase{
+		Repo:     newMemRepo(),
+		Payments: newMemPaymentRepo(),
+		Events:   newMemEventRepo(),
+		Tx:       nopTx{},
+		PG:       okPG{},
+		Clock:    tickClock{t: &now},
+		IDGen:    seqIDGen{n: &n},
+		Locker:   okLocker{},
+	}
+
+	var mine []order.ID
+	for i := 0; i < 5; i++ {
+		o, err := uc.CreateOrder(ctxWithUser("user-1"), usecase.CreateOrderInput{Amount: jpy(int64(100 + i))})
+		if err != nil {
+			t.Fatalf("CreateOrder err = %v", err)
+		}
+		mine = append(mine, o.ID)
+	}
+	if _, err := uc.CreateOrder(ctxWithUser("user-2"), usecase.CreateOrderInput{Amount: jpy(999)}); err != nil {
+		t.Fatalf("CreateOrder err = %v", err)
+	}
+
+	ctx := ctxWithUser("user-1")
+	var got []order.ID
+	cursor := ""
+	for pages := 0; ; pages++ {
+		if pages > 5 {
+			t.Fatalf("too many pages")
+		}
+		page, err := uc.ListOrders(ctx, usecase.ListOrdersQuery{Cursor: cursor, Limit: 2})
+		if err != nil {
+			t.Fatalf("ListOrders err = %v", err)
+		}
+		for _, o := range page.Items {
+			got = append(got, o.ID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	slices.Reverse(mine)
+	if !slices.Equal(got, mine) {
+		t.Fatalf("ids = %v; want %v", got, mine)
+	}
+
+	// 管理者は全ユーザの注文が見える
+	page, err := uc.ListOrders(ctxWithAdmin("admin-1"), usecase.ListOrdersQuery{})
+	if err != nil {
+		t.Fatalf("ListOrders err = %v", err)
+	}
+	if len(page.Items) != 6 || page.NextCursor != "" {
+		t.Fatalf("admin page: %d items, cursor %q", len(page.Items), page.NextCursor)
+	}
+}
+
+func TestOrderUsecase_ListOrders_invalidQuery(t *testing.T) {
+	uc := &usecase.OrderUsecase{
+		Repo:   newMemRepo(),
+		Tx:     nopTx{},
+		PG:     okPG{},
+		Clock:  fixedClock{t: time.Now()},
+		IDGen:  fixedIDGen{v: "x"},
+		Locker: okLocker{},
+	}
+	ctx := ctxWithUser("user-1")
+
+	for _, q := range []usecase.ListOrdersQuery{
+		{Status: "UNKNOWN"},
+		{Limit: 101},
+		{Cursor: "!!"},
+		{CreatedFrom: time.Now(), CreatedTo: time.Now().Add(-time.Hour)},
+	} {
+		if _, err := uc.ListOrders(ctx, q); !errors.Is(err, domain.ErrInvalidArgument) {
+			t.Fatalf("query %+v: err = %v; want ErrInvalidArgument", q, err)
+		}
+	}
+}
+
+func TestOrderUsecase_AuthorizeCapture_partial(t *testing.T) {
+	uc, repo := newRefundTestUsecase()
+	ctx := ctxWithUser("user-1")
+	admin := ctxWithAdmin("admin-1")
+
+	o, _ := uc.CreateOrder(ctx, usecase.CreateOrderInput{Amount: jpy(1000)})
+
+	a, err := uc.AuthorizeOrder(ctx, o.ID, "pm_card_visa")
+	if err != nil {
+		t.Fatalf("AuthorizeOrder err = %v", err)
+	}
+	if a.Amount.Amount != 1000 || a.Status != payment.AuthorizationAuthorized {
+		t.Fatalf("authorization mismatch: %+v", a)
+	}
+	got, _ := repo.FindByID(context.Background(), o.ID)
+	if got.Status != order.StatusAuthorized {
+		t.Fatalf("status = %s; want AUTHORIZED", got.Status)
+	}
+
+	// 与信中は即時決済できない
+	if err := uc.PayOrder(ctx, o.ID, "pm_card_visa"); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("PayOrder err = %v; want ErrConflict", err)
+	}
+	// 売上確定は管理者のみ
+	if _, err := uc.CaptureOrder(ctx, o.ID, money.Money{}); !errors.Is(err, domain.ErrForbidden) {
+		t.Fatalf("CaptureOrder err = %v; want ErrForbidden", err)
+	}
+	if _, err := uc.CaptureOrder(admin, o.ID, jpy(1001)); !errors.Is(err, domain.ErrInvalidArgument) {
+		t.Fatalf("CaptureOrder err = %v; want ErrInvalidArgument", err)
+	}
+
+	p, err := uc.CaptureOrder(admin, o.ID, jpy(600))
+	if err != nil {
+		t.Fatalf("CaptureOrder err = %v", err)
+	}
+	if p.Amount.Amount != 600 {
+		t.Fatalf("payment mismatch: %+v", p)
+	}
+	got, _ = repo.FindByID(context.Background(), o.ID)
+	if got.Status != order.StatusPaid {
+		t.Fatalf("status = %s; want PAID", got.Status)
+	}
+
+	// 返金上限は確定額
+	if _, err := uc.RefundOrder(admin, o.ID, jpy(601), ""); !errors.Is(err, domain.ErrInvalidArgument) {
+		t.Fatalf("RefundOrder err = %v; want ErrInvalidArgument", err)
+	}
+	rf, err := uc.RefundOrder(admin, o.ID, money.Money{}, "")
+	if err != nil {
+		t.Fatalf("RefundOrder err = %v", err)
+	}
+	if rf.Amount.Amount != 600 {
+		t.Fatalf("refund amount = %d; want 600", rf.Amount.Amount)
+	}
+}
+
+func TestOrderUsecase_VoidOrder(t *testing.T) {
+	uc, repo := newRefundTestUsecase()
+	ctx := ctxWithUser("user-1")
+
+	o, _ := uc.CreateOrder(ctx, usecase.CreateOrderInput{Amount: jpy(1000)})
+
+	// 与信前は取り消せない
+	if err := uc.VoidOrder(ctx, o.ID); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("VoidOrder err = %v; want ErrConflict", err)
+	}
+
+	if _, err := uc.AuthorizeOrder(ctx, o.ID, "pm_card_visa"); err != nil {
+		t.Fatalf("AuthorizeOrder err = %v", err)
+	}
+	if err := uc.VoidOrder(ctx, o.ID); err != nil {
+		t.Fatalf("VoidOrder err = %v", err)
+	}
+	got, _ := repo.FindByID(context.Background(), o.ID)
+	if got.Status != order.StatusCanceled {
+		t.Fatalf("status = %s; want CANCELED", got.Status)
+	}
+
+	if _, err := uc.CaptureOrder(ctxWithAdmin("admin-1"), o.ID, money.Money{}); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("CaptureOrder err = %v; want ErrConflict", err)
+	}
+}
+
+func TestOrderUsecase_VoidExpiredAuthorizations(t *testing.T) {
+	now := time.Date(2025, 9, 27, 10, 0, 0, 0, time.UTC)
+	uc, repo := newRefundTestUsecase()
+	uc.AuthorizationTTL = time.Hour
+	uc.Clock = fixedClock{t: now}
+	ctx := ctxWithUser("user-1")
+
+	o, _ := uc.CreateOrder(ctx, usecase.CreateOrderInput{Amount: jpy(1000)})
+	if _, err := uc.AuthorizeOrder(ctx, o.ID, "pm_card_visa"); err != nil {
+		t.Fatalf("AuthorizeOrder err = %v", err)
+	}
+
+	// 期限前は何もしない
+	if n, err := uc.VoidExpiredAuthorizations(context.Background(), 10); err != nil || n != 0 {
+		t.Fatalf("VoidExpiredAuthorizations = %d, %v; want 0, nil", n, err)
+	}
+
+	uc.Clock = fixedClock{t: now.Add(time.Hour)}
+
+	// 期限切れの与信は確定できない
+	if _, err := uc.CaptureOrder(ctxWithAdmin("admin-1"), o.ID, money.Money{}); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("CaptureOrder err = %v; want ErrConflict", err)
+	}
+
+	if n, err := uc.VoidExpiredAuthorizations(context.Background(), 10); err != nil || n != 1 {
+		t.Fatalf("VoidExpiredAuthorizations = %d, %v; want 1, nil", n, err)
+	}
+	got, _ := repo.FindByID(context.Background(), o.ID)
+	if got.Status != order.StatusCanceled {
+		t.Fatalf("status = %s; want CANCELED", got.Status)
+	}
+}
+
+func TestOrderUsecase_PayOrder_pendingRecorded(t *testing.T) {
+	uc, repo := newRefundTestUsecase()
+	uc.PG = okPG{err: domain.ErrPaymentPending}
+	events := uc.Events.(*memEventRepo)
+	ctx := ctxWithUser("user-1")
+
+	o, _ := uc.CreateOrder(ctx, usecase.CreateOrderInput{Amount: jpy(1000)})
+	if err := uc.PayOrder(ctx, o.ID, "pm_card_visa"); !errors.Is(err, domain.ErrPaymentPending) {
+		t.Fatalf("err = %v; want ErrPaymentPending", err)
+	}
+
+	got, _ := repo.FindByID(context.Background(), o.ID)
+	if got.Status != order.StatusPending {
+		t.Fatalf("status = %s; want PENDING", got.Status)
+	}
+	want := []event.Type{event.TypeOrderCreated, event.TypeChargeAttempted, event.TypeChargePending}
+	if got := eventTypes(events.m[o.ID]); !slices.Equal(got, want) {
+		t.Fatalf("events = %v; want %v", got, want)
+	}
+}
+
+func TestWebhookUsecase_paymentSucceeded_dedupe(t *testing.T) {
+	uc, repo := newRefundTestUsecase()
+	events := uc.Events.(*memEventRepo)
+	payments := uc.Payments.(*memPaymentRepo)
+	wh := &usecase.WebhookUsecase{Orders: uc, Inbox: newMemWebhookInbox(), Clock: uc.Clock}
+
+	o, _ := uc.CreateOrder(ctxWithUser("user-1"), usecase.CreateOrderInput{Amount: jpy(1000)})
+
+	ev := &domain.ProviderEvent{
+		Provider:     "stripe",
+		ID:           "evt_1",
+		Type:         domain.ProviderPaymentSucceeded,
+		RawType:      "payment_intent.succeeded",
+		OrderID:      string(o.ID),
+		ProviderTxID: "pi_1",
+		Amount:       1000,
+		Currency:     "jpy",
+	}
+	dup, err := wh.HandleProviderEvent(context.Background(), ev)
+	if err != nil || dup {
+		t.Fatalf("HandleProviderEvent = %v, %v; want false, nil", dup, err)
+	}
+
+	got, _ := repo.FindByID(context.Background(), o.ID)
+	if got.Status != order.StatusPaid {
+		t.Fatalf("status = %s; want PAID", got.Status)
+	}
+	if ps := payments.m[o.ID]; len(ps) != 1 || ps[0].TxID != "pi_1" || ps[0].Amount.Amount != 1000 {
+		t.Fatalf("payments = %+v", ps)
+	}
+
+	// 同じイベントの再送は何もしない
+	dup, err = wh.HandleProviderEvent(context.Background(), ev)
+	if err != nil || !dup {
+		t.Fatalf("HandleProviderEvent (redelivery) = %v, %v; want true, nil", dup, err)
+	}
+	want := []event.Type{event.TypeOrderCreated, event.TypeChargeSucceeded, event.TypeOrderPaid}
+	if got := eventTypes(events.m[o.ID]); !slices.Equal(got, want) {
+		t.Fatalf("events = %v; want %v", got, want)
+	}
+}
+
+func TestWebhookUsecase_paymentSucceeded_mismatch(t *testing.T) {
+	uc, repo := newRefundTestUsecase()
+	events := uc.Events.(*memEventRepo)
+	payments := uc.Payments.(*memPaymentRepo)
+
+	o, _ := uc.CreateOrder(ctxWithUser("user-1"), usecase.CreateOrderInput{Amount: jpy(1000)})
+
+	// 金額・通貨が注文と違う通知では PAID にせず、手動確認に回す
+	for i, tt := range []struct {
+		amount   int64
+		currency string
+		reason   string
+	}{
+		{1, "jpy", "amount mismatch"},
+		{1000, "usd", "currency mismatch"},
+		{1000, "", "currency mismatch"},
+	} {
+		err := uc.ApplyProviderEvent(context.Background(), &domain.ProviderEvent{
+			Provider:     "stripe",
+			ID:           fmt.Sprintf("evt_%d", i),
+			Type:         domain.ProviderPaymentSucceeded,
+			OrderID:      string(o.ID),
+			ProviderTxID: "pi_1",
+			Amount:       tt.amount,
+			Currency:     tt.currency,
+		})
+		if err != nil {
+			t.Fatalf("ApplyProviderEvent(%d %s) err = %v", tt.amount, tt.currency, err)
+		}
+		es := events.m[o.ID]
+		if last := es[len(es)-1]; last.Type != event.TypePaymentReview || last.Payload["reason"] != tt.reason || last.Payload["expected_amount"] != int64(1000) {
+			t.Fatalf("last event = %s %v; want PAYMENT_NEEDS_REVIEW %s", last.Type, last.Payload, tt.reason)
+		}
+	}
+
+	got, _ := repo.FindByID(context.Background(), o.ID)
+	if got.Status != order.StatusPending {
+		t.Fatalf("status = %s; want PENDING", got.Status)
+	}
+	if ps := payments.m[o.ID]; len(ps) != 0 {
+		t.Fatalf("payments = %+v; want none", ps)
+	}
+}
+
+func TestWebhookUsecase_paymentCanceled_authorized(t *testing.T) {
+	uc, repo := newRefundTestUsecase()
+	wh := &usecase.WebhookUsecase{Orders: uc, Inbox: newMemWebhookInbox(), Clock: uc.Clock}
+	ctx := ctxWithUser("user-1")
+
+	o, _ := uc.CreateOrder(ctx, usecase.CreateOrderInput{Amount: jpy(1000)})
+	a, err := uc.AuthorizeOrder(ctx, o.ID, "pm_card_visa")
+	if err != nil {
+		t.Fatalf("AuthorizeOrder err = %v", err)
+	}
+
+	_, err = wh.HandleProviderEvent(context.Background(), &domain.ProviderEvent{
+		Provider: "stripe",
+		ID:       "evt_2",
+		Type:     domain.ProviderPaymentCanceled,
+		OrderID:  string(o.ID),
+		Reason:   "automatic",
+	})
+	if err != nil {
+		t.Fatalf("HandleProviderEvent err = %v", err)
+	}
+
+	got, _ := repo.FindByID(context.Background(), o.ID)
+	if got.Status != order.StatusCanceled {
+		t.Fatalf("status = %s; want CANCELED", got.Status)
+	}
+	if s := uc.Auths.(*memAuthRepo).m[a.ID].Status; s != payment.AuthorizationVoided {
+		t.Fatalf("authorization status = %s; want VOIDED", s)
+	}
+}
+
+func TestWebhookUsecase_disputeByTxID(t *testing.T) {
+	uc, _ := newRefundTestUsecase()
+	uc.Provider = "stripe"
+	events := uc.Events.(*memEventRepo)
+	wh := &usecase.WebhookUsecase{Orders: uc, Inbox: newMemWebhookInbox(), Clock: uc.Clock}
+	ctx := ctxWithUser("user-1")
+
+	o, _ := uc.CreateOrder(ctx, usecase.CreateOrderInput{Amount: jpy(1000)})
+	if err := uc.PayOrder(ctx, o.ID, "pm_card_visa"); err != nil {
+		t.Fatalf("PayOrder err = %v", err)
+	}
+
+	// metadata が無くても決済IDから注文を引ける
+	_, err := wh.HandleProviderEvent(context.Background(), &domain.ProviderEvent{
+		Provider:     "stripe",
+		ID:           "evt_3",
+		Type:         domain.ProviderDisputeCreated,
+		ProviderTxID: "tx1",
+		Amount:       1000,
+		Reason:       "fraudulent",
+	})
+	if err != nil {
+		t.Fatalf("HandleProviderEvent err = %v", err)
+	}
+	if got := eventTypes(events.m[o.ID]); !slices.Contains(got, event.TypeDisputeOpened) {
+		t.Fatalf("events = %v; want DISPUTE_OPENED", got)
+	}
+
+	// 知らない決済の通知は受理だけする（再送させない）。処理済みにもする
+	dup, err := wh.HandleProviderEvent(context.Background(), &domain.ProviderEvent{
+		Provider:     "stripe",
+		ID:           "evt_4",
+		Type:         domain.ProviderDisputeCreated,
+		ProviderTxID: "pi_unknown",
+	})
+	if err != nil || dup {
+		t.Fatalf("HandleProviderEvent = %v, %v; want false, nil", dup, err)
+	}
+}
+
+// 処理済みの記録に失敗したら反映も戻り、再送で一度だけ反映される
+func TestWebhookUsecase_markFailedThenRedelivered(t *testing.T) {
+	uc, _ := newRefundTestUsecase()
+	events := uc.Events.(*memEventRepo)
+	inbox := &failMarkInbox{memWebhookInbox: newMemWebhookInbox(), fail: 1}
+	wh := &usecase.WebhookUsecase{Orders: uc, Inbox: inbox, Clock: uc.Clock}
+	ctx := ctxWithUser("user-1")
+
+	o, _ := uc.CreateOrder(ctx, usecase.CreateOrderInput{Amount: jpy(1000)})
+	if err := uc.PayOrder(ctx, o.ID, "pm_card_visa"); err != nil {
+		t.Fatalf("PayOrder err = %v", err)
+	}
+
+	ev := &domain.ProviderEvent{
+		Provider:     "stripe",
+		ID:           "evt_5",
+		Type:         domain.ProviderDisputeCreated,
+		OrderID:      string(o.ID),
+		ProviderTxID: "tx1",
+		Amount:       1000,
+		Reason:       "fraudulent",
+	}
+	if _, err := wh.HandleProviderEvent(context.Background(), ev); err == nil {
+		t.Fatal("HandleProviderEvent err = nil; want db error")
+	}
+	for i, wantDup := range []bool{false, true} {
+		dup, err := wh.HandleProviderEvent(context.Background(), ev)
+		if err != nil || dup != wantDup {
+			t.Fatalf("redelivery #%d = %v, %v; want %v, nil", i, dup, err, wantDup)
+		}
+	}
+
+	n := 0
+	for _, typ := range eventTypes(events.m[o.ID]) {
+		if typ == event.TypeDisputeOpened {
+			n++
+		}
+	}
+	if n != 1 {
+		t.Fatalf("DISPUTE_OPENED recorded %d times; want 1", n)
+	}
 }
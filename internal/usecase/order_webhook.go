@@ -0,0 +1,299 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/event"
+	"github.com/kazshi01/payment-system/internal/domain/money"
+	"github.com/kazshi01/payment-system/internal/domain/order"
+	"github.com/kazshi01/payment-system/internal/domain/payment"
+)
+
+// ApplyProviderEvent は PG から届いた非同期通知で注文状態を収束させる。
+// Webhook から呼ばれる想定のため認可チェックは行わない。
+// 既に収束済み・対象外の通知は何もせず nil を返す（プロバイダの再送を止めるため）
+func (uc *OrderUsecase) ApplyProviderEvent(ctx context.Context, ev *domain.ProviderEvent) error {
+	return uc.applyProviderEvent(ctx, ev, func(context.Context) error { return nil })
+}
+
+// mark は注文への反映と同じ Tx の中で、反映の前に呼ばれる（受信箱を処理済みにする）。
+// 反映するものが無い通知では Tx の外で呼ばれる
+func (uc *OrderUsecase) applyProviderEvent(ctx context.Context, ev *domain.ProviderEvent, mark func(ctx context.Context) error) error {
+	if ev.Type == "" {
+		return mark(ctx)
+	}
+
+	id, err := uc.resolveEventOrder(ctx, ev)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			log.Printf("warn: provider event for unknown order: provider=%s event_id=%s type=%s", ev.Provider, ev.ID, ev.RawType)
+			return mark(ctx)
+		}
+		return err
+	}
+
+	// API 経由の pay / capture / void と同じロックで直列化する
+	ctx, unlock, err := uc.lockOrder(ctx, id)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// ---- DB 反映は 3s ----
+	dbCtx, cancelDB := context.WithTimeout(ctx, 3*time.Second)
+	defer cancelDB()
+
+	return uc.Tx.Do(dbCtx, func(dbCtx context.Context) error {
+		if err := mark(dbCtx); err != nil {
+			return err
+		}
+		o, err := uc.Repo.FindByID(dbCtx, id)
+		if err != nil {
+			return err
+		}
+
+		switch ev.Type {
+		case domain.ProviderPaymentSucceeded:
+			return uc.applyPaymentSucceeded(dbCtx, o, ev)
+		case domain.ProviderPaymentFailed:
+			return uc.applyPaymentFailed(dbCtx, o, ev)
+		case domain.ProviderPaymentCanceled:
+			return uc.applyPaymentCanceled(dbCtx, o, ev)
+		case domain.ProviderDisputeCreated:
+			return uc.recordEvent(dbCtx, o.ID, event.TypeDisputeOpened, webhookPayload(ev, map[string]any{
+				"provider_tx_id": ev.ProviderTxID,
+				"amount":         ev.Amount,
+				"reason":         ev.Reason,
+			}))
+		}
+		return nil
+	})
+}
+
+// metadata の注文ID を優先し、無ければ決済記録から引く
+func (uc *OrderUsecase) resolveEventOrder(ctx context.Context, ev *domain.ProviderEvent) (order.ID, error) {
+	if ev.OrderID != "" {
+		return order.ID(ev.OrderID), nil
+	}
+	if ev.ProviderTxID == "" {
+		return "", domain.ErrNotFound
+	}
+
+	dbReadCtx, cancelRead := context.WithTimeout(ctx, 3*time.Second)
+	defer cancelRead()
+
+	p, err := uc.Payments.FindByProviderTxID(dbReadCtx, ev.Provider, ev.ProviderTxID)
+	if err != nil {
+		return "", err
+	}
+	return order.ID(p.OrderID), nil
+}
+
+func (uc *OrderUsecase) applyPaymentSucceeded(ctx context.Context, o *order.Order, ev *domain.ProviderEvent) error {
+	now := uc.Clock.Now()
+	method := payment.MethodCard
+
+	switch o.Status {
+	case order.StatusPending, order.StatusAuthorized, order.StatusAwaitingPayment:
+		// 金額・通貨が注文と違う通知では PAID にしない。記録だけ残して手動確認に回す
+		if reason := paymentMismatch(o, ev); reason != "" {
+			log.Printf("warn: payment succeeded with %s: order_id=%s provider_tx_id=%s amount=%d %s", reason, o.ID, ev.ProviderTxID, ev.Amount, ev.Currency)
+			return uc.recordEvent(ctx, o.ID, event.TypePaymentReview, webhookPayload(ev, map[string]any{
+				"provider":          ev.Provider,
+				"provider_tx_id":    ev.ProviderTxID,
+				"amount":            ev.Amount,
+				"currency":          ev.Currency,
+				"expected_amount":   o.Amount.Amount,
+				"expected_currency": string(o.Amount.Currency),
+				"reason":            reason,
+			}))
+		}
+	}
+
+	switch o.Status {
+	case order.StatusPending:
+		// 3-D Secure 等で同期応答が PENDING だった決済の完了
+		if err := uc.transition(ctx, o, order.StatusPaid, now); err != nil {
+			return err
+		}
+
+	case order.StatusAuthorized:
+		// PG 側で直接売上確定された与信
+		a, err := uc.Auths.FindActiveByOrderID(ctx, o.ID)
+		if err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				return fmt.Errorf("%w: no active authorization for order %s", domain.ErrInternal, o.ID)
+			}
+			return err
+		}
+
+		if err := uc.transition(ctx, o, order.StatusPaid, now); err != nil {
+			return err
+		}
+
+		rows, err := uc.Auths.UpdateStatusIf(ctx, a.ID, payment.AuthorizationAuthorized, payment.AuthorizationCaptured, now)
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return domain.ErrConflict
+		}
+
+	case order.StatusAwaitingPayment:
+		// コンビニ店頭での支払い完了
+		k, err := uc.awaitingKonbini(ctx, o.ID)
+		if err != nil {
+			return err
+		}
+
+		if err := uc.transition(ctx, o, order.StatusPaid, now); err != nil {
+			return err
+		}
+
+		rows, err := uc.Konbinis.UpdateStatusIf(ctx, k.ID, payment.KonbiniAwaiting, payment.KonbiniPaid, now)
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return domain.ErrConflict
+		}
+		method = payment.MethodKonbini
+
+	case order.StatusCanceled:
+		// 取り消し後に売上が立った。自動では戻さず記録だけ残して手動対応に回す
+		log.Printf("warn: payment succeeded for canceled order: order_id=%s provider_tx_id=%s", o.ID, ev.ProviderTxID)
+		return uc.recordEvent(ctx, o.ID, event.TypeChargeSucceeded, webhookPayload(ev, map[string]any{
+			"provider":       ev.Provider,
+			"provider_tx_id": ev.ProviderTxID,
+			"amount":         ev.Amount,
+			"ignored":        "order already canceled",
+		}))
+
+	default:
+		// PAID 以降は同期応答で反映済み
+		return nil
+	}
+
+	// 金額は注文と一致することを確認済み
+	p := &payment.Payment{
+		ID:        payment.ID(uc.IDGen.New()),
+		OrderID:   string(o.ID),
+		Method:    method,
+		Provider:  ev.Provider,
+		TxID:      ev.ProviderTxID,
+		Amount:    o.Amount,
+		CreatedAt: now,
+	}
+	if err := uc.Payments.Create(ctx, p); err != nil {
+		return err
+	}
+	if err := uc.postCharge(ctx, p); err != nil {
+		return err
+	}
+
+	if err := uc.recordEvent(ctx, o.ID, event.TypeChargeSucceeded, webhookPayload(ev, map[string]any{
+		"provider":       p.Provider,
+		"provider_tx_id": p.TxID,
+	})); err != nil {
+		return err
+	}
+	return uc.recordEvent(ctx, o.ID, event.TypeOrderPaid, map[string]any{
+		"payment_id": string(p.ID),
+	})
+}
+
+func (uc *OrderUsecase) applyPaymentFailed(ctx context.Context, o *order.Order, ev *domain.ProviderEvent) error {
+	// 失敗しても注文は PENDING のまま（再度 pay できる）。記録だけ残す
+	if o.Status != order.StatusPending {
+		return nil
+	}
+	return uc.recordEvent(ctx, o.ID, event.TypeChargeFailed, webhookPayload(ev, map[string]any{
+		"provider_tx_id": ev.ProviderTxID,
+		"error":          ev.Reason,
+	}))
+}
+
+func (uc *OrderUsecase) applyPaymentCanceled(ctx context.Context, o *order.Order, ev *domain.ProviderEvent) error {
+	now := uc.Clock.Now()
+
+	switch o.Status {
+	case order.StatusPending:
+		if err := uc.transition(ctx, o, order.StatusCanceled, now); err != nil {
+			return err
+		}
+		return uc.recordEvent(ctx, o.ID, event.TypeOrderCanceled, webhookPayload(ev, map[string]any{
+			"reason": ev.Reason,
+		}))
+
+	case order.StatusAuthorized:
+		// PG 側で与信が取り消された（期限切れ等）
+		a, err := uc.Auths.FindActiveByOrderID(ctx, o.ID)
+		if err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				return fmt.Errorf("%w: no active authorization for order %s", domain.ErrInternal, o.ID)
+			}
+			return err
+		}
+
+		if err := uc.transition(ctx, o, order.StatusCanceled, now); err != nil {
+			return err
+		}
+
+		rows, err := uc.Auths.UpdateStatusIf(ctx, a.ID, payment.AuthorizationAuthorized, payment.AuthorizationVoided, now)
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return domain.ErrConflict
+		}
+
+		return uc.recordEvent(ctx, o.ID, event.TypeOrderVoided, webhookPayload(ev, map[string]any{
+			"authorization_id": string(a.ID),
+			"reason":           ev.Reason,
+		}))
+
+	case order.StatusAwaitingPayment:
+		// PG 側で払込番号が無効になった（期限切れ等）
+		k, err := uc.awaitingKonbini(ctx, o.ID)
+		if err != nil {
+			return err
+		}
+
+		if err := uc.transition(ctx, o, order.StatusCanceled, now); err != nil {
+			return err
+		}
+		if err := uc.expireKonbiniRow(ctx, k, now); err != nil {
+			return err
+		}
+
+		return uc.recordEvent(ctx, o.ID, event.TypeOrderCanceled, webhookPayload(ev, map[string]any{
+			"konbini_id": string(k.ID),
+			"reason":     ev.Reason,
+		}))
+	}
+	return nil
+}
+
+// 通知の金額・通貨が注文と一致しなければ理由を返す
+func paymentMismatch(o *order.Order, ev *domain.ProviderEvent) string {
+	c, err := money.ParseCurrency(ev.Currency)
+	if err != nil || c != o.Amount.Currency {
+		return "currency mismatch"
+	}
+	if ev.Amount != o.Amount.Amount {
+		return "amount mismatch"
+	}
+	return ""
+}
+
+// Webhook 起因のイベントには通知元を残す
+func webhookPayload(ev *domain.ProviderEvent, payload map[string]any) map[string]any {
+	payload["source"] = "webhook"
+	payload["provider_event_id"] = ev.ID
+	return payload
+}
@@ -0,0 +1,199 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/event"
+	"github.com/kazshi01/payment-system/internal/domain/order"
+)
+
+const (
+	defaultRelayBatchSize   = 100
+	defaultRelayMaxAttempts = 20
+	defaultRelayBaseBackoff = time.Second
+	defaultRelayMaxBackoff  = 10 * time.Minute
+	defaultRelayLease       = time.Minute
+
+	relayPublishTimeout = 5 * time.Second
+)
+
+// 下流に配信するメッセージ本体（payment_events 1 件分）
+type outboxEnvelope struct {
+	ID        string         `json:"id"`
+	OrderID   string         `json:"order_id"`
+	Type      string         `json:"type"`
+	Payload   map[string]any `json:"payload"`
+	CreatedAt time.Time      `json:"created_at"`
+	Order     *orderSnapshot `json:"order,omitempty"` // イベントを記録した時点の注文
+}
+
+// イベントと同じ Tx で読んだ注文。配信時に読み直すと後の状態変更が混ざるため
+type orderSnapshot struct {
+	ID       string `json:"id"`
+	UserID   string `json:"user_id"`
+	Status   string `json:"status"`
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+func snapshotOf(o *order.Order) *orderSnapshot {
+	return &orderSnapshot{
+		ID:       string(o.ID),
+		UserID:   o.UserID,
+		Status:   string(o.Status),
+		Amount:   o.Amount.Amount,
+		Currency: string(o.Amount.Currency),
+	}
+}
+
+func outboxMessageFromEvent(e *event.Event, o *order.Order) (*domain.OutboxMessage, error) {
+	payload := e.Payload
+	if payload == nil {
+		payload = map[string]any{}
+	}
+	b, err := json.Marshal(outboxEnvelope{
+		ID:        string(e.ID),
+		OrderID:   e.OrderID,
+		Type:      string(e.Type),
+		Payload:   payload,
+		CreatedAt: e.CreatedAt,
+		Order:     snapshotOf(o),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal outbox message: %w", err)
+	}
+	return &domain.OutboxMessage{
+		ID:          string(e.ID),
+		AggregateID: e.OrderID,
+		Type:        string(e.Type),
+		Payload:     b,
+		CreatedAt:   e.CreatedAt,
+	}, nil
+}
+
+// OutboxRelay は outbox の未配信メッセージを EventPublisher に配信する（cmd/relay）。
+// 配信は at-least-once。下流はメッセージID で重複排除する
+type OutboxRelay struct {
+	Outbox    domain.OutboxRepository
+	Publisher domain.EventPublisher
+	Tx        domain.Tx
+	Clock     Clock
+
+	BatchSize   int           // 1回に取り出す件数（0 なら 100）
+	MaxAttempts int           // これを超えて失敗したら配信を諦める（0 なら 20）
+	BaseBackoff time.Duration // 1回目の失敗後の待ち時間（0 なら 1s）。以後倍々
+	MaxBackoff  time.Duration // 待ち時間の上限（0 なら 10m）
+	Lease       time.Duration // 取り出したメッセージを他の relay に渡さない時間（0 なら 1m）
+}
+
+// RelayOnce は1バッチ分を配信し、配信できた件数を返す。
+// 取り出しと結果の記録はそれぞれ短い Tx で行い、配信（外部 I/O）は Tx の外で行う。
+// 取り出した行はリースの間ほかの relay に渡らないので、複数の relay を並べても二重配信しない
+func (r *OutboxRelay) RelayOnce(ctx context.Context) (int, error) {
+	limit := r.BatchSize
+	if limit <= 0 {
+		limit = defaultRelayBatchSize
+	}
+	lease := r.Lease
+	if lease <= 0 {
+		lease = defaultRelayLease
+	}
+
+	now := r.Clock.Now()
+	leaseUntil := now.Add(lease)
+
+	// ---- 取り出しは 3s ----
+	var ms []*domain.OutboxMessage
+	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	err := r.Tx.Do(dbCtx, func(dbCtx context.Context) error {
+		var err error
+		ms, err = r.Outbox.ClaimPending(dbCtx, now, leaseUntil, limit)
+		return err
+	})
+	cancel()
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for _, m := range ms {
+		if err := ctx.Err(); err != nil {
+			return delivered, err
+		}
+		// リース中に配信を終えられないものは残す（リースが切れたら再び取り出される）
+		if !r.Clock.Now().Add(relayPublishTimeout).Before(leaseUntil) {
+			break
+		}
+
+		// ---- 配信は 5s ----
+		pubCtx, cancel := context.WithTimeout(ctx, relayPublishTimeout)
+		pubErr := r.Publisher.Publish(pubCtx, m)
+		cancel()
+
+		if err := r.record(ctx, m, pubErr); err != nil {
+			// 記録できなかったメッセージはリース切れ後に再配信される（下流で重複排除）
+			return delivered, err
+		}
+		if pubErr == nil {
+			delivered++
+		}
+	}
+	return delivered, nil
+}
+
+// 配信結果を 1 件ずつ短い Tx で記録する
+func (r *OutboxRelay) record(ctx context.Context, m *domain.OutboxMessage, pubErr error) error {
+	// ---- DB 反映は 3s ----
+	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	return r.Tx.Do(dbCtx, func(dbCtx context.Context) error {
+		if pubErr != nil {
+			return r.markFailed(dbCtx, m, pubErr)
+		}
+		return r.Outbox.MarkDelivered(dbCtx, m.ID, r.Clock.Now())
+	})
+}
+
+func (r *OutboxRelay) markFailed(ctx context.Context, m *domain.OutboxMessage, cause error) error {
+	maxAttempts := r.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultRelayMaxAttempts
+	}
+
+	attempts := m.Attempts + 1
+	now := r.Clock.Now()
+	if attempts >= maxAttempts {
+		log.Printf("warn: outbox message dead after %d attempts: id=%s type=%s: %v", attempts, m.ID, m.Type, cause)
+		return r.Outbox.MarkDead(ctx, m.ID, cause.Error(), now)
+	}
+
+	log.Printf("warn: publish outbox message: id=%s type=%s attempt=%d: %v", m.ID, m.Type, attempts, cause)
+	return r.Outbox.MarkFailed(ctx, m.ID, cause.Error(), now.Add(r.backoff(attempts)))
+}
+
+// 1s, 2s, 4s, ... を上限で頭打ちにする
+func (r *OutboxRelay) backoff(attempts int) time.Duration {
+	base := r.BaseBackoff
+	if base <= 0 {
+		base = defaultRelayBaseBackoff
+	}
+	maxBackoff := r.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = defaultRelayMaxBackoff
+	}
+
+	d := base
+	for i := 1; i < attempts; i++ {
+		d *= 2
+		if d >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return d
+}
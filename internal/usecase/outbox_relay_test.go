@@ -0,0 +1,267 @@
+package usecase_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/usecase"
+)
+
+type memOutbox struct {
+	ms   []*domain.OutboxMessage
+	next map[string]time.Time
+	done map[string]bool
+	dead map[string]bool
+
+	markErr error // MarkDelivered を失敗させる
+}
+
+func newMemOutbox() *memOutbox {
+	return &memOutbox{next: map[string]time.Time{}, done: map[string]bool{}, dead: map[string]bool{}}
+}
+
+func (o *memOutbox) Enqueue(ctx context.Context, m *domain.OutboxMessage) error {
+	cp := *m
+	o.ms = append(o.ms, &cp)
+	o.next[m.ID] = m.CreatedAt
+	return nil
+}
+
+func (o *memOutbox) ClaimPending(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*domain.OutboxMessage, error) {
+	var out []*domain.OutboxMessage
+	for _, m := range o.ms {
+		if o.done[m.ID] || o.dead[m.ID] || o.next[m.ID].After(now) || len(out) >= limit {
+			continue
+		}
+		o.next[m.ID] = leaseUntil
+		cp := *m
+		out = append(out, &cp)
+	}
+	return out, nil
+}
+
+func (o *memOutbox) MarkDelivered(ctx context.Context, id string, at time.Time) error {
+	if o.markErr != nil {
+		return o.markErr
+	}
+	o.done[id] = true
+	return nil
+}
+
+func (o *memOutbox) MarkFailed(ctx context.Context, id string, lastErr string, nextAttemptAt time.Time) error {
+	if o.done[id] || o.dead[id] {
+		return nil
+	}
+	o.bump(id)
+	o.next[id] = nextAttemptAt
+	return nil
+}
+
+func (o *memOutbox) MarkDead(ctx context.Context, id string, lastErr string, at time.Time) error {
+	if o.done[id] || o.dead[id] {
+		return nil
+	}
+	o.bump(id)
+	o.dead[id] = true
+	return nil
+}
+
+func (o *memOutbox) bump(id string) {
+	for _, m := range o.ms {
+		if m.ID == id {
+			m.Attempts++
+		}
+	}
+}
+
+type recPublisher struct {
+	got []string
+	err error
+
+	open   *int // 配信時に開いている Tx の数（countTx と共有）
+	inTxAt []int
+}
+
+func (p *recPublisher) Publish(ctx context.Context, m *domain.OutboxMessage) error {
+	if p.open != nil {
+		p.inTxAt = append(p.inTxAt, *p.open)
+	}
+	if p.err != nil {
+		return p.err
+	}
+	p.got = append(p.got, m.Type)
+	return nil
+}
+
+// 開いている Tx の数と、開いた回数を数える
+type countTx struct{ open, calls *int }
+
+func (t countTx) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	*t.open++
+	*t.calls++
+	defer func() { *t.open-- }()
+	return fn(ctx)
+}
+
+func TestOrderUsecase_eventsEnqueuedToOutbox(t *testing.T) {
+	uc, _ := newRefundTestUsecase()
+	n := 0
+	uc.IDGen = seqIDGen{n: &n}
+	outbox := newMemOutbox()
+	uc.Outbox = outbox
+	ctx := ctxWithUser("user-1")
+
+	o, _ := uc.CreateOrder(ctx, usecase.CreateOrderInput{Amount: jpy(1000)})
+	if err := uc.PayOrder(ctx, o.ID, "pm_card_visa"); err != nil {
+		t.Fatalf("PayOrder err = %v", err)
+	}
+
+	var types []string
+	for _, m := range outbox.ms {
+		types = append(types, m.Type)
+		if m.AggregateID != string(o.ID) {
+			t.Fatalf("aggregate id = %s; want %s", m.AggregateID, o.ID)
+		}
+	}
+	want := []string{"ORDER_CREATED", "CHARGE_ATTEMPTED", "CHARGE_SUCCEEDED", "ORDER_PAID"}
+	if !slices.Equal(types, want) {
+		t.Fatalf("outbox types = %v; want %v", types, want)
+	}
+
+	// 本体はイベントと同じ ID・注文ID を持つ JSON
+	var env struct {
+		ID      string `json:"id"`
+		OrderID string `json:"order_id"`
+		Type    string `json:"type"`
+	}
+	last := outbox.ms[len(outbox.ms)-1]
+	if err := json.Unmarshal(last.Payload, &env); err != nil {
+		t.Fatalf("unmarshal payload: %v", err)
+	}
+	if env.ID != last.ID || env.OrderID != string(o.ID) || env.Type != "ORDER_PAID" {
+		t.Fatalf("envelope = %+v", env)
+	}
+}
+
+func TestOutboxRelay_delivers(t *testing.T) {
+	now := time.Date(2025, 9, 27, 7, 0, 0, 0, time.UTC)
+	outbox := newMemOutbox()
+	for _, id := range []string{"e1", "e2", "e3"} {
+		_ = outbox.Enqueue(context.Background(), &domain.OutboxMessage{ID: id, Type: "T-" + id, CreatedAt: now})
+	}
+	pub := &recPublisher{}
+	relay := &usecase.OutboxRelay{Outbox: outbox, Publisher: pub, Tx: nopTx{}, Clock: fixedClock{t: now}, BatchSize: 2}
+
+	n, err := relay.RelayOnce(context.Background())
+	if err != nil || n != 2 {
+		t.Fatalf("RelayOnce = %d, %v; want 2, nil", n, err)
+	}
+	n, err = relay.RelayOnce(context.Background())
+	if err != nil || n != 1 {
+		t.Fatalf("RelayOnce = %d, %v; want 1, nil", n, err)
+	}
+	if want := []string{"T-e1", "T-e2", "T-e3"}; !slices.Equal(pub.got, want) {
+		t.Fatalf("published = %v; want %v", pub.got, want)
+	}
+}
+
+func TestOutboxRelay_backoffThenDead(t *testing.T) {
+	now := time.Date(2025, 9, 27, 7, 0, 0, 0, time.UTC)
+	outbox := newMemOutbox()
+	_ = outbox.Enqueue(context.Background(), &domain.OutboxMessage{ID: "e1", Type: "T", CreatedAt: now})
+
+	pub := &recPublisher{err: errors.New("redis down")}
+	start := now
+	clk := &now
+	relay := &usecase.OutboxRelay{
+		Outbox: outbox, Publisher: pub, Tx: nopTx{}, Clock: tickClock{t: clk},
+		MaxAttempts: 3, BaseBackoff: time.Minute, MaxBackoff: time.Hour, Lease: time.Hour,
+	}
+
+	// 1回目の失敗: 1分後まで延期
+	if n, err := relay.RelayOnce(context.Background()); err != nil || n != 0 {
+		t.Fatalf("RelayOnce = %d, %v", n, err)
+	}
+	if outbox.ms[0].Attempts != 1 || !outbox.next["e1"].After(start.Add(time.Minute)) {
+		t.Fatalf("attempts = %d next = %s", outbox.ms[0].Attempts, outbox.next["e1"])
+	}
+
+	// 3回失敗したら配信を諦める
+	for i := 0; i < 10 && !outbox.dead["e1"]; i++ {
+		*clk = clk.Add(time.Hour)
+		_, _ = relay.RelayOnce(context.Background())
+	}
+	if !outbox.dead["e1"] || outbox.ms[0].Attempts != 3 {
+		t.Fatalf("dead = %v attempts = %d; want dead after 3 attempts", outbox.dead["e1"], outbox.ms[0].Attempts)
+	}
+
+	// 復旧しても dead のメッセージは配信しない
+	pub.err = nil
+	*clk = clk.Add(time.Hour)
+	if n, _ := relay.RelayOnce(context.Background()); n != 0 {
+		t.Fatalf("dead message was relayed")
+	}
+}
+
+// 配信は Tx の外で行い、取り出しと 1 件ごとの記録はそれぞれ別の短い Tx で行う
+func TestOutboxRelay_publishesOutsideTx(t *testing.T) {
+	now := time.Date(2025, 9, 27, 7, 0, 0, 0, time.UTC)
+	outbox := newMemOutbox()
+	for _, id := range []string{"e1", "e2", "e3"} {
+		_ = outbox.Enqueue(context.Background(), &domain.OutboxMessage{ID: id, Type: "T-" + id, CreatedAt: now})
+	}
+	open, calls := 0, 0
+	pub := &recPublisher{open: &open}
+	relay := &usecase.OutboxRelay{Outbox: outbox, Publisher: pub, Tx: countTx{open: &open, calls: &calls}, Clock: fixedClock{t: now}}
+
+	if n, err := relay.RelayOnce(context.Background()); err != nil || n != 3 {
+		t.Fatalf("RelayOnce = %d, %v; want 3, nil", n, err)
+	}
+	if want := []int{0, 0, 0}; !slices.Equal(pub.inTxAt, want) {
+		t.Fatalf("open txs while publishing = %v; want %v", pub.inTxAt, want)
+	}
+	if calls != 4 {
+		t.Fatalf("tx calls = %d; want 4 (claim + one per message)", calls)
+	}
+}
+
+// 結果を記録できなかったメッセージは、リースが切れるまで他の relay に渡さず、切れたら再配信する
+func TestOutboxRelay_leaseExpiresThenRedelivers(t *testing.T) {
+	now := time.Date(2025, 9, 27, 7, 0, 0, 0, time.UTC)
+	outbox := newMemOutbox()
+	_ = outbox.Enqueue(context.Background(), &domain.OutboxMessage{ID: "e1", Type: "T", CreatedAt: now})
+	outbox.markErr = errors.New("db down")
+
+	pub := &recPublisher{}
+	clk := &fixedClock{t: now}
+	newRelay := func() *usecase.OutboxRelay {
+		return &usecase.OutboxRelay{Outbox: outbox, Publisher: pub, Tx: nopTx{}, Clock: *clk, Lease: time.Minute}
+	}
+
+	if _, err := newRelay().RelayOnce(context.Background()); err == nil {
+		t.Fatalf("RelayOnce err = nil; want the mark error")
+	}
+	outbox.markErr = nil
+
+	// リース中は別の relay も取り出さない
+	clk.t = now.Add(30 * time.Second)
+	if n, err := newRelay().RelayOnce(context.Background()); err != nil || n != 0 {
+		t.Fatalf("RelayOnce during lease = %d, %v; want 0, nil", n, err)
+	}
+
+	clk.t = now.Add(2 * time.Minute)
+	if n, err := newRelay().RelayOnce(context.Background()); err != nil || n != 1 {
+		t.Fatalf("RelayOnce after lease = %d, %v; want 1, nil", n, err)
+	}
+	if want := []string{"T", "T"}; !slices.Equal(pub.got, want) {
+		t.Fatalf("published = %v; want %v (at-least-once)", pub.got, want)
+	}
+	if !outbox.done["e1"] || outbox.ms[0].Attempts != 0 {
+		t.Fatalf("done = %v attempts = %d; want delivered without a failed attempt", outbox.done["e1"], outbox.ms[0].Attempts)
+	}
+}
@@ -0,0 +1,20 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+)
+
+// MultiPublisher は複数の配信先に順に配信する。
+// 途中で失敗すると relay が全体を再送するので、各配信先は重複に耐えること
+type MultiPublisher []domain.EventPublisher
+
+func (ps MultiPublisher) Publish(ctx context.Context, m *domain.OutboxMessage) error {
+	for _, p := range ps {
+		if err := p.Publish(ctx, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
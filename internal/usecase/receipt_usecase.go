@@ -0,0 +1,83 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/merchant"
+	"github.com/kazshi01/payment-system/internal/domain/order"
+	"github.com/kazshi01/payment-system/internal/domain/receipt"
+)
+
+// ReceiptUsecase は支払い済みの注文の領収書を発行する
+type ReceiptUsecase struct {
+	Orders    *OrderUsecase // 注文の読み込みと所有者チェック
+	Receipts  domain.ReceiptRepository
+	Merchants domain.MerchantSettingsRepository // 発行者の情報
+	Renderer  domain.ReceiptRenderer
+	Tx        domain.Tx
+	Clock     Clock
+}
+
+// IssueReceipt は領収書の PDF を返す。初回のダウンロードで番号を振り、2 回目以降は「再発行」になる。
+// 発行者（注文の所有ユーザ）の名称が未設定なら ErrConflict
+func (uc *ReceiptUsecase) IssueReceipt(ctx context.Context, id order.ID) ([]byte, *receipt.Receipt, error) {
+	// 所有者チェックを兼ねて明細・内訳ごと読み込む
+	o, err := uc.Orders.GetOrder(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if o.Status != order.StatusPaid {
+		return nil, nil, fmt.Errorf("%w: order is %s; receipts are issued for %s orders", domain.ErrConflict, o.Status, order.StatusPaid)
+	}
+
+	payments, err := uc.Orders.ListPayments(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	paidAt := o.UpdatedAt
+	if n := len(payments); n > 0 {
+		paidAt = payments[n-1].CreatedAt
+	}
+
+	// ---- DB 反映は 3s ----
+	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	ms, err := uc.Merchants.Get(dbCtx, o.UserID)
+	if errors.Is(err, domain.ErrNotFound) {
+		ms, err = merchant.Default(o.UserID), nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if ms.Issuer.Name == "" {
+		return nil, nil, fmt.Errorf("%w: issuer name is not set in merchant settings", domain.ErrConflict)
+	}
+
+	// 描画に失敗したら番号もダウンロード回数も戻す
+	var (
+		pdf []byte
+		rc  *receipt.Receipt
+	)
+	err = uc.Tx.Do(dbCtx, func(ctx context.Context) error {
+		var err error
+		if rc, err = uc.Receipts.Issue(ctx, o.ID, o.UserID, uc.Clock.Now()); err != nil {
+			return err
+		}
+		pdf, err = uc.Renderer.Render(&receipt.Document{
+			Receipt: *rc,
+			Issuer:  ms.Issuer,
+			Order:   o,
+			PaidAt:  paidAt,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return pdf, rc, nil
+}
@@ -0,0 +1,160 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/merchant"
+	"github.com/kazshi01/payment-system/internal/domain/order"
+	"github.com/kazshi01/payment-system/internal/domain/receipt"
+	"github.com/kazshi01/payment-system/internal/domain/tax"
+	"github.com/kazshi01/payment-system/internal/usecase"
+)
+
+type memReceiptRepo struct {
+	m    map[order.ID]receipt.Receipt
+	last map[string]int64
+}
+
+func newMemReceiptRepo() *memReceiptRepo {
+	return &memReceiptRepo{m: map[order.ID]receipt.Receipt{}, last: map[string]int64{}}
+}
+
+func (r *memReceiptRepo) Issue(ctx context.Context, id order.ID, issuerID string, at time.Time) (*receipt.Receipt, error) {
+	rc, ok := r.m[id]
+	if ok {
+		rc.Downloads++
+	} else {
+		r.last[issuerID]++
+		rc = receipt.Receipt{OrderID: id, IssuerID: issuerID, Number: r.last[issuerID], IssuedAt: at, Downloads: 1}
+	}
+	r.m[id] = rc
+	return &rc, nil
+}
+
+type stubRenderer struct{ docs []receipt.Document }
+
+func (s *stubRenderer) Render(d *receipt.Document) ([]byte, error) {
+	s.docs = append(s.docs, *d)
+	return []byte("%PDF-1.7"), nil
+}
+
+func TestReceiptUsecase_IssueReceipt(t *testing.T) {
+	n := 0
+	now := time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC)
+	merchants := &memMerchantRepo{m: map[string]merchant.Settings{}}
+	orders := &usecase.OrderUsecase{
+		Repo:      newMemRepo(),
+		Items:     newMemItemRepo(),
+		Taxes:     newMemTaxRepo(),
+		Payments:  newMemPaymentRepo(),
+		Events:    newMemEventRepo(),
+		Merchants: merchants,
+		Tx:        nopTx{},
+		PG:        okPG{txid: "tx-1"},
+		Clock:     fixedClock{t: now},
+		IDGen:     seqIDGen{n: &n},
+		Locker:    okLocker{},
+	}
+	renderer := &stubRenderer{}
+	uc := &usecase.ReceiptUsecase{
+		Orders:    orders,
+		Receipts:  newMemReceiptRepo(),
+		Merchants: merchants,
+		Renderer:  renderer,
+		Tx:        nopTx{},
+		Clock:     fixedClock{t: now},
+	}
+
+	ctx := ctxWithUser("user-1")
+	o, err := orders.CreateOrder(ctx, usecase.CreateOrderInput{Items: []order.Item{
+		{SKU: "tea", Name: "お茶", UnitPrice: jpy(150), Quantity: 2, TaxRate: tax.RateReduced},
+	}})
+	if err != nil {
+		t.Fatalf("CreateOrder err = %v", err)
+	}
+
+	// 未払いの注文には発行しない
+	if _, _, err := uc.IssueReceipt(ctx, o.ID); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("IssueReceipt(PENDING) err = %v; want ErrConflict", err)
+	}
+	if err := orders.PayOrder(ctx, o.ID, "pm_card_visa"); err != nil {
+		t.Fatalf("PayOrder err = %v", err)
+	}
+
+	// 発行者の名称が未設定
+	if _, _, err := uc.IssueReceipt(ctx, o.ID); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("IssueReceipt without issuer err = %v; want ErrConflict", err)
+	}
+	issuer := merchant.Issuer{Name: "株式会社サンプル", RegistrationNumber: "T1234567890123"}
+	merchants.m["user-1"] = merchant.Settings{UserID: "user-1", TaxRounding: tax.RoundingFloor, Issuer: issuer}
+
+	_, first, err := uc.IssueReceipt(ctx, o.ID)
+	if err != nil {
+		t.Fatalf("IssueReceipt err = %v", err)
+	}
+	if first.Number != 1 || first.Reissued() {
+		t.Fatalf("first receipt = %+v; want number 1, not reissued", first)
+	}
+	doc := renderer.docs[0]
+	if doc.Issuer != issuer || doc.Order.Amount != jpy(324) || len(doc.Order.Tax) != 1 || len(doc.Order.Items) != 1 {
+		t.Fatalf("document = %+v; want issuer, items and tax of the order", doc)
+	}
+
+	_, again, err := uc.IssueReceipt(ctx, o.ID)
+	if err != nil {
+		t.Fatalf("IssueReceipt (again) err = %v", err)
+	}
+	if again.Number != 1 || !again.Reissued() || !renderer.docs[1].Receipt.Reissued() {
+		t.Fatalf("second receipt = %+v; want same number, reissued", again)
+	}
+
+	// 他人の注文は見えない
+	if _, _, err := uc.IssueReceipt(ctxWithUser("user-2"), o.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("IssueReceipt(other user) err = %v; want ErrNotFound", err)
+	}
+
+	// 同じ発行者の次の注文は次の番号
+	o2, _ := orders.CreateOrder(ctx, usecase.CreateOrderInput{Amount: jpy(1000)})
+	if err := orders.PayOrder(ctx, o2.ID, "pm_card_visa"); err != nil {
+		t.Fatalf("PayOrder err = %v", err)
+	}
+	if _, rc, err := uc.IssueReceipt(ctx, o2.ID); err != nil || rc.Number != 2 {
+		t.Fatalf("IssueReceipt(order 2) = %+v, %v; want number 2", rc, err)
+	}
+}
+
+func TestMerchantSettingsUsecase_UpdateSettings(t *testing.T) {
+	uc := &usecase.MerchantSettingsUsecase{
+		Repo:  &memMerchantRepo{m: map[string]merchant.Settings{}},
+		Clock: fixedClock{t: time.Now()},
+	}
+	ctx := ctxWithUser("user-1")
+	str := func(s string) *string { return &s }
+
+	if _, err := uc.UpdateSettings(ctx, usecase.UpdateSettingsInput{TaxRounding: str("ceil")}); err != nil {
+		t.Fatalf("UpdateSettings err = %v", err)
+	}
+	got, err := uc.UpdateSettings(ctx, usecase.UpdateSettingsInput{
+		IssuerName:         str(" 株式会社サンプル "),
+		RegistrationNumber: str("t1234567890123"),
+	})
+	if err != nil {
+		t.Fatalf("UpdateSettings err = %v", err)
+	}
+	// 指定しなかった端数処理は変わらない
+	want := merchant.Issuer{Name: "株式会社サンプル", RegistrationNumber: "T1234567890123"}
+	if got.TaxRounding != tax.RoundingCeil || got.Issuer != want {
+		t.Fatalf("settings = %+v; want ceil and %+v", got, want)
+	}
+
+	for _, num := range []string{"1234567890123", "T123456789012", "T12345678901234", "TABCDEFGHIJKLM"} {
+		_, err := uc.UpdateSettings(ctx, usecase.UpdateSettingsInput{RegistrationNumber: str(num)})
+		if !errors.Is(err, domain.ErrInvalidArgument) || !errors.Is(err, merchant.ErrInvalidRegistrationNumber) {
+			t.Fatalf("UpdateSettings(%q) err = %v; want ErrInvalidRegistrationNumber", num, err)
+		}
+	}
+}
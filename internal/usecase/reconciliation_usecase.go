@@ -0,0 +1,112 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/auth"
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/payment"
+	"github.com/kazshi01/payment-system/internal/domain/settlement"
+)
+
+// ReconciliationUsecase は PG の精算ファイルと payments の突き合わせ。
+// 突き合わせは cmd/reconcile が行い、API は結果の参照のみ（管理者）
+type ReconciliationUsecase struct {
+	Payments domain.PaymentRepository
+	Runs     domain.ReconciliationRunRepository
+	Clock    Clock
+	IDGen    IDGen
+}
+
+// ReconcileInput は突き合わせ 1 回分の入力。[From, To) に作成した自社の決済と比べる
+type ReconcileInput struct {
+	Provider string
+	Source   string // 精算ファイル名（記録用）
+	From     time.Time
+	To       time.Time
+	Rows     []settlement.Row
+}
+
+// Reconcile は精算ファイルの行を provider_tx_id で payments と突き合わせ、結果を記録する。
+// 運用コマンドから呼ぶので認可は見ない。タイムアウトは ctx に任せる
+func (uc *ReconciliationUsecase) Reconcile(ctx context.Context, in ReconcileInput) (*settlement.Run, error) {
+	if in.Provider == "" {
+		return nil, fmt.Errorf("%w: provider is required", domain.ErrInvalidArgument)
+	}
+	if !in.From.Before(in.To) {
+		return nil, fmt.Errorf("%w: from must be before to", domain.ErrInvalidArgument)
+	}
+
+	ours, err := uc.Payments.ListByProvider(ctx, in.Provider, in.From, in.To)
+	if err != nil {
+		return nil, err
+	}
+	// 期間外の決済が精算されていることもあるので、見つからない取引は個別に探す
+	lookup := func(txID string) (*payment.Payment, error) {
+		p, err := uc.Payments.FindByProviderTxID(ctx, in.Provider, txID)
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, nil
+		}
+		return p, err
+	}
+	items, sum, err := settlement.Reconcile(in.Rows, ours, lookup)
+	if err != nil {
+		return nil, err
+	}
+
+	run := &settlement.Run{
+		ID:        uc.IDGen.New(),
+		Provider:  in.Provider,
+		Source:    in.Source,
+		From:      in.From,
+		To:        in.To,
+		Summary:   sum,
+		Items:     items,
+		CreatedAt: uc.Clock.Now(),
+	}
+	if err := uc.Runs.Create(ctx, run); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// GetRun は取引ごとの結果を含めて返す（管理者のみ）
+func (uc *ReconciliationUsecase) GetRun(ctx context.Context, id string) (*settlement.Run, error) {
+	if !auth.IsAdmin(ctx) {
+		if _, ok := auth.UserIDFrom(ctx); !ok {
+			return nil, domain.ErrUnauthorized
+		}
+		return nil, domain.ErrForbidden
+	}
+
+	// ---- DB 読み取りは 3s ----
+	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	return uc.Runs.FindByID(dbCtx, id)
+}
+
+// ListRuns は新しい順に件数だけを返す（管理者のみ）。limit が 0 なら既定値
+func (uc *ReconciliationUsecase) ListRuns(ctx context.Context, limit int) ([]*settlement.Run, error) {
+	if !auth.IsAdmin(ctx) {
+		if _, ok := auth.UserIDFrom(ctx); !ok {
+			return nil, domain.ErrUnauthorized
+		}
+		return nil, domain.ErrForbidden
+	}
+	if limit == 0 {
+		limit = defaultListLimit
+	}
+	if limit < 0 || limit > maxListLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", domain.ErrInvalidArgument, maxListLimit)
+	}
+
+	// ---- DB 読み取りは 3s ----
+	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	return uc.Runs.List(dbCtx, limit)
+}
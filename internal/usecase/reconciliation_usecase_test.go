@@ -0,0 +1,89 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+	"github.com/kazshi01/payment-system/internal/domain/payment"
+	"github.com/kazshi01/payment-system/internal/domain/settlement"
+	"github.com/kazshi01/payment-system/internal/usecase"
+)
+
+type memRunRepo struct{ runs []*settlement.Run }
+
+func (r *memRunRepo) Create(ctx context.Context, run *settlement.Run) error {
+	r.runs = append(r.runs, run)
+	return nil
+}
+
+func (r *memRunRepo) FindByID(ctx context.Context, id string) (*settlement.Run, error) {
+	for _, run := range r.runs {
+		if run.ID == id {
+			return run, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (r *memRunRepo) List(ctx context.Context, limit int) ([]*settlement.Run, error) {
+	return r.runs, nil
+}
+
+func TestReconciliationUsecase_Reconcile(t *testing.T) {
+	day := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	payments := newMemPaymentRepo()
+	for _, p := range []*payment.Payment{
+		{ID: "p0", OrderID: "o0", Provider: "stripe", TxID: "pi_0", Amount: jpy(300), CreatedAt: day.Add(-time.Hour)}, // 前日
+		{ID: "p1", OrderID: "o1", Provider: "stripe", TxID: "pi_1", Amount: jpy(1200), CreatedAt: day.Add(time.Hour)},
+		{ID: "p2", OrderID: "o2", Provider: "stripe", TxID: "pi_2", Amount: jpy(500), CreatedAt: day.Add(2 * time.Hour)},
+		{ID: "p3", OrderID: "o3", Provider: "nop", TxID: "pi_3", Amount: jpy(800), CreatedAt: day.Add(3 * time.Hour)}, // 別の PG
+	} {
+		_ = payments.Create(context.Background(), p)
+	}
+	runs := &memRunRepo{}
+	n := 0
+	uc := &usecase.ReconciliationUsecase{
+		Payments: payments,
+		Runs:     runs,
+		Clock:    fixedClock{t: day.Add(48 * time.Hour)},
+		IDGen:    seqIDGen{n: &n},
+	}
+
+	run, err := uc.Reconcile(context.Background(), usecase.ReconcileInput{
+		Provider: "stripe",
+		Source:   "settlement-2026-10-01.csv",
+		From:     day,
+		To:       day.Add(24 * time.Hour),
+		Rows: []settlement.Row{
+			{Line: 2, TxID: "pi_0", Amount: jpy(300)},
+			{Line: 3, TxID: "pi_1", Amount: jpy(1000)},
+			{Line: 4, TxID: "pi_3", Amount: jpy(800)},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Reconcile err = %v", err)
+	}
+	// pi_0 は期間外でも一致、pi_3 は別の PG の決済なので PG 側にしかない扱い
+	want := settlement.Summary{Matched: 1, AmountMismatch: 1, MissingInOurs: 1, MissingInTheirs: 1}
+	if run.Summary != want || len(runs.runs) != 1 || run.Source != "settlement-2026-10-01.csv" {
+		t.Fatalf("run = %+v; want summary %+v, recorded", run, want)
+	}
+
+	if _, err := uc.Reconcile(context.Background(), usecase.ReconcileInput{Provider: "stripe", From: day, To: day}); !errors.Is(err, domain.ErrInvalidArgument) {
+		t.Fatalf("Reconcile(empty period) err = %v; want ErrInvalidArgument", err)
+	}
+
+	// 結果の参照は管理者のみ
+	if _, err := uc.GetRun(ctxWithUser("user-1"), run.ID); !errors.Is(err, domain.ErrForbidden) {
+		t.Fatalf("GetRun(user) err = %v; want ErrForbidden", err)
+	}
+	if got, err := uc.GetRun(ctxWithAdmin("admin-1"), run.ID); err != nil || len(got.Items) != 4 {
+		t.Fatalf("GetRun = %+v, %v; want 4 items", got, err)
+	}
+	if _, err := uc.ListRuns(ctxWithAdmin("admin-1"), 101); !errors.Is(err, domain.ErrInvalidArgument) {
+		t.Fatalf("ListRuns(101) err = %v; want ErrInvalidArgument", err)
+	}
+}
@@ -0,0 +1,52 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"github.com/kazshi01/payment-system/internal/domain"
+)
+
+// WebhookUsecase は PG からの Webhook をイベントID で重複排除して注文に反映する
+type WebhookUsecase struct {
+	Orders *OrderUsecase
+	Inbox  domain.WebhookInbox
+	Clock  Clock
+}
+
+// HandleProviderEvent は署名検証済みのイベントを処理する。
+// 処理済みのイベントなら duplicate=true を返し、何もしない。
+// エラー時は未処理のまま残るため、プロバイダの再送で再試行される
+func (uc *WebhookUsecase) HandleProviderEvent(ctx context.Context, ev *domain.ProviderEvent) (duplicate bool, err error) {
+	if ev.Provider == "" || ev.ID == "" {
+		return false, domain.ErrInvalidArgument
+	}
+
+	processed, err := uc.Inbox.Receive(ctx, ev, uc.Clock.Now())
+	if err != nil {
+		return false, err
+	}
+	if processed {
+		return true, nil
+	}
+
+	// 処理済みの記録は注文への反映と同じ Tx で行う（片方だけ残ると再送で二重に反映される）。
+	// 同じイベントが同時に届いた場合に備え、注文のロックを取った後でもう一度確かめる
+	err = uc.Orders.applyProviderEvent(ctx, ev, func(ctx context.Context) error {
+		processed, err := uc.Inbox.Receive(ctx, ev, uc.Clock.Now())
+		if err != nil {
+			return err
+		}
+		if processed {
+			return errEventProcessed
+		}
+		return uc.Inbox.MarkProcessed(ctx, ev.Provider, ev.ID, uc.Clock.Now())
+	})
+	if errors.Is(err, errEventProcessed) {
+		return true, nil
+	}
+	return false, err
+}
+
+// 先に届いた同じイベントが処理済みにした（Tx を戻して何もしない）
+var errEventProcessed = errors.New("webhook event already processed")